@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+)
+
+// GitHubWorkspaceEnvVar is the directory GitHub Actions checks repositories out to, annotation paths are relative to it
+const GitHubWorkspaceEnvVar = "GITHUB_WORKSPACE"
+
+type annotationsKey struct{}
+
+// WithAnnotations returns a context that writes the annotations of invalid workflows to w
+//
+// Workflows are fetched outside of any step, so there is no step output to write them to.
+// Without it, invalid workflows are not annotated
+func WithAnnotations(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, annotationsKey{}, w)
+}
+
+// annotationWriter returns the writer set by WithAnnotations, or nil if there is none
+func annotationWriter(ctx context.Context) io.Writer {
+	w, _ := ctx.Value(annotationsKey{}).(io.Writer)
+	return w
+}
+
+// annotatedError is an error that has already been surfaced as a GitHub Actions annotation
+type annotatedError struct {
+	error
+}
+
+// Unwrap returns the underlying error
+func (e *annotatedError) Unwrap() error {
+	return e.error
+}
+
+// annotate emits a GitHub Actions workflow command for err, so it surfaces inline in the Checks UI
+//
+// path is a YAML path (e.g. $.tasks.build.steps[0]) within the workflow at origin, resolved to a file and line when the workflow is local
+//
+// https://docs.github.com/en/actions/reference/workflows-and-actions/workflow-commands#setting-an-error-message
+func annotate(ctx context.Context, w io.Writer, level string, origin *url.URL, path, title string, err error) error {
+	if !isGitHubActions() || w == nil || err == nil {
+		return err
+	}
+
+	props := []string{}
+	if file, line := sourceLocation(origin, path); file != "" {
+		props = append(props, "file="+escapeAnnotationProperty(file))
+		if line > 0 {
+			props = append(props, fmt.Sprintf("line=%d", line))
+		}
+	}
+	if title != "" {
+		props = append(props, "title="+escapeAnnotationProperty(title))
+	}
+
+	msg := secretsFromContext(ctx).Mask(err.Error())
+	if len(props) > 0 {
+		_, _ = fmt.Fprintf(w, "::%s %s::%s\n", level, strings.Join(props, ","), escapeAnnotationData(msg))
+	} else {
+		_, _ = fmt.Fprintf(w, "::%s::%s\n", level, escapeAnnotationData(msg))
+	}
+
+	return &annotatedError{err}
+}
+
+// isAnnotated reports whether err, or any error it wraps, has already been annotated
+func isAnnotated(err error) bool {
+	var aErr *annotatedError
+	return errors.As(err, &aErr)
+}
+
+// sourceLocation returns the path of a local workflow relative to the GitHub workspace, and the line of a YAML path within it
+//
+// Remote workflows have no file, and the line is 0 if the path could not be found
+func sourceLocation(origin *url.URL, path string) (string, int) {
+	if origin == nil || origin.Scheme != "file" {
+		return "", 0
+	}
+
+	file := origin.Opaque
+	if file == "" {
+		file = origin.Path
+	}
+
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return "", 0
+	}
+
+	if ws := os.Getenv(GitHubWorkspaceEnvVar); ws != "" {
+		if abs, err := filepath.Abs(file); err == nil {
+			if rel, err := filepath.Rel(ws, abs); err == nil && filepath.IsLocal(rel) {
+				file = rel
+			}
+		}
+	}
+	file = filepath.ToSlash(file)
+
+	if path == "" {
+		return file, 0
+	}
+	p, err := yaml.PathString(path)
+	if err != nil {
+		return file, 0
+	}
+	node, err := p.ReadNode(bytes.NewReader(src))
+	if err != nil || node == nil {
+		return file, 0
+	}
+	// the token of a mapping is the separator of its last key, so use its first key instead
+	if m, ok := node.(*ast.MappingNode); ok && len(m.Values) > 0 {
+		node = m.Values[0].Key
+	}
+	if node.GetToken() == nil {
+		return file, 0
+	}
+	return file, node.GetToken().Position.Line
+}
+
+var (
+	validationStepPath = regexp.MustCompile(`^\.tasks\.([\w-]+)\[(\d+)\]((?:\.[\w-]+)?)`)
+	validationPath     = regexp.MustCompile(`^(?:\.[\w-]+)+`)
+)
+
+// validationErrorPath converts the location at the start of a validation error (e.g. .tasks.build[0].uses) to a YAML path
+func validationErrorPath(err error) string {
+	msg := err.Error()
+	if m := validationStepPath.FindStringSubmatch(msg); m != nil {
+		return fmt.Sprintf("$.tasks.%s.steps[%s]%s", m[1], m[2], m[3])
+	}
+	if m := validationPath.FindString(msg); m != "" {
+		return "$" + m
+	}
+	return ""
+}
+
+// escapeAnnotationData escapes the message of a workflow command
+func escapeAnnotationData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeAnnotationProperty escapes a property value of a workflow command
+func escapeAnnotationProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
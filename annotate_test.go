@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+func TestAnnotate(t *testing.T) {
+	// reset state of the check to be "blank" after tests are done, this function must EXACTLY match its counterpart
+	t.Cleanup(func() {
+		isGitHubActions = sync.OnceValue(func() bool {
+			return os.Getenv(GitHubActionsEnvVar) == "true"
+		})
+	})
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv(GitHubWorkspaceEnvVar, dir)
+	require.NoError(t, os.WriteFile("tasks.yaml", []byte(`schema-version: v1
+tasks:
+  default:
+    steps:
+      - run: exit 1
+`), 0o644))
+
+	origin := &url.URL{Scheme: "file", Opaque: "tasks.yaml"}
+	ctx := WithSecrets(t.Context(), Secrets{"token": "hunter2"})
+	boom := errors.New("bad token hunter2\n100% failed")
+
+	t.Run("outside of GitHub Actions", func(t *testing.T) {
+		isGitHubActions = func() bool { return false }
+
+		var buf strings.Builder
+		assert.Same(t, boom, annotate(ctx, &buf, "error", origin, "$.tasks.default.steps[0]", "default[0]", boom))
+		assert.Empty(t, buf.String())
+	})
+
+	isGitHubActions = func() bool { return true }
+
+	t.Run("step", func(t *testing.T) {
+		var buf strings.Builder
+		err := annotate(ctx, &buf, "error", origin, "$.tasks.default.steps[0]", "default[0]", boom)
+		require.ErrorIs(t, err, boom)
+		assert.True(t, isAnnotated(err))
+		assert.Equal(t, "::error file=tasks.yaml,line=5,title=default[0]::bad token ***%0A100%25 failed\n", buf.String())
+	})
+
+	t.Run("remote", func(t *testing.T) {
+		var buf strings.Builder
+		_ = annotate(ctx, &buf, "warning", &url.URL{Scheme: "https", Host: "example.com", Path: "/tasks.yaml"}, "$.tasks.default", "", boom)
+		assert.Equal(t, "::warning::bad token ***%0A100%25 failed\n", buf.String())
+	})
+
+	t.Run("invalid workflow", func(t *testing.T) {
+		require.NoError(t, os.WriteFile("invalid.yaml", []byte(`schema-version: v1
+tasks:
+  default:
+    steps:
+      - uses: missing
+`), 0o644))
+		svc, err := uses.NewFetcherService()
+		require.NoError(t, err)
+		invalid := &url.URL{Scheme: "file", Opaque: "invalid.yaml"}
+
+		// only annotated to the writer set on the context
+		_, err = Fetch(ctx, svc, invalid)
+		require.Error(t, err)
+		assert.False(t, isAnnotated(err))
+
+		var buf strings.Builder
+		_, err = Fetch(WithAnnotations(ctx, &buf), svc, invalid)
+		require.Error(t, err)
+		assert.True(t, isAnnotated(err))
+		assert.Equal(t, "::error file=invalid.yaml,line=5,title=invalid workflow::.tasks.default[0].uses \"missing\" not found\n", buf.String())
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		var buf strings.Builder
+		assert.NoError(t, annotate(ctx, &buf, "error", origin, "", "", nil))
+		assert.Equal(t, boom, annotate(ctx, nil, "error", origin, "", "", boom))
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestIsAnnotated(t *testing.T) {
+	err := errors.New("boom")
+	assert.False(t, isAnnotated(err))
+	assert.False(t, isAnnotated(nil))
+	assert.True(t, isAnnotated(&annotatedError{err}))
+	assert.True(t, isAnnotated(addTrace(&annotatedError{err}, "at default[0] (file:tasks.yaml)")))
+	assert.True(t, isAnnotated(fmt.Errorf("wrapped: %w", &annotatedError{err})))
+}
+
+func TestSourceLocation(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv(GitHubWorkspaceEnvVar, "")
+
+	require.NoError(t, os.MkdirAll("lib", 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join("lib", "tasks.yaml"), []byte(`schema-version: v1
+tasks:
+  build:
+    inputs:
+      name:
+        description: A name
+    steps:
+      - run: echo "building"
+      - uses: test
+        with:
+          name: ${{ input "name" }}
+`), 0o644))
+
+	origin := &url.URL{Scheme: "file", Opaque: "lib/tasks.yaml"}
+
+	testCases := []struct {
+		name         string
+		origin       *url.URL
+		path         string
+		expectedFile string
+		expectedLine int
+	}{
+		{"step", origin, "$.tasks.build.steps[0]", "lib/tasks.yaml", 8},
+		{"step field", origin, "$.tasks.build.steps[1].with", "lib/tasks.yaml", 11},
+		{"inputs", origin, "$.tasks.build.inputs", "lib/tasks.yaml", 5},
+		{"missing path", origin, "$.tasks.test", "lib/tasks.yaml", 0},
+		{"invalid path", origin, "tasks", "lib/tasks.yaml", 0},
+		{"no path", origin, "", "lib/tasks.yaml", 0},
+		{"absolute", &url.URL{Scheme: "file", Path: filepath.Join(dir, "lib", "tasks.yaml")}, "$.tasks.build", filepath.ToSlash(filepath.Join(dir, "lib", "tasks.yaml")), 4},
+		{"missing file", &url.URL{Scheme: "file", Opaque: "missing.yaml"}, "$.tasks.build", "", 0},
+		{"remote", &url.URL{Scheme: "https", Host: "example.com", Path: "/tasks.yaml"}, "$.tasks.build", "", 0},
+		{"nil", nil, "$.tasks.build", "", 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			file, line := sourceLocation(tc.origin, tc.path)
+			assert.Equal(t, tc.expectedFile, file)
+			assert.Equal(t, tc.expectedLine, line)
+		})
+	}
+
+	t.Run("relative to workspace", func(t *testing.T) {
+		t.Setenv(GitHubWorkspaceEnvVar, filepath.Join(dir, "lib"))
+		file, line := sourceLocation(origin, "$.tasks.build.steps[1]")
+		assert.Equal(t, "tasks.yaml", file)
+		assert.Equal(t, 9, line)
+	})
+
+	t.Run("outside of workspace", func(t *testing.T) {
+		t.Setenv(GitHubWorkspaceEnvVar, filepath.Join(dir, "other"))
+		file, _ := sourceLocation(origin, "")
+		assert.Equal(t, "lib/tasks.yaml", file)
+	})
+}
+
+func TestValidationErrorPath(t *testing.T) {
+	testCases := []struct {
+		err      string
+		expected string
+	}{
+		{".tasks.build[0] has both run and uses fields set", "$.tasks.build.steps[0]"},
+		{".tasks.build[2].uses \"tset\" not found, did you mean \"test\"?", "$.tasks.build.steps[2].uses"},
+		{".tasks.build-all.mutex \"a b\" does not satisfy \"^[_a-zA-Z][a-zA-Z0-9_-]*$\"", "$.tasks.build-all.mutex"},
+		{".aliases.gh cannot be an absolute path: /tmp", "$.aliases.gh"},
+		{".inputs.name: error parsing regexp", "$.inputs.name"},
+		{"no tasks available", ""},
+		{"task name \"1\" does not satisfy \"^[_a-zA-Z][a-zA-Z0-9_-]*$\"", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.err, func(t *testing.T) {
+			assert.Equal(t, tc.expected, validationErrorPath(errors.New(tc.err)))
+		})
+	}
+}
+
+func TestEscapeAnnotation(t *testing.T) {
+	assert.Equal(t, "100%25 done%0D%0Anext: a,b", escapeAnnotationData("100% done\r\nnext: a,b"))
+	assert.Equal(t, "100%25 done%0D%0Anext%3A a%2Cb", escapeAnnotationProperty("100% done\r\nnext: a,b"))
+}
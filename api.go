@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"cmp"
+	"context"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+
+	"github.com/defenseunicorns/maru2/schema"
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+// APIVersion is the version of the machine readable responses returned by `maru2 api`
+//
+// Fields may be added within a version, but are never removed or changed
+const APIVersion = "v0"
+
+// APIResponse is the envelope for every response returned by `maru2 api`
+//
+// Exactly one of the command specific fields is set on success, Error is set on failure
+type APIResponse struct {
+	// Version of the API response schema
+	APIVersion string `json:"api-version"`
+	// Command that generated the response
+	Command string `json:"command"`
+	// Resolved location of the workflow
+	Location string `json:"location,omitempty"`
+	// Error message if the command failed
+	Error string `json:"error,omitempty"`
+	// Tasks in the workflow, in the same order as `maru2 --list`
+	List []APITask `json:"list,omitempty"`
+	// The full workflow, migrated to the latest schema version
+	Describe *v1.Workflow `json:"describe,omitempty"`
+	// Validation results
+	Validate *APIValidation `json:"validate,omitempty"`
+	// Markdown explanation of the workflow or task(s), same as `maru2 --explain`
+	Explain string `json:"explain,omitempty"`
+}
+
+// APITask is a summary of a single task
+type APITask struct {
+	// Name of the task
+	Name string `json:"name"`
+	// Description of the task
+	Description string `json:"description,omitempty"`
+	// Whether the task is run when no task is specified
+	Default bool `json:"default,omitempty"`
+	// Input parameters of the task
+	Inputs v1.InputMap `json:"inputs,omitempty"`
+	// Alias the task is run through, empty for tasks in the workflow itself
+	Alias string `json:"alias,omitempty"`
+	// Task this task is a shorthand for, set by alias-of
+	AliasOf string `json:"alias-of,omitempty"`
+	// Message to display when the task is deprecated
+	DeprecatedMessage string `json:"deprecated-message,omitempty"`
+}
+
+// APIValidation is the result of validating a workflow
+type APIValidation struct {
+	// Whether the workflow is valid
+	Valid bool `json:"valid"`
+	// Validation errors, empty if valid
+	Errors []string `json:"errors,omitempty"`
+	// Unused inputs and unreachable tasks of a valid workflow, same as `maru2 lint`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// APIListTasks summarizes the tasks in a workflow
+func APIListTasks(wf v1.Workflow) []APITask {
+	tasks := make([]APITask, 0, len(wf.Tasks))
+	for name, task := range wf.Tasks.OrderedSeq() {
+		// an alias is listed with the inputs of the task it is an alias of
+		target, _ := wf.Tasks.Find(name)
+		tasks = append(tasks, APITask{
+			Name:              name,
+			Description:       cmp.Or(task.Description, target.Description),
+			Default:           name == schema.DefaultTaskName,
+			Inputs:            target.Inputs,
+			AliasOf:           task.AliasOf,
+			DeprecatedMessage: cmp.Or(task.DeprecatedMessage, target.DeprecatedMessage),
+		})
+	}
+	return tasks
+}
+
+// ListTasks summarizes the tasks in a workflow and the tasks of its local aliases, in the same order as `maru2 --list`
+//
+// Aliased tasks are named alias:task, the same as they are called
+func ListTasks(ctx context.Context, svc *uses.FetcherService, origin *url.URL, wf v1.Workflow) ([]APITask, error) {
+	tasks := APIListTasks(wf)
+
+	for name, alias := range wf.Aliases.OrderedSeq() {
+		if alias.Path == "" {
+			continue
+		}
+		next, err := svc.ResolveRelative(origin, "file:"+alias.Path, wf.Aliases)
+		if err != nil {
+			return nil, err
+		}
+		aliasedWF, err := Fetch(ctx, svc, next)
+		if err != nil {
+			return nil, err
+		}
+		for n, task := range aliasedWF.Tasks.OrderedSeq() {
+			tasks = append(tasks, APITask{
+				Name:              name + ":" + n,
+				Description:       task.Description,
+				Inputs:            task.Inputs,
+				Alias:             name,
+				DeprecatedMessage: task.DeprecatedMessage,
+			})
+		}
+	}
+
+	return tasks, nil
+}
+
+// APIValidateWorkflow reads and validates the workflow located at uri the same as ReadWorkflow, collecting every validation error
+func APIValidateWorkflow(ctx context.Context, svc *uses.FetcherService, r io.Reader, uri *url.URL) APIValidation {
+	wf, err := ReadWorkflow(ctx, svc, r, uri)
+	if err == nil {
+		return APIValidation{Valid: true, Warnings: v1.Lint(wf)}
+	}
+
+	var errs []string
+	for _, e := range flattenErrors(err) {
+		errs = append(errs, strings.TrimSpace(e.Error()))
+	}
+
+	return APIValidation{Valid: false, Errors: errs}
+}
+
+// APISchema generates the JSON schema for `maru2 api` responses
+func APISchema() *jsonschema.Schema {
+	reflector := jsonschema.Reflector{DoNotReference: true}
+	return reflector.Reflect(&APIResponse{})
+}
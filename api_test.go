@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+func TestAPIListTasks(t *testing.T) {
+	wf := v1.Workflow{
+		Tasks: v1.TaskMap{
+			"build": v1.Task{
+				Description: "Build it",
+				Inputs: v1.InputMap{
+					"name": v1.InputParameter{Description: "The name"},
+				},
+			},
+			"default": v1.Task{},
+			"alpha":   v1.Task{},
+			"b":       v1.Task{AliasOf: "build"},
+			"old":     v1.Task{DeprecatedMessage: "Use build"},
+		},
+	}
+
+	assert.Equal(t, []APITask{
+		{Name: "default", Default: true},
+		{Name: "alpha"},
+		{Name: "b", Description: "Build it", Inputs: v1.InputMap{"name": v1.InputParameter{Description: "The name"}}, AliasOf: "build"},
+		{Name: "build", Description: "Build it", Inputs: v1.InputMap{"name": v1.InputParameter{Description: "The name"}}},
+		{Name: "old", DeprecatedMessage: "Use build"},
+	}, APIListTasks(wf))
+
+	assert.Empty(t, APIListTasks(v1.Workflow{}))
+}
+
+func TestListTasks(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "lib/tasks.yaml", []byte(`schema-version: v1
+tasks:
+  setup:
+    description: Setup cluster
+    inputs:
+      ctx:
+        description: Context
+    steps:
+      - run: echo setup
+`), 0o644))
+
+	svc, err := uses.NewFetcherService(uses.WithFS(fs))
+	require.NoError(t, err)
+
+	wf := v1.Workflow{
+		Aliases: v1.AliasMap{
+			"lib":    v1.Alias{Path: "lib/tasks.yaml"},
+			"remote": v1.Alias{Type: "github"},
+		},
+		Tasks: v1.TaskMap{
+			"default": v1.Task{},
+			"build":   v1.Task{Description: "Build it"},
+		},
+	}
+
+	origin := &url.URL{Scheme: "file", Opaque: "tasks.yaml"}
+	ctx := log.WithContext(t.Context(), log.New(io.Discard))
+
+	tasks, err := ListTasks(ctx, svc, origin, wf)
+	require.NoError(t, err)
+	assert.Equal(t, []APITask{
+		{Name: "default", Default: true},
+		{Name: "build", Description: "Build it"},
+		{Name: "lib:setup", Description: "Setup cluster", Inputs: v1.InputMap{"ctx": v1.InputParameter{Description: "Context"}}, Alias: "lib"},
+	}, tasks)
+
+	wf.Aliases["missing"] = v1.Alias{Path: "missing.yaml"}
+	_, err = ListTasks(ctx, svc, origin, wf)
+	require.ErrorContains(t, err, "missing.yaml")
+}
+
+func TestAPIValidateWorkflow(t *testing.T) {
+	tests := []struct {
+		name     string
+		workflow string
+		expected APIValidation
+	}{
+		{
+			name: "valid",
+			workflow: `schema-version: v1
+tasks:
+  default:
+    steps:
+      - run: echo hello
+`,
+			expected: APIValidation{Valid: true},
+		},
+		{
+			name: "valid v0",
+			workflow: `schema-version: v0
+tasks:
+  default:
+    - run: echo hello
+`,
+			expected: APIValidation{Valid: true},
+		},
+		{
+			name: "valid with warnings",
+			workflow: `schema-version: v1
+tasks:
+  default:
+    inputs:
+      name:
+        description: Name to greet
+    steps:
+      - run: echo hello
+  helper:
+    steps:
+      - run: echo helper
+`,
+			expected: APIValidation{Valid: true, Warnings: []string{
+				".tasks.default.inputs.name is never referenced",
+				".tasks.helper is not used by the default task, a task with a description or a test",
+			}},
+		},
+		{
+			name: "invalid reference",
+			workflow: `schema-version: v1
+tasks:
+  default:
+    steps:
+      - uses: missing
+`,
+			expected: APIValidation{Errors: []string{`.tasks.default[0].uses "missing" not found`}},
+		},
+		{
+			name: "multiple schema errors",
+			workflow: `schema-version: v1
+tasks:
+  default:
+    steps:
+      - run: echo hello
+        shell: fish
+      - run: echo hello
+        shell: zsh
+`,
+			expected: APIValidation{Errors: []string{
+				`tasks.default.steps.0.shell: tasks.default.steps.0.shell must be one of the following: "sh", "bash", "pwsh", "powershell"`,
+				`tasks.default.steps.1.shell: tasks.default.steps.1.shell must be one of the following: "sh", "bash", "pwsh", "powershell"`,
+			}},
+		},
+		{
+			name:     "invalid yaml",
+			workflow: "tasks: [",
+			expected: APIValidation{Errors: []string{"[1:8] sequence end token ']' not found\n>  1 | tasks: [\n              ^"}},
+		},
+		{
+			name: "included tasks",
+			workflow: `schema-version: v1
+includes:
+  - path: ci.yaml
+    prefix: ci-
+tasks:
+  default:
+    steps:
+      - uses: ci-lint
+`,
+			expected: APIValidation{Valid: true},
+		},
+	}
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "ci.yaml", []byte(`schema-version: v1
+tasks:
+  lint:
+    steps:
+      - run: echo lint
+`), 0o644))
+	svc, err := uses.NewFetcherService(uses.WithFS(fs))
+	require.NoError(t, err)
+	ctx := log.WithContext(t.Context(), log.New(io.Discard))
+	origin := &url.URL{Scheme: "file", Opaque: "tasks.yaml"}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, APIValidateWorkflow(ctx, svc, strings.NewReader(tc.workflow), origin))
+		})
+	}
+}
+
+func TestAPISchema(t *testing.T) {
+	s := APISchema()
+	require.NotNil(t, s)
+
+	for _, prop := range []string{"api-version", "command", "location", "error", "list", "describe", "validate", "explain"} {
+		_, ok := s.Properties.Get(prop)
+		assert.True(t, ok, prop)
+	}
+	assert.Equal(t, []string{"api-version", "command"}, s.Required)
+}
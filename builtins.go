@@ -6,16 +6,84 @@ package maru2
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/charmbracelet/log"
 	"github.com/go-viper/mapstructure/v2"
+	"github.com/invopop/jsonschema"
 
 	"github.com/defenseunicorns/maru2/builtins"
 	"github.com/defenseunicorns/maru2/schema"
 	v1 "github.com/defenseunicorns/maru2/schema/v1"
 )
 
+// Builtin is the interface implemented by builtin: tasks, see builtins.Builtin
+type Builtin = builtins.Builtin
+
+// RegisterBuiltin registers impl to run as builtin:<name>, for embedders to add their own builtin tasks
+//
+// impl must be a pointer to a struct, every step runs a shallow copy of it with the step's with decoded into its fields.
+// Implement builtins.DryRunner to describe the builtin during a dry run. Registered builtins are included in
+// the schema returned by WorkflowSchema. Returns an error if name is already registered
+func RegisterBuiltin(name string, impl Builtin) error {
+	factory, err := copier(impl)
+	if err != nil {
+		return fmt.Errorf("builtin %q %w", name, err)
+	}
+	return builtins.Register(name, factory)
+}
+
+// copier returns a function that returns a shallow copy of impl
+func copier(impl Builtin) (func() builtins.Builtin, error) {
+	t := reflect.TypeOf(impl)
+	if t == nil || t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct || reflect.ValueOf(impl).IsNil() {
+		return nil, fmt.Errorf("must be a non-nil pointer to a struct, got %T", impl)
+	}
+
+	return func() builtins.Builtin {
+		clone := reflect.New(t.Elem())
+		clone.Elem().Set(reflect.ValueOf(impl).Elem())
+		return clone.Interface().(builtins.Builtin)
+	}, nil
+}
+
+// BuiltinDescription describes the inputs and outputs of a registered builtin
+type BuiltinDescription struct {
+	Name string `json:"name"`
+	// Whether the builtin describes what it would do during a dry run
+	DryRun bool `json:"dry-run"`
+	// JSON schema of with, derived from the builtin's struct tags
+	Inputs *jsonschema.Schema `json:"inputs"`
+	// JSON schema of the outputs, nil if the builtin does not describe them
+	Outputs *jsonschema.Schema `json:"outputs,omitempty"`
+}
+
+// DescribeBuiltin describes a registered builtin, returning false if it does not exist
+//
+// Unlike the workflow schema, inputs are described by their Go types and do not also accept template strings
+func DescribeBuiltin(name string) (BuiltinDescription, bool) {
+	b := builtins.Get(name)
+	if b == nil {
+		return BuiltinDescription{}, false
+	}
+
+	reflector := jsonschema.Reflector{DoNotReference: true}
+	schemaOf := func(v any) *jsonschema.Schema {
+		s := reflector.Reflect(v)
+		s.Version = ""
+		s.ID = jsonschema.EmptyID
+		return s
+	}
+
+	desc := BuiltinDescription{Name: name, Inputs: schemaOf(b)}
+	_, desc.DryRun = b.(builtins.DryRunner)
+	if od, ok := b.(builtins.OutputDescriber); ok {
+		desc.Outputs = schemaOf(od.Outputs())
+	}
+	return desc, true
+}
+
 // ExecuteBuiltin dispatches to registered builtin tasks (builtin:echo, builtin:fetch)
 //
 // Strips the "builtin:" prefix, renders templates in the With map,
@@ -29,8 +97,13 @@ func ExecuteBuiltin(ctx context.Context, step v1.Step, with schema.With, previou
 		return nil, fmt.Errorf("%s not found", step.Uses)
 	}
 
+	readCtx := ctx
+	ctx = builtins.WithFileReadCheck(ctx, func(path string) error {
+		return checkFileRead(readCtx, path)
+	})
+
 	var rendered schema.With
-	if with != nil {
+	if step.With != nil {
 		var err error
 		rendered, err = TemplateWithMap(ctx, step.With, with, previousOutputs, dry)
 		if err != nil {
@@ -39,27 +112,45 @@ func ExecuteBuiltin(ctx context.Context, step v1.Step, with schema.With, previou
 	}
 
 	if dry {
+		planFromContext(ctx).recordWith(ctx, rendered)
 		logger.Info("dry run", "builtin", name)
-		printBuiltin(logger, rendered)
-		return nil, nil
-	}
+		printBuiltin(logger, rendered, structuredLogger(ctx) == nil)
 
-	if rendered != nil {
-		config := &mapstructure.DecoderConfig{
-			WeaklyTypedInput: true,
-			Result:           &builtin,
+		dr, ok := builtin.(builtins.DryRunner)
+		if !ok {
+			return nil, nil
 		}
-		decoder, err := mapstructure.NewDecoder(config)
-		if err != nil {
-			return nil, fmt.Errorf("%s: %w", step.Uses, err)
+		if err := decodeBuiltin(builtin, rendered); err != nil {
+			// placeholders of missing inputs and outputs may not decode into the builtin's fields
+			logger.Warn("unable to describe builtin", "builtin", name, "err", err)
+			return nil, nil
 		}
-		if err := decoder.Decode(rendered); err != nil {
+		if err := dr.DryRun(ctx); err != nil {
 			return nil, fmt.Errorf("%s: %w", step.Uses, err)
 		}
+		return nil, nil
+	}
+
+	manifestFromContext(ctx).recordBuiltin(ManifestBuiltin{ManifestStep: manifestStep(ctx), Name: name})
+
+	if err := v1.ValidateBuiltinWith(name, rendered, true); err != nil {
+		return nil, fmt.Errorf("%s: %w", step.Uses, err)
+	}
+
+	if err := decodeBuiltin(builtin, rendered); err != nil {
+		return nil, fmt.Errorf("%s: %w", step.Uses, err)
 	}
 
 	logger.Debug(">", "builtin", name, "with", builtin)
 
+	ctx = builtins.WithRenderer(ctx, func(ctx context.Context, text string) (string, error) {
+		return TemplateString(ctx, text, with, previousOutputs, false)
+	})
+
+	if secrets := secretsFromContext(ctx); secrets != nil {
+		ctx = builtins.WithMasker(ctx, secrets.addMasked)
+	}
+
 	result, err := builtin.Execute(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", step.Uses, err)
@@ -67,3 +158,19 @@ func ExecuteBuiltin(ctx context.Context, step v1.Step, with schema.With, previou
 
 	return result, nil
 }
+
+// decodeBuiltin decodes the rendered with into the builtin's fields
+func decodeBuiltin(builtin builtins.Builtin, rendered schema.With) error {
+	if rendered == nil {
+		return nil
+	}
+	config := &mapstructure.DecoderConfig{
+		WeaklyTypedInput: true,
+		Result:           &builtin,
+	}
+	decoder, err := mapstructure.NewDecoder(config)
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(rendered)
+}
@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package builtins
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+	"oras.land/oras-go/v2/registry/remote/retry"
+)
+
+// MediaTypeArtifact is the default artifact type of artifacts pushed by builtin:push-artifact
+const MediaTypeArtifact = "application/vnd.maru2.artifact.v1"
+
+// pushArtifact pushes files as an OCI artifact, so results can be handed between disconnected pipeline stages
+type pushArtifact struct {
+	Ref                   string            `json:"ref"                                mapstructure:"ref"                      jsonschema:"description=Tagged reference to push the artifact to (e.g. ghcr.io/org/results:build-1)"`
+	Files                 []string          `json:"files"                              mapstructure:"files"                    jsonschema:"description=Relative paths of the files and directories to push,minItems=1"`
+	ArtifactType          string            `json:"artifact-type,omitempty"            mapstructure:"artifact-type"            jsonschema:"description=Artifact type of the manifest (defaults to application/vnd.maru2.artifact.v1)"`
+	Annotations           map[string]string `json:"annotations,omitempty"              mapstructure:"annotations"              jsonschema:"description=Annotations to set on the manifest"`
+	PlainHTTP             bool              `json:"plain-http,omitempty"               mapstructure:"plain-http"               jsonschema:"description=Force the connection over HTTP instead of HTTPS"`
+	InsecureSkipTLSVerify bool              `json:"insecure-skip-tls-verify,omitempty" mapstructure:"insecure-skip-tls-verify" jsonschema:"description=Allow connections to registries without valid certificates"`
+}
+
+type pushArtifactOutputs struct {
+	Digest string `json:"digest" jsonschema:"description=The digest of the pushed manifest"`
+	Ref    string `json:"ref"    jsonschema:"description=The reference of the pushed manifest by digest"`
+	Tag    string `json:"tag"    jsonschema:"description=The tag the manifest was pushed to"`
+}
+
+// Outputs describes the outputs of the builtin
+func (b *pushArtifact) Outputs() any { return pushArtifactOutputs{} }
+
+// Execute the builtin
+func (b *pushArtifact) Execute(ctx context.Context) (map[string]any, error) {
+	logger := log.FromContext(ctx)
+
+	ref, err := registry.ParseReference(strings.TrimPrefix(b.Ref, "oci:"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse reference: %w", err)
+	}
+	if err := ref.ValidateReferenceAsTag(); err != nil {
+		return nil, fmt.Errorf("reference is not a tag: %w", err)
+	}
+
+	if len(b.Files) == 0 {
+		return nil, fmt.Errorf("at least one file is required")
+	}
+
+	artifactType := b.ArtifactType
+	if artifactType == "" {
+		artifactType = MediaTypeArtifact
+	}
+
+	cwd, err := filepath.Abs(resolvePath(ctx, "."))
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.MkdirTemp("", "")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	store, err := file.New(tmp)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	layers := make([]ocispec.Descriptor, 0, len(b.Files))
+	for _, f := range b.Files {
+		// names are written as-is when the artifact is pulled, so they must stay within the working directory
+		if !filepath.IsLocal(f) {
+			return nil, fmt.Errorf("file %q must be a relative path within the working directory", f)
+		}
+		if err := checkFileRead(ctx, f); err != nil {
+			return nil, err
+		}
+		name := filepath.ToSlash(filepath.Clean(f))
+
+		logger.Debug("staging", "entry", name)
+		desc, err := store.Add(ctx, name, "", filepath.Join(cwd, f))
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, desc)
+	}
+
+	root, err := oras.PackManifest(ctx, store, oras.PackManifestVersion1_1, artifactType, oras.PackManifestOptions{
+		Layers:              layers,
+		ManifestAnnotations: b.Annotations,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := store.Tag(ctx, root, root.Digest.String()); err != nil {
+		return nil, err
+	}
+
+	dst, err := b.repository(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err := oras.Copy(ctx, store, root.Digest.String(), dst, ref.Reference, oras.DefaultCopyOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	pushed := fmt.Sprintf("%s/%s@%s", ref.Registry, ref.Repository, desc.Digest)
+	logger.Info("pushed artifact", "ref", pushed, "tag", ref.Reference)
+
+	return map[string]any{
+		"digest": desc.Digest.String(),
+		"ref":    pushed,
+		"tag":    ref.Reference,
+	}, nil
+}
+
+// repository creates a client for the repository of ref, authenticated with credentials from the docker config
+func (b *pushArtifact) repository(ref registry.Reference) (*remote.Repository, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{} //nolint:gosec // InsecureSkipVerify is opt-in
+	}
+	transport.TLSClientConfig.InsecureSkipVerify = b.InsecureSkipTLSVerify
+
+	credStore, err := credentials.NewStoreFromDocker(credentials.StoreOptions{DetectDefaultNativeStore: true})
+	if err != nil {
+		return nil, err
+	}
+
+	client := &auth.Client{
+		Client:     &http.Client{Transport: retry.NewTransport(transport)},
+		Cache:      auth.NewCache(),
+		Credential: credentials.Credential(credStore),
+	}
+	client.SetUserAgent("maru2")
+
+	return &remote.Repository{
+		Reference: ref,
+		PlainHTTP: b.PlainHTTP,
+		Client:    client,
+	}, nil
+}
@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package builtins
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/olareg/olareg"
+	olaregcfg "github.com/olareg/olareg/config"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+func TestBuiltinPushArtifact(t *testing.T) {
+	r := olareg.New(olaregcfg.Config{
+		Storage: olaregcfg.ConfigStorage{
+			StoreType: olaregcfg.StoreMem,
+		},
+	})
+	s := httptest.NewServer(r)
+	t.Cleanup(func() {
+		s.Close()
+		_ = r.Close()
+	})
+
+	serverURL, err := url.Parse(s.URL)
+	require.NoError(t, err)
+
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.MkdirAll("dist", 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join("dist", "report.txt"), []byte("all good\n"), 0o644))
+
+	ctx := log.WithContext(t.Context(), log.New(io.Discard))
+
+	testCases := []struct {
+		name        string
+		builtin     pushArtifact
+		tag         string
+		expectedErr string
+	}{
+		{
+			name: "push files",
+			builtin: pushArtifact{
+				Ref:         serverURL.Host + "/results:build-1",
+				Files:       []string{"dist/report.txt"},
+				Annotations: map[string]string{"stage": "build"},
+				PlainHTTP:   true,
+			},
+			tag: "build-1",
+		},
+		{
+			name: "push with oci prefix and artifact type",
+			builtin: pushArtifact{
+				Ref:          "oci:" + serverURL.Host + "/results:build-2",
+				Files:        []string{"dist"},
+				ArtifactType: "application/vnd.example.report.v1",
+				PlainHTTP:    true,
+			},
+			tag: "build-2",
+		},
+		{
+			name:        "invalid reference",
+			builtin:     pushArtifact{Ref: "not a ref", Files: []string{"dist/report.txt"}},
+			expectedErr: "unable to parse reference",
+		},
+		{
+			name:        "digest reference",
+			builtin:     pushArtifact{Ref: serverURL.Host + "/results@sha256:0000000000000000000000000000000000000000000000000000000000000000", Files: []string{"dist/report.txt"}},
+			expectedErr: "reference is not a tag",
+		},
+		{
+			name:        "no files",
+			builtin:     pushArtifact{Ref: serverURL.Host + "/results:empty"},
+			expectedErr: "at least one file is required",
+		},
+		{
+			name:        "file outside working directory",
+			builtin:     pushArtifact{Ref: serverURL.Host + "/results:escape", Files: []string{"../secret.txt"}},
+			expectedErr: `file "../secret.txt" must be a relative path within the working directory`,
+		},
+		{
+			name:        "missing file",
+			builtin:     pushArtifact{Ref: serverURL.Host + "/results:missing", Files: []string{"missing.txt"}, PlainHTTP: true},
+			expectedErr: "no such file or directory",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tc.builtin.Execute(ctx)
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				assert.Nil(t, result)
+				return
+			}
+			require.NoError(t, err)
+
+			repo, err := remote.NewRepository(serverURL.Host + "/results")
+			require.NoError(t, err)
+			repo.PlainHTTP = true
+
+			desc, err := repo.Resolve(ctx, tc.tag)
+			require.NoError(t, err)
+
+			assert.Equal(t, map[string]any{
+				"digest": desc.Digest.String(),
+				"ref":    serverURL.Host + "/results@" + desc.Digest.String(),
+				"tag":    tc.tag,
+			}, result)
+
+			b, err := content.FetchAll(ctx, repo, desc)
+			require.NoError(t, err)
+			var manifest ocispec.Manifest
+			require.NoError(t, json.Unmarshal(b, &manifest))
+
+			expectedType := tc.builtin.ArtifactType
+			if expectedType == "" {
+				expectedType = MediaTypeArtifact
+			}
+			assert.Equal(t, expectedType, manifest.ArtifactType)
+			require.Len(t, manifest.Layers, len(tc.builtin.Files))
+			for i, f := range tc.builtin.Files {
+				assert.Equal(t, f, manifest.Layers[i].Annotations[ocispec.AnnotationTitle])
+			}
+			for k, v := range tc.builtin.Annotations {
+				assert.Equal(t, v, manifest.Annotations[k])
+			}
+		})
+	}
+}
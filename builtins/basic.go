@@ -7,10 +7,15 @@ package builtins
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/log"
@@ -21,6 +26,13 @@ type echo struct {
 	Text string `json:"text" jsonschema:"description=Text to echo"`
 }
 
+type echoOutputs struct {
+	Stdout string `json:"stdout" jsonschema:"description=The echoed text"`
+}
+
+// Outputs describes the outputs of the builtin
+func (b *echo) Outputs() any { return echoOutputs{} }
+
 // Execute the builtin
 func (b *echo) Execute(ctx context.Context) (map[string]any, error) {
 	logger := log.FromContext(ctx)
@@ -29,16 +41,64 @@ func (b *echo) Execute(ctx context.Context) (map[string]any, error) {
 	return map[string]any{"stdout": b.Text}, nil
 }
 
-// fetch is a sample builtin to showcase configuration and schema gen
+type httpClientKey struct{}
+
+type hostHeadersClientKey struct{}
+
+// WithHTTPClient returns a context carrying the HTTP client used by builtins making HTTP requests
+//
+// The client is provided by the runner, so requests share the transport configured for uses
+func WithHTTPClient(ctx context.Context, client *http.Client) context.Context {
+	return context.WithValue(ctx, httpClientKey{}, client)
+}
+
+// WithHostHeadersClient returns a context carrying the HTTP client used when a builtin sets host-headers
+//
+// Unlike the client of WithHTTPClient it also sends the host headers of the system config
+func WithHostHeadersClient(ctx context.Context, client *http.Client) context.Context {
+	return context.WithValue(ctx, hostHeadersClientKey{}, client)
+}
+
+// httpClient returns a copy of the HTTP client carried by the context, or a default client, with the timeout set
+func httpClient(ctx context.Context, hostHeaders bool, timeout time.Duration) *http.Client {
+	var key any = httpClientKey{}
+	if hostHeaders {
+		key = hostHeadersClientKey{}
+	}
+	client := &http.Client{}
+	if shared, ok := ctx.Value(key).(*http.Client); ok && shared != nil {
+		clone := *shared
+		client = &clone
+	}
+	client.Timeout = timeout
+	return client
+}
+
+// fetch makes an HTTP request, returning the response body or downloading it to a path
 type fetch struct {
-	URL     string            `json:"url"               jsonschema:"description=URL to fetch"`
-	Method  string            `json:"method,omitempty"  jsonschema:"description=HTTP method to use"`
-	Timeout string            `json:"timeout,omitempty" jsonschema:"description=Timeout for the request"`
-	Headers map[string]string `json:"headers,omitempty" jsonschema:"description=HTTP headers to send"`
+	URL         string            `json:"url"                    mapstructure:"url"          jsonschema:"description=URL to fetch"`
+	Method      string            `json:"method,omitempty"       mapstructure:"method"       jsonschema:"description=HTTP method to use"`
+	Timeout     string            `json:"timeout,omitempty"      mapstructure:"timeout"      jsonschema:"description=Timeout for the request"`
+	Headers     map[string]string `json:"headers,omitempty"      mapstructure:"headers"      jsonschema:"description=HTTP headers to send"`
+	HostHeaders bool              `json:"host-headers,omitempty" mapstructure:"host-headers" jsonschema:"description=Also send the headers configured for the host in the system config (defaults to false)"`
+	Path        string            `json:"path,omitempty"         mapstructure:"path"         jsonschema:"description=Download the response body to this path instead of returning it"`
+	Checksum    string            `json:"checksum,omitempty"     mapstructure:"checksum"     jsonschema:"description=Expected sha256 digest of the downloaded file (e.g. sha256:abc...),pattern=^(sha256:)?[a-fA-F0-9]{64}$"`
+	Retries     int               `json:"retries,omitempty"      mapstructure:"retries"      jsonschema:"description=Number of times to retry the request upon a network error or a 429 or 5xx status code,minimum=0"`
+	RetryDelay  string            `json:"retry-delay,omitempty"  mapstructure:"retry-delay"  jsonschema:"description=Delay before the first retry; doubled after every retry (defaults to 1s)"`
 
-	parsedTimeout time.Duration
+	parsedTimeout    time.Duration
+	parsedRetryDelay time.Duration
 }
 
+type fetchOutputs struct {
+	Body   string `json:"body,omitempty"   jsonschema:"description=The response body when path is not set"`
+	Path   string `json:"path,omitempty"   jsonschema:"description=The absolute path of the downloaded file when path is set"`
+	Digest string `json:"digest,omitempty" jsonschema:"description=The sha256 digest of the downloaded file when path is set"`
+}
+
+// Outputs describes the outputs of the builtin
+func (b *fetch) Outputs() any { return fetchOutputs{} }
+
 // Execute the builtin
 func (b *fetch) Execute(ctx context.Context) (map[string]any, error) {
 	logger := log.FromContext(ctx)
@@ -47,25 +107,14 @@ func (b *fetch) Execute(ctx context.Context) (map[string]any, error) {
 		return nil, err
 	}
 
-	client := &http.Client{
-		Timeout: b.parsedTimeout,
-	}
-
-	logger.Printf("Headers: %s", b.Headers)
-
-	req, err := http.NewRequestWithContext(ctx, b.Method, b.URL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-
-	resp, err := client.Do(req)
+	resp, err := b.do(ctx, httpClient(ctx, b.HostHeaders, b.parsedTimeout))
 	if err != nil {
-		return nil, fmt.Errorf("error executing request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("expected status code %d got %d", http.StatusOK, resp.StatusCode)
+	if b.Path != "" {
+		return b.download(ctx, resp.Body)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -92,6 +141,107 @@ func (b *fetch) Execute(ctx context.Context) (map[string]any, error) {
 	return map[string]any{"body": string(body)}, nil
 }
 
+// do sends the request, retrying upon a network error or a 429 or 5xx status code
+func (b *fetch) do(ctx context.Context, client *http.Client) (*http.Response, error) {
+	logger := log.FromContext(ctx)
+	delay := b.parsedRetryDelay
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, b.Method, b.URL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error creating request: %w", err)
+		}
+		for k, v := range b.Headers {
+			req.Header.Set(k, v)
+		}
+
+		resp, err := client.Do(req)
+		if err == nil && resp.StatusCode == http.StatusOK {
+			return resp, nil
+		}
+
+		var retryable bool
+		if err != nil {
+			err = fmt.Errorf("error executing request: %w", err)
+			retryable = ctx.Err() == nil
+		} else {
+			resp.Body.Close()
+			err = fmt.Errorf("expected status code %d got %d", http.StatusOK, resp.StatusCode)
+			retryable = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+		}
+
+		if !retryable || attempt >= b.Retries {
+			return nil, err
+		}
+
+		logger.Warn("retrying request", "url", b.URL, "err", err, "attempt", attempt+1, "delay", delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// download writes body to the path, verifying its checksum before it replaces any existing file
+func (b *fetch) download(ctx context.Context, body io.Reader) (map[string]any, error) {
+	logger := log.FromContext(ctx)
+
+	dst, err := filepath.Abs(resolvePath(ctx, b.Path))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return nil, err
+	}
+
+	// written next to the destination so the rename is atomic
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	hasher := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, hasher), body)
+	if err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+
+	digest := "sha256:" + hex.EncodeToString(hasher.Sum(nil))
+
+	if b.Checksum != "" {
+		expected := "sha256:" + strings.ToLower(strings.TrimPrefix(b.Checksum, "sha256:"))
+		if digest != expected {
+			return nil, fmt.Errorf("checksum mismatch for %s: expected %s got %s", b.URL, expected, digest)
+		}
+	}
+
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return nil, err
+	}
+
+	logger.Info("downloaded", "url", b.URL, "path", dst, "size", n, "digest", digest)
+
+	return map[string]any{
+		"path":   dst,
+		"digest": digest,
+	}, nil
+}
+
 func (b *fetch) setDefaults() error {
 	if b.Method == "" {
 		b.Method = "GET"
@@ -105,5 +255,28 @@ func (b *fetch) setDefaults() error {
 		}
 		b.parsedTimeout = parsedTimeout
 	}
+
+	b.parsedRetryDelay = time.Second
+	if b.RetryDelay != "" {
+		parsedRetryDelay, err := time.ParseDuration(b.RetryDelay)
+		if err != nil {
+			return fmt.Errorf("invalid retry-delay: %w", err)
+		}
+		b.parsedRetryDelay = parsedRetryDelay
+	}
+
+	if b.Retries < 0 {
+		return fmt.Errorf("retries must be at least 0")
+	}
+
+	if b.Checksum != "" {
+		if b.Path == "" {
+			return fmt.Errorf("checksum requires path")
+		}
+		sum := strings.TrimPrefix(b.Checksum, "sha256:")
+		if _, err := hex.DecodeString(sum); err != nil || len(sum) != sha256.Size*2 {
+			return fmt.Errorf("invalid checksum %q, expected a sha256 digest", b.Checksum)
+		}
+	}
 	return nil
 }
@@ -5,9 +5,17 @@ package builtins
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -161,6 +169,7 @@ func TestBuiltinFetch(t *testing.T) {
 					"X-Custom-Header": "custom-value",
 				},
 			},
+			body: "custom-value",
 		},
 		{
 			name: "with timeout",
@@ -225,6 +234,138 @@ func TestBuiltinFetch(t *testing.T) {
 	}
 }
 
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestBuiltinFetchDownload(t *testing.T) {
+	content := []byte("#!/bin/sh\necho installed\n")
+	sum := sha256.Sum256(content)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+
+	var flaky atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/install.sh":
+			_, _ = w.Write(content)
+		case "/flaky":
+			if flaky.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write(content)
+		case "/auth":
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write(content)
+		case "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	t.Run("download with checksum", func(t *testing.T) {
+		ctx := log.WithContext(t.Context(), log.New(io.Discard))
+		dst := filepath.Join(t.TempDir(), "bin", "install.sh")
+
+		b := &fetch{URL: server.URL + "/install.sh", Path: dst, Checksum: strings.ToUpper(hex.EncodeToString(sum[:]))}
+		result, err := b.Execute(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"path": dst, "digest": digest}, result)
+
+		b2, err := os.ReadFile(dst)
+		require.NoError(t, err)
+		assert.Equal(t, content, b2)
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		ctx := log.WithContext(t.Context(), log.New(io.Discard))
+		dir := t.TempDir()
+		dst := filepath.Join(dir, "install.sh")
+		require.NoError(t, os.WriteFile(dst, []byte("existing"), 0o644))
+
+		b := &fetch{URL: server.URL + "/install.sh", Path: dst, Checksum: "sha256:" + strings.Repeat("0", 64)}
+		_, err := b.Execute(ctx)
+		require.EqualError(t, err, fmt.Sprintf("checksum mismatch for %s/install.sh: expected sha256:%s got %s", server.URL, strings.Repeat("0", 64), digest))
+
+		// the existing file is untouched and no temporary files are left behind
+		b2, err := os.ReadFile(dst)
+		require.NoError(t, err)
+		assert.Equal(t, "existing", string(b2))
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("retries", func(t *testing.T) {
+		ctx := log.WithContext(t.Context(), log.New(io.Discard))
+		dst := filepath.Join(t.TempDir(), "install.sh")
+
+		b := &fetch{URL: server.URL + "/flaky", Path: dst, Retries: 2, RetryDelay: "1ms"}
+		result, err := b.Execute(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, digest, result["digest"])
+		assert.Equal(t, int32(3), flaky.Load())
+
+		b = &fetch{URL: server.URL + "/unavailable", Retries: 1, RetryDelay: "1ms"}
+		_, err = b.Execute(ctx)
+		require.EqualError(t, err, "expected status code 200 got 503")
+	})
+
+	t.Run("client from context", func(t *testing.T) {
+		ctx := log.WithContext(t.Context(), log.New(io.Discard))
+		dst := filepath.Join(t.TempDir(), "install.sh")
+
+		b := &fetch{URL: server.URL + "/auth", Path: dst}
+		_, err := b.Execute(ctx)
+		require.EqualError(t, err, "expected status code 200 got 401")
+
+		client := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.Header.Set("Authorization", "Bearer token")
+			return http.DefaultTransport.RoundTrip(req)
+		})}
+
+		b = &fetch{URL: server.URL + "/auth", Path: dst}
+		result, err := b.Execute(WithHTTPClient(ctx, client))
+		require.NoError(t, err)
+		assert.Equal(t, digest, result["digest"])
+
+		// the client sending the host headers is only used when asked
+		ctx = WithHostHeadersClient(ctx, client)
+		b = &fetch{URL: server.URL + "/auth", Path: dst}
+		_, err = b.Execute(ctx)
+		require.EqualError(t, err, "expected status code 200 got 401")
+
+		b = &fetch{URL: server.URL + "/auth", Path: dst, HostHeaders: true}
+		result, err = b.Execute(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, digest, result["digest"])
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		ctx := log.WithContext(t.Context(), log.New(io.Discard))
+
+		_, err := (&fetch{URL: server.URL + "/install.sh", Checksum: digest}).Execute(ctx)
+		require.EqualError(t, err, "checksum requires path")
+
+		_, err = (&fetch{URL: server.URL + "/install.sh", Path: "install.sh", Checksum: "md5:abc"}).Execute(ctx)
+		require.EqualError(t, err, `invalid checksum "md5:abc", expected a sha256 digest`)
+
+		_, err = (&fetch{URL: server.URL + "/install.sh", Retries: -1}).Execute(ctx)
+		require.EqualError(t, err, "retries must be at least 0")
+
+		_, err = (&fetch{URL: server.URL + "/install.sh", RetryDelay: "soon"}).Execute(ctx)
+		require.EqualError(t, err, `invalid retry-delay: time: invalid duration "soon"`)
+	})
+}
+
 func TestBuiltinWackyStructs(t *testing.T) {
 	wacky := Get("wacky-structs")
 	assert.Implements(t, (*Builtin)(nil), wacky)
@@ -233,3 +374,58 @@ func TestBuiltinWackyStructs(t *testing.T) {
 	assert.Nil(t, out)
 	require.Error(t, err)
 }
+
+func TestBuiltinMaru2(t *testing.T) {
+	testCases := []struct {
+		name        string
+		builtin     *maru2
+		invoker     Invoker
+		expected    map[string]any
+		expectedErr string
+	}{
+		{
+			name:        "no invoker",
+			builtin:     &maru2{Task: "build"},
+			expectedErr: "not running within a workflow",
+		},
+		{
+			name:        "no from or task",
+			builtin:     &maru2{},
+			invoker:     func(context.Context, string, string, map[string]any) (map[string]any, error) { return nil, nil },
+			expectedErr: "one of from or task must be set",
+		},
+		{
+			name:    "invokes",
+			builtin: &maru2{From: "file:tasks.yaml", Task: "build", With: map[string]any{"a": "b"}},
+			invoker: func(_ context.Context, from, task string, with map[string]any) (map[string]any, error) {
+				return map[string]any{"from": from, "task": task, "a": with["a"]}, nil
+			},
+			expected: map[string]any{"from": "file:tasks.yaml", "task": "build", "a": "b"},
+		},
+		{
+			name:    "invoker error",
+			builtin: &maru2{From: "file:tasks.yaml"},
+			invoker: func(context.Context, string, string, map[string]any) (map[string]any, error) {
+				return nil, fmt.Errorf("boom")
+			},
+			expectedErr: "boom",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := t.Context()
+			if tc.invoker != nil {
+				ctx = WithInvoker(ctx, tc.invoker)
+			}
+
+			result, err := tc.builtin.Execute(ctx)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
@@ -0,0 +1,361 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package builtins
+
+import (
+	"archive/tar"
+	"cmp"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+// CacheStore stores and retrieves archives for builtin:cache-save and builtin:cache-restore
+//
+// It is satisfied by the store of remote workflows, so caches share its location, locking and garbage collection
+type CacheStore interface {
+	Fetch(ctx context.Context, uri *url.URL) (io.ReadCloser, error)
+	Exists(uri *url.URL) (bool, error)
+	Store(r io.Reader, uri *url.URL) error
+}
+
+type cacheStoreKey struct{}
+
+// WithCacheStore returns a context carrying the store used by builtin:cache-save and builtin:cache-restore
+func WithCacheStore(ctx context.Context, store CacheStore) context.Context {
+	return context.WithValue(ctx, cacheStoreKey{}, store)
+}
+
+// cacheStoreFromContext returns the store carried by the context
+func cacheStoreFromContext(ctx context.Context) (CacheStore, error) {
+	store, ok := ctx.Value(cacheStoreKey{}).(CacheStore)
+	if !ok || store == nil {
+		return nil, fmt.Errorf("no store is configured")
+	}
+	return store, nil
+}
+
+// cacheURL returns the URL a cache is stored under
+func cacheURL(key string) (*url.URL, error) {
+	if strings.TrimSpace(key) == "" {
+		return nil, fmt.Errorf("key must be set")
+	}
+	return &url.URL{Scheme: "cache", Opaque: url.PathEscape(key)}, nil
+}
+
+// cacheSave archives paths into the store under a key
+type cacheSave struct {
+	Key   string   `json:"key"   mapstructure:"key"   jsonschema:"description=Key to save the cache under (e.g. go-${{ hashFiles \"go.sum\" }}); an existing cache is never overwritten"`
+	Paths []string `json:"paths" mapstructure:"paths" jsonschema:"description=Files and directories to cache relative to the working directory,minItems=1"`
+}
+
+type cacheSaveOutputs struct {
+	Saved bool `json:"saved" jsonschema:"description=Whether the cache was saved; false if a cache already exists under the key"`
+}
+
+// Outputs describes the outputs of the builtin
+func (b *cacheSave) Outputs() any { return cacheSaveOutputs{} }
+
+// Execute the builtin
+func (b *cacheSave) Execute(ctx context.Context) (map[string]any, error) {
+	logger := log.FromContext(ctx)
+
+	uri, err := b.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := cacheStoreFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range b.Paths {
+		if err := checkFileRead(ctx, p); err != nil {
+			return nil, err
+		}
+	}
+
+	exists, err := store.Exists(uri)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		logger.Info("cache already exists", "key", b.Key)
+		return map[string]any{"saved": false}, nil
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeCacheArchive(pw, workingDir(ctx), b.Paths))
+	}()
+
+	if err := store.Store(pr, uri); err != nil {
+		pr.CloseWithError(err)
+		return nil, fmt.Errorf("unable to save cache: %w", err)
+	}
+
+	logger.Info("saved cache", "key", b.Key, "paths", b.Paths)
+
+	return map[string]any{"saved": true}, nil
+}
+
+// DryRun logs the cache that would be saved
+func (b *cacheSave) DryRun(ctx context.Context) error {
+	if _, err := b.validate(); err != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("would save cache", "key", b.Key, "paths", b.Paths)
+	return nil
+}
+
+func (b *cacheSave) validate() (*url.URL, error) {
+	if len(b.Paths) == 0 {
+		return nil, fmt.Errorf("paths must be set")
+	}
+	for _, p := range b.Paths {
+		if !filepath.IsLocal(p) {
+			return nil, fmt.Errorf("path %q must be relative to and within the working directory", p)
+		}
+	}
+	return cacheURL(b.Key)
+}
+
+// writeCacheArchive writes a gzipped tarball of the paths within dir to w, entries are named relative to dir
+func writeCacheArchive(w io.Writer, dir string, paths []string) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	for _, root := range paths {
+		err := filepath.WalkDir(filepath.Join(dir, root), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			name, err := filepath.Rel(cmp.Or(dir, "."), path)
+			if err != nil {
+				return err
+			}
+
+			fi, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			var link string
+			if fi.Mode()&fs.ModeSymlink != 0 {
+				link, err = os.Readlink(path)
+				if err != nil {
+					return err
+				}
+			}
+
+			hdr, err := tar.FileInfoHeader(fi, link)
+			if err != nil {
+				return err
+			}
+			hdr.Name = filepath.ToSlash(name)
+			if d.IsDir() {
+				hdr.Name += "/"
+			}
+
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+
+			if !fi.Mode().IsRegular() {
+				return nil
+			}
+
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = io.Copy(tw, f)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// cacheRestore extracts a cache from the store into the working directory
+type cacheRestore struct {
+	Key string `json:"key" mapstructure:"key" jsonschema:"description=Key of the cache to restore; a missing cache is not an error"`
+}
+
+type cacheRestoreOutputs struct {
+	Hit bool `json:"hit" jsonschema:"description=Whether the cache was found and restored"`
+}
+
+// Outputs describes the outputs of the builtin
+func (b *cacheRestore) Outputs() any { return cacheRestoreOutputs{} }
+
+// Execute the builtin
+func (b *cacheRestore) Execute(ctx context.Context) (map[string]any, error) {
+	logger := log.FromContext(ctx)
+
+	uri, err := cacheURL(b.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := cacheStoreFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := store.Exists(uri)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		logger.Info("cache not found", "key", b.Key)
+		return map[string]any{"hit": false}, nil
+	}
+
+	rc, err := store.Fetch(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	n, err := extractCacheArchive(rc, workingDir(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("unable to restore cache: %w", err)
+	}
+
+	logger.Info("restored cache", "key", b.Key, "files", n)
+
+	return map[string]any{"hit": true}, nil
+}
+
+// DryRun logs the cache that would be restored
+func (b *cacheRestore) DryRun(ctx context.Context) error {
+	if _, err := cacheURL(b.Key); err != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("would restore cache", "key", b.Key)
+	return nil
+}
+
+// extractCacheArchive extracts a gzipped tarball into dir, returning the number of files extracted
+//
+// Entries, and the targets of symlinks, must stay within dir. Entries are written through an os.Root,
+// so a path crossing a symlink (extracted or already in dir) can never escape it
+func extractCacheArchive(r io.Reader, dir string) (int, error) {
+	// an empty dir is the current working directory
+	root, err := os.OpenRoot(cmp.Or(dir, "."))
+	if err != nil {
+		return 0, err
+	}
+	defer root.Close()
+
+	var links []string
+	n, err := extractCacheEntries(r, root, &links)
+
+	// a link is only checked lexically when it is extracted, and can still resolve outside of dir through
+	// links extracted before or after it (e.g. d -> x/.., d2 -> d/..), so every link is resolved once done
+	for _, name := range links {
+		if _, statErr := root.Stat(name); statErr != nil && !errors.Is(statErr, fs.ErrNotExist) {
+			if removeErr := root.Remove(name); removeErr != nil && !errors.Is(removeErr, fs.ErrNotExist) {
+				return n, removeErr
+			}
+			if err == nil {
+				err = fmt.Errorf("%q links outside of the working directory", filepath.ToSlash(name))
+			}
+		}
+	}
+	return n, err
+}
+
+// extractCacheEntries extracts every entry of a gzipped tarball into root, appending the symlinks it creates to links
+func extractCacheEntries(r io.Reader, root *os.Root, links *[]string) (int, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, err
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	n := 0
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+
+		name := filepath.FromSlash(strings.TrimSuffix(hdr.Name, "/"))
+		if !filepath.IsLocal(name) {
+			return n, fmt.Errorf("%q is outside of the working directory", hdr.Name)
+		}
+		mode := hdr.FileInfo().Mode().Perm()
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := root.MkdirAll(name, mode|0o700); err != nil {
+				return n, err
+			}
+			continue
+		case tar.TypeReg, tar.TypeSymlink:
+		default:
+			return n, fmt.Errorf("%q has an unsupported type %q", hdr.Name, hdr.Typeflag)
+		}
+
+		if err := root.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			return n, err
+		}
+		// replaced rather than written through, so an existing symlink is never followed
+		if err := root.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return n, err
+		}
+
+		if hdr.Typeflag == tar.TypeSymlink {
+			if filepath.IsAbs(hdr.Linkname) || !filepath.IsLocal(filepath.Join(filepath.Dir(name), hdr.Linkname)) {
+				return n, fmt.Errorf("%q links outside of the working directory", hdr.Name)
+			}
+			if err := root.Symlink(hdr.Linkname, name); err != nil {
+				return n, err
+			}
+			*links = append(*links, name)
+			n++
+			continue
+		}
+
+		f, err := root.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_EXCL, mode)
+		if err != nil {
+			return n, err
+		}
+		_, err = io.Copy(f, tr)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return n, err
+		}
+		if err := root.Chtimes(name, hdr.ModTime, hdr.ModTime); err != nil {
+			return n, err
+		}
+		n++
+	}
+}
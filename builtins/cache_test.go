@@ -0,0 +1,231 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package builtins
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCacheStore is an in-memory CacheStore
+type memoryCacheStore map[string][]byte
+
+func (s memoryCacheStore) Fetch(_ context.Context, uri *url.URL) (io.ReadCloser, error) {
+	b, ok := s[uri.String()]
+	if !ok {
+		return nil, fmt.Errorf("descriptor not found")
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (s memoryCacheStore) Exists(uri *url.URL) (bool, error) {
+	_, ok := s[uri.String()]
+	return ok, nil
+}
+
+func (s memoryCacheStore) Store(r io.Reader, uri *url.URL) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s[uri.String()] = b
+	return nil
+}
+
+func TestBuiltinCache(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	store := memoryCacheStore{}
+	ctx := WithCacheStore(log.WithContext(t.Context(), log.New(io.Discard)), store)
+
+	require.NoError(t, os.MkdirAll("node_modules/pkg/bin", 0o755))
+	require.NoError(t, os.MkdirAll("node_modules/.bin", 0o755))
+	require.NoError(t, os.WriteFile("node_modules/pkg/index.js", []byte("module.exports = {}"), 0o644))
+	require.NoError(t, os.WriteFile("node_modules/pkg/bin/cli.js", []byte("#!/usr/bin/env node"), 0o755))
+	require.NoError(t, os.Symlink("../pkg/bin/cli.js", "node_modules/.bin/cli"))
+	require.NoError(t, os.WriteFile("build.txt", []byte("built"), 0o644))
+
+	result, err := (&cacheSave{Key: "npm abc", Paths: []string{"node_modules", "build.txt"}}).Execute(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"saved": true}, result)
+	assert.Contains(t, store, "cache:npm%20abc")
+
+	// an existing cache is never overwritten
+	saved := store["cache:npm%20abc"]
+	require.NoError(t, os.WriteFile("build.txt", []byte("changed"), 0o644))
+	result, err = (&cacheSave{Key: "npm abc", Paths: []string{"build.txt"}}).Execute(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"saved": false}, result)
+	assert.Equal(t, saved, store["cache:npm%20abc"])
+
+	require.NoError(t, os.RemoveAll("node_modules"))
+
+	result, err = (&cacheRestore{Key: "npm abc"}).Execute(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"hit": true}, result)
+
+	b, err := os.ReadFile("node_modules/pkg/index.js")
+	require.NoError(t, err)
+	assert.Equal(t, "module.exports = {}", string(b))
+
+	fi, err := os.Stat("node_modules/pkg/bin/cli.js")
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o755), fi.Mode().Perm())
+
+	link, err := os.Readlink("node_modules/.bin/cli")
+	require.NoError(t, err)
+	assert.Equal(t, "../pkg/bin/cli.js", link)
+
+	// existing files are replaced
+	b, err = os.ReadFile("build.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "built", string(b))
+
+	result, err = (&cacheRestore{Key: "missing"}).Execute(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"hit": false}, result)
+}
+
+func TestBuiltinCacheErrors(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	store := memoryCacheStore{}
+	ctx := WithCacheStore(log.WithContext(t.Context(), log.New(io.Discard)), store)
+
+	testCases := []struct {
+		name        string
+		ctx         context.Context
+		builtin     Builtin
+		expectedErr string
+	}{
+		{
+			name:        "save without key",
+			builtin:     &cacheSave{Paths: []string{"."}},
+			expectedErr: "key must be set",
+		},
+		{
+			name:        "save without paths",
+			builtin:     &cacheSave{Key: "foo"},
+			expectedErr: "paths must be set",
+		},
+		{
+			name:        "save absolute path",
+			builtin:     &cacheSave{Key: "foo", Paths: []string{"/etc"}},
+			expectedErr: `path "/etc" must be relative to and within the working directory`,
+		},
+		{
+			name:        "save path outside of working directory",
+			builtin:     &cacheSave{Key: "foo", Paths: []string{"../foo"}},
+			expectedErr: `path "../foo" must be relative to and within the working directory`,
+		},
+		{
+			name:        "save missing path",
+			builtin:     &cacheSave{Key: "foo", Paths: []string{"missing"}},
+			expectedErr: "unable to save cache: lstat missing: no such file or directory",
+		},
+		{
+			name:        "save without store",
+			ctx:         log.WithContext(t.Context(), log.New(io.Discard)),
+			builtin:     &cacheSave{Key: "foo", Paths: []string{"."}},
+			expectedErr: "no store is configured",
+		},
+		{
+			name:        "restore without key",
+			builtin:     &cacheRestore{Key: " "},
+			expectedErr: "key must be set",
+		},
+		{
+			name:        "restore without store",
+			ctx:         log.WithContext(t.Context(), log.New(io.Discard)),
+			builtin:     &cacheRestore{Key: "foo"},
+			expectedErr: "no store is configured",
+		},
+		{
+			name:        "restore entry outside of working directory",
+			builtin:     &cacheRestore{Key: "traversal"},
+			expectedErr: `unable to restore cache: "../escape.txt" is outside of the working directory`,
+		},
+		{
+			name:        "restore symlink outside of working directory",
+			builtin:     &cacheRestore{Key: "symlink"},
+			expectedErr: `unable to restore cache: "link" links outside of the working directory`,
+		},
+		{
+			name:        "restore through chained symlinks",
+			builtin:     &cacheRestore{Key: "chained"},
+			expectedErr: "unable to restore cache: mkdirat d2: statat d2: path escapes from parent",
+		},
+		{
+			name:        "restore chained symlink outside of working directory",
+			builtin:     &cacheRestore{Key: "chained-link"},
+			expectedErr: `unable to restore cache: "d2" links outside of the working directory`,
+		},
+	}
+
+	archive := func(hdrs ...*tar.Header) []byte {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gw)
+		for _, hdr := range hdrs {
+			require.NoError(t, tw.WriteHeader(hdr))
+		}
+		require.NoError(t, tw.Close())
+		require.NoError(t, gw.Close())
+		return buf.Bytes()
+	}
+	store["cache:traversal"] = archive(&tar.Header{Name: "../escape.txt", Typeflag: tar.TypeReg, Mode: 0o644})
+	store["cache:symlink"] = archive(&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "../../etc/passwd", Mode: 0o777})
+	// each link is lexically within the working directory, but d2 resolves to its parent
+	chained := []*tar.Header{
+		{Name: "x/", Typeflag: tar.TypeDir, Mode: 0o755},
+		{Name: "d", Typeflag: tar.TypeSymlink, Linkname: "x/..", Mode: 0o777},
+		{Name: "d2", Typeflag: tar.TypeSymlink, Linkname: "d/..", Mode: 0o777},
+	}
+	store["cache:chained"] = archive(append(chained, &tar.Header{Name: "d2/escape.txt", Typeflag: tar.TypeReg, Mode: 0o644})...)
+	store["cache:chained-link"] = archive(chained...)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := ctx
+			if tc.ctx != nil {
+				c = tc.ctx
+			}
+
+			result, err := tc.builtin.Execute(c)
+			require.EqualError(t, err, tc.expectedErr)
+			assert.Nil(t, result)
+		})
+	}
+
+	_, err := os.Lstat(filepath.Join("..", "escape.txt"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	_, err = os.Lstat("link")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	_, err = os.Lstat("d2")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestBuiltinCacheDryRun(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := log.WithContext(t.Context(), log.New(&buf))
+
+	require.NoError(t, (&cacheSave{Key: "go-abc", Paths: []string{"vendor"}}).DryRun(ctx))
+	require.NoError(t, (&cacheRestore{Key: "go-abc"}).DryRun(ctx))
+	assert.Equal(t, "INFO would save cache key=go-abc paths=[vendor]\nINFO would restore cache key=go-abc\n", buf.String())
+
+	require.EqualError(t, (&cacheSave{Key: "go-abc"}).DryRun(ctx), "paths must be set")
+	require.EqualError(t, (&cacheRestore{}).DryRun(ctx), "key must be set")
+}
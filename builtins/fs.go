@@ -0,0 +1,258 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package builtins
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+// Actions supported by builtin:fs
+const (
+	FSActionCopy   = "copy"
+	FSActionMove   = "move"
+	FSActionRemove = "remove"
+	FSActionMkdir  = "mkdir"
+	FSActionChmod  = "chmod"
+)
+
+// files performs simple file operations without relying upon shell specific syntax
+type files struct {
+	Action string   `json:"action"         mapstructure:"action" jsonschema:"description=Operation to perform,enum=copy,enum=move,enum=remove,enum=mkdir,enum=chmod"`
+	Paths  []string `json:"paths"          mapstructure:"paths"  jsonschema:"description=Paths to operate on; supports glob patterns (except for mkdir),minItems=1"`
+	Dest   string   `json:"dest,omitempty" mapstructure:"dest"   jsonschema:"description=Destination of copy and move; a directory if it already exists or ends with a separator or multiple paths match"`
+	Mode   string   `json:"mode,omitempty" mapstructure:"mode"   jsonschema:"description=File mode in octal for chmod (required) and mkdir (defaults to 0755),pattern=^0?[0-7]{3}$"`
+}
+
+// fsOp is a single file operation, resolved from the paths and dest of builtin:fs
+type fsOp struct {
+	src  string
+	dest string
+}
+
+type filesOutputs struct {
+	Paths []string `json:"paths" jsonschema:"description=The paths that were created or changed (the destinations of copy and move)"`
+}
+
+// Outputs describes the outputs of the builtin
+func (b *files) Outputs() any { return filesOutputs{} }
+
+// Execute the builtin
+func (b *files) Execute(ctx context.Context) (map[string]any, error) {
+	logger := log.FromContext(ctx)
+
+	ops, mode, err := b.plan(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(ops))
+	for _, op := range ops {
+		logger.Debug(b.Action, "src", op.src, "dest", op.dest)
+
+		src, dest := resolvePath(ctx, op.src), resolvePath(ctx, op.dest)
+		switch b.Action {
+		case FSActionCopy:
+			err = copyPath(src, dest)
+		case FSActionMove:
+			err = movePath(src, dest)
+		case FSActionRemove:
+			err = os.RemoveAll(src)
+		case FSActionMkdir:
+			err = os.MkdirAll(src, mode)
+		case FSActionChmod:
+			err = os.Chmod(src, mode)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		paths = append(paths, cmp.Or(op.dest, op.src))
+	}
+
+	return map[string]any{"paths": paths}, nil
+}
+
+// DryRun logs the operations that would be performed
+func (b *files) DryRun(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+
+	ops, _, err := b.plan(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	for _, op := range ops {
+		if op.dest != "" {
+			logger.Info("would "+b.Action, "src", op.src, "dest", op.dest)
+		} else {
+			logger.Info("would "+b.Action, "path", op.src)
+		}
+	}
+	return nil
+}
+
+// plan validates the configuration and expands paths into the operations to perform
+//
+// Paths are matched within the step's working directory, and stay relative to it.
+// Unmatched patterns are an error, unless dry is set as previous steps may create them, or when removing
+func (b *files) plan(ctx context.Context, dry bool) ([]fsOp, os.FileMode, error) {
+	if len(b.Paths) == 0 {
+		return nil, 0, fmt.Errorf("at least one path is required")
+	}
+
+	var mode os.FileMode
+	switch b.Action {
+	case FSActionCopy, FSActionMove:
+		if b.Dest == "" {
+			return nil, 0, fmt.Errorf("dest is required for %s", b.Action)
+		}
+	case FSActionRemove:
+	case FSActionMkdir:
+		mode = 0o755
+	case FSActionChmod:
+		if b.Mode == "" {
+			return nil, 0, fmt.Errorf("mode is required for %s", b.Action)
+		}
+	default:
+		return nil, 0, fmt.Errorf("unsupported action %q, expected one of copy, move, remove, mkdir or chmod", b.Action)
+	}
+
+	if b.Mode != "" {
+		m, err := strconv.ParseUint(b.Mode, 8, 32)
+		if err != nil || m > 0o777 {
+			return nil, 0, fmt.Errorf("invalid mode %q", b.Mode)
+		}
+		mode = os.FileMode(m)
+	}
+
+	var matches []string
+	for _, p := range b.Paths {
+		if err := checkFileRead(ctx, p); err != nil {
+			return nil, 0, err
+		}
+		if b.Action == FSActionMkdir {
+			matches = append(matches, p)
+			continue
+		}
+		pattern := resolvePath(ctx, p)
+		found, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		if len(found) == 0 && b.Action != FSActionRemove && !dry {
+			return nil, 0, fmt.Errorf("no files match %q", p)
+		}
+		for _, match := range found {
+			if pattern != p {
+				// matches of a relative pattern stay relative to the working directory
+				if rel, err := filepath.Rel(workingDir(ctx), match); err == nil {
+					match = rel
+				}
+			}
+			matches = append(matches, match)
+		}
+	}
+
+	ops := make([]fsOp, 0, len(matches))
+	for _, src := range matches {
+		op := fsOp{src: src}
+		if b.Dest != "" {
+			op.dest = b.Dest
+			if len(matches) > 1 || strings.HasSuffix(b.Dest, "/") || strings.HasSuffix(b.Dest, string(filepath.Separator)) || isDir(resolvePath(ctx, b.Dest)) {
+				op.dest = filepath.Join(b.Dest, filepath.Base(src))
+			}
+		}
+		ops = append(ops, op)
+	}
+	return ops, mode, nil
+}
+
+// isDir returns whether path is an existing directory
+func isDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
+
+// movePath renames src to dest, creating the parent directories of dest
+func movePath(src, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	return os.Rename(src, dest)
+}
+
+// copyPath copies a file, symlink or directory (recursively) from src to dest, preserving file modes
+func copyPath(src, dest string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		default:
+			return fmt.Errorf("unable to copy %s: unsupported file type %s", path, d.Type())
+		}
+	})
+}
+
+// copyFile copies the contents of a regular file from src to dest
+func copyFile(src, dest string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Chmod(dest, mode)
+}
@@ -0,0 +1,206 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package builtins
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinFS(t *testing.T) {
+	// each case runs in a fresh working directory holding:
+	//
+	//	a.txt
+	//	b.txt
+	//	dir/c.txt
+	//	dir/link -> c.txt
+	setup := func(t *testing.T) {
+		t.Helper()
+		t.Chdir(t.TempDir())
+		require.NoError(t, os.WriteFile("a.txt", []byte("a"), 0o644))
+		require.NoError(t, os.WriteFile("b.txt", []byte("b"), 0o600))
+		require.NoError(t, os.MkdirAll("dir", 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join("dir", "c.txt"), []byte("c"), 0o644))
+		require.NoError(t, os.Symlink("c.txt", filepath.Join("dir", "link")))
+	}
+
+	testCases := []struct {
+		name        string
+		builtin     *files
+		expected    []string
+		expectedErr string
+		check       func(t *testing.T)
+	}{
+		{
+			name:     "copy file",
+			builtin:  &files{Action: FSActionCopy, Paths: []string{"b.txt"}, Dest: "out/b-copy.txt"},
+			expected: []string{"out/b-copy.txt"},
+			check: func(t *testing.T) {
+				b, err := os.ReadFile("out/b-copy.txt")
+				require.NoError(t, err)
+				assert.Equal(t, "b", string(b))
+				fi, err := os.Stat("out/b-copy.txt")
+				require.NoError(t, err)
+				assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+				assert.FileExists(t, "b.txt")
+			},
+		},
+		{
+			name:     "copy glob into directory",
+			builtin:  &files{Action: FSActionCopy, Paths: []string{"*.txt"}, Dest: "out"},
+			expected: []string{"out/a.txt", "out/b.txt"},
+			check: func(t *testing.T) {
+				assert.FileExists(t, "out/a.txt")
+				assert.FileExists(t, "out/b.txt")
+			},
+		},
+		{
+			name:     "copy directory",
+			builtin:  &files{Action: FSActionCopy, Paths: []string{"dir"}, Dest: "out/"},
+			expected: []string{"out/dir"},
+			check: func(t *testing.T) {
+				assert.FileExists(t, "out/dir/c.txt")
+				link, err := os.Readlink("out/dir/link")
+				require.NoError(t, err)
+				assert.Equal(t, "c.txt", link)
+			},
+		},
+		{
+			name:     "move",
+			builtin:  &files{Action: FSActionMove, Paths: []string{"a.txt"}, Dest: "out/moved.txt"},
+			expected: []string{"out/moved.txt"},
+			check: func(t *testing.T) {
+				assert.NoFileExists(t, "a.txt")
+				assert.FileExists(t, "out/moved.txt")
+			},
+		},
+		{
+			name:     "remove",
+			builtin:  &files{Action: FSActionRemove, Paths: []string{"*.txt", "dir", "missing-*"}},
+			expected: []string{"a.txt", "b.txt", "dir"},
+			check: func(t *testing.T) {
+				entries, err := os.ReadDir(".")
+				require.NoError(t, err)
+				assert.Empty(t, entries)
+			},
+		},
+		{
+			name:     "mkdir",
+			builtin:  &files{Action: FSActionMkdir, Paths: []string{"out/[nested]/dir"}, Mode: "0700"},
+			expected: []string{"out/[nested]/dir"},
+			check: func(t *testing.T) {
+				fi, err := os.Stat("out/[nested]/dir")
+				require.NoError(t, err)
+				assert.True(t, fi.IsDir())
+				assert.Equal(t, os.FileMode(0o700), fi.Mode().Perm())
+			},
+		},
+		{
+			name:     "chmod",
+			builtin:  &files{Action: FSActionChmod, Paths: []string{"*.txt"}, Mode: "755"},
+			expected: []string{"a.txt", "b.txt"},
+			check: func(t *testing.T) {
+				fi, err := os.Stat("b.txt")
+				require.NoError(t, err)
+				assert.Equal(t, os.FileMode(0o755), fi.Mode().Perm())
+			},
+		},
+		{
+			name:        "no match",
+			builtin:     &files{Action: FSActionCopy, Paths: []string{"*.md"}, Dest: "out"},
+			expectedErr: `no files match "*.md"`,
+		},
+		{
+			name:        "no paths",
+			builtin:     &files{Action: FSActionRemove},
+			expectedErr: "at least one path is required",
+		},
+		{
+			name:        "no dest",
+			builtin:     &files{Action: FSActionMove, Paths: []string{"a.txt"}},
+			expectedErr: "dest is required for move",
+		},
+		{
+			name:        "no mode",
+			builtin:     &files{Action: FSActionChmod, Paths: []string{"a.txt"}},
+			expectedErr: "mode is required for chmod",
+		},
+		{
+			name:        "invalid mode",
+			builtin:     &files{Action: FSActionChmod, Paths: []string{"a.txt"}, Mode: "999"},
+			expectedErr: `invalid mode "999"`,
+		},
+		{
+			name:        "invalid pattern",
+			builtin:     &files{Action: FSActionRemove, Paths: []string{"[a-"}},
+			expectedErr: `invalid pattern "[a-": syntax error in pattern`,
+		},
+		{
+			name:        "unsupported action",
+			builtin:     &files{Action: "touch", Paths: []string{"a.txt"}},
+			expectedErr: `unsupported action "touch", expected one of copy, move, remove, mkdir or chmod`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setup(t)
+			ctx := log.WithContext(t.Context(), log.New(io.Discard))
+
+			result, err := tc.builtin.Execute(ctx)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				assert.Nil(t, result)
+				return
+			}
+			require.NoError(t, err)
+
+			expected := make([]string, 0, len(tc.expected))
+			for _, p := range tc.expected {
+				expected = append(expected, filepath.FromSlash(p))
+			}
+			assert.Equal(t, map[string]any{"paths": expected}, result)
+			tc.check(t)
+		})
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		setup(t)
+		var buf bytes.Buffer
+		ctx := log.WithContext(t.Context(), log.New(&buf))
+
+		b := &files{Action: FSActionCopy, Paths: []string{"*.txt", "not-created-yet/*"}, Dest: "out"}
+		require.NoError(t, b.DryRun(ctx))
+		assert.Equal(t, "INFO would copy src=a.txt dest=out/a.txt\nINFO would copy src=b.txt dest=out/b.txt\n", buf.String())
+		assert.NoDirExists(t, "out")
+
+		buf.Reset()
+		b = &files{Action: FSActionRemove, Paths: []string{"dir"}}
+		require.NoError(t, b.DryRun(ctx))
+		assert.Equal(t, "INFO would remove path=dir\n", buf.String())
+		assert.DirExists(t, "dir")
+
+		b = &files{Action: FSActionChmod, Paths: []string{"a.txt"}}
+		require.EqualError(t, b.DryRun(ctx), "mode is required for chmod")
+	})
+
+	t.Run("working dir", func(t *testing.T) {
+		setup(t)
+		ctx := WithWorkingDir(log.WithContext(t.Context(), log.New(io.Discard)), "dir")
+
+		b := &files{Action: FSActionCopy, Paths: []string{"*.txt"}, Dest: "out/"}
+		result, err := b.Execute(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"paths": []string{"out/c.txt"}}, result)
+		assert.FileExists(t, filepath.Join("dir", "out", "c.txt"))
+		assert.NoDirExists(t, "out")
+	})
+}
@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package builtins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// httpRequest makes an HTTP request to an API, parsing JSON responses into outputs
+type httpRequest struct {
+	URL         string            `json:"url"                    mapstructure:"url"          jsonschema:"description=URL to send the request to"`
+	Method      string            `json:"method,omitempty"       mapstructure:"method"       jsonschema:"description=HTTP method to use (defaults to GET)"`
+	Headers     map[string]string `json:"headers,omitempty"      mapstructure:"headers"      jsonschema:"description=HTTP headers to send"`
+	HostHeaders bool              `json:"host-headers,omitempty" mapstructure:"host-headers" jsonschema:"description=Also send the headers configured for the host in the system config (defaults to false)"`
+	Body        any               `json:"body,omitempty"         mapstructure:"body"         jsonschema:"description=Request body; objects are encoded as JSON and strings are sent as-is"`
+	Status      int               `json:"status,omitempty"       mapstructure:"status"       jsonschema:"description=Expected HTTP status code (defaults to any 2xx),minimum=100,maximum=599"`
+	Timeout     string            `json:"timeout,omitempty"      mapstructure:"timeout"      jsonschema:"description=Timeout for the request (defaults to 30s)"`
+
+	parsedTimeout time.Duration
+}
+
+type httpRequestOutputs struct {
+	Status int    `json:"status"         jsonschema:"description=The HTTP status code of the response"`
+	Body   string `json:"body"           jsonschema:"description=The response body"`
+	JSON   any    `json:"json,omitempty" jsonschema:"description=The parsed response body when the response has a JSON content type"`
+}
+
+// Outputs describes the outputs of the builtin
+func (b *httpRequest) Outputs() any { return httpRequestOutputs{} }
+
+// Execute the builtin
+func (b *httpRequest) Execute(ctx context.Context) (map[string]any, error) {
+	logger := log.FromContext(ctx)
+
+	if err := b.setDefaults(); err != nil {
+		return nil, err
+	}
+
+	body, contentType, err := b.body()
+	if err != nil {
+		return nil, err
+	}
+
+	client := httpClient(ctx, b.HostHeaders, b.parsedTimeout)
+
+	req, err := http.NewRequestWithContext(ctx, b.Method, b.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for k, v := range b.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	if !b.expected(resp.StatusCode) {
+		msg := bytes.TrimSpace(respBody)
+		if len(msg) > 1<<10 {
+			msg = msg[:1<<10]
+		}
+		if b.Status != 0 {
+			return nil, fmt.Errorf("expected status code %d got %d: %s", b.Status, resp.StatusCode, msg)
+		}
+		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, msg)
+	}
+
+	logger.Info("sent request", "method", b.Method, "url", b.URL, "status", resp.StatusCode, "size", len(respBody))
+
+	result := map[string]any{
+		"status": resp.StatusCode,
+		"body":   string(respBody),
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if len(respBody) > 0 && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) {
+		var parsed any
+		if err := json.Unmarshal(respBody, &parsed); err != nil {
+			return nil, fmt.Errorf("unable to parse response: %w", err)
+		}
+		result["json"] = parsed
+	}
+
+	return result, nil
+}
+
+// DryRun logs the request that would be sent, header values are omitted as they often hold a token
+func (b *httpRequest) DryRun(ctx context.Context) error {
+	if err := b.setDefaults(); err != nil {
+		return err
+	}
+	body, _, err := b.body()
+	if err != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("would request", "method", b.Method, "url", b.URL, "body", string(body))
+	return nil
+}
+
+// expected reports whether the status code is the expected one
+func (b *httpRequest) expected(code int) bool {
+	if b.Status != 0 {
+		return code == b.Status
+	}
+	return code >= 200 && code <= 299
+}
+
+// body returns the request body and its content type, if one is implied by the body
+func (b *httpRequest) body() ([]byte, string, error) {
+	switch body := b.Body.(type) {
+	case nil:
+		return nil, "", nil
+	case string:
+		return []byte(body), "", nil
+	default:
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, "", fmt.Errorf("unable to encode body: %w", err)
+		}
+		return encoded, "application/json", nil
+	}
+}
+
+func (b *httpRequest) setDefaults() error {
+	if b.URL == "" {
+		return fmt.Errorf("url must be set")
+	}
+
+	if b.Method == "" {
+		b.Method = http.MethodGet
+	}
+	b.Method = strings.ToUpper(b.Method)
+
+	b.parsedTimeout = 30 * time.Second
+	if b.Timeout != "" {
+		parsedTimeout, err := time.ParseDuration(b.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout: %w", err)
+		}
+		b.parsedTimeout = parsedTimeout
+	}
+	return nil
+}
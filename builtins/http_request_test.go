@@ -0,0 +1,180 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package builtins
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinHTTPRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pipelines":
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"method":"` + r.Method + `","type":"` + r.Header.Get("Content-Type") + `","token":"` + r.Header.Get("Private-Token") + `","received":` + string(body) + `}`))
+		case "/text":
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte(r.Method + " " + r.Header.Get("Content-Type") + " " + string(body)))
+		case "/invalid-json":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte("{"))
+		case "/accepted":
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("not found\n"))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	testCases := []struct {
+		name        string
+		builtin     *httpRequest
+		expected    map[string]any
+		expectedErr string
+	}{
+		{
+			name: "json body and response",
+			builtin: &httpRequest{
+				URL:     server.URL + "/pipelines",
+				Method:  "post",
+				Headers: map[string]string{"Private-Token": "foo"},
+				Body:    map[string]any{"ref": "main"},
+			},
+			expected: map[string]any{
+				"status": http.StatusCreated,
+				"body":   `{"method":"POST","type":"application/json","token":"foo","received":{"ref":"main"}}`,
+				"json": map[string]any{
+					"method":   "POST",
+					"type":     "application/json",
+					"token":    "foo",
+					"received": map[string]any{"ref": "main"},
+				},
+			},
+		},
+		{
+			name: "content type header takes priority",
+			builtin: &httpRequest{
+				URL:     server.URL + "/text",
+				Method:  http.MethodPut,
+				Headers: map[string]string{"Content-Type": "application/vnd.api+json"},
+				Body:    map[string]any{"ref": "main"},
+			},
+			expected: map[string]any{
+				"status": http.StatusOK,
+				"body":   `PUT application/vnd.api+json {"ref":"main"}`,
+			},
+		},
+		{
+			name:    "string body",
+			builtin: &httpRequest{URL: server.URL + "/text", Method: http.MethodPost, Body: "ref=main"},
+			expected: map[string]any{
+				"status": http.StatusOK,
+				"body":   "POST  ref=main",
+			},
+		},
+		{
+			name:    "defaults to GET",
+			builtin: &httpRequest{URL: server.URL + "/text"},
+			expected: map[string]any{
+				"status": http.StatusOK,
+				"body":   "GET  ",
+			},
+		},
+		{
+			name:    "expected status",
+			builtin: &httpRequest{URL: server.URL + "/missing", Status: http.StatusNotFound},
+			expected: map[string]any{
+				"status": http.StatusNotFound,
+				"body":   "not found\n",
+			},
+		},
+		{
+			name:     "empty response",
+			builtin:  &httpRequest{URL: server.URL + "/accepted"},
+			expected: map[string]any{"status": http.StatusAccepted, "body": ""},
+		},
+		{
+			name:        "unexpected status",
+			builtin:     &httpRequest{URL: server.URL + "/missing"},
+			expectedErr: "unexpected status code 404: not found",
+		},
+		{
+			name:        "status mismatch",
+			builtin:     &httpRequest{URL: server.URL + "/accepted", Status: http.StatusOK},
+			expectedErr: "expected status code 200 got 202: ",
+		},
+		{
+			name:        "invalid json response",
+			builtin:     &httpRequest{URL: server.URL + "/invalid-json"},
+			expectedErr: "unable to parse response: unexpected end of JSON input",
+		},
+		{
+			name:        "no url",
+			builtin:     &httpRequest{},
+			expectedErr: "url must be set",
+		},
+		{
+			name:        "invalid timeout",
+			builtin:     &httpRequest{URL: server.URL, Timeout: "soon"},
+			expectedErr: `invalid timeout: time: invalid duration "soon"`,
+		},
+		{
+			name:        "invalid body",
+			builtin:     &httpRequest{URL: server.URL, Body: map[string]any{"ch": make(chan int)}},
+			expectedErr: "unable to encode body: json: unsupported type: chan int",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := log.WithContext(t.Context(), log.New(io.Discard))
+
+			result, err := tc.builtin.Execute(ctx)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				assert.Nil(t, result)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+
+	t.Run("shared client", func(t *testing.T) {
+		var sent *http.Request
+		client := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			sent = req
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(nil)), Request: req}, nil
+		})}
+		ctx := WithHTTPClient(log.WithContext(t.Context(), log.New(io.Discard)), client)
+
+		_, err := (&httpRequest{URL: "https://example.com/api", Method: http.MethodDelete}).Execute(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, sent)
+		assert.Equal(t, http.MethodDelete, sent.Method)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		var buf bytes.Buffer
+		ctx := log.WithContext(t.Context(), log.New(&buf))
+
+		require.NoError(t, (&httpRequest{URL: "https://example.com/api", Method: "post", Headers: map[string]string{"Authorization": "Bearer secret"}, Body: map[string]any{"ref": "main"}}).DryRun(ctx))
+		assert.Equal(t, "INFO would request method=POST url=https://example.com/api body=\"{\\\"ref\\\":\\\"main\\\"}\"\n", buf.String())
+		assert.NotContains(t, buf.String(), "secret")
+
+		require.EqualError(t, (&httpRequest{}).DryRun(ctx), "url must be set")
+	})
+}
@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package builtins
+
+import (
+	"context"
+	"fmt"
+)
+
+// Invoker runs a task from a workflow location, resolved relative to the workflow currently running
+//
+// An empty from runs the task from the current workflow
+type Invoker func(ctx context.Context, from, task string, with map[string]any) (map[string]any, error)
+
+type invokerKey struct{}
+
+// WithInvoker returns a context carrying the invoker used by builtin:maru2
+//
+// The invoker is provided by the runner, as this package cannot depend upon it
+func WithInvoker(ctx context.Context, invoker Invoker) context.Context {
+	return context.WithValue(ctx, invokerKey{}, invoker)
+}
+
+// maru2 runs a task from another workflow location through the full resolve/fetch/run pipeline
+type maru2 struct {
+	From string         `json:"from,omitempty" jsonschema:"description=Location of the workflow to run the task from; resolved the same as uses (defaults to the current workflow)"`
+	Task string         `json:"task,omitempty" jsonschema:"description=Name of the task to run (defaults to the task qualifier in from or the default task)"`
+	With map[string]any `json:"with,omitempty" jsonschema:"description=Inputs to pass to the task"`
+}
+
+// Execute the builtin
+func (b *maru2) Execute(ctx context.Context) (map[string]any, error) {
+	invoke, ok := ctx.Value(invokerKey{}).(Invoker)
+	if !ok || invoke == nil {
+		return nil, fmt.Errorf("not running within a workflow")
+	}
+
+	if b.From == "" && b.Task == "" {
+		return nil, fmt.Errorf("one of from or task must be set")
+	}
+
+	return invoke(ctx, b.From, b.Task, b.With)
+}
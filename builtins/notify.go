@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package builtins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// Types of webhooks supported by builtin:notify
+const (
+	NotifyTypeGeneric = "generic"
+	NotifyTypeSlack   = "slack"
+	NotifyTypeTeams   = "teams"
+)
+
+// notify posts a message to a webhook
+type notify struct {
+	URL         string            `json:"url"                    mapstructure:"url"          jsonschema:"description=Webhook URL to post to; it often holds a token so prefer passing it as a secret"`
+	Type        string            `json:"type,omitempty"         mapstructure:"type"         jsonschema:"description=Type of webhook used to build the payload from message (defaults to generic),enum=generic,enum=slack,enum=teams"`
+	Message     string            `json:"message,omitempty"      mapstructure:"message"      jsonschema:"description=Message to send"`
+	Payload     any               `json:"payload,omitempty"      mapstructure:"payload"      jsonschema:"description=Payload to send instead of one built from message; objects are encoded as JSON and strings are sent as-is"`
+	Headers     map[string]string `json:"headers,omitempty"      mapstructure:"headers"      jsonschema:"description=HTTP headers to send"`
+	HostHeaders bool              `json:"host-headers,omitempty" mapstructure:"host-headers" jsonschema:"description=Also send the headers configured for the host in the system config (defaults to false)"`
+	Timeout     string            `json:"timeout,omitempty"      mapstructure:"timeout"      jsonschema:"description=Timeout for the request (defaults to 30s)"`
+}
+
+type notifyOutputs struct {
+	Status int `json:"status" jsonschema:"description=The HTTP status code of the response"`
+}
+
+// Outputs describes the outputs of the builtin
+func (b *notify) Outputs() any { return notifyOutputs{} }
+
+// Execute the builtin
+func (b *notify) Execute(ctx context.Context) (map[string]any, error) {
+	logger := log.FromContext(ctx)
+
+	body, err := b.body()
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := 30 * time.Second
+	if b.Timeout != "" {
+		timeout, err = time.ParseDuration(b.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+
+	client := httpClient(ctx, b.HostHeaders, timeout)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", redactURL(err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range b.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending notification: %w", redactURL(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return nil, fmt.Errorf("webhook responded with %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	logger.Info("sent notification", "type", b.notifyType(), "host", req.URL.Host, "status", resp.StatusCode)
+
+	return map[string]any{"status": resp.StatusCode}, nil
+}
+
+// DryRun logs the payload that would be sent, the URL is omitted as it often holds a token
+func (b *notify) DryRun(ctx context.Context) error {
+	body, err := b.body()
+	if err != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("would notify", "type", b.notifyType(), "payload", string(body))
+	return nil
+}
+
+// notifyType returns the type of webhook, defaulting to generic
+func (b *notify) notifyType() string {
+	if b.Type == "" {
+		return NotifyTypeGeneric
+	}
+	return b.Type
+}
+
+// body returns the JSON payload to post
+func (b *notify) body() ([]byte, error) {
+	if b.URL == "" {
+		return nil, fmt.Errorf("url must be set")
+	}
+
+	switch payload := b.Payload.(type) {
+	case nil:
+	case string:
+		return []byte(payload), nil
+	default:
+		return json.Marshal(payload)
+	}
+
+	if b.Message == "" {
+		return nil, fmt.Errorf("one of message or payload must be set")
+	}
+
+	switch b.notifyType() {
+	case NotifyTypeGeneric:
+		return json.Marshal(map[string]any{"message": b.Message})
+	case NotifyTypeSlack:
+		return json.Marshal(map[string]any{"text": b.Message})
+	case NotifyTypeTeams:
+		// Teams workflows expect an adaptive card
+		return json.Marshal(map[string]any{
+			"type": "message",
+			"attachments": []any{
+				map[string]any{
+					"contentType": "application/vnd.microsoft.card.adaptive",
+					"content": map[string]any{
+						"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+						"type":    "AdaptiveCard",
+						"version": "1.4",
+						"body": []any{
+							map[string]any{"type": "TextBlock", "text": b.Message, "wrap": true},
+						},
+					},
+				},
+			},
+		})
+	default:
+		return nil, fmt.Errorf("unsupported type %q, expected one of generic, slack or teams", b.Type)
+	}
+}
+
+// redactURL removes the URL from request errors, as webhook URLs often hold a token
+func redactURL(err error) error {
+	var ue *url.Error
+	if errors.As(err, &ue) {
+		return fmt.Errorf("%s: %w", ue.Op, ue.Err)
+	}
+	return err
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package builtins
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinNotify(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received = map[string]string{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received[r.URL.Path] = r.Header.Get("Content-Type") + " " + r.Header.Get("X-Custom") + " " + string(body)
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/error":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("invalid_payload\n"))
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	testCases := []struct {
+		name        string
+		builtin     *notify
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "generic",
+			builtin:  &notify{URL: server.URL + "/generic", Message: "build failed"},
+			expected: `application/json  {"message":"build failed"}`,
+		},
+		{
+			name:     "slack",
+			builtin:  &notify{URL: server.URL + "/slack", Type: NotifyTypeSlack, Message: "build failed"},
+			expected: `application/json  {"text":"build failed"}`,
+		},
+		{
+			name:     "teams",
+			builtin:  &notify{URL: server.URL + "/teams", Type: NotifyTypeTeams, Message: "build failed"},
+			expected: `application/json  {"attachments":[{"content":{"$schema":"http://adaptivecards.io/schemas/adaptive-card.json","body":[{"text":"build failed","type":"TextBlock","wrap":true}],"type":"AdaptiveCard","version":"1.4"},"contentType":"application/vnd.microsoft.card.adaptive"}],"type":"message"}`,
+		},
+		{
+			name:     "object payload",
+			builtin:  &notify{URL: server.URL + "/object", Payload: map[string]any{"content": "build failed"}, Headers: map[string]string{"X-Custom": "foo"}},
+			expected: `application/json foo {"content":"build failed"}`,
+		},
+		{
+			name:     "string payload",
+			builtin:  &notify{URL: server.URL + "/string", Payload: `{"content": "build failed"}`},
+			expected: `application/json  {"content": "build failed"}`,
+		},
+		{
+			name:        "error status",
+			builtin:     &notify{URL: server.URL + "/error", Message: "build failed"},
+			expectedErr: "webhook responded with 400 Bad Request: invalid_payload",
+		},
+		{
+			name:        "no url",
+			builtin:     &notify{Message: "build failed"},
+			expectedErr: "url must be set",
+		},
+		{
+			name:        "no message",
+			builtin:     &notify{URL: server.URL},
+			expectedErr: "one of message or payload must be set",
+		},
+		{
+			name:        "unsupported type",
+			builtin:     &notify{URL: server.URL, Type: "email", Message: "build failed"},
+			expectedErr: `unsupported type "email", expected one of generic, slack or teams`,
+		},
+		{
+			name:        "invalid timeout",
+			builtin:     &notify{URL: server.URL, Message: "build failed", Timeout: "soon"},
+			expectedErr: `invalid timeout: time: invalid duration "soon"`,
+		},
+		{
+			name:        "url is redacted",
+			builtin:     &notify{URL: "http://localhost:123456/services/T000/B000/XXXX", Message: "build failed"},
+			expectedErr: "error sending notification: Post: dial tcp: address 123456: invalid port",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := log.WithContext(t.Context(), log.New(io.Discard))
+
+			result, err := tc.builtin.Execute(ctx)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				assert.Nil(t, result)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, map[string]any{"status": http.StatusOK}, result)
+
+			mu.Lock()
+			defer mu.Unlock()
+			u, err := url.Parse(tc.builtin.URL)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, received[u.Path])
+		})
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		var buf bytes.Buffer
+		ctx := log.WithContext(t.Context(), log.New(&buf))
+
+		require.NoError(t, (&notify{URL: "https://hooks.slack.com/services/secret", Type: NotifyTypeSlack, Message: "build failed"}).DryRun(ctx))
+		assert.Equal(t, "INFO would notify type=slack payload=\"{\\\"text\\\":\\\"build failed\\\"}\"\n", buf.String())
+		assert.NotContains(t, buf.String(), "secret")
+
+		require.EqualError(t, (&notify{}).DryRun(ctx), "url must be set")
+	})
+}
@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package builtins
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+// maxPromptAttempts is how many times the user is asked before an invalid answer fails the step
+const maxPromptAttempts = 3
+
+// Prompter asks the user a question, returning their answer
+//
+// Secret answers must not be echoed back to the terminal
+type Prompter func(ctx context.Context, message string, secret bool) (string, error)
+
+type prompterKey struct{}
+
+// WithPrompter returns a context carrying the prompter used by builtin:prompt and builtin:confirm
+//
+// The prompter is provided by the CLI when stdin is a terminal, without one the builtins return their defaults
+func WithPrompter(ctx context.Context, prompter Prompter) context.Context {
+	return context.WithValue(ctx, prompterKey{}, prompter)
+}
+
+// prompterFromContext returns the prompter, or nil if the user cannot be asked
+func prompterFromContext(ctx context.Context) Prompter {
+	p, _ := ctx.Value(prompterKey{}).(Prompter)
+	return p
+}
+
+// Masker registers a value to be masked in all output for the rest of the run
+type Masker func(value string)
+
+type maskerKey struct{}
+
+// WithMasker returns a context carrying how builtin:prompt masks secret answers
+func WithMasker(ctx context.Context, masker Masker) context.Context {
+	return context.WithValue(ctx, maskerKey{}, masker)
+}
+
+// maskSecret masks the value in all output, if the context carries a masker
+func maskSecret(ctx context.Context, value string) {
+	if mask, ok := ctx.Value(maskerKey{}).(Masker); ok && mask != nil && value != "" {
+		mask(value)
+	}
+}
+
+// prompt asks the user for a value
+type prompt struct {
+	Message  string `json:"message"            mapstructure:"message"  jsonschema:"description=Question to ask the user"`
+	Default  string `json:"default,omitempty"  mapstructure:"default"  jsonschema:"description=Value used when no answer is given or the user cannot be asked"`
+	Validate string `json:"validate,omitempty" mapstructure:"validate" jsonschema:"description=Regular expression the value must match"`
+	Secret   bool   `json:"secret,omitempty"   mapstructure:"secret"   jsonschema:"description=Hide the answer as it is typed and mask it in all output"`
+}
+
+type promptOutputs struct {
+	Value string `json:"value" jsonschema:"description=The answer or the default"`
+}
+
+// Outputs describes the outputs of the builtin
+func (b *prompt) Outputs() any { return promptOutputs{} }
+
+// Execute the builtin
+func (b *prompt) Execute(ctx context.Context) (map[string]any, error) {
+	logger := log.FromContext(ctx)
+
+	if b.Message == "" {
+		return nil, fmt.Errorf("message must be set")
+	}
+
+	var expr *regexp.Regexp
+	if b.Validate != "" {
+		var err error
+		expr, err = regexp.Compile(b.Validate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid validate: %w", err)
+		}
+	}
+
+	ask := prompterFromContext(ctx)
+	if ask == nil {
+		logger.Debug("not interactive, using the default", "message", b.Message)
+		if expr != nil && !expr.MatchString(b.Default) {
+			return nil, fmt.Errorf("unable to prompt %q and the default does not match %s", b.Message, b.Validate)
+		}
+		if b.Secret {
+			maskSecret(ctx, b.Default)
+		}
+		return map[string]any{"value": b.Default}, nil
+	}
+
+	message := b.Message
+	if b.Default != "" && !b.Secret {
+		message = fmt.Sprintf("%s [%s]", message, b.Default)
+	}
+
+	for range maxPromptAttempts {
+		answer, err := ask(ctx, message+": ", b.Secret)
+		if err != nil {
+			return nil, err
+		}
+		value := strings.TrimSpace(answer)
+		if value == "" {
+			value = b.Default
+		}
+		if expr == nil || expr.MatchString(value) {
+			if b.Secret {
+				maskSecret(ctx, value)
+			}
+			return map[string]any{"value": value}, nil
+		}
+		logger.Warn("invalid value, must match", "validate", b.Validate)
+	}
+	return nil, fmt.Errorf("no valid value given after %d attempts", maxPromptAttempts)
+}
+
+// DryRun logs what would be asked
+func (b *prompt) DryRun(ctx context.Context) error {
+	if b.Secret {
+		log.FromContext(ctx).Info("would prompt", "message", b.Message, "secret", true)
+		return nil
+	}
+	log.FromContext(ctx).Info("would prompt", "message", b.Message, "default", b.Default)
+	return nil
+}
+
+// confirm asks the user a yes/no question
+type confirm struct {
+	Message string `json:"message"           mapstructure:"message" jsonschema:"description=Question to ask the user"`
+	Default bool   `json:"default,omitempty" mapstructure:"default" jsonschema:"description=Answer used when no answer is given or the user cannot be asked (defaults to false)"`
+}
+
+type confirmOutputs struct {
+	Confirmed bool `json:"confirmed" jsonschema:"description=Whether the user answered yes or the default"`
+}
+
+// Outputs describes the outputs of the builtin
+func (b *confirm) Outputs() any { return confirmOutputs{} }
+
+// Execute the builtin
+func (b *confirm) Execute(ctx context.Context) (map[string]any, error) {
+	logger := log.FromContext(ctx)
+
+	if b.Message == "" {
+		return nil, fmt.Errorf("message must be set")
+	}
+
+	ask := prompterFromContext(ctx)
+	if ask == nil {
+		logger.Debug("not interactive, using the default", "message", b.Message)
+		return map[string]any{"confirmed": b.Default}, nil
+	}
+
+	choices := "[y/N]"
+	if b.Default {
+		choices = "[Y/n]"
+	}
+
+	for range maxPromptAttempts {
+		answer, err := ask(ctx, fmt.Sprintf("%s %s: ", b.Message, choices), false)
+		if err != nil {
+			return nil, err
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "":
+			return map[string]any{"confirmed": b.Default}, nil
+		case "y", "yes":
+			return map[string]any{"confirmed": true}, nil
+		case "n", "no":
+			return map[string]any{"confirmed": false}, nil
+		}
+		logger.Warn("invalid answer, expected y or n")
+	}
+	return nil, fmt.Errorf("no valid answer given after %d attempts", maxPromptAttempts)
+}
+
+// DryRun logs what would be asked
+func (b *confirm) DryRun(ctx context.Context) error {
+	log.FromContext(ctx).Info("would confirm", "message", b.Message, "default", b.Default)
+	return nil
+}
@@ -0,0 +1,235 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package builtins
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// answers returns a prompter that answers with each of the given answers in turn, recording the questions asked
+func answers(asked *[]string, values ...string) Prompter {
+	return func(_ context.Context, message string, secret bool) (string, error) {
+		*asked = append(*asked, fmt.Sprintf("%s(secret=%t)", message, secret))
+		if len(values) == 0 {
+			return "", io.EOF
+		}
+		v := values[0]
+		values = values[1:]
+		return v, nil
+	}
+}
+
+func TestBuiltinPrompt(t *testing.T) {
+	testCases := []struct {
+		name          string
+		builtin       *prompt
+		answers       []string
+		noPrompter    bool
+		expected      map[string]any
+		expectedAsked []string
+		expectedErr   string
+	}{
+		{
+			name:          "answer",
+			builtin:       &prompt{Message: "Name"},
+			answers:       []string{"world\n"},
+			expected:      map[string]any{"value": "world"},
+			expectedAsked: []string{"Name: (secret=false)"},
+		},
+		{
+			name:          "default",
+			builtin:       &prompt{Message: "Name", Default: "world"},
+			answers:       []string{"\n"},
+			expected:      map[string]any{"value": "world"},
+			expectedAsked: []string{"Name [world]: (secret=false)"},
+		},
+		{
+			name:          "secret hides the default",
+			builtin:       &prompt{Message: "Token", Default: "hunter2", Secret: true},
+			answers:       []string{"s3cret"},
+			expected:      map[string]any{"value": "s3cret"},
+			expectedAsked: []string{"Token: (secret=true)"},
+		},
+		{
+			name:          "validate asks again",
+			builtin:       &prompt{Message: "Replicas", Validate: `^\d+$`},
+			answers:       []string{"three\n", "3\n"},
+			expected:      map[string]any{"value": "3"},
+			expectedAsked: []string{"Replicas: (secret=false)", "Replicas: (secret=false)"},
+		},
+		{
+			name:          "too many invalid answers",
+			builtin:       &prompt{Message: "Replicas", Validate: `^\d+$`},
+			answers:       []string{"a\n", "b\n", "c\n"},
+			expectedAsked: []string{"Replicas: (secret=false)", "Replicas: (secret=false)", "Replicas: (secret=false)"},
+			expectedErr:   "no valid value given after 3 attempts",
+		},
+		{
+			name:          "prompter error",
+			builtin:       &prompt{Message: "Name"},
+			expectedAsked: []string{"Name: (secret=false)"},
+			expectedErr:   "EOF",
+		},
+		{
+			name:       "not interactive",
+			builtin:    &prompt{Message: "Name", Default: "world"},
+			noPrompter: true,
+			expected:   map[string]any{"value": "world"},
+		},
+		{
+			name:        "not interactive with invalid default",
+			builtin:     &prompt{Message: "Replicas", Validate: `^\d+$`},
+			noPrompter:  true,
+			expectedErr: `unable to prompt "Replicas" and the default does not match ^\d+$`,
+		},
+		{
+			name:        "no message",
+			builtin:     &prompt{},
+			expectedErr: "message must be set",
+		},
+		{
+			name:        "invalid validate",
+			builtin:     &prompt{Message: "Name", Validate: "("},
+			expectedErr: "invalid validate: error parsing regexp: missing closing ): `(`",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := log.WithContext(t.Context(), log.New(io.Discard))
+			var asked []string
+			if !tc.noPrompter {
+				ctx = WithPrompter(ctx, answers(&asked, tc.answers...))
+			}
+
+			result, err := tc.builtin.Execute(ctx)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expected, result)
+			assert.Equal(t, tc.expectedAsked, asked)
+		})
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		var buf bytes.Buffer
+		ctx := log.WithContext(t.Context(), log.New(&buf))
+
+		require.NoError(t, (&prompt{Message: "Name", Default: "world"}).DryRun(ctx))
+		require.NoError(t, (&prompt{Message: "Token", Default: "hunter2", Secret: true}).DryRun(ctx))
+		assert.Equal(t, "INFO would prompt message=Name default=world\nINFO would prompt message=Token secret=true\n", buf.String())
+	})
+
+	t.Run("secret answers are masked", func(t *testing.T) {
+		var masked []string
+		ctx := log.WithContext(t.Context(), log.New(io.Discard))
+		ctx = WithMasker(ctx, func(value string) { masked = append(masked, value) })
+
+		_, err := (&prompt{Message: "Name", Default: "world"}).Execute(ctx)
+		require.NoError(t, err)
+		_, err = (&prompt{Message: "Token", Default: "hunter2", Secret: true}).Execute(ctx)
+		require.NoError(t, err)
+
+		var asked []string
+		_, err = (&prompt{Message: "Token", Secret: true}).Execute(WithPrompter(ctx, answers(&asked, " s3cr3t ")))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"hunter2", "s3cr3t"}, masked)
+	})
+}
+
+func TestBuiltinConfirm(t *testing.T) {
+	testCases := []struct {
+		name          string
+		builtin       *confirm
+		answers       []string
+		noPrompter    bool
+		expected      map[string]any
+		expectedAsked []string
+		expectedErr   string
+	}{
+		{
+			name:          "yes",
+			builtin:       &confirm{Message: "Continue?"},
+			answers:       []string{"Y\n"},
+			expected:      map[string]any{"confirmed": true},
+			expectedAsked: []string{"Continue? [y/N]: (secret=false)"},
+		},
+		{
+			name:          "no",
+			builtin:       &confirm{Message: "Continue?", Default: true},
+			answers:       []string{"no\n"},
+			expected:      map[string]any{"confirmed": false},
+			expectedAsked: []string{"Continue? [Y/n]: (secret=false)"},
+		},
+		{
+			name:          "default",
+			builtin:       &confirm{Message: "Continue?", Default: true},
+			answers:       []string{"\n"},
+			expected:      map[string]any{"confirmed": true},
+			expectedAsked: []string{"Continue? [Y/n]: (secret=false)"},
+		},
+		{
+			name:          "invalid answer asks again",
+			builtin:       &confirm{Message: "Continue?"},
+			answers:       []string{"maybe\n", "yes\n"},
+			expected:      map[string]any{"confirmed": true},
+			expectedAsked: []string{"Continue? [y/N]: (secret=false)", "Continue? [y/N]: (secret=false)"},
+		},
+		{
+			name:          "too many invalid answers",
+			builtin:       &confirm{Message: "Continue?"},
+			answers:       []string{"a\n", "b\n", "c\n"},
+			expectedAsked: []string{"Continue? [y/N]: (secret=false)", "Continue? [y/N]: (secret=false)", "Continue? [y/N]: (secret=false)"},
+			expectedErr:   "no valid answer given after 3 attempts",
+		},
+		{
+			name:       "not interactive",
+			builtin:    &confirm{Message: "Continue?", Default: true},
+			noPrompter: true,
+			expected:   map[string]any{"confirmed": true},
+		},
+		{
+			name:        "no message",
+			builtin:     &confirm{},
+			expectedErr: "message must be set",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := log.WithContext(t.Context(), log.New(io.Discard))
+			var asked []string
+			if !tc.noPrompter {
+				ctx = WithPrompter(ctx, answers(&asked, tc.answers...))
+			}
+
+			result, err := tc.builtin.Execute(ctx)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expected, result)
+			assert.Equal(t, tc.expectedAsked, asked)
+		})
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		var buf bytes.Buffer
+		ctx := log.WithContext(t.Context(), log.New(&buf))
+
+		require.NoError(t, (&confirm{Message: "Continue?"}).DryRun(ctx))
+		assert.Equal(t, "INFO would confirm message=Continue? default=false\n", buf.String())
+	})
+}
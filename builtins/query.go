@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package builtins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"github.com/goccy/go-yaml"
+)
+
+// Limits applied to query expressions, mirroring those of if expressions
+const (
+	queryMaxNodes     = 1000
+	queryMemoryBudget = 100_000
+)
+
+// query evaluates an expression against a JSON or YAML document
+type query struct {
+	File  string `json:"file,omitempty"  mapstructure:"file"  jsonschema:"description=Path of a JSON or YAML file to query"`
+	Input string `json:"input,omitempty" mapstructure:"input" jsonschema:"description=JSON or YAML document to query (e.g. the output of a previous step)"`
+	Query string `json:"query"           mapstructure:"query" jsonschema:"description=Expression to evaluate against the document (available as data); a leading . is short for data (e.g. .items[0].name)"`
+}
+
+type queryOutputs struct {
+	Result any    `json:"result" jsonschema:"description=The result of the query"`
+	JSON   string `json:"json"   jsonschema:"description=The result of the query encoded as JSON"`
+}
+
+// Outputs describes the outputs of the builtin
+func (b *query) Outputs() any { return queryOutputs{} }
+
+// Execute the builtin
+func (b *query) Execute(ctx context.Context) (map[string]any, error) {
+	logger := log.FromContext(ctx)
+
+	if (b.File == "") == (b.Input == "") {
+		return nil, fmt.Errorf("exactly one of file or input must be set")
+	}
+	if b.Query == "" {
+		return nil, fmt.Errorf("query must be set")
+	}
+
+	src := []byte(b.Input)
+	if b.File != "" {
+		if err := checkFileRead(ctx, b.File); err != nil {
+			return nil, err
+		}
+		var err error
+		src, err = os.ReadFile(resolvePath(ctx, b.File))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// JSON is a subset of YAML, so both are parsed the same
+	var data any
+	if err := yaml.Unmarshal(src, &data); err != nil {
+		return nil, fmt.Errorf("unable to parse document: %w", err)
+	}
+
+	expression := strings.TrimSpace(b.Query)
+	switch {
+	case expression == ".":
+		expression = "data"
+	case strings.HasPrefix(expression, ".["):
+		expression = "data" + expression[1:]
+	case strings.HasPrefix(expression, "."):
+		expression = "data" + expression
+	}
+
+	env := map[string]any{"data": data}
+	program, err := expr.Compile(expression, expr.Env(env), expr.MaxNodes(queryMaxNodes))
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+
+	machine := vm.VM{MemoryBudget: queryMemoryBudget}
+	result, err := machine.Run(program, env)
+	if err != nil {
+		return nil, fmt.Errorf("unable to evaluate query: %w", err)
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode result: %w", err)
+	}
+
+	logger.Debug("queried", "query", b.Query, "result", string(encoded))
+
+	return map[string]any{
+		"result": result,
+		"json":   string(encoded),
+	}, nil
+}
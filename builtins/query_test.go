@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package builtins
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinQuery(t *testing.T) {
+	dir := t.TempDir()
+	values := filepath.Join(dir, "values.yaml")
+	require.NoError(t, os.WriteFile(values, []byte(`image:
+  repository: ghcr.io/org/app
+  tag: v1.2.3
+replicas: 3
+ingress:
+  enabled: false
+`), 0o644))
+
+	manifest := `{"items":[{"name":"a","ready":true},{"name":"b","ready":false}],"bad-key":"x"}`
+
+	testCases := []struct {
+		name         string
+		builtin      *query
+		expected     any
+		expectedJSON string
+		expectedErr  string
+	}{
+		{
+			name:         "yaml file path",
+			builtin:      &query{File: values, Query: ".image.tag"},
+			expected:     "v1.2.3",
+			expectedJSON: `"v1.2.3"`,
+		},
+		{
+			name:         "number",
+			builtin:      &query{File: values, Query: ".replicas"},
+			expected:     uint64(3),
+			expectedJSON: `3`,
+		},
+		{
+			name:         "bool",
+			builtin:      &query{File: values, Query: "data.ingress.enabled"},
+			expected:     false,
+			expectedJSON: `false`,
+		},
+		{
+			name:         "whole document",
+			builtin:      &query{Input: `{"a": 1}`, Query: "."},
+			expected:     map[string]any{"a": uint64(1)},
+			expectedJSON: `{"a":1}`,
+		},
+		{
+			name:         "index",
+			builtin:      &query{Input: `[1, 2, 3]`, Query: ".[1]"},
+			expected:     uint64(2),
+			expectedJSON: `2`,
+		},
+		{
+			name:         "json input with functions",
+			builtin:      &query{Input: manifest, Query: `map(filter(data.items, .ready), .name)`},
+			expected:     []any{"a"},
+			expectedJSON: `["a"]`,
+		},
+		{
+			name:         "key with dashes",
+			builtin:      &query{Input: manifest, Query: `.["bad-key"]`},
+			expected:     "x",
+			expectedJSON: `"x"`,
+		},
+		{
+			name:         "missing key",
+			builtin:      &query{Input: manifest, Query: ".missing"},
+			expected:     nil,
+			expectedJSON: `null`,
+		},
+		{
+			name:        "no document",
+			builtin:     &query{Query: "."},
+			expectedErr: "exactly one of file or input must be set",
+		},
+		{
+			name:        "both documents",
+			builtin:     &query{File: values, Input: "{}", Query: "."},
+			expectedErr: "exactly one of file or input must be set",
+		},
+		{
+			name:        "no query",
+			builtin:     &query{Input: "{}"},
+			expectedErr: "query must be set",
+		},
+		{
+			name:        "missing file",
+			builtin:     &query{File: filepath.Join(dir, "missing.yaml"), Query: "."},
+			expectedErr: "no such file or directory",
+		},
+		{
+			name:        "invalid document",
+			builtin:     &query{Input: "a: [", Query: "."},
+			expectedErr: "unable to parse document",
+		},
+		{
+			name:        "invalid query",
+			builtin:     &query{Input: "{}", Query: ".a +"},
+			expectedErr: "invalid query",
+		},
+		{
+			name:        "evaluation error",
+			builtin:     &query{Input: manifest, Query: ".items[5]"},
+			expectedErr: "unable to evaluate query: index out of range",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := log.WithContext(t.Context(), log.New(io.Discard))
+
+			result, err := tc.builtin.Execute(ctx)
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				assert.Nil(t, result)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, map[string]any{"result": tc.expected, "json": tc.expectedJSON}, result)
+		})
+	}
+}
@@ -6,6 +6,7 @@ package builtins
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"slices"
 	"sync"
 )
@@ -20,10 +21,80 @@ type Builtin interface {
 	Execute(ctx context.Context) (map[string]any, error)
 }
 
+// DryRunner is implemented by builtins that can describe what they would do during a dry run
+//
+// DryRun is called instead of Execute, it must not have side effects
+type DryRunner interface {
+	DryRun(ctx context.Context) error
+}
+
+// OutputDescriber is implemented by builtins that describe their outputs
+//
+// Outputs returns a zero value of a struct whose fields, like the builtin's own, describe the outputs through their struct tags
+type OutputDescriber interface {
+	Outputs() any
+}
+
+type workingDirKey struct{}
+
+// WithWorkingDir returns a context carrying the working directory of the step running a builtin
+//
+// Relative paths given to builtins are resolved against it, the same as the commands of run steps
+func WithWorkingDir(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, workingDirKey{}, dir)
+}
+
+// workingDir returns the working directory carried by the context, empty for the current directory
+func workingDir(ctx context.Context) string {
+	dir, _ := ctx.Value(workingDirKey{}).(string)
+	return dir
+}
+
+// resolvePath joins a relative path onto the working directory carried by the context
+func resolvePath(ctx context.Context, p string) string {
+	dir := workingDir(ctx)
+	if dir == "" || p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(dir, p)
+}
+
+// FileReadCheck returns an error if a builtin is not allowed to read the local files at path
+type FileReadCheck func(path string) error
+
+type fileReadCheckKey struct{}
+
+// WithFileReadCheck returns a context carrying the check made before builtins read local files
+//
+// The runner uses it to stop remote workflows from reading local files when run with --no-remote-file-reads
+func WithFileReadCheck(ctx context.Context, check FileReadCheck) context.Context {
+	return context.WithValue(ctx, fileReadCheckKey{}, check)
+}
+
+// checkFileRead returns an error if the check carried by the context does not allow reading path
+func checkFileRead(ctx context.Context, path string) error {
+	if check, ok := ctx.Value(fileReadCheckKey{}).(FileReadCheck); ok && check != nil {
+		return check(path)
+	}
+	return nil
+}
+
 var _registrations = map[string]func() Builtin{
+	"cache-restore": func() Builtin { return &cacheRestore{} },
+	"cache-save":    func() Builtin { return &cacheSave{} },
+	"confirm":       func() Builtin { return &confirm{} },
 	"echo":          func() Builtin { return &echo{} },
 	"fetch":         func() Builtin { return &fetch{} },
+	"fs":            func() Builtin { return &files{} },
+	"http-request":  func() Builtin { return &httpRequest{} },
+	"maru2":         func() Builtin { return &maru2{} },
+	"notify":        func() Builtin { return &notify{} },
+	"prompt":        func() Builtin { return &prompt{} },
+	"push-artifact": func() Builtin { return &pushArtifact{} },
+	"query":         func() Builtin { return &query{} },
+	"template":      func() Builtin { return &tmpl{} },
 	"wacky-structs": func() Builtin { return &wackyStructs{} },
+	"wait-for":      func() Builtin { return &waitFor{} },
 }
 
 // Get retrieves a fresh instance of a registered builtin task
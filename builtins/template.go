@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package builtins
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/charmbracelet/log"
+)
+
+// Renderer expands templates in text, with the same functions (input, from, secret, etc...) available to the calling step
+type Renderer func(ctx context.Context, text string) (string, error)
+
+type rendererKey struct{}
+
+// WithRenderer returns a context carrying the renderer used by builtin:template
+//
+// The renderer is provided by the runner, as this package cannot depend upon it
+func WithRenderer(ctx context.Context, renderer Renderer) context.Context {
+	return context.WithValue(ctx, rendererKey{}, renderer)
+}
+
+// tmpl renders a template file to a path
+type tmpl struct {
+	File string `json:"file"           mapstructure:"file" jsonschema:"description=Path of the template file to render"`
+	Path string `json:"path"           mapstructure:"path" jsonschema:"description=Path to write the rendered file to"`
+	Mode string `json:"mode,omitempty" mapstructure:"mode" jsonschema:"description=File mode of the rendered file in octal (defaults to 0644),pattern=^0?[0-7]{3}$"`
+}
+
+type tmplOutputs struct {
+	Path string `json:"path" jsonschema:"description=The absolute path of the rendered file"`
+}
+
+// Outputs describes the outputs of the builtin
+func (b *tmpl) Outputs() any { return tmplOutputs{} }
+
+// Execute the builtin
+func (b *tmpl) Execute(ctx context.Context) (map[string]any, error) {
+	logger := log.FromContext(ctx)
+
+	render, ok := ctx.Value(rendererKey{}).(Renderer)
+	if !ok || render == nil {
+		return nil, fmt.Errorf("not running within a workflow")
+	}
+
+	if b.File == "" || b.Path == "" {
+		return nil, fmt.Errorf("file and path must be set")
+	}
+
+	mode := os.FileMode(0o644)
+	if b.Mode != "" {
+		m, err := strconv.ParseUint(b.Mode, 8, 32)
+		if err != nil || m > 0o777 {
+			return nil, fmt.Errorf("invalid mode %q", b.Mode)
+		}
+		mode = os.FileMode(m)
+	}
+
+	if err := checkFileRead(ctx, b.File); err != nil {
+		return nil, err
+	}
+
+	src, err := os.ReadFile(resolvePath(ctx, b.File))
+	if err != nil {
+		return nil, err
+	}
+
+	rendered, err := render(ctx, string(src))
+	if err != nil {
+		return nil, fmt.Errorf("unable to render %s: %w", b.File, err)
+	}
+
+	dst, err := filepath.Abs(resolvePath(ctx, b.Path))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(dst, []byte(rendered), mode); err != nil {
+		return nil, err
+	}
+	// the mode of an existing file is not changed by os.WriteFile
+	if err := os.Chmod(dst, mode); err != nil {
+		return nil, err
+	}
+
+	logger.Info("rendered", "file", b.File, "path", dst)
+
+	return map[string]any{"path": dst}, nil
+}
@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package builtins
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinTemplate(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "config.yaml.tmpl")
+	require.NoError(t, os.WriteFile(src, []byte("env: ${{ input \"env\" }}\n"), 0o644))
+
+	render := Renderer(func(_ context.Context, text string) (string, error) {
+		return strings.ReplaceAll(text, `${{ input "env" }}`, "prod"), nil
+	})
+
+	testCases := []struct {
+		name        string
+		builtin     *tmpl
+		renderer    Renderer
+		expected    string
+		mode        os.FileMode
+		expectedErr string
+	}{
+		{
+			name:        "no renderer",
+			builtin:     &tmpl{File: src, Path: filepath.Join(dir, "no-renderer.yaml")},
+			expectedErr: "not running within a workflow",
+		},
+		{
+			name:        "no path",
+			builtin:     &tmpl{File: src},
+			renderer:    render,
+			expectedErr: "file and path must be set",
+		},
+		{
+			name:     "renders",
+			builtin:  &tmpl{File: src, Path: filepath.Join(dir, "out", "config.yaml")},
+			renderer: render,
+			expected: "env: prod\n",
+			mode:     0o644,
+		},
+		{
+			name:     "mode",
+			builtin:  &tmpl{File: src, Path: filepath.Join(dir, "run.sh"), Mode: "0755"},
+			renderer: render,
+			expected: "env: prod\n",
+			mode:     0o755,
+		},
+		{
+			name:        "invalid mode",
+			builtin:     &tmpl{File: src, Path: filepath.Join(dir, "invalid.yaml"), Mode: "rwx"},
+			renderer:    render,
+			expectedErr: `invalid mode "rwx"`,
+		},
+		{
+			name:        "missing file",
+			builtin:     &tmpl{File: filepath.Join(dir, "missing.tmpl"), Path: filepath.Join(dir, "missing.yaml")},
+			renderer:    render,
+			expectedErr: "no such file or directory",
+		},
+		{
+			name:        "render error",
+			builtin:     &tmpl{File: src, Path: filepath.Join(dir, "fail.yaml")},
+			renderer:    func(context.Context, string) (string, error) { return "", fmt.Errorf("boom") },
+			expectedErr: fmt.Sprintf("unable to render %s: boom", src),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := log.WithContext(t.Context(), log.New(io.Discard))
+			if tc.renderer != nil {
+				ctx = WithRenderer(ctx, tc.renderer)
+			}
+
+			result, err := tc.builtin.Execute(ctx)
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				assert.Nil(t, result)
+				if tc.builtin.Path != "" {
+					assert.NoFileExists(t, tc.builtin.Path)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, map[string]any{"path": tc.builtin.Path}, result)
+
+			b, err := os.ReadFile(tc.builtin.Path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(b))
+
+			fi, err := os.Stat(tc.builtin.Path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.mode, fi.Mode().Perm())
+		})
+	}
+
+	t.Run("file reads disabled", func(t *testing.T) {
+		ctx := WithRenderer(log.WithContext(t.Context(), log.New(io.Discard)), render)
+		ctx = WithFileReadCheck(ctx, func(path string) error {
+			return fmt.Errorf("unable to read %q", path)
+		})
+
+		dst := filepath.Join(dir, "disabled.yaml")
+		result, err := (&tmpl{File: src, Path: dst}).Execute(ctx)
+		require.EqualError(t, err, fmt.Sprintf("unable to read %q", src))
+		assert.Nil(t, result)
+		assert.NoFileExists(t, dst)
+	})
+}
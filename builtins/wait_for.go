@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package builtins
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// waitFor polls a TCP address, HTTP endpoint or file until it is ready, for steps that depend on background services
+type waitFor struct {
+	TCP         string `json:"tcp,omitempty"          mapstructure:"tcp"          jsonschema:"description=Address (host:port) to wait for a TCP connection to succeed"`
+	HTTP        string `json:"http,omitempty"         mapstructure:"http"         jsonschema:"description=URL to wait for a GET request to respond with the expected status"`
+	Status      int    `json:"status,omitempty"       mapstructure:"status"       jsonschema:"description=Expected HTTP status code (defaults to 200),minimum=100,maximum=599"`
+	HostHeaders bool   `json:"host-headers,omitempty" mapstructure:"host-headers" jsonschema:"description=Also send the headers configured for the host in the system config (defaults to false)"`
+	File        string `json:"file,omitempty"         mapstructure:"file"         jsonschema:"description=Path to wait for to exist"`
+	Timeout     string `json:"timeout,omitempty"      mapstructure:"timeout"      jsonschema:"description=Maximum time to wait (defaults to 1m)"`
+	Interval    string `json:"interval,omitempty"     mapstructure:"interval"     jsonschema:"description=Time between checks (defaults to 1s)"`
+
+	parsedTimeout  time.Duration
+	parsedInterval time.Duration
+}
+
+type waitForOutputs struct {
+	Attempts int    `json:"attempts" jsonschema:"description=The number of checks made"`
+	Elapsed  string `json:"elapsed"  jsonschema:"description=How long it took to be ready (e.g. 1.5s)"`
+}
+
+// Outputs describes the outputs of the builtin
+func (b *waitFor) Outputs() any { return waitForOutputs{} }
+
+// Execute the builtin
+func (b *waitFor) Execute(ctx context.Context) (map[string]any, error) {
+	logger := log.FromContext(ctx)
+
+	if err := b.setDefaults(); err != nil {
+		return nil, err
+	}
+
+	client := httpClient(ctx, b.HostHeaders, b.parsedInterval)
+
+	ctx, cancel := context.WithTimeout(ctx, b.parsedTimeout)
+	defer cancel()
+
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		err := b.check(ctx, client)
+		if err == nil {
+			elapsed := time.Since(start).Round(time.Millisecond)
+			logger.Info("ready", b.kind(), b.target(), "attempts", attempt, "elapsed", elapsed)
+			return map[string]any{
+				"attempts": attempt,
+				"elapsed":  elapsed.String(),
+			}, nil
+		}
+		logger.Debug("not ready", b.kind(), b.target(), "attempt", attempt, "err", err)
+
+		timer := time.NewTimer(b.parsedInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("timed out after %s waiting for %s %s: %w", b.parsedTimeout, b.kind(), b.target(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// DryRun logs what would be waited for
+func (b *waitFor) DryRun(ctx context.Context) error {
+	if err := b.setDefaults(); err != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("would wait for", b.kind(), b.target(), "timeout", b.parsedTimeout, "interval", b.parsedInterval)
+	return nil
+}
+
+// check returns nil once the target is ready
+func (b *waitFor) check(ctx context.Context, client *http.Client) error {
+	switch {
+	case b.TCP != "":
+		var d net.Dialer
+		dialCtx, cancel := context.WithTimeout(ctx, b.parsedInterval)
+		defer cancel()
+		conn, err := d.DialContext(dialCtx, "tcp", b.TCP)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	case b.HTTP != "":
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.HTTP, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		// drain the body so the connection can be reused
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+		resp.Body.Close()
+		if resp.StatusCode != b.Status {
+			return fmt.Errorf("expected status code %d got %d", b.Status, resp.StatusCode)
+		}
+		return nil
+	default:
+		_, err := os.Stat(resolvePath(ctx, b.File))
+		return err
+	}
+}
+
+// kind returns what is being waited for (tcp, http or file)
+func (b *waitFor) kind() string {
+	switch {
+	case b.TCP != "":
+		return "tcp"
+	case b.HTTP != "":
+		return "http"
+	default:
+		return "file"
+	}
+}
+
+// target returns the address, URL or path being waited for
+func (b *waitFor) target() string {
+	switch {
+	case b.TCP != "":
+		return b.TCP
+	case b.HTTP != "":
+		return b.HTTP
+	default:
+		return b.File
+	}
+}
+
+func (b *waitFor) setDefaults() error {
+	set := 0
+	for _, target := range []string{b.TCP, b.HTTP, b.File} {
+		if target != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("exactly one of tcp, http or file must be set")
+	}
+
+	if b.Status == 0 {
+		b.Status = http.StatusOK
+	}
+	if b.HTTP == "" && b.Status != http.StatusOK {
+		return fmt.Errorf("status can only be set with http")
+	}
+
+	b.parsedTimeout = time.Minute
+	if b.Timeout != "" {
+		parsedTimeout, err := time.ParseDuration(b.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout: %w", err)
+		}
+		b.parsedTimeout = parsedTimeout
+	}
+
+	b.parsedInterval = time.Second
+	if b.Interval != "" {
+		parsedInterval, err := time.ParseDuration(b.Interval)
+		if err != nil {
+			return fmt.Errorf("invalid interval: %w", err)
+		}
+		b.parsedInterval = parsedInterval
+	}
+
+	if b.parsedTimeout <= 0 || b.parsedInterval <= 0 {
+		return fmt.Errorf("timeout and interval must be greater than 0")
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package builtins
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinWaitFor(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ready":
+			w.WriteHeader(http.StatusOK)
+		case "/eventually":
+			if requests.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = lis.Close() })
+
+	// a port that nothing is listening on
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddr := closed.Addr().String()
+	require.NoError(t, closed.Close())
+
+	dir := t.TempDir()
+	ready := filepath.Join(dir, "ready")
+	require.NoError(t, os.WriteFile(ready, nil, 0o644))
+
+	testCases := []struct {
+		name             string
+		builtin          *waitFor
+		expectedAttempts int
+		expectedErr      string
+	}{
+		{
+			name:             "tcp",
+			builtin:          &waitFor{TCP: lis.Addr().String()},
+			expectedAttempts: 1,
+		},
+		{
+			name:             "http",
+			builtin:          &waitFor{HTTP: server.URL + "/ready"},
+			expectedAttempts: 1,
+		},
+		{
+			name:             "http eventually ready with status",
+			builtin:          &waitFor{HTTP: server.URL + "/eventually", Status: http.StatusNoContent, Interval: "10ms"},
+			expectedAttempts: 3,
+		},
+		{
+			name:             "file",
+			builtin:          &waitFor{File: ready},
+			expectedAttempts: 1,
+		},
+		{
+			name:        "tcp timeout",
+			builtin:     &waitFor{TCP: closedAddr, Timeout: "50ms", Interval: "10ms"},
+			expectedErr: "timed out after 50ms waiting for tcp " + closedAddr + ": dial tcp " + closedAddr,
+		},
+		{
+			name:        "http timeout",
+			builtin:     &waitFor{HTTP: server.URL + "/missing", Timeout: "50ms", Interval: "10ms"},
+			expectedErr: "timed out after 50ms waiting for http " + server.URL + "/missing: expected status code 200 got 404",
+		},
+		{
+			name:        "file timeout",
+			builtin:     &waitFor{File: filepath.Join(dir, "missing"), Timeout: "50ms", Interval: "10ms"},
+			expectedErr: "timed out after 50ms waiting for file " + filepath.Join(dir, "missing") + ": stat " + filepath.Join(dir, "missing") + ": no such file or directory",
+		},
+		{
+			name:        "nothing to wait for",
+			builtin:     &waitFor{},
+			expectedErr: "exactly one of tcp, http or file must be set",
+		},
+		{
+			name:        "multiple targets",
+			builtin:     &waitFor{TCP: lis.Addr().String(), File: ready},
+			expectedErr: "exactly one of tcp, http or file must be set",
+		},
+		{
+			name:        "status without http",
+			builtin:     &waitFor{File: ready, Status: http.StatusNoContent},
+			expectedErr: "status can only be set with http",
+		},
+		{
+			name:        "invalid timeout",
+			builtin:     &waitFor{File: ready, Timeout: "soon"},
+			expectedErr: `invalid timeout: time: invalid duration "soon"`,
+		},
+		{
+			name:        "invalid interval",
+			builtin:     &waitFor{File: ready, Interval: "often"},
+			expectedErr: `invalid interval: time: invalid duration "often"`,
+		},
+		{
+			name:        "zero interval",
+			builtin:     &waitFor{File: ready, Interval: "0s"},
+			expectedErr: "timeout and interval must be greater than 0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := log.WithContext(t.Context(), log.New(io.Discard))
+
+			result, err := tc.builtin.Execute(ctx)
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				assert.Nil(t, result)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedAttempts, result["attempts"])
+			_, err = time.ParseDuration(result["elapsed"].(string))
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		var buf bytes.Buffer
+		ctx := log.WithContext(t.Context(), log.New(&buf))
+
+		require.NoError(t, (&waitFor{HTTP: "http://localhost:8080/healthz", Timeout: "30s"}).DryRun(ctx))
+		assert.Equal(t, "INFO would wait for http=http://localhost:8080/healthz timeout=30s interval=1s\n", buf.String())
+
+		require.EqualError(t, (&waitFor{}).DryRun(ctx), "exactly one of tcp, http or file must be set")
+	})
+
+	t.Run("working dir", func(t *testing.T) {
+		ctx := WithWorkingDir(log.WithContext(t.Context(), log.New(io.Discard)), dir)
+
+		result, err := (&waitFor{File: "ready"}).Execute(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, result["attempts"])
+	})
+}
@@ -5,6 +5,7 @@ package maru2
 
 import (
 	"bytes"
+	"context"
 	"testing"
 
 	"github.com/charmbracelet/log"
@@ -63,6 +64,32 @@ func TestExecuteBuiltin(t *testing.T) {
 			dry:         true, // Use dry run to avoid actual HTTP requests
 			expectedLog: "dry run",
 		},
+		{
+			name: "fs builtin dry run",
+			step: v1.Step{
+				Uses: "builtin:fs",
+				With: schema.With{
+					"action": "mkdir",
+					"paths":  []any{"${{ input \"dir\" }}"},
+				},
+			},
+			with:        schema.With{"dir": "does-not-exist/yet"},
+			dry:         true,
+			expectedLog: "would mkdir path=does-not-exist/yet",
+		},
+		{
+			name: "fs builtin dry run with invalid action",
+			step: v1.Step{
+				Uses: "builtin:fs",
+				With: schema.With{
+					"action": "touch",
+					"paths":  []any{"a"},
+				},
+			},
+			with:          schema.With{},
+			dry:           true,
+			expectedError: `builtin:fs: unsupported action "touch", expected one of copy, move, remove, mkdir or chmod`,
+		},
 		{
 			name: "non-existent builtin",
 			step: v1.Step{
@@ -80,7 +107,7 @@ func TestExecuteBuiltin(t *testing.T) {
 				},
 			},
 			with:          schema.With{},
-			expectedError: "builtin:echo: decoding failed due to the following error(s):\n\n'Text' expected type 'string', got unconvertible type 'chan int'",
+			expectedError: "builtin:echo: with: json: unsupported type: chan int",
 		},
 		{
 			name: "fetch builtin with invalid with",
@@ -88,7 +115,7 @@ func TestExecuteBuiltin(t *testing.T) {
 				Uses: "builtin:fetch",
 			},
 			with:          schema.With{},
-			expectedError: "builtin:fetch: error executing request: Get \"\": unsupported protocol scheme \"\"",
+			expectedError: "builtin:fetch: with: url is required",
 		},
 		{
 			name: "echo builtin with templated with",
@@ -102,6 +129,19 @@ func TestExecuteBuiltin(t *testing.T) {
 			expectedLog: "Hello from template\n",
 			expected:    map[string]any{"stdout": "Hello from template"},
 		},
+		{
+			name: "builtin with invalid templated with",
+			step: v1.Step{
+				Uses: "builtin:wait-for",
+				With: schema.With{
+					"http":    "http://localhost",
+					"timeout": "${{ input \"timeout\" }}",
+					"status":  "${{ input \"status\" }}",
+				},
+			},
+			with:          schema.With{"timeout": "1m", "status": "ok"},
+			expectedError: "builtin:wait-for: with.status: \"ok\" is not an integer",
+		},
 		{
 			name: "echo builtin with broken structure",
 			step: v1.Step{
@@ -111,7 +151,7 @@ func TestExecuteBuiltin(t *testing.T) {
 				},
 			},
 			with:          schema.With{},
-			expectedError: "builtin:echo: decoding failed due to the following error(s):\n\n'Text' expected type 'string', got unconvertible type '[]string'",
+			expectedError: "builtin:echo: with.text: Invalid type. Expected: string, given: array",
 		},
 		{
 			name: "echo builtin with previous step output",
@@ -291,3 +331,70 @@ func TestExecuteBuiltin(t *testing.T) {
 		})
 	}
 }
+
+// greet is a builtin registered by an embedder
+type greet struct {
+	Greeting string `json:"greeting"`
+	Name     string `json:"name"`
+}
+
+func (b *greet) Execute(_ context.Context) (map[string]any, error) {
+	return map[string]any{"stdout": b.Greeting + ", " + b.Name}, nil
+}
+
+func TestRegisterBuiltin(t *testing.T) {
+	require.EqualError(t, RegisterBuiltin("echo", &greet{}), `"echo" is already registered`)
+	require.EqualError(t, RegisterBuiltin("greet", nil), `builtin "greet" must be a non-nil pointer to a struct, got <nil>`)
+	require.EqualError(t, RegisterBuiltin("greet", (*greet)(nil)), `builtin "greet" must be a non-nil pointer to a struct, got *maru2.greet`)
+
+	factory, err := copier(&greet{Greeting: "Hello"})
+	require.NoError(t, err)
+
+	// every call returns a copy of the prototype, so steps do not share state
+	first := factory()
+	second := factory()
+	require.NotSame(t, first, second)
+	first.(*greet).Name = "world"
+	assert.Equal(t, &greet{Greeting: "Hello"}, second)
+
+	result, err := first.Execute(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"stdout": "Hello, world"}, result)
+}
+
+func TestDescribeBuiltin(t *testing.T) {
+	desc, ok := DescribeBuiltin("wait-for")
+	require.True(t, ok)
+	assert.Equal(t, "wait-for", desc.Name)
+	assert.True(t, desc.DryRun)
+	assert.Empty(t, desc.Inputs.Version)
+
+	timeout, ok := desc.Inputs.Properties.Get("timeout")
+	require.True(t, ok)
+	assert.Equal(t, "string", timeout.Type)
+	assert.Equal(t, "Maximum time to wait (defaults to 1m)", timeout.Description)
+
+	require.NotNil(t, desc.Outputs)
+	attempts, ok := desc.Outputs.Properties.Get("attempts")
+	require.True(t, ok)
+	assert.Equal(t, "integer", attempts.Type)
+
+	// builtin:maru2 returns the outputs of the task it runs, so does not describe them
+	desc, ok = DescribeBuiltin("maru2")
+	require.True(t, ok)
+	assert.False(t, desc.DryRun)
+	assert.Nil(t, desc.Outputs)
+
+	_, ok = DescribeBuiltin("does-not-exist")
+	assert.False(t, ok)
+
+	// every builtin native to maru2 describes its outputs
+	for _, name := range []string{"cache-restore", "cache-save", "confirm", "echo", "fetch", "fs", "http-request", "notify", "prompt", "push-artifact", "query", "template", "wait-for"} {
+		desc, ok := DescribeBuiltin(name)
+		require.True(t, ok, name)
+		require.NotNil(t, desc.Outputs, name)
+		for pair := desc.Outputs.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			assert.NotEmpty(t, pair.Value.Description, "%s output %s", name, pair.Key)
+		}
+	}
+}
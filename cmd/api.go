@@ -0,0 +1,202 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/url"
+
+	"github.com/spf13/cobra"
+
+	"github.com/defenseunicorns/maru2"
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+// newAPICmd creates the `api` sub-command, a stable machine readable interface for editors and other integrations
+func newAPICmd(src workflowSource) *cobra.Command {
+	var stdin bool
+
+	api := &cobra.Command{
+		Use:   "api",
+		Short: "Machine readable JSON interface for editor integrations",
+		Long: `Machine readable JSON interface for editor integrations
+
+Every command prints a single JSON object to stdout, see "maru2 api schema" for the response schema.
+
+The response schema is versioned using the "api-version" field, fields may be added within a version but are never removed or changed.`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	api.PersistentFlags().BoolVar(&stdin, "stdin", false, "Read the workflow from stdin instead of --from (e.g. for unsaved editor buffers)")
+
+	// run loads the workflow and writes the response for a command
+	run := func(cmd *cobra.Command, fill func(res *maru2.APIResponse, w apiWorkflow) error) error {
+		res := maru2.APIResponse{
+			APIVersion: maru2.APIVersion,
+			Command:    cmd.Name(),
+		}
+
+		w, err := readWorkflow(cmd, src, stdin)
+		if err == nil {
+			if !stdin {
+				res.Location = w.origin.String()
+			}
+			err = fill(&res, w)
+		}
+		if err != nil {
+			res.Error = err.Error()
+		}
+
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		if encErr := enc.Encode(res); encErr != nil {
+			return encErr
+		}
+		return err
+	}
+
+	// parse merges the workflows that w includes, the same as when it is run
+	parse := func(cmd *cobra.Command, w apiWorkflow) (v1.Workflow, error) {
+		return maru2.ReadWorkflow(cmd.Context(), w.svc, bytes.NewReader(w.raw), w.origin)
+	}
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List tasks in the workflow",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return run(cmd, func(res *maru2.APIResponse, w apiWorkflow) error {
+				wf, err := parse(cmd, w)
+				if err != nil {
+					return err
+				}
+				res.List = maru2.APIListTasks(wf)
+				return nil
+			})
+		},
+	}
+
+	describe := &cobra.Command{
+		Use:   "describe",
+		Short: "Describe the full workflow, migrated to the latest schema version",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return run(cmd, func(res *maru2.APIResponse, w apiWorkflow) error {
+				wf, err := parse(cmd, w)
+				if err != nil {
+					return err
+				}
+				res.Describe = &wf
+				return nil
+			})
+		},
+	}
+
+	validate := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the workflow",
+		Long: `Validate the workflow
+
+An invalid workflow is not an error, check the "valid" field of the response.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return run(cmd, func(res *maru2.APIResponse, w apiWorkflow) error {
+				result := maru2.APIValidateWorkflow(cmd.Context(), w.svc, bytes.NewReader(w.raw), w.origin)
+				res.Validate = &result
+				return nil
+			})
+		},
+	}
+
+	explain := &cobra.Command{
+		Use:   "explain [task...]",
+		Short: "Explain the workflow or task(s) as markdown",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(cmd, func(res *maru2.APIResponse, w apiWorkflow) error {
+				wf, err := parse(cmd, w)
+				if err != nil {
+					return err
+				}
+				res.Explain, err = maru2.Explain(cmd.Context(), w.svc, wf, w.origin, args...)
+				return err
+			})
+		},
+	}
+
+	schema := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON schema for API responses",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(maru2.APISchema())
+		},
+	}
+
+	serve := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve diagnostics, completions and hover docs over stdin/stdout",
+		Long: `Serve diagnostics, completions and hover docs over stdin/stdout
+
+Reads one JSON request per line from stdin and writes one JSON response per line to stdout until stdin is closed.
+Requests include the full document text, so unsaved editor buffers are supported.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return maru2.ServeEditor(cmd.Context(), cmd.InOrStdin(), cmd.OutOrStdout())
+		},
+	}
+
+	for _, sub := range []*cobra.Command{list, describe, validate, explain, schema, serve} {
+		sub.SilenceUsage = true
+		sub.SilenceErrors = true
+		api.AddCommand(sub)
+	}
+
+	return api
+}
+
+// apiWorkflow is the raw workflow read by an api command, along with its location and the service to fetch what it references
+type apiWorkflow struct {
+	raw    []byte
+	origin *url.URL
+	svc    *uses.FetcherService
+}
+
+// readWorkflow reads the raw workflow from stdin or the location set by --from
+//
+// A workflow read from stdin is treated as the contents of the workflow set by --from, so its includes are resolved the same
+func readWorkflow(cmd *cobra.Command, src workflowSource, stdin bool) (apiWorkflow, error) {
+	svc, err := src.newFetcherService()
+	if err != nil {
+		return apiWorkflow{}, err
+	}
+
+	resolved, err := src.resolve(svc)
+	if err != nil {
+		return apiWorkflow{}, err
+	}
+
+	if stdin {
+		raw, err := io.ReadAll(cmd.InOrStdin())
+		return apiWorkflow{raw: raw, origin: resolved, svc: svc}, err
+	}
+
+	fetcher, err := svc.GetFetcher(resolved)
+	if err != nil {
+		return apiWorkflow{}, err
+	}
+
+	rc, err := fetcher.Fetch(cmd.Context(), resolved)
+	if err != nil {
+		return apiWorkflow{}, err
+	}
+	defer rc.Close()
+
+	raw, err := io.ReadAll(rc)
+	return apiWorkflow{raw: raw, origin: resolved, svc: svc}, err
+}
@@ -0,0 +1,189 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/invopop/jsonschema"
+	"github.com/spf13/cobra"
+
+	"github.com/defenseunicorns/maru2"
+	"github.com/defenseunicorns/maru2/builtins"
+)
+
+// newBuiltinsCmd creates the `builtins` sub-command, used to discover builtin: tasks and their inputs and outputs
+func newBuiltinsCmd() *cobra.Command {
+	var format string
+
+	checkFormat := func(*cobra.Command, []string) error {
+		if format != "text" && format != "json" {
+			return fmt.Errorf("format %q must be one of text or json", format)
+		}
+		return nil
+	}
+
+	cmd := &cobra.Command{
+		Use:   "builtins",
+		Short: "Discover builtin: tasks and their inputs and outputs",
+		Long: `Discover builtin: tasks and their inputs and outputs
+
+Inputs and outputs are described by JSON schemas derived from each builtin's struct tags,
+use --format json to consume them from other tools.`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	cmd.PersistentFlags().StringVar(&format, "format", "text", `Output format ("text", "json")`)
+	_ = cmd.RegisterFlagCompletionFunc("format", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	ls := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the registered builtins",
+		Args:    cobra.NoArgs,
+		PreRunE: checkFormat,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			descs := []maru2.BuiltinDescription{}
+			for _, name := range builtins.Names() {
+				desc, _ := maru2.DescribeBuiltin(name)
+				descs = append(descs, desc)
+			}
+
+			if format == "json" {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(descs)
+			}
+
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "NAME\tDRY RUN\tINPUTS\tOUTPUTS")
+			for _, desc := range descs {
+				fmt.Fprintf(tw, "builtin:%s\t%s\t%s\t%s\n", desc.Name, yesNo(desc.DryRun), propertyNames(desc.Inputs), propertyNames(desc.Outputs))
+			}
+			return tw.Flush()
+		},
+	}
+
+	describe := &cobra.Command{
+		Use:   "describe <name>",
+		Short: "Describe the inputs and outputs of a builtin",
+		Example: `
+maru2 builtins describe fetch
+
+maru2 builtins describe builtin:wait-for --format json
+`,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: checkFormat,
+		ValidArgsFunction: func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return builtins.Names(), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := strings.TrimPrefix(args[0], "builtin:")
+			desc, ok := maru2.DescribeBuiltin(name)
+			if !ok {
+				return fmt.Errorf("builtin:%s not found, available: %s", name, builtins.Names())
+			}
+
+			if format == "json" {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(desc)
+			}
+			return printBuiltinDescription(cmd.OutOrStdout(), desc)
+		},
+	}
+
+	cmd.AddCommand(ls, describe)
+
+	return cmd
+}
+
+// printBuiltinDescription prints the inputs and outputs of a builtin as tables
+func printBuiltinDescription(w io.Writer, desc maru2.BuiltinDescription) error {
+	fmt.Fprintf(w, "builtin:%s\n\n", desc.Name)
+	if desc.DryRun {
+		fmt.Fprintln(w, "Describes what it would do during a dry run.")
+	} else {
+		fmt.Fprintln(w, "Skipped during a dry run.")
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "\nInputs:")
+	if desc.Inputs == nil || desc.Inputs.Properties.Len() == 0 {
+		fmt.Fprintln(tw, "  none")
+	} else {
+		fmt.Fprintln(tw, "  NAME\tTYPE\tREQUIRED\tDESCRIPTION")
+		for pair := desc.Inputs.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			required := false
+			for _, r := range desc.Inputs.Required {
+				required = required || r == pair.Key
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", pair.Key, schemaType(pair.Value), yesNo(required), schemaDescription(pair.Value))
+		}
+	}
+
+	fmt.Fprintln(tw, "\nOutputs:")
+	if desc.Outputs == nil || desc.Outputs.Properties.Len() == 0 {
+		fmt.Fprintln(tw, "  not described")
+	} else {
+		fmt.Fprintln(tw, "  NAME\tTYPE\tDESCRIPTION")
+		for pair := desc.Outputs.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", pair.Key, schemaType(pair.Value), schemaDescription(pair.Value))
+		}
+	}
+
+	return tw.Flush()
+}
+
+// propertyNames returns the comma separated names of the properties of an object schema
+func propertyNames(s *jsonschema.Schema) string {
+	if s == nil || s.Properties.Len() == 0 {
+		return "-"
+	}
+	names := make([]string, 0, s.Properties.Len())
+	for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		names = append(names, pair.Key)
+	}
+	return strings.Join(names, ",")
+}
+
+// schemaType returns a short name for the type of a schema, "any" when untyped
+func schemaType(s *jsonschema.Schema) string {
+	switch {
+	case s.Type == "":
+		return "any"
+	case s.Type == "array" && s.Items != nil:
+		return schemaType(s.Items) + "[]"
+	case s.Type == "object" && s.AdditionalProperties != nil && s.AdditionalProperties != jsonschema.FalseSchema:
+		return "map[string]" + schemaType(s.AdditionalProperties)
+	default:
+		return s.Type
+	}
+}
+
+// schemaDescription returns the description of a schema, along with its allowed values
+func schemaDescription(s *jsonschema.Schema) string {
+	if len(s.Enum) == 0 {
+		return s.Description
+	}
+	values := make([]string, 0, len(s.Enum))
+	for _, v := range s.Enum {
+		values = append(values, fmt.Sprint(v))
+	}
+	return fmt.Sprintf("%s (one of %s)", s.Description, strings.Join(values, ", "))
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,406 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/defenseunicorns/maru2"
+	"github.com/defenseunicorns/maru2/builtins"
+	"github.com/defenseunicorns/maru2/schema"
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+const (
+	// bundleManifestName is the name of the file within a bundle describing its contents
+	bundleManifestName = "bundle.json"
+	// bundleStoreDir is the directory within a bundle holding the store
+	bundleStoreDir = "store"
+)
+
+// bundleManifest describes the contents of a bundle
+type bundleManifest struct {
+	// From is the resolved location of the entry workflow
+	From string `json:"from"`
+}
+
+// bundle is a bundle read into memory
+type bundle struct {
+	manifest bundleManifest
+	index    map[string]uses.Descriptor
+	files    map[string][]byte
+}
+
+// newBundleCmd creates the `bundle` sub-command, used to move a workflow and its dependencies into an air-gapped environment
+func newBundleCmd(src workflowSource) *cobra.Command {
+	b := &cobra.Command{
+		Use:   "bundle",
+		Short: "Package a workflow and all of its dependencies for air-gapped use",
+		Long: `Package a workflow and all of its dependencies for air-gapped use
+
+A bundle is a tarball of the workflow set by --from, every transitive uses: reference
+and any local files they use, stored the same as the store of fetched workflows.`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	create := &cobra.Command{
+		Use:   "create OUT.tar",
+		Short: "Create a bundle from the workflow set by --from",
+		Example: `
+maru2 bundle create tasks.tar
+
+maru2 bundle create --from "pkg:github/defenseunicorns/maru2@main" maru2.tar`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			logger := log.FromContext(ctx)
+
+			fs := afero.NewOsFs()
+
+			tmp, err := afero.TempDir(fs, "", "maru2-bundle-")
+			if err != nil {
+				return err
+			}
+			defer fs.RemoveAll(tmp)
+
+			storeFs := afero.NewBasePathFs(fs, tmp)
+			store, err := uses.NewLocalStore(storeFs)
+			if err != nil {
+				return fmt.Errorf("failed to initialize bundle store: %w", err)
+			}
+
+			svc, err := src.newFetcherService(
+				uses.WithStorage(store),
+				uses.WithVendor(nil), // always fetch from the source
+				uses.WithFetchPolicy(uses.FetchPolicyAlways),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to initialize fetcher service: %w", err)
+			}
+
+			wf, resolved, err := src.fetch(ctx, svc)
+			if err != nil {
+				return err
+			}
+
+			if err := maru2.FetchAll(ctx, svc, wf, resolved); err != nil {
+				return err
+			}
+
+			// local workflows are not stored when fetched, store them so they can be run with fetch-policy never
+			locals, err := maru2.ListAllLocal(ctx, resolved, fs)
+			if err != nil {
+				return err
+			}
+			for _, local := range locals {
+				uri, err := url.Parse(local)
+				if err != nil {
+					return err
+				}
+				if err := addToStore(fs, store, uri.Opaque, uri); err != nil {
+					return err
+				}
+			}
+
+			clone := *resolved
+			clone.RawQuery = ""
+			if err := writeBundle(storeFs, args[0], bundleManifest{From: clone.String()}); err != nil {
+				return err
+			}
+
+			for id := range store.List() {
+				logger.Debug("bundled", "url", id)
+			}
+			logger.Info("created", "bundle", args[0], "from", clone.String(), "workflows", len(maps.Collect(store.List())))
+			return nil
+		},
+	}
+
+	var s string
+
+	extract := &cobra.Command{
+		Use:   "extract BUNDLE",
+		Short: "Add the workflows within a bundle to the store",
+		Long: `Add the workflows within a bundle to the store
+
+Once extracted, run the workflow with --fetch-policy never.`,
+		Example: `
+maru2 bundle extract tasks.tar
+
+maru2 --from file:tasks.yaml --fetch-policy never`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := log.FromContext(cmd.Context())
+
+			bdl, err := readBundle(args[0])
+			if err != nil {
+				return err
+			}
+
+			store, _, err := src.openStore(afero.NewOsFs(), s, cmd.Flags().Changed("store"))
+			if err != nil {
+				return err
+			}
+
+			if err := bdl.seed(store); err != nil {
+				return err
+			}
+
+			logger.Info("extracted", "from", bdl.manifest.From, "workflows", len(bdl.index))
+			return nil
+		},
+	}
+
+	extract.Flags().StringVarP(&s, "store", "s", "${HOME}/.maru2/store", "Set storage directory")
+	_ = extract.MarkFlagDirname("store")
+
+	var (
+		w   map[string]string
+		dry bool
+		yes bool
+	)
+
+	run := &cobra.Command{
+		Use:   "run BUNDLE [TASK...]",
+		Short: "Run a workflow from a bundle without fetching anything",
+		Long: `Run a workflow from a bundle without fetching anything
+
+The bundle is extracted to a temporary store, and the workflow is run with --fetch-policy never.`,
+		Example: `
+maru2 bundle run tasks.tar
+
+maru2 bundle run tasks.tar build -w version=1.0.0`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			bdl, err := readBundle(args[0])
+			if err != nil {
+				return err
+			}
+
+			fs := afero.NewOsFs()
+
+			tmp, err := afero.TempDir(fs, "", "maru2-bundle-")
+			if err != nil {
+				return err
+			}
+			defer fs.RemoveAll(tmp)
+
+			store, err := uses.NewLocalStore(afero.NewBasePathFs(fs, tmp))
+			if err != nil {
+				return fmt.Errorf("failed to initialize bundle store: %w", err)
+			}
+
+			if err := bdl.seed(store); err != nil {
+				return err
+			}
+
+			svc, err := src.newFetcherService(
+				uses.WithStorage(store),
+				uses.WithVendor(nil),
+				uses.WithFetchPolicy(uses.FetchPolicyNever),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to initialize fetcher service: %w", err)
+			}
+
+			from, err := url.Parse(bdl.manifest.From)
+			if err != nil {
+				return err
+			}
+
+			wf, err := maru2.Fetch(ctx, svc, from)
+			if err != nil {
+				return fmt.Errorf("failed to fetch %q: %w", from, err)
+			}
+
+			with := make(schema.With, len(w))
+			for k, v := range w {
+				with[k] = v
+			}
+
+			tasks := args[1:]
+			if len(tasks) == 0 {
+				tasks = append(tasks, schema.DefaultTaskName)
+			}
+
+			runID := os.Getenv("MARU2_RUN_ID")
+			if runID == "" {
+				runID = maru2.NewRunID()
+			}
+			ctx = maru2.WithRunID(ctx, runID)
+			prompter := newPrompter(cmd.InOrStdin(), cmd.ErrOrStderr())
+			ctx = maru2.WithConfirm(ctx, newConfirm(prompter, yes))
+			ctx = builtins.WithPrompter(ctx, prompter)
+			// bundles take no secrets, but secret prompt answers are still masked
+			secrets := maru2.Secrets{}
+			ctx = maru2.WithSecrets(ctx, secrets)
+			setMaskedOutput(log.FromContext(ctx), cmd.ErrOrStderr(), secrets)
+
+			opts := maru2.RuntimeOptions{
+				Dry:    dry,
+				Env:    os.Environ(),
+				Stdout: cmd.OutOrStdout(),
+				Stderr: cmd.OutOrStderr(),
+				Stdin:  cmd.InOrStdin(),
+			}
+
+			for _, task := range tasks {
+				if _, err := maru2.Run(ctx, svc, wf, task, with, from, opts); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	run.Flags().StringToStringVarP(&w, "with", "w", nil, "Pass key=value pairs to the called task(s)")
+	run.Flags().BoolVar(&dry, "dry-run", false, "Don't actually run anything; just print")
+	run.Flags().BoolVarP(&yes, "yes", "y", false, "Run tasks that set confirm without prompting for confirmation")
+
+	b.AddCommand(create, extract, run)
+
+	return b
+}
+
+// writeBundle writes the manifest and the store in storeFs to a tarball at dst
+func writeBundle(storeFs afero.Fs, dst string, manifest bundleManifest) error {
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	tw := tar.NewWriter(f)
+
+	add := func(name string, b []byte) error {
+		if err := tw.WriteHeader(&tar.Header{
+			Name: name,
+			Mode: 0o644,
+			Size: int64(len(b)),
+		}); err != nil {
+			return err
+		}
+		_, err := tw.Write(b)
+		return err
+	}
+
+	b, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	if err := add(bundleManifestName, b); err != nil {
+		return err
+	}
+
+	entries, err := afero.ReadDir(storeFs, ".")
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		b, err := afero.ReadFile(storeFs, entry.Name())
+		if err != nil {
+			return err
+		}
+		if err := add(path.Join(bundleStoreDir, entry.Name()), b); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// readBundle reads a bundle tarball into memory
+func readBundle(src string) (*bundle, error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a valid bundle: %w", src, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		files[path.Clean(hdr.Name)] = b
+	}
+
+	b, ok := files[bundleManifestName]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a valid bundle: missing %s", src, bundleManifestName)
+	}
+	var manifest bundleManifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return nil, fmt.Errorf("%s is not a valid bundle: %w", src, err)
+	}
+	if manifest.From == "" {
+		return nil, fmt.Errorf("%s is not a valid bundle: missing from", src)
+	}
+
+	index, err := uses.ParseIndex(bytes.NewReader(files[path.Join(bundleStoreDir, uses.IndexFileName)]))
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid bundle: %w", src, err)
+	}
+
+	return &bundle{manifest: manifest, index: index, files: files}, nil
+}
+
+// seed adds every workflow within the bundle to the store
+func (b *bundle) seed(store *uses.LocalStore) error {
+	for _, id := range slices.Sorted(maps.Keys(b.index)) {
+		desc := b.index[id]
+		content, ok := b.files[path.Join(bundleStoreDir, desc.Hex)]
+		if !ok {
+			return fmt.Errorf("bundle is missing the content of %s", id)
+		}
+		if sum := sha256.Sum256(content); hex.EncodeToString(sum[:]) != desc.Hex {
+			return fmt.Errorf("bundle content of %s does not match its digest", id)
+		}
+		uri, err := url.Parse(id)
+		if err != nil {
+			return err
+		}
+		if err := store.Store(bytes.NewReader(content), uri); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	configv0 "github.com/defenseunicorns/maru2/config/v0"
+	configv1 "github.com/defenseunicorns/maru2/config/v1"
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+// newCacheCmd creates the `cache` sub-command, used to inspect and manage the store of fetched workflows
+func newCacheCmd(src workflowSource) *cobra.Command {
+	var s string
+
+	cache := &cobra.Command{
+		Use:     "cache",
+		Aliases: []string{"store"},
+		Short:   "Inspect and manage the store of fetched workflows",
+		Long: `Inspect and manage the store of fetched workflows
+
+Remote workflows are saved to the store when fetched, and reused according to the fetch policy.`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	cache.PersistentFlags().StringVarP(&s, "store", "s", "${HOME}/.maru2/store", "Set storage directory")
+	_ = cache.MarkPersistentFlagDirname("store")
+
+	open := func(cmd *cobra.Command) (*uses.LocalStore, string, error) {
+		return src.openStore(afero.NewOsFs(), s, cmd.Flags().Changed("store"))
+	}
+
+	ls := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List the workflows in the store",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, _, err := open(cmd)
+			if err != nil {
+				return err
+			}
+
+			index := maps.Collect(store.List())
+
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "URL\tDIGEST\tSIZE\tFETCHED")
+			for _, id := range slices.Sorted(maps.Keys(index)) {
+				desc := index[id]
+				fmt.Fprintf(tw, "%s\th1:%s\t%d\t%s\n", id, desc.Hex[:12], desc.Size, fetchedAt(desc))
+			}
+			return tw.Flush()
+		},
+	}
+
+	info := &cobra.Command{
+		Use:   "info",
+		Short: "Print a summary of the store",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, path, err := open(cmd)
+			if err != nil {
+				return err
+			}
+
+			var size int64
+			var oldest, newest uses.Descriptor
+			files := map[string]bool{}
+			count := 0
+			for _, desc := range store.List() {
+				count++
+				// workflows with the same content share a file
+				if !files[desc.Hex] {
+					files[desc.Hex] = true
+					size += desc.Size
+				}
+				if count == 1 || desc.Fetched.Before(oldest.Fetched) {
+					oldest = desc
+				}
+				if count == 1 || desc.Fetched.After(newest.Fetched) {
+					newest = desc
+				}
+			}
+
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintf(tw, "Path:\t%s\n", path)
+			fmt.Fprintf(tw, "Workflows:\t%d\n", count)
+			fmt.Fprintf(tw, "Files:\t%d\n", len(files))
+			fmt.Fprintf(tw, "Size:\t%d\n", size)
+			if count > 0 {
+				fmt.Fprintf(tw, "Oldest:\t%s\n", fetchedAt(oldest))
+				fmt.Fprintf(tw, "Newest:\t%s\n", fetchedAt(newest))
+			}
+			return tw.Flush()
+		},
+	}
+
+	rm := &cobra.Command{
+		Use:     "rm URL...",
+		Aliases: []string{"remove"},
+		Short:   "Remove workflows from the store",
+		Long: `Remove workflows from the store
+
+URLs are matched as listed by "maru2 cache ls", any query parameters (e.g. ?task=) are ignored.`,
+		Args: cobra.MinimumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+			store, _, err := open(cmd)
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+			return slices.Sorted(maps.Keys(maps.Collect(store.List()))), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := log.FromContext(cmd.Context())
+
+			store, _, err := open(cmd)
+			if err != nil {
+				return err
+			}
+
+			for _, arg := range args {
+				uri, err := url.Parse(arg)
+				if err != nil {
+					return err
+				}
+				if err := store.Remove(uri); err != nil {
+					return err
+				}
+				logger.Info("removed", "url", arg)
+			}
+
+			return store.GC()
+		},
+	}
+
+	var as []string
+
+	add := &cobra.Command{
+		Use:   "add PATH",
+		Short: "Add workflows to the store without fetching them",
+		Long: `Add workflows to the store without fetching them
+
+Used to pre-populate the store, for example in an air-gapped environment from removable media.
+
+When PATH is a file, it is stored under each --as URL.
+When PATH is a directory, every .yaml/.yml file within it is stored relative to each --as URL,
+resolved the same as a file: reference from a workflow at that URL.
+
+URLs are resolved using the aliases from the system config, the same as --from.`,
+		Example: `
+maru2 cache add tasks.yaml --as "pkg:github/defenseunicorns/maru2@main?task=echo"
+
+maru2 cache add /media/usb/workflows --as https://example.com/workflows/tasks.yaml`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := log.FromContext(cmd.Context())
+			cfg := src.config()
+			fs := afero.NewOsFs()
+
+			store, _, err := open(cmd)
+			if err != nil {
+				return err
+			}
+
+			// relative paths of the workflows to add, an empty path is PATH itself
+			paths := []string{""}
+			fi, err := fs.Stat(args[0])
+			if err != nil {
+				return err
+			}
+			if fi.IsDir() {
+				paths = nil
+				err := afero.Walk(fs, args[0], func(path string, info os.FileInfo, err error) error {
+					if err != nil {
+						return err
+					}
+					if info.IsDir() {
+						return nil
+					}
+					if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+						return nil
+					}
+					rel, err := filepath.Rel(args[0], path)
+					if err != nil {
+						return err
+					}
+					paths = append(paths, filepath.ToSlash(rel))
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			for _, a := range as {
+				base, err := uses.ResolveRelative(nil, a, cfg.Aliases)
+				if err != nil {
+					return fmt.Errorf("failed to resolve %q: %w", a, err)
+				}
+
+				for _, rel := range paths {
+					uri := base
+					if rel != "" {
+						uri, err = uses.ResolveRelative(base, "file:"+rel, cfg.Aliases)
+						if err != nil {
+							return fmt.Errorf("failed to resolve %q relative to %q: %w", rel, base, err)
+						}
+					}
+
+					path := filepath.Join(args[0], filepath.FromSlash(rel))
+					if err := addToStore(fs, store, path, uri); err != nil {
+						return err
+					}
+					logger.Info("added", "path", path, "url", uri)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	add.Flags().StringSliceVar(&as, "as", nil, "URL to store the workflow(s) under, may be repeated")
+	_ = add.MarkFlagRequired("as")
+
+	var maxAge time.Duration
+	var maxSize string
+
+	prune := &cobra.Command{
+		Use:   "prune",
+		Short: "Evict workflows from the store and remove unreferenced files",
+		Long: `Evict workflows from the store and remove unreferenced files
+
+Without flags, the cache eviction policy from the system config is used.
+Flags take priority over the system config.`,
+		Example: `
+maru2 cache prune --max-age 720h
+
+maru2 cache prune --max-size 100MB`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg := src.config()
+
+			policy := configv0.Cache{}
+			if cfg.Cache != nil {
+				policy = *cfg.Cache
+			}
+			if cmd.Flags().Changed("max-age") {
+				policy.MaxAge = maxAge.String()
+			}
+			if cmd.Flags().Changed("max-size") {
+				if _, err := configv0.ParseSize(maxSize); err != nil {
+					return fmt.Errorf("--max-size %q is not a valid size", maxSize)
+				}
+				policy.MaxSize = maxSize
+			}
+
+			store, _, err := open(cmd)
+			if err != nil {
+				return err
+			}
+
+			before := len(maps.Collect(store.List()))
+
+			if err := gcStore(store, &configv1.Config{Config: configv0.Config{Cache: &policy}}); err != nil {
+				return err
+			}
+
+			logger := log.FromContext(cmd.Context())
+			logger.Info("pruned", "evicted", before-len(maps.Collect(store.List())))
+			return nil
+		},
+	}
+
+	prune.Flags().DurationVar(&maxAge, "max-age", 0, "Evict workflows stored longer ago than this duration")
+	prune.Flags().StringVar(&maxSize, "max-size", "", "Evict the least recently stored workflows until the store is at most this size (e.g. 100MB)")
+
+	cache.AddCommand(ls, info, add, rm, prune)
+
+	return cache
+}
+
+// fetchedAt formats when a workflow was stored, or "-" if unknown
+func fetchedAt(desc uses.Descriptor) string {
+	if desc.Fetched.IsZero() {
+		return "-"
+	}
+	return desc.Fetched.Format(time.RFC3339)
+}
+
+// addToStore validates the workflow at path and stores it under uri
+func addToStore(fs afero.Fs, store *uses.LocalStore, path string, uri *url.URL) error {
+	b, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return err
+	}
+	if _, err := v1.ReadAndValidate(bytes.NewReader(b)); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return store.Store(bytes.NewReader(b), uri)
+}
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/defenseunicorns/maru2"
+	"github.com/defenseunicorns/maru2/builtins"
+)
+
+// newPrompter returns how the user is asked questions, or nil if stdin is not a terminal
+//
+// Prompts are written to out, secret answers are read without echo
+func newPrompter(in io.Reader, out io.Writer) builtins.Prompter {
+	f, ok := in.(*os.File)
+	if !ok || !IsTerminal(f) {
+		return nil
+	}
+
+	reader := bufio.NewReader(in)
+	return func(_ context.Context, message string, secret bool) (string, error) {
+		fmt.Fprint(out, message)
+		if secret {
+			b, err := term.ReadPassword(int(f.Fd()))
+			fmt.Fprintln(out)
+			return string(b), err
+		}
+		answer, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		return answer, nil
+	}
+}
+
+// newConfirm returns how tasks that set confirm are confirmed
+//
+// --yes confirms every task, otherwise the user is prompted if stdin is a terminal, and tasks fail if it is not
+func newConfirm(prompter builtins.Prompter, yes bool) maru2.Confirm {
+	if yes {
+		return maru2.AlwaysConfirm
+	}
+
+	if prompter == nil {
+		return func(context.Context, string, string) (bool, error) {
+			return false, errors.New("stdin is not a terminal, use --yes to confirm")
+		}
+	}
+
+	return func(ctx context.Context, _, message string) (bool, error) {
+		answer, err := prompter(ctx, message+" [y/N]: ", false)
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			return true, nil
+		default:
+			return false, nil
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/alecthomas/chroma/v2/quick"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+// newDiffCmd creates the `diff` sub-command, used to review changes to remote workflows
+func newDiffCmd(src workflowSource) *cobra.Command {
+	var s string
+
+	diff := &cobra.Command{
+		Use:   "diff <uses-ref> [uses-ref]",
+		Short: "Show changes between versions of a remote workflow",
+		Long: `Show changes between versions of a remote workflow
+
+With one reference, the stored copy of the workflow is compared against its source.
+With two references, both are fetched from their source and compared, e.g. to review the changes
+between two versions before bumping a pin.
+
+References are resolved the same as a uses: step in the workflow set by --from, including the workflow's aliases.
+Neither the store nor vendored workflows are updated.`,
+		Example: `
+maru2 diff "pkg:github/defenseunicorns/maru2@main?task=echo#testdata/simple.yaml"
+
+maru2 diff "pkg:github/my-org/tasks@v1.0.0" "pkg:github/my-org/tasks@v2.0.0"
+`,
+		Args:          cobra.RangeArgs(1, 2),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			// without a store or vendored workflows, every workflow is fetched from its source
+			svc, err := src.newFetcherService(uses.WithVendor(nil))
+			if err != nil {
+				return err
+			}
+
+			wf, origin, err := src.fetch(ctx, svc)
+			if err != nil {
+				return err
+			}
+
+			from, err := resolveRef(svc, wf, origin, args[0])
+			if err != nil {
+				return err
+			}
+
+			var a, b []byte
+			var aName, bName string
+
+			if len(args) == 1 {
+				if from.Scheme == "file" {
+					return fmt.Errorf("%q is a local file, local files are not stored", args[0])
+				}
+
+				store, _, err := src.openStore(afero.NewOsFs(), s, cmd.Flags().Changed("store"))
+				if err != nil {
+					return err
+				}
+				desc, ok := store.Describe(from)
+				if !ok {
+					return fmt.Errorf("%q is not stored", from)
+				}
+
+				a, err = fetchRaw(ctx, store, from)
+				if err != nil {
+					return err
+				}
+				aName = fmt.Sprintf("%s (stored h1:%s)", from, desc.Hex[:12])
+
+				b, err = fetchSource(ctx, svc, from)
+				if err != nil {
+					return err
+				}
+				bName = fmt.Sprintf("%s (source)", from)
+			} else {
+				to, err := resolveRef(svc, wf, origin, args[1])
+				if err != nil {
+					return err
+				}
+
+				a, err = fetchSource(ctx, svc, from)
+				if err != nil {
+					return err
+				}
+				b, err = fetchSource(ctx, svc, to)
+				if err != nil {
+					return err
+				}
+				aName, bName = from.String(), to.String()
+			}
+
+			out, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        difflib.SplitLines(string(a)),
+				B:        difflib.SplitLines(string(b)),
+				FromFile: aName,
+				ToFile:   bName,
+				Context:  3,
+			})
+			if err != nil {
+				return err
+			}
+
+			if out == "" {
+				return nil
+			}
+
+			if IsTerminal(cmd.OutOrStdout()) && !termenv.EnvNoColor() {
+				style := "tokyonight-day"
+				if lipgloss.HasDarkBackground() {
+					style = "tokyonight-moon"
+				}
+				return quick.Highlight(cmd.OutOrStdout(), out, "diff", "terminal256", style)
+			}
+
+			_, err = io.WriteString(cmd.OutOrStdout(), out)
+			return err
+		},
+	}
+
+	diff.Flags().StringVarP(&s, "store", "s", "${HOME}/.maru2/store", "Set storage directory")
+	_ = diff.MarkFlagDirname("store")
+
+	return diff
+}
+
+// fetchSource fetches the unparsed contents of a workflow using the fetcher the service returns for it
+func fetchSource(ctx context.Context, svc *uses.FetcherService, uri *url.URL) ([]byte, error) {
+	fetcher, err := svc.GetFetcher(uri)
+	if err != nil {
+		return nil, err
+	}
+	return fetchRaw(ctx, fetcher, uri)
+}
+
+// fetchRaw fetches the unparsed contents of a workflow
+func fetchRaw(ctx context.Context, fetcher uses.Fetcher, uri *url.URL) ([]byte, error) {
+	rc, err := fetcher.Fetch(ctx, uri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %q: %w", uri, err)
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
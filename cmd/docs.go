@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/spf13/cobra"
+
+	"github.com/defenseunicorns/maru2"
+)
+
+// newDocsCmd creates the `docs` sub-command, used to generate a markdown reference of workflows
+func newDocsCmd(src workflowSource) *cobra.Command {
+	docs := &cobra.Command{
+		Use:   "docs [workflow...]",
+		Short: "Generate a markdown reference of workflows",
+		Long: `Generate a markdown reference of workflows
+
+Every task is documented with its description, inputs, the tasks it uses and an example call,
+along with the remote workflows each workflow depends on. The reference is printed to stdout,
+redirect it to a file to commit it alongside the workflows.
+
+When no workflows are given, the workflow set by --from is documented.`,
+		Example: `
+maru2 docs > docs/tasks.md
+
+maru2 docs tasks.yaml ci/tasks.yaml > docs/tasks.md
+`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			svc, err := src.newFetcherService()
+			if err != nil {
+				return err
+			}
+
+			var origins []*url.URL
+			if len(args) == 0 {
+				resolved, err := src.resolve(svc)
+				if err != nil {
+					return err
+				}
+				origins = append(origins, resolved)
+			}
+			for _, arg := range args {
+				resolved, err := svc.ResolveRelative(nil, arg, src.config().Aliases)
+				if err != nil {
+					return fmt.Errorf("failed to resolve %q: %w", arg, err)
+				}
+				origins = append(origins, resolved)
+			}
+
+			out, err := maru2.Docs(cmd.Context(), svc, origins...)
+			if err != nil {
+				return err
+			}
+
+			_, err = io.WriteString(cmd.OutOrStdout(), out)
+			return err
+		},
+	}
+
+	return docs
+}
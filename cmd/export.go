@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/goccy/go-yaml"
+	"github.com/spf13/cobra"
+
+	"github.com/defenseunicorns/maru2"
+)
+
+var releaseVersionPattern = regexp.MustCompile(`^v\d+\.\d+\.\d+$`)
+
+// newExportCmd creates the `export` sub-command, used to convert maru2 tasks into other formats
+func newExportCmd(src workflowSource) *cobra.Command {
+	export := &cobra.Command{
+		Use:           "export",
+		Short:         "Convert a maru2 task into another format",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	var (
+		version    string
+		actionFrom string
+	)
+
+	gha := &cobra.Command{
+		Use:   "gha <task>",
+		Short: "Wrap a task in a GitHub Actions composite action",
+		Long: `Wrap a task in a GitHub Actions composite action
+
+The composite action is printed to stdout, redirect it to an action.yaml to save it.
+
+Local workflows are referenced relative to the action's directory, so the action.yaml
+should be written to the current directory (or the directory set by -C).`,
+		Example: `
+maru2 export gha build > action.yaml
+
+maru2 -f "pkg:github/defenseunicorns/maru2@main#testdata/simple.yaml" export gha echo > action.yaml
+`,
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			svc, err := src.newFetcherService()
+			if err != nil {
+				return err
+			}
+
+			wf, resolved, err := src.fetch(cmd.Context(), svc)
+			if err != nil {
+				return err
+			}
+
+			if !cmd.Flags().Changed("maru2-version") {
+				v, err := currentVersion()
+				if err != nil {
+					return err
+				}
+				// development builds are not available as releases
+				if releaseVersionPattern.MatchString(v) {
+					version = v
+				}
+			}
+
+			from := actionFrom
+			if from == "" {
+				from = resolved.String()
+				if resolved.Scheme == "file" {
+					p := resolved.Opaque
+					if p == "" {
+						p = resolved.Path
+					}
+					if filepath.IsAbs(p) {
+						wd, err := os.Getwd()
+						if err != nil {
+							return err
+						}
+						p, err = filepath.Rel(wd, p)
+						if err != nil {
+							return err
+						}
+					}
+					from = "file:${{ github.action_path }}/" + filepath.ToSlash(p)
+				}
+			}
+
+			action, err := maru2.ExportGitHubAction(wf, args[0], from, version)
+			if err != nil {
+				return err
+			}
+
+			b, err := yaml.MarshalWithOptions(action, yaml.UseLiteralStyleIfMultiline(true))
+			if err != nil {
+				return err
+			}
+
+			_, err = cmd.OutOrStdout().Write(b)
+			return err
+		},
+	}
+
+	gha.Flags().StringVar(&version, "maru2-version", "", "Version of maru2 for the action to install (default is the running version, or the latest release for development builds)")
+	gha.Flags().StringVar(&actionFrom, "action-from", "", "Workflow location for the action to run (default is derived from --from)")
+
+	export.AddCommand(gha)
+
+	return export
+}
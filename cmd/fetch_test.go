@@ -4,6 +4,7 @@
 package cmd_test
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -13,6 +14,7 @@ import (
 	"github.com/goccy/go-yaml"
 	"github.com/rogpeppe/go-internal/testscript"
 
+	"github.com/defenseunicorns/maru2/schema"
 	v1 "github.com/defenseunicorns/maru2/schema/v1"
 )
 
@@ -78,6 +80,24 @@ func TestFetchE2E(t *testing.T) {
 			b, _ := yaml.Marshal(wf)
 			_, _ = w.Write(b)
 
+		case "/private.yaml":
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			wf := v1.Workflow{
+				SchemaVersion: v1.SchemaVersion,
+				Tasks: v1.TaskMap{
+					"default": v1.Task{
+						Steps: []v1.Step{
+							{Run: "echo 'Hello from behind auth!'"},
+						},
+					},
+				},
+			}
+			b, _ := yaml.Marshal(wf)
+			_, _ = w.Write(b)
+
 		case "/invalid.yaml":
 			_, _ = w.Write([]byte("not a valid workflow yaml"))
 
@@ -114,6 +134,49 @@ func TestFetchE2E(t *testing.T) {
 			b, _ := yaml.Marshal(wf)
 			_, _ = w.Write(b)
 
+		case "/read-file.yaml":
+			wf := v1.Workflow{
+				SchemaVersion: v1.SchemaVersion,
+				Tasks: v1.TaskMap{
+					"read": v1.Task{
+						Steps: []v1.Step{
+							{Run: `echo "secret is ${{ file "secret.txt" }}"`},
+						},
+					},
+					"hash": v1.Task{
+						Steps: []v1.Step{
+							{Run: `echo "hash is ${{ hashFiles "secret.txt" }}"`},
+						},
+					},
+					"query": v1.Task{
+						Steps: []v1.Step{
+							{Uses: "builtin:query", With: schema.With{"file": "secret.txt", "query": "."}},
+						},
+					},
+					"copy": v1.Task{
+						Steps: []v1.Step{
+							{Uses: "builtin:fs", With: schema.With{"action": "copy", "paths": []any{"secret.txt"}, "dest": "stolen.txt"}},
+						},
+					},
+				},
+			}
+			b, _ := yaml.Marshal(wf)
+			_, _ = w.Write(b)
+
+		case "/api/pipelines":
+			if r.Method != http.MethodPost || r.Header.Get("Private-Token") != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			var body map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": 42, "ref": body["ref"], "status": "pending"})
+
 		default:
 			w.WriteHeader(http.StatusNotFound)
 			_, _ = w.Write([]byte("not found"))
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/defenseunicorns/maru2"
+)
+
+// newGraphCmd creates the `graph` sub-command, used to visualize the dependencies between tasks
+func newGraphCmd(src workflowSource) *cobra.Command {
+	var format string
+
+	graph := &cobra.Command{
+		Use:   "graph [task...]",
+		Short: "Render the uses: dependency graph of tasks",
+		Long: `Render the uses: dependency graph of tasks
+
+Every uses: reference is resolved and fetched the same as when running, so tasks from remote
+workflows are included. When no tasks are given, the graph of every task in the workflow is rendered.`,
+		Example: `
+maru2 graph build | dot -Tsvg > build.svg
+
+maru2 graph --format mermaid > graph.mmd
+`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if format != "dot" && format != "mermaid" {
+				return fmt.Errorf("format %q must be one of dot or mermaid", format)
+			}
+
+			svc, err := src.newFetcherService()
+			if err != nil {
+				return err
+			}
+
+			wf, resolved, err := src.fetch(ctx, svc)
+			if err != nil {
+				return err
+			}
+
+			g, err := maru2.NewGraph(ctx, svc, wf, resolved, args...)
+			if err != nil {
+				return err
+			}
+
+			if format == "mermaid" {
+				_, err = io.WriteString(cmd.OutOrStdout(), g.Mermaid())
+				return err
+			}
+			_, err = io.WriteString(cmd.OutOrStdout(), g.DOT())
+			return err
+		},
+	}
+
+	graph.Flags().StringVar(&format, "format", "dot", `Output format ("dot", "mermaid")`)
+	_ = graph.RegisterFlagCompletionFunc("format", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"dot", "mermaid"}, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return graph
+}
@@ -0,0 +1,180 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/defenseunicorns/maru2"
+	"github.com/defenseunicorns/maru2/config"
+)
+
+// newHistoryCmd creates the `history` sub-command, used to list and inspect past runs
+func newHistoryCmd() *cobra.Command {
+	var (
+		limit  int
+		report string
+	)
+
+	history := &cobra.Command{
+		Use:   "history [run-id]",
+		Short: "List and inspect past runs",
+		Long: `List and inspect past runs
+
+Every run is recorded to ~/.maru2/history: the workflow it started from, the called task(s),
+a digest of the inputs, its duration and result, along with the run's report.
+The inputs themselves are not recorded, as they may hold secrets.
+
+Without a run ID, the most recent runs are listed. With a run ID (or a unique prefix of one),
+the run's details and summary are printed.`,
+		Example: `
+maru2 history
+
+maru2 history 01J9Z3
+
+maru2 history 01J9Z3 --report junit > report.xml
+`,
+		Args:          cobra.MaximumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		ValidArgsFunction: func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			h, err := openHistory()
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+			runs, err := h.List()
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+			ids := make([]string, 0, len(runs))
+			for _, run := range runs {
+				ids = append(ids, strings.Join([]string{run.RunID, strings.Join(run.Tasks, " ")}, "\t"))
+			}
+			return ids, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			h, err := openHistory()
+			if err != nil {
+				return err
+			}
+
+			if len(args) == 0 {
+				runs, err := h.List()
+				if err != nil {
+					return err
+				}
+				if limit > 0 && len(runs) > limit {
+					runs = runs[:limit]
+				}
+
+				tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+				fmt.Fprintln(tw, "RUN ID\tSTARTED\tDURATION\tSTATUS\tTASKS\tFROM")
+				for _, run := range runs {
+					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", run.RunID, run.Started.Local().Format(time.DateTime), historyDuration(run), historyStatus(run), strings.Join(run.Tasks, " "), run.From)
+				}
+				return tw.Flush()
+			}
+
+			run, err := h.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			if report != "" {
+				if run.Report == nil {
+					return fmt.Errorf("run %q has no report", run.RunID)
+				}
+				return writeReport(cmd.OutOrStdout(), run.Report, report)
+			}
+
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintf(tw, "Run ID:\t%s\n", run.RunID)
+			fmt.Fprintf(tw, "From:\t%s\n", run.From)
+			fmt.Fprintf(tw, "Tasks:\t%s\n", strings.Join(run.Tasks, " "))
+			fmt.Fprintf(tw, "Inputs:\tsha256:%s\n", run.InputsHash)
+			fmt.Fprintf(tw, "Started:\t%s\n", run.Started.Format(time.RFC3339))
+			fmt.Fprintf(tw, "Duration:\t%s\n", historyDuration(run))
+			fmt.Fprintf(tw, "Status:\t%s\n", historyStatus(run))
+			if run.Error != "" {
+				fmt.Fprintf(tw, "Error:\t%s\n", run.Error)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+
+			if run.Report != nil && len(run.Report.Tasks) > 0 {
+				fmt.Fprintln(cmd.OutOrStdout())
+				fmt.Fprintln(cmd.OutOrStdout(), "Run summary:")
+				fmt.Fprintln(cmd.OutOrStdout(), run.Report.Summary())
+			}
+			return nil
+		},
+	}
+
+	history.Flags().IntVarP(&limit, "limit", "n", 20, "Maximum number of runs to list, 0 lists every run")
+	history.Flags().StringVar(&report, "report", "", "Print the report of the run as json or junit instead of its summary")
+	_ = history.RegisterFlagCompletionFunc("report", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "junit"}, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return history
+}
+
+// historyDirectory returns the directory runs are recorded to (~/.maru2/history)
+func historyDirectory() (string, error) {
+	dir, err := config.DefaultDirectory()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history"), nil
+}
+
+// openHistory opens the history for reading, it is empty until the first run is recorded
+func openHistory() (*maru2.History, error) {
+	path, err := historyDirectory()
+	if err != nil {
+		return nil, err
+	}
+	return maru2.NewHistory(afero.NewBasePathFs(afero.NewOsFs(), path), 0), nil
+}
+
+// recordHistory records a finished run to the history, keeping at most maxRuns runs
+func recordHistory(maxRuns int, run maru2.HistoryRun) error {
+	path, err := historyDirectory()
+	if err != nil {
+		return err
+	}
+	fs := afero.NewOsFs()
+	if err := fs.MkdirAll(path, 0o700); err != nil {
+		return err
+	}
+	return maru2.NewHistory(afero.NewBasePathFs(fs, path), maxRuns).Record(run)
+}
+
+// historyStatus returns the status of a recorded run, noting dry runs
+func historyStatus(run maru2.HistoryRun) string {
+	if run.DryRun {
+		return run.Status + " (dry run)"
+	}
+	return run.Status
+}
+
+// historyDuration rounds the duration of a recorded run for display
+func historyDuration(run maru2.HistoryRun) string {
+	d, err := time.ParseDuration(run.Duration)
+	if err != nil {
+		return run.Duration
+	}
+	return d.Round(time.Millisecond).String()
+}
@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/goccy/go-yaml"
+	"github.com/spf13/cobra"
+
+	"github.com/defenseunicorns/maru2"
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+)
+
+// newImportCmd creates the `import` sub-command, used to convert other task runner formats into maru2 workflows
+func newImportCmd() *cobra.Command {
+	imp := &cobra.Command{
+		Use:   "import",
+		Short: "Convert a Makefile or Taskfile into a maru2 workflow",
+		Long: `Convert a Makefile or Taskfile into a maru2 workflow
+
+The converted workflow is printed to stdout, redirect it to a file to save it.
+
+The conversion is best-effort, review the output before use.`,
+		Example: `
+maru2 import make > tasks.yaml
+
+maru2 import taskfile path/to/Taskfile.yml > tasks.yaml
+`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	imp.AddCommand(
+		newImporterCmd("make", "Makefile", "Convert a Makefile into a maru2 workflow", maru2.ImportMakefile),
+		newImporterCmd("taskfile", "Taskfile.yml", "Convert a Taskfile into a maru2 workflow", maru2.ImportTaskfile),
+	)
+
+	return imp
+}
+
+func newImporterCmd(use, defaultPath, short string, importer func(io.Reader) (v1.Workflow, error)) *cobra.Command {
+	return &cobra.Command{
+		Use:           fmt.Sprintf("%s [path]", use),
+		Short:         short,
+		Args:          cobra.MaximumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := defaultPath
+			if len(args) == 1 {
+				path = args[0]
+			}
+
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			wf, err := importer(f)
+			if err != nil {
+				return fmt.Errorf("failed to import %q: %w", path, err)
+			}
+
+			b, err := yaml.MarshalWithOptions(wf, yaml.UseLiteralStyleIfMultiline(true))
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "# yaml-language-server: $schema=%s\n", v1.SchemaURL)
+			_, err = out.Write(b)
+			return err
+		},
+	}
+}
@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/defenseunicorns/maru2"
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+)
+
+// newLintCmd creates the `lint` sub-command, used to find unused inputs and tasks in a workflow
+func newLintCmd(src workflowSource) *cobra.Command {
+	lint := &cobra.Command{
+		Use:   "lint",
+		Short: "Find unused inputs and unreachable tasks",
+		Long: `Find unused inputs and unreachable tasks
+
+An input is unused when no template, if expression or INPUT_ environment variable in its task references it.
+A task is unreachable when it is not the default task, has no description, is not run by a test,
+and is not used by any such task. Scripts run by a task may still read INPUT_ environment variables,
+and other workflows may still use a task, so warnings are not errors when the workflow runs.`,
+		Example: `
+maru2 lint
+
+maru2 lint --from file:ci/tasks.yaml
+`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			svc, err := src.newFetcherService()
+			if err != nil {
+				return err
+			}
+
+			// an invalid workflow is annotated the same as when running
+			wf, _, err := src.fetch(maru2.WithAnnotations(cmd.Context(), cmd.OutOrStdout()), svc)
+			if err != nil {
+				return err
+			}
+
+			warnings := v1.Lint(wf)
+			for _, warning := range warnings {
+				fmt.Fprintln(cmd.OutOrStdout(), warning)
+			}
+
+			if len(warnings) > 0 {
+				return fmt.Errorf("found %d warnings", len(warnings))
+			}
+			return nil
+		},
+	}
+
+	return lint
+}
@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+// newLoginCmd creates the `login` sub-command, used to store a token for a host in the OS keychain
+func newLoginCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "login <host>",
+		Short: "Store a token for a host in the OS keychain",
+		Long: `Store a token for a host in the OS keychain
+
+The token is used when fetching pkg:github, pkg:gitlab and oci workflows from the host,
+taking priority over GITHUB_TOKEN, GITLAB_TOKEN, the GitHub CLI and docker credentials.
+
+The token is prompted for when stdin is a terminal, otherwise it is read from stdin.
+For oci registries, a token in the form username:password is used as basic credentials.`,
+		Example: `
+maru2 login github.com
+
+maru2 login gitlab.example.com < token.txt
+
+echo "$REGISTRY_USER:$REGISTRY_PASSWORD" | maru2 login registry.example.com
+`,
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			host, err := loginHost(args[0])
+			if err != nil {
+				return err
+			}
+
+			var token string
+			if prompter := newPrompter(cmd.InOrStdin(), cmd.ErrOrStderr()); prompter != nil {
+				token, err = prompter(cmd.Context(), fmt.Sprintf("Token for %s: ", host), true)
+			} else {
+				var b []byte
+				b, err = io.ReadAll(cmd.InOrStdin())
+				token = string(b)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read token: %w", err)
+			}
+
+			token = strings.TrimSpace(token)
+			if token == "" {
+				return fmt.Errorf("no token provided for %s", host)
+			}
+
+			if err := uses.StoreKeychainToken(host, token); err != nil {
+				return fmt.Errorf("failed to store token for %s: %w", host, err)
+			}
+
+			log.FromContext(cmd.Context()).Infof("Stored token for %s in the OS keychain", host)
+			return nil
+		},
+	}
+}
+
+// newLogoutCmd creates the `logout` sub-command, used to remove a host's token from the OS keychain
+func newLogoutCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout <host>",
+		Short: "Remove a host's token from the OS keychain",
+		Example: `
+maru2 logout github.com
+`,
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			host, err := loginHost(args[0])
+			if err != nil {
+				return err
+			}
+
+			if err := uses.DeleteKeychainToken(host); err != nil {
+				return err
+			}
+
+			log.FromContext(cmd.Context()).Infof("Removed token for %s from the OS keychain", host)
+			return nil
+		},
+	}
+}
+
+// loginHost returns the host tokens are stored under, accepting a host (e.g. github.com) or a URL
+//
+// https://api.github.com is stored as github.com, matching how pkg:github fetches look up tokens
+func loginHost(arg string) (string, error) {
+	host := arg
+	if strings.Contains(arg, "://") {
+		u, err := url.Parse(arg)
+		if err != nil || u.Host == "" {
+			return "", fmt.Errorf("%q is not a valid host or URL", arg)
+		}
+		host = uses.GitHubHost(u.Scheme + "://" + u.Host)
+	}
+
+	if host == "" || strings.ContainsAny(host, "/?#@ ") {
+		return "", fmt.Errorf("%q is not a valid host or URL", arg)
+	}
+	return host, nil
+}
@@ -9,7 +9,6 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	"runtime/debug"
 	"syscall"
 
 	"github.com/charmbracelet/log"
@@ -21,6 +20,7 @@ import (
 	"oras.land/oras-go/v2/registry/remote/retry"
 
 	"github.com/defenseunicorns/maru2"
+	"github.com/defenseunicorns/maru2/uses"
 )
 
 // NewPublishCmd creates the root command for the maru2-publish CLI.
@@ -60,21 +60,11 @@ func NewPublishCmd() *cobra.Command {
 			logger := log.FromContext(ctx)
 
 			if ver && len(args) == 0 {
-				bi, ok := debug.ReadBuildInfo()
-				if !ok {
-					return fmt.Errorf("version information not available")
-				}
-				switch bi.Main.Path {
-				case "github.com/defenseunicorns/maru2":
-					fmt.Fprintln(cmd.OutOrStdout(), bi.Main.Version)
-				default:
-					for _, dep := range bi.Deps {
-						if dep.Path == "github.com/defenseunicorns/maru2" {
-							fmt.Fprintln(cmd.OutOrStdout(), dep.Version)
-							break
-						}
-					}
+				v, err := currentVersion()
+				if err != nil {
+					return err
 				}
+				fmt.Fprintln(cmd.OutOrStdout(), v)
 				return nil
 			}
 
@@ -101,8 +91,7 @@ func NewPublishCmd() *cobra.Command {
 			transport := http.DefaultTransport.(*http.Transport).Clone()
 			transport.TLSClientConfig.InsecureSkipVerify = insecureSkipTLS
 
-			storeOpts := credentials.StoreOptions{}
-			credStore, err := credentials.NewStoreFromDocker(storeOpts)
+			credStore, err := uses.DockerCredentials()
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/defenseunicorns/maru2"
+)
+
+// parseReport parses a --report value in the form format[=path], an empty path or - writes to stdout
+func parseReport(spec string) (string, string, error) {
+	format, path, _ := strings.Cut(spec, "=")
+	switch format {
+	case "json", "junit":
+		return format, path, nil
+	default:
+		return "", "", fmt.Errorf("invalid report %q, format must be one of json or junit", spec)
+	}
+}
+
+// writeReport writes a report in the format and to the path of a --report value
+func writeReport(stdout io.Writer, r *maru2.Report, spec string) error {
+	format, path, err := parseReport(spec)
+	if err != nil {
+		return err
+	}
+
+	write := r.WriteJSON
+	if format == "junit" {
+		write = r.WriteJUnit
+	}
+
+	if path == "" || path == "-" {
+		return write(stdout)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
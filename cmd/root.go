@@ -5,15 +5,20 @@
 package cmd
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"regexp/syntax"
 	"runtime/debug"
 	"slices"
 	"strings"
@@ -22,14 +27,19 @@ import (
 
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
+	"github.com/goccy/go-yaml"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 
 	"github.com/defenseunicorns/maru2"
-	configv0 "github.com/defenseunicorns/maru2/config/v0"
+	"github.com/defenseunicorns/maru2/builtins"
+	"github.com/defenseunicorns/maru2/config"
+	configv1 "github.com/defenseunicorns/maru2/config/v1"
 	"github.com/defenseunicorns/maru2/schema"
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
 	"github.com/defenseunicorns/maru2/uses"
 )
 
@@ -38,9 +48,12 @@ func NewRootCmd() *cobra.Command {
 	var (
 		w          map[string]string
 		withFile   string
+		withStdin  bool
 		level      string
+		logFormat  string
 		ver        bool
 		list       bool
+		listFormat string
 		explain    bool
 		from       string
 		policy     = uses.DefaultFetchPolicy // VarP does not allow you to set a default value
@@ -51,9 +64,23 @@ func NewRootCmd() *cobra.Command {
 		configPath string
 		fetchAll   bool
 		gc         bool
+		manifest   string
+		reports    []string
+		summary    bool
+		logDir     string
+		secrets    []string
+		profile    string
+		yes        bool
+		noRemoteFS bool
+		skip       []string
+		only       []string
+
+		unsupportedPlatform = maru2.UnsupportedPlatformSkip // VarP does not allow you to set a default value
 	)
 
-	var cfg *configv0.Config // cfg is not set via CLI flag
+	var cfg *configv1.Config // cfg is not set via CLI flag
+
+	var profileWith map[string]any // with-values of the selected profile, lowest priority after task defaults
 
 	// closure initializer
 	loadConfig := func(cmd *cobra.Command) error {
@@ -64,7 +91,7 @@ func NewRootCmd() *cobra.Command {
 				return fmt.Errorf("failed to open config file: %w", err)
 			}
 			defer f.Close()
-			cfg, err = configv0.LoadConfig(f)
+			cfg, err = configv1.LoadConfig(f)
 			if err != nil {
 				return fmt.Errorf("failed to load config file: %w", err)
 			}
@@ -74,25 +101,77 @@ func NewRootCmd() *cobra.Command {
 				return fmt.Errorf("failed to open config file: %w", err)
 			}
 			defer f.Close()
-			cfg, err = configv0.LoadConfig(f)
+			cfg, err = configv1.LoadConfig(f)
 			if err != nil {
 				return fmt.Errorf("failed to load config file: %w", err)
 			}
 		default:
 			var err error
-			cfg, err = configv0.LoadDefaultConfig()
+			cfg, err = configv1.LoadDefaultConfig()
 			if err != nil {
 				return err
 			}
 		}
 
-		// default < cfg < flags
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		projectPath, err := config.FindProjectConfig(wd)
+		if err != nil {
+			return err
+		}
+		if projectPath != "" {
+			cfg, err = configv1.LoadProjectConfig(cfg, projectPath)
+			if err != nil {
+				return fmt.Errorf("failed to load project config file %s: %w", projectPath, err)
+			}
+		}
+
+		// variables already set in the environment take priority over the config's env
+		for _, k := range slices.Sorted(maps.Keys(cfg.Env)) {
+			if os.Getenv(k) != "" {
+				continue
+			}
+			if err := os.Setenv(k, os.ExpandEnv(cfg.Env[k])); err != nil {
+				return fmt.Errorf("failed to set %q from config: %w", k, err)
+			}
+		}
+
+		if !cmd.Flags().Changed("profile") {
+			profile = os.Getenv("MARU2_PROFILE")
+		}
+		if profile != "" {
+			p, err := cfg.Profile(profile)
+			if err != nil {
+				return err
+			}
+			if p.FetchPolicy != "" {
+				cfg.FetchPolicy = p.FetchPolicy
+			}
+			for _, k := range slices.Sorted(maps.Keys(p.Env)) {
+				if err := os.Setenv(k, os.ExpandEnv(p.Env[k])); err != nil {
+					return fmt.Errorf("failed to set %q from profile %q: %w", k, profile, err)
+				}
+			}
+			profileWith = p.With
+		}
+
+		// default < global cfg < project cfg < profile < flags
 		if !cmd.Flags().Changed("fetch-policy") && cfg.FetchPolicy != policy {
 			if err := policy.Set(cfg.FetchPolicy.String()); err != nil {
 				return err // since config validates and has defaults during loading, this error is basically impossible to trigger, but leaving in case a regression happens in schema validation
 			}
 		}
 
+		if !cmd.Flags().Changed("timeout") {
+			d, err := cfg.RunTimeout()
+			if err != nil {
+				return err
+			}
+			timeout = d
+		}
+
 		if policy == uses.FetchPolicyNever && fetchAll {
 			return fmt.Errorf("cannot fetch all with fetch policy %q", policy)
 		}
@@ -100,6 +179,75 @@ func NewRootCmd() *cobra.Command {
 		return nil
 	}
 
+	// src gives sub-commands access to the workflow set by --from, resolved using the system config
+	src := workflowSource{
+		resolve: func(svc *uses.FetcherService) (*url.URL, error) {
+			resolved, err := svc.ResolveRelative(nil, from, cfg.Aliases)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve %q: %w", from, err)
+			}
+			return resolved, nil
+		},
+		newFetcherService: func(opts ...uses.FetcherServiceOption) (*uses.FetcherService, error) {
+			headers, err := cfg.Headers()
+			if err != nil {
+				return nil, err
+			}
+
+			transport, err := cfg.TransportOptions()
+			if err != nil {
+				return nil, err
+			}
+
+			credentials, err := cfg.CredentialOptions()
+			if err != nil {
+				return nil, err
+			}
+
+			vendor, err := withVendor(afero.NewOsFs())
+			if err != nil {
+				return nil, err
+			}
+
+			defaults := append([]uses.FetcherServiceOption{uses.WithHeaders(headers), uses.WithGitHubTokenFromGH(cfg.TokenFromGH()), uses.WithKeychain(true), vendor}, append(transport, credentials...)...)
+			return uses.NewFetcherService(append(defaults, opts...)...)
+		},
+		config: func() *configv1.Config {
+			return cfg
+		},
+	}
+
+	// completionWorkflow fetches the workflow set by --from for tab completions
+	completionWorkflow := func(cmd *cobra.Command) (*uses.FetcherService, v1.Workflow, *url.URL, error) {
+		// load the cfg as PersistentPreRun isnt run when performing tab completions
+		if err := loadConfig(cmd); err != nil {
+			return nil, v1.Workflow{}, nil, err
+		}
+
+		completionTimeout, err := cfg.CompletionTimeout()
+		if err != nil {
+			return nil, v1.Workflow{}, nil, err
+		}
+
+		// configured the same as when running, so completions can reach hosts that need headers, proxies or credentials
+		svc, err := src.newFetcherService(uses.WithTimeout(completionTimeout))
+		if err != nil {
+			return nil, v1.Workflow{}, nil, err
+		}
+
+		resolved, err := svc.ResolveRelative(nil, from, cfg.Aliases)
+		if err != nil {
+			return nil, v1.Workflow{}, nil, err
+		}
+
+		wf, err := maru2.Fetch(cmd.Context(), svc, resolved)
+		if err != nil {
+			return nil, v1.Workflow{}, nil, err
+		}
+
+		return svc, wf, resolved, nil
+	}
+
 	root := &cobra.Command{
 		Use:   "maru2",
 		Short: "A simple task runner",
@@ -118,7 +266,30 @@ maru2 -f ../foo.yaml bar baz -w zab="zaz"
 
 maru2 -f "pkg:github/defenseunicorns/maru2@main#testdata/simple.yaml" echo -w message="hello world"
 `,
+		Args: cobra.ArbitraryArgs,
 		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			l, err := log.ParseLevel(level)
+			if err != nil {
+				return err
+			}
+			logger := log.FromContext(cmd.Context())
+			logger.SetLevel(l)
+
+			switch logFormat {
+			case "text":
+			case "json":
+				logger.SetFormatter(log.JSONFormatter)
+				logger.SetReportTimestamp(true)
+				logger.SetTimeFormat(time.RFC3339)
+				cmd.SetContext(maru2.WithStructuredLogs(cmd.Context(), logger))
+			default:
+				return fmt.Errorf("log format %q must be one of text or json", logFormat)
+			}
+
+			// fix fish needing "'pkg:...'" for tab completion
+			from = strings.Trim(from, `"`)
+			from = strings.Trim(from, `'`)
+
 			if dir != "" {
 				if err := os.Chdir(dir); err != nil {
 					return err
@@ -128,29 +299,7 @@ maru2 -f "pkg:github/defenseunicorns/maru2@main#testdata/simple.yaml" echo -w me
 			return loadConfig(cmd)
 		},
 		ValidArgsFunction: func(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
-			svc, err := uses.NewFetcherService(
-				uses.WithClient(&http.Client{
-					Timeout: 500 * time.Millisecond,
-				}),
-			)
-			if err != nil {
-				return nil, cobra.ShellCompDirectiveError
-			}
-
-			// if we are a sub-command, load the cfg as PersistentPreRun isnt run
-			// when performing tab completions on sub-commands
-			if cmd.Parent() != nil {
-				if err := loadConfig(cmd); err != nil {
-					return nil, cobra.ShellCompDirectiveError
-				}
-			}
-
-			resolved, err := uses.ResolveRelative(nil, from, cfg.Aliases)
-			if err != nil {
-				return nil, cobra.ShellCompDirectiveError
-			}
-
-			wf, err := maru2.Fetch(cmd.Context(), svc, resolved)
+			svc, wf, resolved, err := completionWorkflow(cmd)
 			if err != nil {
 				return nil, cobra.ShellCompDirectiveError
 			}
@@ -160,9 +309,9 @@ maru2 -f "pkg:github/defenseunicorns/maru2@main#testdata/simple.yaml" echo -w me
 				names = append(names, strings.Join([]string{name, wf.Tasks[name].Description}, "\t"))
 			}
 
-			for name, alias := range wf.Aliases {
+			for name, alias := range wf.Aliases.OrderedSeq() {
 				if alias.Path != "" {
-					next, err := uses.ResolveRelative(resolved, strings.Join([]string{"file", alias.Path}, ":"), wf.Aliases)
+					next, err := svc.ResolveRelative(resolved, strings.Join([]string{"file", alias.Path}, ":"), wf.Aliases)
 					if err != nil {
 						return nil, cobra.ShellCompDirectiveError
 					}
@@ -170,81 +319,37 @@ maru2 -f "pkg:github/defenseunicorns/maru2@main#testdata/simple.yaml" echo -w me
 					if err != nil {
 						return nil, cobra.ShellCompDirectiveError
 					}
-					for _, n := range aliasedWF.Tasks.OrderedTaskNames() {
-						names = append(names, strings.Join([]string{fmt.Sprintf("%s:%s", name, n), wf.Tasks[name].Description}, "\t"))
+					for n, task := range aliasedWF.Tasks.OrderedSeq() {
+						names = append(names, strings.Join([]string{fmt.Sprintf("%s:%s", name, n), task.Description}, "\t"))
 					}
 				}
 			}
 
 			return names, cobra.ShellCompDirectiveNoFileComp
 		},
-		PreRunE: func(cmd *cobra.Command, _ []string) error {
-			l, err := log.ParseLevel(level)
-			if err != nil {
-				return err
-			}
-			logger := log.FromContext(cmd.Context())
-			logger.SetLevel(l)
-
-			return nil
-		},
 		SilenceUsage:  true,
 		SilenceErrors: true,
-		RunE: func(cmd *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			ctx := cmd.Context()
 			logger := log.FromContext(ctx)
 
 			if ver && len(args) == 0 {
-				bi, ok := debug.ReadBuildInfo()
-				if !ok {
-					return fmt.Errorf("version information not available")
-				}
-				switch bi.Main.Path {
-				case "github.com/defenseunicorns/maru2":
-					fmt.Fprintln(cmd.OutOrStdout(), bi.Main.Version)
-				default:
-					for _, dep := range bi.Deps {
-						if dep.Path == "github.com/defenseunicorns/maru2" {
-							fmt.Fprintln(cmd.OutOrStdout(), dep.Version)
-							break
-						}
-					}
+				v, err := currentVersion()
+				if err != nil {
+					return err
 				}
+				fmt.Fprintln(cmd.OutOrStdout(), v)
 				return nil
 			}
 
-			// fix fish needing "'pkg:...'" for tab completion
-			from = strings.Trim(from, `"`)
-			from = strings.Trim(from, `'`)
-
 			fs := afero.NewOsFs()
 
-			createDir := true
-			if !cmd.Flags().Changed("store") {
-				localStorePath := ".maru2/store"
-				if fi, err := fs.Stat(localStorePath); err == nil && fi.IsDir() {
-					s = localStorePath
-					createDir = false
-				}
-			}
-
-			s = filepath.Clean(os.ExpandEnv(s))
-			if s == "." {
-				s = ".maru2/store"
-			}
-
-			if createDir {
-				if err := fs.MkdirAll(s, 0o744); err != nil {
-					return err
-				}
-			}
-
-			store, err := uses.NewLocalStore(afero.NewBasePathFs(fs, s))
+			store, _, err := src.openStore(fs, s, cmd.Flags().Changed("store"))
 			if err != nil {
-				return fmt.Errorf("failed to initialize store: %w", err)
+				return err
 			}
 
-			svc, err := uses.NewFetcherService(
+			svc, err := src.newFetcherService(
 				uses.WithStorage(store),
 				uses.WithFetchPolicy(policy),
 			)
@@ -259,7 +364,67 @@ maru2 -f "pkg:github/defenseunicorns/maru2@main#testdata/simple.yaml" echo -w me
 				cmd.SetContext(ctx)
 			}
 
-			resolved, err := uses.ResolveRelative(nil, from, cfg.Aliases)
+			// reuse the ID of a parent run so nested maru2 calls can be correlated
+			runID := os.Getenv("MARU2_RUN_ID")
+			nested := runID != ""
+			if !nested {
+				runID = maru2.NewRunID()
+			}
+			ctx = maru2.WithRunID(ctx, runID)
+			ctx = maru2.WithMutexes(ctx, store)
+			prompter := newPrompter(cmd.InOrStdin(), cmd.ErrOrStderr())
+			ctx = maru2.WithConfirm(ctx, newConfirm(prompter, yes))
+			ctx = maru2.WithUnsupportedPlatform(ctx, unsupportedPlatform)
+			ctx = builtins.WithPrompter(ctx, prompter)
+			if noRemoteFS {
+				ctx = maru2.WithoutRemoteFileReads(ctx)
+			}
+
+			if manifest != "" {
+				m := maru2.NewManifest(runID, dry)
+				ctx = maru2.WithManifest(ctx, m)
+				// always written, so failed runs can be reviewed too
+				defer func() {
+					if err := m.WriteFile(manifest); err != nil {
+						logger.Error("failed to write manifest", "path", manifest, "err", err)
+					}
+				}()
+			}
+
+			// the summary is always shown at debug level, so slow steps are easy to spot, unless it would break up structured logs
+			summary = summary || (logger.GetLevel() == log.DebugLevel && logFormat == "text")
+			for _, spec := range reports {
+				if _, _, err := parseReport(spec); err != nil {
+					return err
+				}
+			}
+			// always recorded for the history, only the called task(s) are recorded though
+			r := maru2.NewReport(runID, dry)
+			ctx = maru2.WithReport(ctx, r)
+			var record func() error
+			// always written, so failed runs are reported too
+			defer func() {
+				r.Finish(ctx, err)
+				if summary && len(r.Tasks) > 0 {
+					fmt.Fprintln(cmd.ErrOrStderr(), "Run summary:")
+					fmt.Fprintln(cmd.ErrOrStderr(), r.Summary())
+				}
+				for _, spec := range reports {
+					if err := writeReport(cmd.OutOrStdout(), r, spec); err != nil {
+						logger.Error("failed to write report", "report", spec, "err", err)
+					}
+				}
+				if record != nil {
+					if err := record(); err != nil {
+						logger.Warn("failed to record run to history", "err", err)
+					}
+				}
+			}()
+
+			// invalid workflows are annotated to the same output as failed steps
+			ctx = maru2.WithAnnotations(ctx, cmd.OutOrStdout())
+
+			resolved, err := svc.ResolveRelative(nil, from, cfg.Aliases)
 			if err != nil {
 				return fmt.Errorf("failed to resolve %q: %w", from, err)
 			}
@@ -270,18 +435,42 @@ maru2 -f "pkg:github/defenseunicorns/maru2@main#testdata/simple.yaml" echo -w me
 			}
 
 			if list {
-				t, err := maru2.NewDetailedTaskList(ctx, svc, resolved, wf)
-				if err != nil {
-					return err
-				}
+				switch listFormat {
+				case "text":
+					t, err := maru2.NewDetailedTaskList(ctx, svc, resolved, wf)
+					if err != nil {
+						return err
+					}
 
-				fmt.Fprintln(cmd.OutOrStdout(), "Available tasks:")
-				fmt.Fprintln(cmd.OutOrStdout(), t)
+					fmt.Fprintln(cmd.OutOrStdout(), "Available tasks:")
+					fmt.Fprintln(cmd.OutOrStdout(), t)
 
-				return nil
+					return nil
+				case "json", "yaml":
+					tasks, err := maru2.ListTasks(ctx, svc, resolved, wf)
+					if err != nil {
+						return err
+					}
+					if listFormat == "json" {
+						return json.NewEncoder(cmd.OutOrStdout()).Encode(tasks)
+					}
+					b, err := yaml.Marshal(tasks)
+					if err != nil {
+						return err
+					}
+					_, err = cmd.OutOrStdout().Write(b)
+					return err
+				default:
+					return fmt.Errorf("list format %q must be one of text, json or yaml", listFormat)
+				}
 			}
 
 			if explain {
+				explanation, err := maru2.Explain(ctx, svc, wf, resolved, args...)
+				if err != nil {
+					return err
+				}
+
 				if IsTerminal(cmd.OutOrStdout()) {
 					renderer, err := glamour.NewTermRenderer(glamour.WithStyles(styles.TokyoNightStyleConfig), glamour.WithWordWrap(100))
 					if err != nil {
@@ -289,7 +478,7 @@ maru2 -f "pkg:github/defenseunicorns/maru2@main#testdata/simple.yaml" echo -w me
 					}
 					defer renderer.Close()
 
-					out, err := renderer.Render(wf.Explain(args...))
+					out, err := renderer.Render(explanation)
 					if err != nil {
 						return err
 					}
@@ -298,7 +487,7 @@ maru2 -f "pkg:github/defenseunicorns/maru2@main#testdata/simple.yaml" echo -w me
 					return nil
 				}
 
-				fmt.Fprintln(cmd.OutOrStdout(), wf.Explain(args...))
+				fmt.Fprintln(cmd.OutOrStdout(), explanation)
 				return nil
 			}
 
@@ -310,17 +499,54 @@ maru2 -f "pkg:github/defenseunicorns/maru2@main#testdata/simple.yaml" echo -w me
 				// allow no args w/ fetch all
 				if len(args) == 0 {
 					if gc {
-						return store.GC()
+						return gcStore(store, cfg)
 					}
 					return nil
 				}
 			}
 
+			sources := maps.Clone(cfg.Secrets)
+			if sources == nil {
+				sources = make(map[string]string, len(secrets))
+			}
+			for _, secret := range secrets {
+				name, source, ok := strings.Cut(secret, "=")
+				if !ok {
+					return fmt.Errorf("invalid secret %q, must be in the form name=source", secret)
+				}
+				sources[name] = source
+			}
+			// only loaded when tasks run, as cmd: sources run commands, always set so secret prompt answers can be masked too
+			loaded, err := maru2.LoadSecrets(ctx, sources)
+			if err != nil {
+				return fmt.Errorf("failed to load secrets: %w", err)
+			}
+			ctx = maru2.WithSecrets(ctx, loaded)
+			setMaskedOutput(logger, cmd.ErrOrStderr(), loaded)
+
 			with := make(schema.With, len(w))
 			for k, v := range w {
 				with[k] = v
 			}
 
+			if withStdin {
+				b, err := io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return fmt.Errorf("failed reading with from stdin: %w", err)
+				}
+				// JSON is valid YAML, so both are parsed the same
+				var fromStdin map[string]any
+				if err := yaml.Unmarshal(b, &fromStdin); err != nil {
+					return fmt.Errorf("failed parsing with from stdin, must be a JSON or YAML object: %w", err)
+				}
+				for k, v := range fromStdin {
+					_, ok := with[k]
+					if !ok { // CLI --with takes priority
+						with[k] = v
+					}
+				}
+			}
+
 			if withFile != "" {
 				f, err := fs.Open(withFile)
 				if err != nil {
@@ -339,23 +565,63 @@ maru2 -f "pkg:github/defenseunicorns/maru2@main#testdata/simple.yaml" echo -w me
 				}
 			}
 
+			for k, v := range profileWith {
+				_, ok := with[k]
+				if !ok { // CLI --with, --with-stdin and --with-file take priority
+					with[k] = v
+				}
+			}
+
 			if len(args) == 0 {
 				args = append(args, schema.DefaultTaskName)
 			}
 
+			// structured logs are for machines, so are dry runs with them
+			if dry && logFormat == "json" {
+				plan := maru2.NewPlan(runID)
+				ctx = maru2.WithPlan(ctx, plan)
+				// always written, so steps that failed to template are planned too
+				defer func() {
+					if err := plan.WriteJSON(cmd.OutOrStdout()); err != nil {
+						logger.Error("failed to write plan", "err", err)
+					}
+				}()
+			}
+
+			// nested maru2 calls are part of their parent's run
+			if maxRuns := cfg.HistoryMaxRuns(); maxRuns > 0 && !nested {
+				record = func() error {
+					return recordHistory(maxRuns, maru2.NewHistoryRun(resolved.String(), args, with, r))
+				}
+			}
+
 			opts := maru2.RuntimeOptions{
 				Dry:    dry,
 				Env:    os.Environ(),
 				Stdout: cmd.OutOrStdout(),
 				Stderr: cmd.OutOrStderr(),
 				Stdin:  cmd.InOrStdin(),
+				LogDir: logDir,
+			}
+
+			if len(skip) > 0 || len(only) > 0 {
+				filter := &maru2.StepFilter{Skip: skip, Only: only}
+				ctx = maru2.WithStepFilter(ctx, filter)
+				// a typo in a step id would otherwise silently run everything (or nothing)
+				defer func() {
+					if err == nil {
+						for _, id := range filter.Unmatched() {
+							logger.Warn("no step found with id", "id", id)
+						}
+					}
+				}()
 			}
 
 			for _, call := range args {
 				parts := strings.SplitN(call, ":", 2)
 
 				if len(parts) == 2 {
-					next, err := uses.ResolveRelative(resolved, call, wf.Aliases)
+					next, err := svc.ResolveRelative(resolved, call, wf.Aliases)
 					if err != nil {
 						return err
 					}
@@ -364,21 +630,21 @@ maru2 -f "pkg:github/defenseunicorns/maru2@main#testdata/simple.yaml" echo -w me
 						return err
 					}
 
-					_, err = maru2.Run(ctx, svc, nextWf, parts[1], with, next, opts)
+					_, err = maru2.Run(ctx, svc, nextWf, parts[1], withTaskDefaults(with, cfg.TaskWith(next, parts[1])), next, opts)
 					if err != nil {
 						return err
 					}
 					continue
 				}
 
-				_, err := maru2.Run(ctx, svc, wf, call, with, resolved, opts)
+				_, err := maru2.Run(ctx, svc, wf, call, withTaskDefaults(with, cfg.TaskWith(resolved, call)), resolved, opts)
 				if err != nil {
 					return err
 				}
 			}
 
 			if gc {
-				return store.GC()
+				return gcStore(store, cfg)
 			}
 
 			return nil
@@ -386,22 +652,88 @@ maru2 -f "pkg:github/defenseunicorns/maru2@main#testdata/simple.yaml" echo -w me
 	}
 
 	root.Flags().StringToStringVarP(&w, "with", "w", nil, "Pass key=value pairs to the called task(s)")
+	_ = root.RegisterFlagCompletionFunc("with", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		svc, wf, resolved, err := completionWorkflow(cmd)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return completeWith(cmd.Context(), svc, wf, resolved, args, w, toComplete)
+	})
 	root.Flags().StringVar(&withFile, "with-file", "", "Extra text file to parse as key=value pairs to pass to the called task(s)")
 	_ = root.MarkFlagFilename("with-file", "txt")
-	root.Flags().StringVarP(&level, "log-level", "l", "info", "Set log level")
+	root.Flags().BoolVar(&withStdin, "with-stdin", false, "Read a JSON or YAML object from stdin to pass to the called task(s)")
+	root.PersistentFlags().StringVarP(&level, "log-level", "l", "info", "Set log level")
 	_ = root.RegisterFlagCompletionFunc("log-level", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
 		return []string{log.DebugLevel.String(), log.InfoLevel.String(), log.WarnLevel.String(), log.ErrorLevel.String(), log.FatalLevel.String()}, cobra.ShellCompDirectiveNoFileComp
 	})
+	root.PersistentFlags().StringVarP(&logFormat, "log-format", "o", "text", `Set log format ("text", "json")`)
+	_ = root.RegisterFlagCompletionFunc("log-format", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp
+	})
 	root.Flags().BoolVarP(&ver, "version", "V", false, "Print version number and exit")
 	root.Flags().BoolVar(&list, "list", false, "Print list of available tasks and exit")
+	root.Flags().StringVar(&listFormat, "list-format", "text", `Set the format of --list ("text", "json", "yaml")`)
+	_ = root.RegisterFlagCompletionFunc("list-format", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"text", "json", "yaml"}, cobra.ShellCompDirectiveNoFileComp
+	})
 	root.Flags().BoolVar(&explain, "explain", false, "Print explanation of workflow/task(s) and exit")
-	root.Flags().StringVarP(&from, "from", "f", "file:"+uses.DefaultFileName, "Read location as workflow definition")
-	root.Flags().DurationVarP(&timeout, "timeout", "t", time.Hour, "Maximum time allowed for execution")
+	root.PersistentFlags().StringVarP(&from, "from", "f", "file:"+uses.DefaultFileName, "Read location as workflow definition")
+	_ = root.RegisterFlagCompletionFunc("from", func(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if !strings.HasPrefix(toComplete, "oci:") {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		completionTimeout, err := cfg.CompletionTimeout()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return completeOCITags(cmd.Context(), toComplete, completionTimeout)
+	})
+	root.Flags().DurationVarP(&timeout, "timeout", "t", time.Hour, "Maximum time allowed for execution, 0 disables the timeout")
 	root.Flags().BoolVar(&dry, "dry-run", false, "Don't actually run anything; just print")
-	root.Flags().StringVarP(&dir, "directory", "C", "", "Change to directory before doing anything")
+	root.Flags().StringArrayVar(&skip, "skip", nil, "Skip the steps with the given id")
+	root.Flags().StringArrayVar(&only, "only", nil, "Run only the steps with the given id, and the tasks they call")
+	root.Flags().Var(&unsupportedPlatform, "unsupported-platform", fmt.Sprintf(`Set how tasks whose platforms do not include the current platform are handled ("%s")`, strings.Join(maru2.AvailableUnsupportedPlatforms(), `", "`)))
+	_ = root.RegisterFlagCompletionFunc("unsupported-platform", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return maru2.AvailableUnsupportedPlatforms(), cobra.ShellCompDirectiveNoFileComp
+	})
+	completeStepIDs := func(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		_, wf, _, err := completionWorkflow(cmd)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		var ids []string
+		for name, task := range wf.Tasks.OrderedSeq() {
+			for _, step := range task.Steps {
+				if step.ID != "" {
+					ids = append(ids, strings.Join([]string{step.ID, cmp.Or(step.Name, name)}, "\t"))
+				}
+			}
+		}
+		return ids, cobra.ShellCompDirectiveNoFileComp
+	}
+	_ = root.RegisterFlagCompletionFunc("skip", completeStepIDs)
+	_ = root.RegisterFlagCompletionFunc("only", completeStepIDs)
+	root.Flags().BoolVarP(&yes, "yes", "y", false, "Run tasks that set confirm without prompting for confirmation")
+	root.Flags().BoolVar(&noRemoteFS, "no-remote-file-reads", false, "Fail local file reads (file, hashFiles and builtins) in workflows fetched from http, https, pkg and oci locations")
+	root.Flags().StringVar(&manifest, "manifest", "", "Write an inventory of every workflow fetched and command executed to a JSON file")
+	_ = root.MarkFlagFilename("manifest", "json")
+	root.Flags().StringVar(&logDir, "log-dir", "", "Write the stdout and stderr of every step to timestamped files in a directory, in addition to the console")
+	_ = root.MarkFlagDirname("log-dir")
+	root.Flags().BoolVar(&summary, "summary", false, "Print the duration and status of every task and step once the run finishes")
+	root.Flags().StringArrayVar(&reports, "report", nil, "Write a summary of the run once it finishes, as format[=path] (json, junit), to stdout if no path is given")
+	root.Flags().StringArrayVar(&secrets, "secret", nil, "Provide a secret from env:VAR, file:PATH or cmd:COMMAND (e.g. token=env:GITHUB_TOKEN), masked in all output")
+	root.PersistentFlags().StringVarP(&dir, "directory", "C", "", "Change to directory before doing anything")
 	_ = root.MarkFlagDirname("directory")
-	root.Flags().StringVarP(&configPath, "config", "", "${HOME}/.maru2/config.yaml", "Path to maru2 config file") // mirrors config.DefaultDirectory
+	root.PersistentFlags().StringVarP(&configPath, "config", "", "${HOME}/.maru2/config.yaml", "Path to maru2 config file") // mirrors config.DefaultDirectory
 	_ = root.MarkFlagFilename("config", "yaml", "yml")
+	root.PersistentFlags().StringVar(&profile, "profile", "", "Select a profile from the config file (env, with and fetch-policy)")
+	_ = root.RegisterFlagCompletionFunc("profile", func(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		if err := loadConfig(cmd); err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return slices.Sorted(maps.Keys(cfg.Profiles)), cobra.ShellCompDirectiveNoFileComp
+	})
 	root.Flags().VarP(&policy, "fetch-policy", "p", fmt.Sprintf(`Set fetch policy ("%s")`, strings.Join(uses.AvailablePolicies(), `", "`)))
 	_ = root.RegisterFlagCompletionFunc("fetch-policy", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
 		return uses.AvailablePolicies(), cobra.ShellCompDirectiveNoFileComp
@@ -411,9 +743,51 @@ maru2 -f "pkg:github/defenseunicorns/maru2@main#testdata/simple.yaml" echo -w me
 	root.Flags().BoolVar(&gc, "gc", false, "Perform garbage collection on the store")
 	root.Flags().BoolVar(&fetchAll, "fetch-all", false, "Fetch all tasks")
 
+	root.AddCommand(newImportCmd(), newExportCmd(src), newVendorCmd(src), newAPICmd(src), newCacheCmd(src), newBundleCmd(src), newGraphCmd(src), newTestCmd(src), newDocsCmd(src), newWhichCmd(src), newLintCmd(src), newDiffCmd(src), newHistoryCmd(), newBuiltinsCmd(), newLoginCmd(), newLogoutCmd())
+
 	return root
 }
 
+// openStore opens the store at path, creating it if it does not exist, and returns it along with its cleaned path
+//
+// If path was not explicitly set and ./.maru2/store exists, it is used instead
+func openStore(fs afero.Fs, path string, explicit bool) (*uses.LocalStore, string, error) {
+	createDir := true
+	if !explicit {
+		localStorePath := ".maru2/store"
+		if fi, err := fs.Stat(localStorePath); err == nil && fi.IsDir() {
+			path = localStorePath
+			createDir = false
+		}
+	}
+
+	path = filepath.Clean(os.ExpandEnv(path))
+	if path == "." {
+		path = ".maru2/store"
+	}
+
+	if createDir {
+		if err := fs.MkdirAll(path, 0o744); err != nil {
+			return nil, "", err
+		}
+	}
+
+	store, err := uses.NewLocalStore(afero.NewBasePathFs(fs, path))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to initialize store: %w", err)
+	}
+	return store, path, nil
+}
+
+// gcStore garbage collects the store using the eviction policy from the config
+func gcStore(store *uses.LocalStore, cfg *configv1.Config) error {
+	opts, err := cfg.GCOptions()
+	if err != nil {
+		return err
+	}
+	return store.GC(opts...)
+}
+
 // Main executes the root command for the maru2 CLI.
 //
 // It returns 0 on success, 1 on failure and logs any errors.
@@ -483,6 +857,53 @@ func ParseExitCode(err error) int {
 	return 1
 }
 
+// workflowSource provides sub-commands access to the workflow set by --from, fetchers configured by the system config and the config itself
+type workflowSource struct {
+	resolve           func(svc *uses.FetcherService) (*url.URL, error)
+	newFetcherService func(opts ...uses.FetcherServiceOption) (*uses.FetcherService, error)
+	config            func() *configv1.Config
+}
+
+// openStore opens the store at path, using the config's store when path was not explicitly set
+func (ws workflowSource) openStore(fs afero.Fs, path string, explicit bool) (*uses.LocalStore, string, error) {
+	if cfg := ws.config(); !explicit && cfg != nil && cfg.Store != "" {
+		path, explicit = cfg.Store, true
+	}
+	return openStore(fs, path, explicit)
+}
+
+// fetch resolves and fetches the workflow
+func (ws workflowSource) fetch(ctx context.Context, svc *uses.FetcherService) (v1.Workflow, *url.URL, error) {
+	resolved, err := ws.resolve(svc)
+	if err != nil {
+		return v1.Workflow{}, nil, err
+	}
+
+	wf, err := maru2.Fetch(ctx, svc, resolved)
+	if err != nil {
+		return v1.Workflow{}, nil, fmt.Errorf("failed to fetch %q: %w", resolved, err)
+	}
+
+	return wf, resolved, nil
+}
+
+// currentVersion returns the version of maru2 that is currently running, whether as the main module or embedded as a library
+func currentVersion() (string, error) {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", fmt.Errorf("version information not available")
+	}
+	if bi.Main.Path == "github.com/defenseunicorns/maru2" {
+		return bi.Main.Version, nil
+	}
+	for _, dep := range bi.Deps {
+		if dep.Path == "github.com/defenseunicorns/maru2" {
+			return dep.Version, nil
+		}
+	}
+	return "", nil
+}
+
 // IsTerminal is a slim wrapper around term.IsTerminal, exported just so that E2E tests can mock
 var IsTerminal = func(wr io.Writer) bool {
 	if f, ok := wr.(*os.File); ok && f != nil {
@@ -491,3 +912,262 @@ var IsTerminal = func(wr io.Writer) bool {
 
 	return false
 }
+
+// completeOCITags completes an oci: reference with the tags available in its repository
+func completeOCITags(ctx context.Context, toComplete string, timeout time.Duration) ([]string, cobra.ShellCompDirective) {
+	uri, err := url.Parse(toComplete)
+	if err != nil || !strings.Contains(uri.Opaque, "/") {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	client, err := uses.NewOCIClient(&http.Client{
+		Timeout: timeout,
+	}, uri.Query().Get(uses.OCIQueryParamInsecureSkipTLSVerify) == "true", uri.Query().Get(uses.OCIQueryParamPlainHTTP) == "true")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	tags, err := client.Tags(ctx, uri)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	// everything up to the tag separator, the registry host may contain a port
+	repo := uri.Opaque
+	if i := strings.Index(repo, "@"); i != -1 {
+		repo = repo[:i]
+	}
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo = repo[:i]
+	}
+
+	completions := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		next := url.URL{Scheme: "oci", Opaque: repo + ":" + tag, RawQuery: uri.RawQuery}
+		completions = append(completions, next.String())
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// setMaskedOutput masks secrets in everything the logger writes to w
+//
+// The colors detected for w are kept, as the mask writer wrapping it is not a terminal
+func setMaskedOutput(logger *log.Logger, w io.Writer, secrets maru2.Secrets) {
+	logger.SetOutput(maru2.NewMaskWriter(w, secrets))
+	logger.SetColorProfile(lipgloss.NewRenderer(w).ColorProfile())
+}
+
+// withTaskDefaults returns with, adding the config's task defaults for keys it does not set
+func withTaskDefaults(with schema.With, defaults map[string]any) schema.With {
+	if len(defaults) == 0 {
+		return with
+	}
+	merged := maps.Clone(with)
+	for k, v := range defaults {
+		if _, ok := merged[k]; !ok {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// completeWith completes -w with the input names of the called task(s), and the values of an input once its name is given
+//
+// Tasks are found the same as when running, so aliased and remote tasks are completed too
+func completeWith(ctx context.Context, svc *uses.FetcherService, wf v1.Workflow, resolved *url.URL, calls []string, set map[string]string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(calls) == 0 {
+		calls = []string{schema.DefaultTaskName}
+	}
+
+	inputs := v1.InputMap{}
+	for _, call := range calls {
+		taskWf, name := wf, call
+		if parts := strings.SplitN(call, ":", 2); len(parts) == 2 {
+			next, err := svc.ResolveRelative(resolved, call, wf.Aliases)
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+			taskWf, err = maru2.Fetch(ctx, svc, next)
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+			name = parts[1]
+		}
+		task, ok := taskWf.Tasks.Find(name)
+		if !ok {
+			continue
+		}
+		maps.Copy(inputs, task.Inputs)
+	}
+
+	if key, value, ok := strings.Cut(toComplete, "="); ok {
+		param, ok := inputs[key]
+		if !ok {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		if param.Type == v1.InputTypePath {
+			return completePath(key, value, param.Path), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+		}
+		options := inputOptions(param)
+		completions := make([]string, 0, len(options))
+		for _, option := range options {
+			completions = append(completions, key+"="+option)
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	completions := make([]string, 0, len(inputs))
+	for name, param := range inputs.OrderedSeq() {
+		if _, ok := set[name]; ok {
+			continue
+		}
+		completions = append(completions, strings.Join([]string{name + "=", param.Description}, "\t"))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// completePath lists the files and directories that complete the value of a path input
+//
+// Directories are always listed so they can be completed into, files are filtered by the input's path options
+func completePath(key, value string, opts *v1.PathOptions) []string {
+	dir, base := filepath.Split(value)
+	entries, err := os.ReadDir(cmp.Or(dir, "."))
+	if err != nil {
+		return nil
+	}
+
+	var completions []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, base) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".")) {
+			continue
+		}
+		if entry.IsDir() {
+			completions = append(completions, key+"="+dir+name+string(filepath.Separator))
+			continue
+		}
+		if opts != nil {
+			if opts.Kind == "directory" {
+				continue
+			}
+			if len(opts.Extensions) > 0 && !slices.ContainsFunc(opts.Extensions, func(ext string) bool { return strings.HasSuffix(name, ext) }) {
+				continue
+			}
+		}
+		completions = append(completions, key+"="+dir+name)
+	}
+	return completions
+}
+
+// inputOptions returns the values an input accepts, if they can be listed
+//
+// Values are listed when the input's validate pattern only matches a small set of strings (e.g. ^(dev|prod)$),
+// or when its default is a boolean
+func inputOptions(param v1.InputParameter) []string {
+	if param.Validate != "" {
+		re, err := syntax.Parse(param.Validate, syntax.Perl)
+		if err != nil {
+			return nil
+		}
+		// unanchored patterns match any surrounding text, so cannot be listed
+		if !anchored(re) {
+			return nil
+		}
+		matches, ok := literalMatches(re)
+		if !ok {
+			return nil
+		}
+		// keep the order the values are written in
+		options := make([]string, 0, len(matches))
+		for _, m := range matches {
+			if !slices.Contains(options, m) {
+				options = append(options, m)
+			}
+		}
+		return options
+	}
+
+	if _, ok := param.Default.(bool); ok {
+		return []string{"true", "false"}
+	}
+
+	return nil
+}
+
+// anchored reports whether every match of a regular expression must span the whole string
+func anchored(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpCapture:
+		return anchored(re.Sub[0])
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if !anchored(sub) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpConcat:
+		first, last := re.Sub[0].Op, re.Sub[len(re.Sub)-1].Op
+		return (first == syntax.OpBeginText || first == syntax.OpBeginLine) && (last == syntax.OpEndText || last == syntax.OpEndLine)
+	default:
+		return false
+	}
+}
+
+// maxInputOptions is the most values inputOptions will list
+const maxInputOptions = 50
+
+// literalMatches lists every string matched by a regular expression, ignoring anchors, if there are at most maxInputOptions of them
+func literalMatches(re *syntax.Regexp) ([]string, bool) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase != 0 {
+			return nil, false
+		}
+		return []string{string(re.Rune)}, true
+	case syntax.OpEmptyMatch, syntax.OpBeginText, syntax.OpEndText, syntax.OpBeginLine, syntax.OpEndLine:
+		return []string{""}, true
+	case syntax.OpCapture:
+		return literalMatches(re.Sub[0])
+	case syntax.OpQuest:
+		sub, ok := literalMatches(re.Sub[0])
+		if !ok {
+			return nil, false
+		}
+		return append(sub, ""), len(sub) < maxInputOptions
+	case syntax.OpAlternate:
+		var all []string
+		for _, sub := range re.Sub {
+			matches, ok := literalMatches(sub)
+			if !ok {
+				return nil, false
+			}
+			all = append(all, matches...)
+			if len(all) > maxInputOptions {
+				return nil, false
+			}
+		}
+		return all, true
+	case syntax.OpConcat:
+		all := []string{""}
+		for _, sub := range re.Sub {
+			matches, ok := literalMatches(sub)
+			if !ok {
+				return nil, false
+			}
+			next := make([]string, 0, len(all)*len(matches))
+			for _, prefix := range all {
+				for _, m := range matches {
+					next = append(next, prefix+m)
+				}
+			}
+			if len(next) > maxInputOptions {
+				return nil, false
+			}
+			all = next
+		}
+		return all, true
+	default:
+		return nil, false
+	}
+}
@@ -159,6 +159,13 @@ tasks:
 		"  • builtin:fetch                                                                                 ",
 		"                                                                                                  ",
 		"  ### echo                                                                                        ",
+		"                                                                                                  ",
+		"  ## Call Tree                                                                                    ",
+		"                                                                                                  ",
+		"  • default                                                                                       ",
+		"    • echo                                                                                        ",
+		"    • builtin:fetch                                                                               ",
+		"  • echo                                                                                          ",
 		"",
 		"",
 		"",
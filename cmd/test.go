@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+
+	"github.com/defenseunicorns/maru2"
+)
+
+// newTestCmd creates the `test` sub-command, used to run the tests declared in a workflow
+func newTestCmd(src workflowSource) *cobra.Command {
+	var dry bool
+
+	test := &cobra.Command{
+		Use:   "test [test...]",
+		Short: "Run the tests declared in a workflow",
+		Long: `Run the tests declared in a workflow
+
+Every test calls a task with the given inputs, optionally as a dry run or with uses: steps mocked,
+then checks its outputs, exit code and error. When no tests are given, every test in the workflow is run.`,
+		Example: `
+maru2 test
+
+maru2 test builds-release --dry-run
+`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := maru2.WithAnnotations(cmd.Context(), cmd.OutOrStdout())
+			logger := log.FromContext(ctx)
+
+			svc, err := src.newFetcherService()
+			if err != nil {
+				return err
+			}
+
+			wf, resolved, err := src.fetch(ctx, svc)
+			if err != nil {
+				return err
+			}
+
+			if len(wf.Tests) == 0 {
+				logger.Warn("no tests found", "from", resolved)
+				return nil
+			}
+
+			ctx = maru2.WithRunID(ctx, maru2.NewRunID())
+
+			results, err := maru2.RunTests(ctx, svc, wf, resolved, maru2.RuntimeOptions{
+				Dry:    dry,
+				Env:    os.Environ(),
+				Stdout: cmd.OutOrStdout(),
+				Stderr: cmd.OutOrStderr(),
+				Stdin:  cmd.InOrStdin(),
+			}, args...)
+			if err != nil {
+				return err
+			}
+
+			failed := 0
+			for _, result := range results {
+				status := "PASS"
+				if !result.Passed() {
+					status = "FAIL"
+					failed++
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s %s (%s)\n", status, result.Name, result.Duration.Round(time.Millisecond))
+				for _, failure := range result.Failures {
+					fmt.Fprintf(cmd.OutOrStdout(), "    %s\n", failure)
+				}
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d of %d tests failed", failed, len(results))
+			}
+			return nil
+		},
+	}
+
+	test.Flags().BoolVar(&dry, "dry-run", false, "Run every test as a dry run, even if the test does not set dry-run")
+
+	return test
+}
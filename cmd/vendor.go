@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/defenseunicorns/maru2"
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+// newVendorCmd creates the `vendor` sub-command, used to save all remote dependencies of a workflow locally
+func newVendorCmd(src workflowSource) *cobra.Command {
+	return &cobra.Command{
+		Use:   "vendor",
+		Short: "Save all remote dependencies of a workflow for offline use",
+		Long: `Save all remote dependencies of a workflow for offline use
+
+Every transitive uses: reference is fetched and written to .maru2/vendor.
+When .maru2/vendor exists, vendored workflows take precedence over all other sources.
+
+Re-run to update the vendored workflows, any that are no longer referenced are removed.`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			logger := log.FromContext(ctx)
+
+			fs := afero.NewOsFs()
+
+			// vendor into a temporary directory so a failed fetch does not leave behind a partial vendor directory
+			tmp := uses.VendorDirectory + ".tmp"
+			if err := fs.RemoveAll(tmp); err != nil {
+				return err
+			}
+			if err := fs.MkdirAll(tmp, 0o744); err != nil {
+				return err
+			}
+			defer fs.RemoveAll(tmp)
+
+			store, err := uses.NewLocalStore(afero.NewBasePathFs(fs, tmp))
+			if err != nil {
+				return fmt.Errorf("failed to initialize vendor store: %w", err)
+			}
+
+			svc, err := src.newFetcherService(
+				uses.WithStorage(store),
+				uses.WithVendor(nil), // always fetch from the source
+				uses.WithFetchPolicy(uses.FetchPolicyAlways),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to initialize fetcher service: %w", err)
+			}
+
+			wf, resolved, err := src.fetch(ctx, svc)
+			if err != nil {
+				return err
+			}
+
+			if err := maru2.FetchAll(ctx, svc, wf, resolved); err != nil {
+				return err
+			}
+
+			if err := fs.RemoveAll(uses.VendorDirectory); err != nil {
+				return err
+			}
+			if err := fs.Rename(tmp, uses.VendorDirectory); err != nil {
+				return err
+			}
+
+			vendored := []string{}
+			for id := range store.List() {
+				vendored = append(vendored, id)
+			}
+			slices.Sort(vendored)
+
+			for _, id := range vendored {
+				logger.Info("vendored", "url", id)
+			}
+			if len(vendored) == 0 {
+				logger.Info("no remote dependencies to vendor")
+			}
+
+			return nil
+		},
+	}
+}
+
+// withVendor configures a fetcher service to use the vendored workflows in the vendor directory, if it exists
+func withVendor(fs afero.Fs) (uses.FetcherServiceOption, error) {
+	fi, err := fs.Stat(uses.VendorDirectory)
+	if err != nil || !fi.IsDir() {
+		return uses.WithVendor(nil), nil
+	}
+
+	vendor, err := uses.NewLocalStore(afero.NewBasePathFs(fs, uses.VendorDirectory))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vendored workflows: %w", err)
+	}
+
+	return uses.WithVendor(vendor), nil
+}
@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/defenseunicorns/maru2/schema"
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+// newWhichCmd creates the `which` sub-command, used to show where a uses: reference is fetched from
+func newWhichCmd(src workflowSource) *cobra.Command {
+	var s string
+
+	which := &cobra.Command{
+		Use:   "which <uses-ref>",
+		Short: "Show where a uses: reference is fetched from",
+		Long: `Show where a uses: reference is fetched from
+
+The reference is resolved the same as a uses: step in the workflow set by --from, including the workflow's aliases.
+Remote workflows are looked up in the store and the vendor directory, local files are read from disk,
+and the digest of the workflow that would run is printed, without fetching anything.`,
+		Example: `
+maru2 which "pkg:github/defenseunicorns/maru2@main?task=echo#testdata/simple.yaml"
+
+maru2 which common:setup
+`,
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ref := args[0]
+
+			svc, err := src.newFetcherService()
+			if err != nil {
+				return err
+			}
+
+			wf, origin, err := src.fetch(cmd.Context(), svc)
+			if err != nil {
+				return err
+			}
+
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintf(tw, "Reference:\t%s\n", ref)
+
+			if strings.HasPrefix(ref, "builtin:") {
+				fmt.Fprintf(tw, "Builtin:\t%s\n", strings.TrimPrefix(ref, "builtin:"))
+				return tw.Flush()
+			}
+
+			resolved, err := resolveRef(svc, wf, origin, ref)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(tw, "Resolved:\t%s\n", resolved)
+			task := resolved.Query().Get(uses.QualifierTask)
+			if task == "" {
+				task = schema.DefaultTaskName
+			}
+			fmt.Fprintf(tw, "Task:\t%s\n", task)
+
+			fs := afero.NewOsFs()
+
+			if resolved.Scheme == "file" {
+				p := resolved.Opaque
+				if p == "" {
+					p = resolved.Path
+				}
+				abs, err := filepath.Abs(p)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(tw, "Path:\t%s\n", abs)
+
+				digest, err := fileDigest(fs, abs)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(tw, "Digest:\th1:%s\n", digest)
+				return tw.Flush()
+			}
+
+			store, _, err := src.openStore(fs, s, cmd.Flags().Changed("store"))
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(tw, "Store:\t%s\n", storeStatus(store, resolved))
+
+			if fi, err := fs.Stat(uses.VendorDirectory); err == nil && fi.IsDir() {
+				vendor, err := uses.NewLocalStore(afero.NewBasePathFs(fs, uses.VendorDirectory))
+				if err != nil {
+					return fmt.Errorf("failed to load vendored workflows: %w", err)
+				}
+				// vendored workflows take priority over the store and the source
+				fmt.Fprintf(tw, "Vendored:\t%s\n", storeStatus(vendor, resolved))
+			}
+
+			return tw.Flush()
+		},
+	}
+
+	which.Flags().StringVarP(&s, "store", "s", "${HOME}/.maru2/store", "Set storage directory")
+	_ = which.MarkFlagDirname("store")
+
+	return which
+}
+
+// resolveRef resolves a uses: reference the same as a step in the given workflow, a task in the workflow resolves to the workflow itself
+func resolveRef(svc *uses.FetcherService, wf v1.Workflow, origin *url.URL, ref string) (*url.URL, error) {
+	if _, ok := wf.Tasks.Find(ref); ok {
+		clone := *origin
+		q := clone.Query()
+		q.Set(uses.QualifierTask, ref)
+		clone.RawQuery = q.Encode()
+		return &clone, nil
+	}
+
+	resolved, err := svc.ResolveRelative(origin, ref, wf.Aliases)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %q: %w", ref, err)
+	}
+	return resolved, nil
+}
+
+// storeStatus describes whether a workflow is in a store, and if so its digest and when it was fetched
+func storeStatus(store *uses.LocalStore, uri *url.URL) string {
+	exists, err := store.Exists(uri)
+	if err != nil {
+		return fmt.Sprintf("corrupt (%s)", err)
+	}
+	if !exists {
+		return "not stored"
+	}
+	desc, _ := store.Describe(uri)
+	return fmt.Sprintf("h1:%s (fetched %s)", desc.Hex, fetchedAt(desc))
+}
+
+// fileDigest returns the hex encoded SHA-256 digest of a file, the same as the store's digests
+func fileDigest(fs afero.Fs, path string) (string, error) {
+	f, err := fs.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
@@ -24,3 +24,34 @@ func DefaultDirectory() (string, error) {
 
 	return filepath.Join(homeDir, ".maru2"), nil
 }
+
+// ProjectDirectory is the directory holding a project's config file, relative to the project root
+const ProjectDirectory = ".maru2"
+
+// FindProjectConfig returns the path to the project config file (.maru2/config.yaml) in dir or its closest parent
+//
+// The global config file is never returned as a project config, an empty string is returned if no project config is found
+func FindProjectConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var global string
+	if globalDir, err := DefaultDirectory(); err == nil {
+		global = filepath.Join(globalDir, DefaultFileName)
+	}
+
+	for {
+		path := filepath.Join(dir, ProjectDirectory, DefaultFileName)
+		if fi, err := os.Stat(path); err == nil && !fi.IsDir() && path != global {
+			return path, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
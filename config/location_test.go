@@ -80,3 +80,37 @@ fetch-policy: always
 	require.NoError(t, err)
 	assert.Equal(t, tcfg, cfg)
 }
+
+func TestFindProjectConfig(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("HOME", root)
+
+	nested := filepath.Join(root, "project", "a", "b")
+	require.NoError(t, os.MkdirAll(nested, 0o755))
+
+	// no project config
+	path, err := config.FindProjectConfig(nested)
+	require.NoError(t, err)
+	assert.Empty(t, path)
+
+	// the global config is not a project config
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".maru2"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".maru2", config.DefaultFileName), []byte("schema-version: v1\n"), 0o644))
+	path, err = config.FindProjectConfig(nested)
+	require.NoError(t, err)
+	assert.Empty(t, path)
+
+	// the closest project config is found
+	project := filepath.Join(root, "project", config.ProjectDirectory, config.DefaultFileName)
+	require.NoError(t, os.MkdirAll(filepath.Dir(project), 0o755))
+	require.NoError(t, os.WriteFile(project, []byte("schema-version: v1\n"), 0o644))
+	path, err = config.FindProjectConfig(nested)
+	require.NoError(t, err)
+	assert.Equal(t, project, path)
+
+	closer := filepath.Join(nested, config.ProjectDirectory, config.DefaultFileName)
+	require.NoError(t, os.MkdirAll(closer, 0o755)) // a directory is not a config file
+	path, err = config.FindProjectConfig(nested)
+	require.NoError(t, err)
+	assert.Equal(t, project, path)
+}
@@ -7,16 +7,25 @@
 package v0
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/goccy/go-yaml"
 	"github.com/invopop/jsonschema"
+	"github.com/package-url/packageurl-go"
 	"github.com/xeipuuv/gojsonschema"
 
 	"github.com/defenseunicorns/maru2/config"
@@ -33,6 +42,433 @@ type Config struct {
 	SchemaVersion string           `json:"schema-version"`
 	Aliases       v1.AliasMap      `json:"aliases"`
 	FetchPolicy   uses.FetchPolicy `json:"fetch-policy"`
+	Hosts         map[string]Host  `json:"hosts,omitempty"`
+	// Proxy to use for all requests, overrides the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables
+	Proxy string `json:"proxy,omitempty"`
+	TLS   *TLS   `json:"tls,omitempty"`
+	// Retry policy for remote fetches, retries are enabled by default
+	Retry    *Retry    `json:"retry,omitempty"`
+	Cache    *Cache    `json:"cache,omitempty"`
+	Timeouts *Timeouts `json:"timeouts,omitempty"`
+	// Mirrors remote workflows are fetched from, the first mirror whose prefix matches a workflow's URL is used
+	Mirrors uses.Mirrors `json:"mirrors,omitempty"`
+	GitHub  *GitHub      `json:"github,omitempty"`
+	// Secrets provided to every run, as a map of name to source (env:VAR, file:PATH or cmd:COMMAND)
+	//
+	// Overridden by --secret
+	Secrets map[string]string `json:"secrets,omitempty"`
+	// History of past runs, listed with maru2 history
+	History *History `json:"history,omitempty"`
+	// Named sets of settings, selected with --profile or MARU2_PROFILE
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+}
+
+// Profile is a named set of settings for a single environment (e.g. staging or production)
+type Profile struct {
+	// Environment variables set for the run, values are expanded using environment variables
+	Env map[string]string `json:"env,omitempty"`
+	// Inputs passed to the called task(s), overridden by --with, --with-stdin and --with-file
+	With map[string]any `json:"with,omitempty"`
+	// Fetch policy used instead of .fetch-policy, overridden by --fetch-policy
+	FetchPolicy uses.FetchPolicy `json:"fetch-policy,omitempty"`
+}
+
+// GitHub is the configuration for fetching pkg:github workflows
+type GitHub struct {
+	// Use the GitHub CLI's (gh) token when neither token-from-env nor GITHUB_TOKEN are set
+	TokenFromGH bool `json:"token-from-gh,omitempty"`
+}
+
+// Timeouts are the default timeouts used when not set by CLI flags
+type Timeouts struct {
+	// Maximum time allowed for execution when --timeout is not set, 0 disables the timeout (default 1h)
+	Run string `json:"run,omitempty" jsonschema:"pattern=^(0|([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$"`
+	// Maximum time allowed for each remote request during shell completion (default 500ms)
+	Completion string `json:"completion,omitempty" jsonschema:"pattern=^(0|([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$"`
+	// Maximum time allowed for each remote request when fetching workflows, 0 disables the timeout (default 0)
+	Fetch string `json:"fetch,omitempty" jsonschema:"pattern=^(0|([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$"`
+}
+
+// DefaultTimeouts are the timeouts used when not set in the config
+var DefaultTimeouts = Timeouts{
+	Run:        "1h",
+	Completion: "500ms",
+	Fetch:      "0",
+}
+
+// History is the configuration for recording past runs
+type History struct {
+	// Number of runs kept, the oldest runs are removed first, 0 disables the history
+	MaxRuns int `json:"max-runs" jsonschema:"minimum=0"`
+}
+
+// DefaultHistory is used when history is not set in the config
+var DefaultHistory = History{
+	MaxRuns: 100,
+}
+
+// Cache is the eviction policy applied to the store when garbage collecting with --gc
+type Cache struct {
+	// Evict workflows stored longer ago than this duration (e.g. 720h)
+	MaxAge string `json:"max-age,omitempty"`
+	// Evict the least recently stored workflows until the store is at most this size (e.g. 100MB)
+	MaxSize string `json:"max-size,omitempty" jsonschema:"pattern=^[0-9]+(B|KB|MB|GB|KiB|MiB|GiB)?$"`
+}
+
+// Retry is the retry policy used when fetching remote workflows
+type Retry struct {
+	// Maximum number of retries after the first attempt, 0 disables retries
+	Attempts int `json:"attempts" jsonschema:"minimum=0"`
+	// Delay before the first retry, doubled after every retry (default 1s)
+	Backoff string `json:"backoff,omitempty"`
+	// Maximum delay between retries (default 30s)
+	MaxBackoff string `json:"max-backoff,omitempty"`
+	// HTTP status codes to retry (default 429, 502, 503 and 504)
+	StatusCodes []int `json:"status-codes,omitempty"`
+}
+
+// DefaultRetry is the retry policy used when retry is not set in the config
+var DefaultRetry = Retry{
+	Attempts:   3,
+	Backoff:    "1s",
+	MaxBackoff: "30s",
+}
+
+// TLS is the TLS configuration used when fetching remote workflows
+type TLS struct {
+	// Path to a PEM encoded file of certificate authorities to trust in addition to the system's
+	CAFile string `json:"ca-file,omitempty"`
+	// Path to a PEM encoded client certificate for mutual TLS
+	CertFile string `json:"cert-file,omitempty"`
+	// Path to the PEM encoded private key for the client certificate
+	KeyFile string `json:"key-file,omitempty"`
+}
+
+// Host is the configuration for fetching workflows from a single host
+type Host struct {
+	// Headers to send with every request to the host, values are expanded using environment variables
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Headers returns the headers to send with requests to each host
+//
+// Headers set on an alias apply to the host of its base URL (or the default base URL for its type),
+// headers set in hosts take priority over those set on aliases
+func (c *Config) Headers() (uses.HostHeaders, error) {
+	headers := uses.HostHeaders{}
+
+	add := func(host string, hh map[string]string) {
+		if len(hh) == 0 {
+			return
+		}
+		if headers[host] == nil {
+			headers[host] = http.Header{}
+		}
+		for k, v := range hh {
+			headers[host].Set(k, os.ExpandEnv(v))
+		}
+	}
+
+	for name, alias := range c.Aliases.OrderedSeq() {
+		if len(alias.Headers) == 0 {
+			continue
+		}
+		base := alias.BaseURL
+		if base == "" {
+			switch alias.Type {
+			case packageurl.TypeGithub:
+				base = "https://api.github.com"
+			case packageurl.TypeGitlab:
+				base = "https://gitlab.com"
+			}
+		}
+		u, err := url.Parse(base)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf(".aliases.%s.base-url %q must be a valid URL to set headers", name, alias.BaseURL)
+		}
+		add(u.Host, alias.Headers)
+	}
+
+	for host, h := range c.Hosts {
+		add(host, h.Headers)
+	}
+
+	return headers, nil
+}
+
+// TransportOptions returns the fetcher service options for the configured proxy, TLS, retry, mirror and fetch timeout settings
+func (c *Config) TransportOptions() ([]uses.FetcherServiceOption, error) {
+	var opts []uses.FetcherServiceOption
+
+	if c.Proxy != "" {
+		u, err := url.Parse(c.Proxy)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf(".proxy %q must be a valid URL", c.Proxy)
+		}
+		opts = append(opts, uses.WithProxy(u))
+	}
+
+	retry := DefaultRetry
+	if c.Retry != nil {
+		retry = *c.Retry
+	}
+	if retry.Attempts > 0 {
+		duration := func(field, value, def string) (time.Duration, error) {
+			if value == "" {
+				value = def
+			}
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return 0, fmt.Errorf(".retry.%s %q is not a valid time duration", field, value)
+			}
+			return d, nil
+		}
+
+		backoff, err := duration("backoff", retry.Backoff, DefaultRetry.Backoff)
+		if err != nil {
+			return nil, err
+		}
+		maxBackoff, err := duration("max-backoff", retry.MaxBackoff, DefaultRetry.MaxBackoff)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, uses.WithRetry(uses.RetryPolicy{
+			Attempts:    retry.Attempts,
+			Backoff:     backoff,
+			MaxBackoff:  maxBackoff,
+			StatusCodes: retry.StatusCodes,
+		}))
+	}
+
+	if len(c.Mirrors) > 0 {
+		if err := c.Mirrors.Validate(); err != nil {
+			return nil, fmt.Errorf(".mirrors: %w", err)
+		}
+		opts = append(opts, uses.WithMirrors(c.Mirrors))
+	}
+
+	var fetch string
+	if c.Timeouts != nil {
+		fetch = c.Timeouts.Fetch
+	}
+	timeout, err := parseTimeout("fetch", fetch, DefaultTimeouts.Fetch)
+	if err != nil {
+		return nil, err
+	}
+	if timeout > 0 {
+		opts = append(opts, uses.WithTimeout(timeout))
+	}
+
+	if c.TLS == nil {
+		return opts, nil
+	}
+
+	if c.TLS.CAFile != "" {
+		pool, err := uses.LoadRootCAs(c.TLS.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf(".tls.ca-file: %w", err)
+		}
+		opts = append(opts, uses.WithRootCAs(pool))
+	}
+
+	if c.TLS.CertFile != "" || c.TLS.KeyFile != "" {
+		if c.TLS.CertFile == "" || c.TLS.KeyFile == "" {
+			return nil, errors.New(".tls.cert-file and .tls.key-file must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(c.TLS.CertFile, c.TLS.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf(".tls: %w", err)
+		}
+		opts = append(opts, uses.WithClientCertificates(cert))
+	}
+
+	return opts, nil
+}
+
+// GCOptions returns the store garbage collection options for the configured cache eviction policy
+func (c *Config) GCOptions() ([]uses.GCOption, error) {
+	var opts []uses.GCOption
+	if c.Cache == nil {
+		return opts, nil
+	}
+
+	if c.Cache.MaxAge != "" {
+		age, err := time.ParseDuration(c.Cache.MaxAge)
+		if err != nil {
+			return nil, fmt.Errorf(".cache.max-age %q is not a valid time duration", c.Cache.MaxAge)
+		}
+		opts = append(opts, uses.WithMaxAge(age))
+	}
+
+	if c.Cache.MaxSize != "" {
+		size, err := ParseSize(c.Cache.MaxSize)
+		if err != nil {
+			return nil, fmt.Errorf(".cache.max-size %q is not a valid size", c.Cache.MaxSize)
+		}
+		opts = append(opts, uses.WithMaxSize(size))
+	}
+
+	return opts, nil
+}
+
+// Profile returns the profile with the given name
+func (c *Config) Profile(name string) (Profile, error) {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("profile %q not found%s", name, v1.DidYouMean(name, slices.Sorted(maps.Keys(c.Profiles))))
+	}
+	return profile, nil
+}
+
+// HistoryMaxRuns returns the number of runs kept in the history, 0 disables the history
+func (c *Config) HistoryMaxRuns() int {
+	if c.History == nil {
+		return DefaultHistory.MaxRuns
+	}
+	return c.History.MaxRuns
+}
+
+// TokenFromGH returns whether to use the GitHub CLI's token for pkg:github fetches
+func (c *Config) TokenFromGH() bool {
+	return c.GitHub != nil && c.GitHub.TokenFromGH
+}
+
+// RunTimeout returns the maximum time allowed for execution when --timeout is not set, 0 disables the timeout
+func (c *Config) RunTimeout() (time.Duration, error) {
+	var value string
+	if c.Timeouts != nil {
+		value = c.Timeouts.Run
+	}
+	return parseTimeout("run", value, DefaultTimeouts.Run)
+}
+
+// CompletionTimeout returns the maximum time allowed for each remote request during shell completion
+func (c *Config) CompletionTimeout() (time.Duration, error) {
+	var value string
+	if c.Timeouts != nil {
+		value = c.Timeouts.Completion
+	}
+	d, err := parseTimeout("completion", value, DefaultTimeouts.Completion)
+	if err != nil {
+		return 0, err
+	}
+	// completions must never hang the shell
+	if d == 0 {
+		return 0, fmt.Errorf(".timeouts.completion must be greater than 0")
+	}
+	return d, nil
+}
+
+// parseTimeout parses the timeout set at .timeouts.<field>, using def if unset
+func parseTimeout(field, value, def string) (time.Duration, error) {
+	if value == "" {
+		value = def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf(".timeouts.%s %q is not a valid time duration", field, value)
+	}
+	return d, nil
+}
+
+// ParseSize parses a size in bytes with an optional unit (e.g. 512, 100MB or 1GiB)
+func ParseSize(s string) (int64, error) {
+	units := []struct {
+		suffix     string
+		multiplier int64
+	}{
+		{"KiB", 1 << 10},
+		{"MiB", 1 << 20},
+		{"GiB", 1 << 30},
+		{"KB", 1e3},
+		{"MB", 1e6},
+		{"GB", 1e9},
+		{"B", 1},
+	}
+
+	multiplier := int64(1)
+	for _, unit := range units {
+		if n, ok := strings.CutSuffix(s, unit.suffix); ok {
+			s, multiplier = n, unit.multiplier
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return n * multiplier, nil
+}
+
+// ExpandEnv expands ${VAR} and $VAR in the values that locate resources, failing if a variable is not set
+//
+// These are alias base URLs, paths and token-from-env, the proxy, TLS files and mirrors.
+// Headers and profile env are expanded when they are used instead
+func (c *Config) ExpandEnv() error {
+	for _, name := range slices.Sorted(maps.Keys(c.Aliases)) {
+		alias := c.Aliases[name]
+		for field, value := range map[string]*string{
+			"base-url":       &alias.BaseURL,
+			"token-from-env": &alias.TokenFromEnv,
+			"path":           &alias.Path,
+		} {
+			expanded, err := ExpandEnv(fmt.Sprintf(".aliases.%s.%s", name, field), *value)
+			if err != nil {
+				return err
+			}
+			*value = expanded
+		}
+		c.Aliases[name] = alias
+	}
+
+	var err error
+	if c.Proxy, err = ExpandEnv(".proxy", c.Proxy); err != nil {
+		return err
+	}
+
+	if c.TLS != nil {
+		for field, value := range map[string]*string{
+			"ca-file":   &c.TLS.CAFile,
+			"cert-file": &c.TLS.CertFile,
+			"key-file":  &c.TLS.KeyFile,
+		} {
+			if *value, err = ExpandEnv(".tls."+field, *value); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := range c.Mirrors {
+		for field, value := range map[string]*string{
+			"prefix":   &c.Mirrors[i].Prefix,
+			"replace":  &c.Mirrors[i].Replace,
+			"base-url": &c.Mirrors[i].BaseURL,
+		} {
+			if *value, err = ExpandEnv(fmt.Sprintf(".mirrors[%d].%s", i, field), *value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ExpandEnv expands ${VAR} and $VAR in the value set at field, failing if a variable is not set
+func ExpandEnv(field, value string) (string, error) {
+	var missing []string
+	expanded := os.Expand(value, func(name string) string {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%s: environment variable %s is not set", field, strings.Join(missing, ", "))
+	}
+	return expanded, nil
 }
 
 // the default config, matches flag defaults in cmd/root.go
@@ -76,11 +512,14 @@ func LoadConfig(r io.Reader) (*Config, error) {
 		if err := yaml.Unmarshal(data, cfg); err != nil {
 			return nil, fmt.Errorf("failed to parse config file: %w", err)
 		}
+		if err := cfg.ExpandEnv(); err != nil {
+			return nil, err
+		}
 		if err := Validate(cfg); err != nil {
 			return nil, err
 		}
 		return cfg, nil
-	// See schema/v1/validate.go for an example on how auto migrations during loading/reading can work for when v1 of config is released
+	// v1 configs are loaded by config/v1, which migrates v0 configs
 	default:
 		return nil, fmt.Errorf("unsupported config schema version: expected %q, got %q", SchemaVersion, version)
 	}
@@ -4,7 +4,15 @@
 package v0
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"io"
+	"math/big"
+	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -12,6 +20,7 @@ import (
 	"sync"
 	"testing"
 	"testing/iotest"
+	"time"
 
 	"github.com/package-url/packageurl-go"
 	"github.com/stretchr/testify/assert"
@@ -55,6 +64,177 @@ fetch-policy: always`),
 				},
 			},
 		},
+		{
+			name: "config with headers",
+			reader: strings.NewReader(`schema-version: v0
+aliases:
+  gh:
+    type: github
+    headers:
+      X-Custom: foo
+fetch-policy: always
+hosts:
+  artifacts.example.com:
+    headers:
+      Authorization: Bearer ${TOKEN}`),
+			expected: &Config{
+				SchemaVersion: SchemaVersion,
+				FetchPolicy:   uses.FetchPolicyAlways,
+				Aliases: v1.AliasMap{
+					"gh": {
+						Type:    packageurl.TypeGithub,
+						Headers: map[string]string{"X-Custom": "foo"},
+					},
+				},
+				Hosts: map[string]Host{
+					"artifacts.example.com": {
+						Headers: map[string]string{"Authorization": "Bearer ${TOKEN}"},
+					},
+				},
+			},
+		},
+		{
+			name: "proxy, tls and retry",
+			reader: strings.NewReader(`schema-version: v0
+proxy: http://proxy.example.com:3128
+tls:
+  ca-file: /etc/pki/ca.pem
+retry:
+  attempts: 5
+  backoff: 2s
+  status-codes: [500, 502]`),
+			expected: &Config{
+				SchemaVersion: SchemaVersion,
+				FetchPolicy:   uses.DefaultFetchPolicy,
+				Aliases:       v1.AliasMap{},
+				Proxy:         "http://proxy.example.com:3128",
+				TLS:           &TLS{CAFile: "/etc/pki/ca.pem"},
+				Retry:         &Retry{Attempts: 5, Backoff: "2s", StatusCodes: []int{500, 502}},
+			},
+		},
+		{
+			name: "invalid cache max size",
+			reader: strings.NewReader(`schema-version: v0
+cache:
+  max-size: 1TB`),
+			expectErr: "cache.max-size: Does not match pattern '^[0-9]+(B|KB|MB|GB|KiB|MiB|GiB)?$'",
+		},
+		{
+			name: "timeouts",
+			reader: strings.NewReader(`schema-version: v0
+timeouts:
+  run: 2h30m
+  completion: 2s
+  fetch: "0"`),
+			expected: &Config{
+				SchemaVersion: SchemaVersion,
+				FetchPolicy:   uses.DefaultFetchPolicy,
+				Aliases:       v1.AliasMap{},
+				Timeouts:      &Timeouts{Run: "2h30m", Completion: "2s", Fetch: "0"},
+			},
+		},
+		{
+			name: "invalid timeout",
+			reader: strings.NewReader(`schema-version: v0
+timeouts:
+  run: 1 hour`),
+			expectErr: "timeouts.run: Does not match pattern '^(0|([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$'",
+		},
+		{
+			name: "mirrors",
+			reader: strings.NewReader(`schema-version: v0
+mirrors:
+  - prefix: oci:ghcr.io/
+    replace: oci:zot.example.com/ghcr.io/
+  - prefix: pkg:github/
+    base-url: https://artifactory.example.com/api/github`),
+			expected: &Config{
+				SchemaVersion: SchemaVersion,
+				FetchPolicy:   uses.DefaultFetchPolicy,
+				Aliases:       v1.AliasMap{},
+				Mirrors: uses.Mirrors{
+					{Prefix: "oci:ghcr.io/", Replace: "oci:zot.example.com/ghcr.io/"},
+					{Prefix: "pkg:github/", BaseURL: "https://artifactory.example.com/api/github"},
+				},
+			},
+		},
+		{
+			name: "mirror without prefix",
+			reader: strings.NewReader(`schema-version: v0
+mirrors:
+  - replace: oci:zot.example.com/`),
+			expectErr: "mirrors.0.prefix: String length must be greater than or equal to 1",
+		},
+		{
+			name: "github token from gh",
+			reader: strings.NewReader(`schema-version: v0
+github:
+  token-from-gh: true`),
+			expected: &Config{
+				SchemaVersion: SchemaVersion,
+				FetchPolicy:   uses.DefaultFetchPolicy,
+				Aliases:       v1.AliasMap{},
+				GitHub:        &GitHub{TokenFromGH: true},
+			},
+		},
+		{
+			name: "secrets",
+			reader: strings.NewReader(`schema-version: v0
+secrets:
+  token: env:GITHUB_TOKEN
+  key: file:/run/secrets/key`),
+			expected: &Config{
+				SchemaVersion: SchemaVersion,
+				FetchPolicy:   uses.DefaultFetchPolicy,
+				Aliases:       v1.AliasMap{},
+				Secrets:       map[string]string{"token": "env:GITHUB_TOKEN", "key": "file:/run/secrets/key"},
+			},
+		},
+		{
+			name: "profiles",
+			reader: strings.NewReader(`schema-version: v0
+profiles:
+  staging:
+    env:
+      HOST: staging.example.com
+    with:
+      replicas: 2
+    fetch-policy: always`),
+			expected: &Config{
+				SchemaVersion: SchemaVersion,
+				FetchPolicy:   uses.DefaultFetchPolicy,
+				Aliases:       v1.AliasMap{},
+				Profiles: map[string]Profile{
+					"staging": {
+						Env:         map[string]string{"HOST": "staging.example.com"},
+						With:        map[string]any{"replicas": uint64(2)},
+						FetchPolicy: uses.FetchPolicyAlways,
+					},
+				},
+			},
+		},
+		{
+			name: "negative history max runs",
+			reader: strings.NewReader(`schema-version: v0
+history:
+  max-runs: -1`),
+			expectErr: "history.max-runs: Must be greater than or equal to 0",
+		},
+		{
+			name: "invalid profile fetch policy",
+			reader: strings.NewReader(`schema-version: v0
+profiles:
+  staging:
+    fetch-policy: sometimes`),
+			expectErr: "profiles.staging.fetch-policy must be one of the following",
+		},
+		{
+			name: "negative retry attempts",
+			reader: strings.NewReader(`schema-version: v0
+retry:
+  attempts: -1`),
+			expectErr: "retry.attempts: Must be greater than or equal to 0",
+		},
 		{
 			name:   "empty config uses defaults",
 			reader: strings.NewReader(`schema-version: v0`),
@@ -320,6 +500,249 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestHeaders(t *testing.T) {
+	t.Setenv("MARU2_TEST_TOKEN", "secret")
+
+	tests := []struct {
+		name        string
+		config      *Config
+		expected    uses.HostHeaders
+		expectedErr string
+	}{
+		{
+			name:     "no headers",
+			config:   defaultConfig(),
+			expected: uses.HostHeaders{},
+		},
+		{
+			name: "hosts and aliases",
+			config: &Config{
+				Aliases: v1.AliasMap{
+					"gh":    {Type: packageurl.TypeGithub, Headers: map[string]string{"X-Alias": "gh"}},
+					"gl":    {Type: packageurl.TypeGitlab, Headers: map[string]string{"X-Alias": "gl"}},
+					"ghe":   {Type: packageurl.TypeGithub, BaseURL: "https://github.example.com/api/v3", Headers: map[string]string{"X-Alias": "ghe", "Authorization": "overridden"}},
+					"plain": {Type: packageurl.TypeGithub},
+				},
+				Hosts: map[string]Host{
+					"github.example.com": {Headers: map[string]string{"Authorization": "Bearer ${MARU2_TEST_TOKEN}"}},
+					"empty.example.com":  {},
+				},
+			},
+			expected: uses.HostHeaders{
+				"api.github.com":     http.Header{"X-Alias": []string{"gh"}},
+				"gitlab.com":         http.Header{"X-Alias": []string{"gl"}},
+				"github.example.com": http.Header{"X-Alias": []string{"ghe"}, "Authorization": []string{"Bearer secret"}},
+			},
+		},
+		{
+			name: "invalid base url",
+			config: &Config{
+				Aliases: v1.AliasMap{
+					"bad": {Type: "unknown", Headers: map[string]string{"X-Alias": "bad"}},
+				},
+			},
+			expectedErr: `.aliases.bad.base-url "" must be a valid URL to set headers`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers, err := tt.config.Headers()
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, headers)
+		})
+	}
+}
+
+func TestTransportOptions(t *testing.T) {
+	dir := t.TempDir()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "maru2"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	tests := []struct {
+		name        string
+		config      *Config
+		expected    int
+		expectedErr string
+	}{
+		{
+			name:     "default retry",
+			config:   defaultConfig(),
+			expected: 1,
+		},
+		{
+			name:   "retry disabled",
+			config: &Config{Retry: &Retry{Attempts: 0}},
+		},
+		{
+			name:     "custom retry",
+			config:   &Config{Retry: &Retry{Attempts: 5, Backoff: "100ms", StatusCodes: []int{500}}},
+			expected: 1,
+		},
+		{
+			name:        "invalid retry backoff",
+			config:      &Config{Retry: &Retry{Attempts: 1, Backoff: "soon"}},
+			expectedErr: `.retry.backoff "soon" is not a valid time duration`,
+		},
+		{
+			name:        "invalid retry max backoff",
+			config:      &Config{Retry: &Retry{Attempts: 1, MaxBackoff: "later"}},
+			expectedErr: `.retry.max-backoff "later" is not a valid time duration`,
+		},
+		{
+			name:     "fetch timeout",
+			config:   &Config{Timeouts: &Timeouts{Fetch: "30s"}},
+			expected: 2,
+		},
+		{
+			name:        "invalid fetch timeout",
+			config:      &Config{Timeouts: &Timeouts{Fetch: "-1s"}},
+			expectedErr: `.timeouts.fetch "-1s" is not a valid time duration`,
+		},
+		{
+			name: "mirrors",
+			config: &Config{
+				Mirrors: uses.Mirrors{{Prefix: "oci:ghcr.io/", Replace: "oci:zot.example.com/ghcr.io/"}},
+				Retry:   &Retry{},
+			},
+			expected: 1,
+		},
+		{
+			name:        "invalid mirror",
+			config:      &Config{Mirrors: uses.Mirrors{{Prefix: "oci:ghcr.io/", Replace: "file:mirror/"}}},
+			expectedErr: `.mirrors: mirror 0: "file:mirror/" must start with one of the remote schemes (http, https, pkg, oci)`,
+		},
+		{
+			name: "proxy, ca and client certificate",
+			config: &Config{
+				Proxy: "http://proxy.example.com:3128",
+				TLS:   &TLS{CAFile: certFile, CertFile: certFile, KeyFile: keyFile},
+				Retry: &Retry{},
+			},
+			expected: 3,
+		},
+		{
+			name:        "invalid proxy",
+			config:      &Config{Proxy: "proxy.example.com"},
+			expectedErr: `.proxy "proxy.example.com" must be a valid URL`,
+		},
+		{
+			name:        "missing ca file",
+			config:      &Config{TLS: &TLS{CAFile: filepath.Join(dir, "missing.pem")}},
+			expectedErr: ".tls.ca-file: open " + filepath.Join(dir, "missing.pem") + ": no such file or directory",
+		},
+		{
+			name:        "cert without key",
+			config:      &Config{TLS: &TLS{CertFile: certFile}},
+			expectedErr: ".tls.cert-file and .tls.key-file must be set together",
+		},
+		{
+			name:        "mismatched key",
+			config:      &Config{TLS: &TLS{CertFile: certFile, KeyFile: certFile}},
+			expectedErr: ".tls: tls: found a certificate rather than a key in the PEM for the private key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := tt.config.TransportOptions()
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, opts, tt.expected)
+
+			_, err = uses.NewFetcherService(opts...)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestGCOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      *Config
+		expected    int
+		expectedErr string
+	}{
+		{
+			name:   "no cache policy",
+			config: defaultConfig(),
+		},
+		{
+			name:     "max age and max size",
+			config:   &Config{Cache: &Cache{MaxAge: "720h", MaxSize: "100MB"}},
+			expected: 2,
+		},
+		{
+			name:        "invalid max age",
+			config:      &Config{Cache: &Cache{MaxAge: "a month"}},
+			expectedErr: `.cache.max-age "a month" is not a valid time duration`,
+		},
+		{
+			name:        "invalid max size",
+			config:      &Config{Cache: &Cache{MaxSize: "lots"}},
+			expectedErr: `.cache.max-size "lots" is not a valid size`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := tt.config.GCOptions()
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, opts, tt.expected)
+		})
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"512":    512,
+		"512B":   512,
+		"2KB":    2000,
+		"100MB":  100_000_000,
+		"1GB":    1_000_000_000,
+		"2KiB":   2048,
+		"100MiB": 100 << 20,
+		"1GiB":   1 << 30,
+	}
+	for input, expected := range tests {
+		size, err := ParseSize(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, size, input)
+	}
+
+	for _, input := range []string{"", "MB", "1.5GB", "1TB", "-"} {
+		_, err := ParseSize(input)
+		require.Error(t, err, input)
+	}
+}
+
 func TestValidateSchemaOnce(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -356,3 +779,119 @@ func TestValidateSchemaOnce(t *testing.T) {
 		})
 	}
 }
+
+func TestTimeouts(t *testing.T) {
+	cfg := defaultConfig()
+	run, err := cfg.RunTimeout()
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, run)
+	completion, err := cfg.CompletionTimeout()
+	require.NoError(t, err)
+	assert.Equal(t, 500*time.Millisecond, completion)
+
+	cfg = &Config{Timeouts: &Timeouts{Run: "0", Completion: "2s"}}
+	run, err = cfg.RunTimeout()
+	require.NoError(t, err)
+	assert.Zero(t, run)
+	completion, err = cfg.CompletionTimeout()
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, completion)
+
+	// only set fields override the defaults
+	cfg = &Config{Timeouts: &Timeouts{Fetch: "1m"}}
+	run, err = cfg.RunTimeout()
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, run)
+
+	cfg = &Config{Timeouts: &Timeouts{Run: "soon"}}
+	_, err = cfg.RunTimeout()
+	require.EqualError(t, err, `.timeouts.run "soon" is not a valid time duration`)
+
+	cfg = &Config{Timeouts: &Timeouts{Completion: "0"}}
+	_, err = cfg.CompletionTimeout()
+	require.EqualError(t, err, ".timeouts.completion must be greater than 0")
+}
+
+func TestHistoryMaxRuns(t *testing.T) {
+	assert.Equal(t, DefaultHistory.MaxRuns, defaultConfig().HistoryMaxRuns())
+	assert.Equal(t, 0, (&Config{History: &History{}}).HistoryMaxRuns())
+	assert.Equal(t, 10, (&Config{History: &History{MaxRuns: 10}}).HistoryMaxRuns())
+}
+
+func TestTokenFromGH(t *testing.T) {
+	assert.False(t, defaultConfig().TokenFromGH())
+	assert.False(t, (&Config{GitHub: &GitHub{}}).TokenFromGH())
+	assert.True(t, (&Config{GitHub: &GitHub{TokenFromGH: true}}).TokenFromGH())
+}
+
+func TestProfile(t *testing.T) {
+	cfg := &Config{Profiles: map[string]Profile{
+		"staging":    {Env: map[string]string{"HOST": "staging.example.com"}},
+		"production": {FetchPolicy: uses.FetchPolicyNever},
+	}}
+
+	p, err := cfg.Profile("staging")
+	require.NoError(t, err)
+	assert.Equal(t, "staging.example.com", p.Env["HOST"])
+
+	_, err = cfg.Profile("stagin")
+	require.EqualError(t, err, `profile "stagin" not found, did you mean "staging"?`)
+
+	_, err = defaultConfig().Profile("staging")
+	require.EqualError(t, err, `profile "staging" not found`)
+}
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("GITLAB_URL", "https://gitlab.example.com")
+	t.Setenv("TOKEN_VAR", "CI_GITLAB_TOKEN")
+	t.Setenv("CERTS", "/etc/certs")
+	t.Setenv("MIRROR", "registry.example.com")
+
+	cfg, err := LoadConfig(strings.NewReader(`schema-version: v0
+aliases:
+  gl:
+    type: gitlab
+    base-url: ${GITLAB_URL}
+    token-from-env: ${TOKEN_VAR}
+    headers:
+      X-Token: ${NOT_EXPANDED_YET}
+proxy: http://${MIRROR}:3128
+tls:
+  ca-file: ${CERTS}/ca.pem
+mirrors:
+  - prefix: oci:ghcr.io/
+    replace: oci:${MIRROR}/
+profiles:
+  ci:
+    env:
+      KUBECONFIG: ${NOT_EXPANDED_YET}
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, v1.Alias{
+		Type:         packageurl.TypeGitlab,
+		BaseURL:      "https://gitlab.example.com",
+		TokenFromEnv: "CI_GITLAB_TOKEN",
+		Headers:      map[string]string{"X-Token": "${NOT_EXPANDED_YET}"},
+	}, cfg.Aliases["gl"])
+	assert.Equal(t, "http://registry.example.com:3128", cfg.Proxy)
+	assert.Equal(t, "/etc/certs/ca.pem", cfg.TLS.CAFile)
+	assert.Equal(t, "oci:registry.example.com/", cfg.Mirrors[0].Replace)
+	assert.Equal(t, "${NOT_EXPANDED_YET}", cfg.Profiles["ci"].Env["KUBECONFIG"])
+
+	_, err = LoadConfig(strings.NewReader(`schema-version: v0
+aliases:
+  gl:
+    type: gitlab
+    base-url: https://${GITLAB_HOST}/${GITLAB_GROUP}`))
+	require.EqualError(t, err, ".aliases.gl.base-url: environment variable GITLAB_HOST, GITLAB_GROUP is not set")
+
+	_, err = LoadConfig(strings.NewReader(`schema-version: v0
+tls:
+  key-file: ${KEYS}/client.key`))
+	require.EqualError(t, err, ".tls.key-file: environment variable KEYS is not set")
+
+	value, err := ExpandEnv(".proxy", "no variables")
+	require.NoError(t, err)
+	assert.Equal(t, "no variables", value)
+}
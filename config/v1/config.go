@@ -0,0 +1,339 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+// Package v1 provides the schema for v1 of the system config file for maru2
+//
+// v1 is a superset of v0, v0 config files are migrated to v1 when loaded
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-yaml"
+	"github.com/invopop/jsonschema"
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/defenseunicorns/maru2/config"
+	v0 "github.com/defenseunicorns/maru2/config/v0"
+	"github.com/defenseunicorns/maru2/schema"
+	schemav1 "github.com/defenseunicorns/maru2/schema/v1"
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+// SchemaVersion is the current schema version for configs
+const SchemaVersion = "v1"
+
+// Config is the system configuration file for maru2
+type Config struct {
+	v0.Config `json:",inline"`
+	// Environment variables set for every run when not already set, values are expanded using environment variables
+	//
+	// Overridden by the env of the selected profile
+	Env map[string]string `json:"env,omitempty"`
+	// Directory of the workflow store, overridden by --store
+	Store string `json:"store,omitempty"`
+	// Command run with sh -c to obtain tokens for pkg:github, pkg:gitlab and oci fetches, printing the token to stdout
+	//
+	// MARU2_CREDENTIAL_TYPE (github, gitlab or oci) and MARU2_CREDENTIAL_HOST are set for the command
+	CredentialHelper string `json:"credential-helper,omitempty"`
+	// Use the basic auth credentials in ~/.netrc (or $NETRC) when fetching https workflows
+	Netrc bool `json:"netrc,omitempty"`
+	// Default inputs for tasks called from the CLI, overridden by the selected profile's with, --with, --with-stdin and --with-file
+	TaskDefaults []TaskDefaults `json:"task-defaults,omitempty"`
+	// Maximum number of workflows fetched at once when fetching a workflow's dependencies (default 4)
+	FetchConcurrency int `json:"fetch-concurrency,omitempty" jsonschema:"minimum=0"`
+}
+
+// TaskDefaults are default inputs for a task called from the CLI
+type TaskDefaults struct {
+	// Prefix of the called workflow's resolved URL (e.g. pkg:github/myorg/ or file:tasks.yaml), every workflow matches when unset
+	From string `json:"from,omitempty"`
+	// Name of the called task
+	Task string `json:"task" jsonschema:"minLength=1"`
+	// Inputs passed to the task
+	With map[string]any `json:"with"`
+}
+
+// ProjectKeys are the top level keys a project config is allowed to set
+//
+// Everything else (hosts, secrets, TLS, env, etc.) can only be set by the global config,
+// so that cloning a repository can never change where credentials are sent or the environment
+// (proxies, certificates, PATH) Maru2 and its tasks run with
+var ProjectKeys = []string{"schema-version", "aliases", "fetch-policy", "store", "task-defaults"}
+
+// Migrate converts a v0 config to v1
+func Migrate(old *v0.Config) *Config {
+	cfg := &Config{Config: *old}
+	cfg.SchemaVersion = SchemaVersion
+	return cfg
+}
+
+// the default config, matches flag defaults in cmd/root.go
+func defaultConfig() *Config {
+	return &Config{
+		Config: v0.Config{
+			SchemaVersion: SchemaVersion,
+			Aliases:       schemav1.AliasMap{},
+			FetchPolicy:   uses.DefaultFetchPolicy,
+		},
+	}
+}
+
+// JSONSchemaExtend extends the JSON schema for a config
+func (Config) JSONSchemaExtend(schema *jsonschema.Schema) {
+	if schemaVersion, ok := schema.Properties.Get("schema-version"); ok && schemaVersion != nil {
+		schemaVersion.Description = "Config schema version"
+		schemaVersion.Enum = []any{SchemaVersion}
+		schemaVersion.AdditionalProperties = jsonschema.FalseSchema
+	}
+}
+
+// LoadConfig loads the configuration from r, migrating v0 configs to v1
+func LoadConfig(r io.Reader) (*Config, error) {
+	cfg := defaultConfig()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var versioned schema.Versioned
+	if err := yaml.Unmarshal(data, &versioned); err != nil {
+		return nil, err
+	}
+
+	switch version := versioned.SchemaVersion; version {
+	case SchemaVersion:
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+		if err := cfg.ExpandEnv(); err != nil {
+			return nil, err
+		}
+		if err := Validate(cfg); err != nil {
+			return nil, err
+		}
+		return cfg, nil
+	case v0.SchemaVersion:
+		old, err := v0.LoadConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return Migrate(old), nil
+	default:
+		return nil, fmt.Errorf("unsupported config schema version: expected oneof [%q, %q], got %q", SchemaVersion, v0.SchemaVersion, version)
+	}
+}
+
+// LoadDefaultConfig loads the config from config.DefaultDirectory
+// if this file does not exist, the default config is returned
+func LoadDefaultConfig() (*Config, error) {
+	configDir, err := config.DefaultDirectory()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := defaultConfig()
+
+	f, err := os.Open(filepath.Join(configDir, config.DefaultFileName))
+	if err != nil {
+		if os.IsNotExist(err) { // default config is allowed to not exist
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+
+	loaded, err := LoadConfig(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config file: %w", err)
+	}
+
+	return loaded, nil
+}
+
+// LoadProjectConfig loads the project config at path and merges it over global
+//
+// A project config must be v1 and can only set ProjectKeys, a relative store is resolved against the project root
+// (the parent of the directory holding the config)
+func LoadProjectConfig(global *Config, path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project config file: %w", err)
+	}
+
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse project config file: %w", err)
+	}
+
+	if version := raw["schema-version"]; version != SchemaVersion {
+		return nil, fmt.Errorf("unsupported project config schema version: expected %q, got %q", SchemaVersion, version)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(raw)) {
+		if !slices.Contains(ProjectKeys, key) {
+			return nil, fmt.Errorf(".%s can only be set in the global config, project configs can set %s", key, strings.Join(ProjectKeys[1:], ", "))
+		}
+	}
+
+	var project Config
+	if err := yaml.Unmarshal(data, &project); err != nil {
+		return nil, fmt.Errorf("failed to parse project config file: %w", err)
+	}
+	if err := project.ExpandEnv(); err != nil {
+		return nil, err
+	}
+
+	merged := Merge(global, &project, filepath.Dir(filepath.Dir(path)))
+	if err := Validate(merged); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// Merge returns a copy of global with project merged over it
+//
+// Aliases are merged key by key, fetch policy and store are replaced when set by the project,
+// and task defaults are appended so the project's take priority. A relative project store is resolved against root
+func Merge(global, project *Config, root string) *Config {
+	merged := *global
+
+	merged.Aliases = maps.Clone(global.Aliases)
+	if merged.Aliases == nil {
+		merged.Aliases = schemav1.AliasMap{}
+	}
+	maps.Copy(merged.Aliases, project.Aliases)
+
+	if project.FetchPolicy != "" {
+		merged.FetchPolicy = project.FetchPolicy
+	}
+
+	if project.Store != "" {
+		merged.Store = project.Store
+		if !filepath.IsAbs(project.Store) {
+			merged.Store = filepath.Join(root, project.Store)
+		}
+	}
+
+	if len(project.TaskDefaults) > 0 {
+		merged.TaskDefaults = append(slices.Clone(global.TaskDefaults), project.TaskDefaults...)
+	}
+
+	return &merged
+}
+
+// TaskWith returns the default inputs for task in the workflow at origin, later task defaults take priority
+func (c *Config) TaskWith(origin *url.URL, task string) map[string]any {
+	var with map[string]any
+	for _, d := range c.TaskDefaults {
+		if d.Task != task || (origin != nil && !strings.HasPrefix(origin.String(), d.From)) {
+			continue
+		}
+		if with == nil {
+			with = make(map[string]any, len(d.With))
+		}
+		maps.Copy(with, d.With)
+	}
+	return with
+}
+
+// ExpandEnv expands ${VAR} and $VAR in the values that locate resources (see v0.Config.ExpandEnv) and the store,
+// failing if a variable is not set
+func (c *Config) ExpandEnv() error {
+	if err := c.Config.ExpandEnv(); err != nil {
+		return err
+	}
+	var err error
+	c.Store, err = v0.ExpandEnv(".store", c.Store)
+	return err
+}
+
+// TransportOptions returns the fetcher service options for the configured proxy, TLS, retry, mirror, fetch timeout and fetch concurrency settings
+func (c *Config) TransportOptions() ([]uses.FetcherServiceOption, error) {
+	opts, err := c.Config.TransportOptions()
+	if err != nil {
+		return nil, err
+	}
+	if c.FetchConcurrency > 0 {
+		opts = append(opts, uses.WithConcurrency(c.FetchConcurrency))
+	}
+	return opts, nil
+}
+
+// CredentialOptions returns the fetcher service options for the configured credential helper and .netrc
+func (c *Config) CredentialOptions() ([]uses.FetcherServiceOption, error) {
+	var opts []uses.FetcherServiceOption
+
+	if c.CredentialHelper != "" {
+		opts = append(opts, uses.WithCredentialHelper(uses.CredentialHelper(c.CredentialHelper)))
+	}
+
+	if c.Netrc {
+		path, err := uses.NetrcPath()
+		if err != nil {
+			return nil, fmt.Errorf(".netrc: %w", err)
+		}
+		n, err := uses.LoadNetrc(path)
+		if err != nil {
+			return nil, fmt.Errorf(".netrc: %w", err)
+		}
+		opts = append(opts, uses.WithNetrc(n))
+	}
+
+	return opts, nil
+}
+
+// Since every validation operation leverages the same config, only calculate it once to save some compute cycles
+//
+// This also prevents any schema changes from occurring at runtime
+var schemaOnce = sync.OnceValues(func() (string, error) {
+	s := Schema()
+	b, err := json.Marshal(s)
+	return string(b), err
+})
+
+// Validate checks if a config adheres to the JSON schema
+func Validate(config *Config) error {
+	schema, err := schemaOnce()
+	if err != nil {
+		return err
+	}
+
+	schemaLoader := gojsonschema.NewStringLoader(schema)
+
+	result, err := gojsonschema.Validate(schemaLoader, gojsonschema.NewGoLoader(config))
+	if err != nil {
+		return err
+	}
+
+	if result.Valid() {
+		return nil
+	}
+
+	var resErr error
+	for _, err := range result.Errors() {
+		resErr = errors.Join(resErr, errors.New(err.String()))
+	}
+
+	return resErr
+}
+
+// Schema generates the JSON schema for v1 configuration validation
+//
+// Returns a schema for IDE integration and automated validation
+func Schema() *jsonschema.Schema {
+	reflector := jsonschema.Reflector{DoNotReference: true}
+	return reflector.Reflect(&Config{})
+}
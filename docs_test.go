@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"io"
+	"net/url"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+func TestDocs(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "tasks.yaml", []byte(`schema-version: v1
+aliases:
+  tools:
+    type: github
+    base-url: https://github.example.com
+tasks:
+  default:
+    steps:
+      - uses: build
+      - uses: builtin:echo
+        with:
+          text: done
+  build:
+    description: Build the app
+    inputs:
+      version:
+        description: Version to build
+      arch:
+        description: Architecture
+        default: amd64
+    steps:
+      - uses: pkg:tools/my-org/tools@v1?task=fetch#tasks.yaml
+      - uses: file:lib/tasks.yaml?task=lint
+  release:
+    steps:
+      - uses: pkg:tools/my-org/tools@v1?task=fetch#tasks.yaml
+`), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "lib/tasks.yaml", []byte(`schema-version: v1
+tasks:
+  lint:
+    inputs:
+      fix:
+        description: Fix issues
+        required: false
+    steps:
+      - run: golangci-lint run
+`), 0o644))
+
+	svc, err := uses.NewFetcherService(uses.WithFS(fs))
+	require.NoError(t, err)
+
+	ctx := log.WithContext(t.Context(), log.New(io.Discard))
+
+	docs, err := Docs(ctx, svc,
+		&url.URL{Scheme: "file", Opaque: "tasks.yaml"},
+		&url.URL{Scheme: "file", Opaque: "lib/tasks.yaml"},
+	)
+	require.NoError(t, err)
+
+	header := "> for schema version v1\n>\n> <" + v1.SchemaURL + ">\n\n"
+	assert.Equal(t, "# `tasks.yaml`\n\n"+header+"## Tasks\n\n"+
+		"### `default`\n\n**Uses:**\n\n- `build`\n- `builtin:echo`\n\n"+
+		"**Example:**\n\n```sh\nmaru2\n```\n\n"+
+		"### `build`\n\nBuild the app\n\n"+
+		"**Input Parameters:**\n\n"+
+		"| Name | Description | Required | Default | Validation | Notes |\n"+
+		"|------|-------------|----------|---------|------------|-------|\n"+
+		"| `arch` | Architecture | Yes | `amd64` | - | - |\n"+
+		"| `version` | Version to build | Yes | - | - | - |\n\n"+
+		"**Uses:**\n\n- `pkg:tools/my-org/tools@v1?task=fetch#tasks.yaml`\n- `file:lib/tasks.yaml?task=lint`\n\n"+
+		"**Example:**\n\n```sh\nmaru2 build -w version=...\n```\n\n"+
+		"### `release`\n\n**Uses:**\n\n- `pkg:tools/my-org/tools@v1?task=fetch#tasks.yaml`\n\n"+
+		"**Example:**\n\n```sh\nmaru2 release\n```\n\n"+
+		"## Remote Dependencies\n\n"+
+		"| Reference | Resolved | Used By |\n"+
+		"|-----------|----------|---------|\n"+
+		"| `pkg:tools/my-org/tools@v1?task=fetch#tasks.yaml` | `pkg:github/my-org/tools@v1?base-url=https%3A%2F%2Fgithub.example.com&task=fetch#tasks.yaml` | `build`, `release` |\n\n"+
+		"# `lib/tasks.yaml`\n\n"+header+"## Tasks\n\n"+
+		"### `lint`\n\n**Input Parameters:**\n\n"+
+		"| Name | Description | Required | Default | Validation | Notes |\n"+
+		"|------|-------------|----------|---------|------------|-------|\n"+
+		"| `fix` | Fix issues | No | - | - | - |\n\n"+
+		"**Example:**\n\n```sh\nmaru2 -f \"lib/tasks.yaml\" lint\n```\n", docs)
+
+	_, err = Docs(ctx, svc, &url.URL{Scheme: "file", Opaque: "missing.yaml"})
+	require.ErrorContains(t, err, `failed to fetch "file:missing.yaml"`)
+}
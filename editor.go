@@ -0,0 +1,555 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/token"
+	"github.com/invopop/jsonschema"
+
+	"github.com/defenseunicorns/maru2/builtins"
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+)
+
+// EditorRequest is a single request to the editor server, see ServeEditor
+type EditorRequest struct {
+	// Opaque identifier echoed back in the response
+	ID any `json:"id,omitempty"`
+	// One of "diagnostics", "complete" or "hover"
+	Method string `json:"method"`
+	// Full text of the workflow document
+	Text string `json:"text"`
+	// Cursor position, used by "complete" and "hover"
+	Position EditorPosition `json:"position"`
+}
+
+// EditorPosition is a zero-based position within a document
+//
+// Characters are counted in UTF-16 code units, matching the Language Server Protocol
+type EditorPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// EditorRange is a range within a document, the end position is exclusive
+type EditorRange struct {
+	Start EditorPosition `json:"start"`
+	End   EditorPosition `json:"end"`
+}
+
+// EditorDiagnostic is a single problem found in a document
+type EditorDiagnostic struct {
+	Range   EditorRange `json:"range"`
+	Message string      `json:"message"`
+}
+
+// EditorCompletion is a single completion candidate
+type EditorCompletion struct {
+	// Text to insert
+	Label string `json:"label"`
+	// Kind of completion, one of "task", "builtin", "alias" or "input"
+	Kind string `json:"kind"`
+	// Short description of the completion
+	Detail string `json:"detail,omitempty"`
+}
+
+// EditorResponse is the response to a single EditorRequest
+type EditorResponse struct {
+	// Version of the API response schema
+	APIVersion string `json:"api-version"`
+	// Identifier from the request
+	ID any `json:"id,omitempty"`
+	// Method from the request
+	Method string `json:"method"`
+	// Error message if the request failed
+	Error string `json:"error,omitempty"`
+	// Problems found in the document, absent if there are none
+	Diagnostics []EditorDiagnostic `json:"diagnostics,omitempty"`
+	// Completion candidates at the cursor
+	Completions []EditorCompletion `json:"completions,omitempty"`
+	// Markdown documentation for the symbol at the cursor
+	Hover string `json:"hover,omitempty"`
+}
+
+// ServeEditor reads newline delimited EditorRequests from r and writes one EditorResponse per line to w
+//
+// It returns when r is exhausted or the context is cancelled
+func ServeEditor(ctx context.Context, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	enc := json.NewEncoder(w)
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+
+		var req EditorRequest
+		if err := json.Unmarshal(line, &req); err != nil {
+			if err := enc.Encode(EditorResponse{APIVersion: APIVersion, Error: fmt.Sprintf("invalid request: %v", err)}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		res := EditorResponse{APIVersion: APIVersion, ID: req.ID, Method: req.Method}
+		switch req.Method {
+		case "diagnostics":
+			res.Diagnostics = EditorDiagnostics(req.Text)
+		case "complete":
+			res.Completions = EditorComplete(req.Text, req.Position)
+		case "hover":
+			res.Hover = EditorHover(req.Text, req.Position)
+		default:
+			res.Error = fmt.Sprintf("unsupported method %q", req.Method)
+		}
+
+		if err := enc.Encode(res); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// EditorDiagnostics validates a workflow document, returning every problem found and its location
+//
+// Problems that cannot be located are reported at the start of the document
+func EditorDiagnostics(text string) []EditorDiagnostic {
+	_, err := v1.ReadAndValidate(strings.NewReader(text))
+	if err == nil {
+		return nil
+	}
+
+	lines := strings.Split(text, "\n")
+
+	var yamlErr yaml.Error
+	if errors.As(err, &yamlErr) {
+		return []EditorDiagnostic{{
+			Range:   tokenRange(lines, yamlErr.GetToken()),
+			Message: yamlErr.GetMessage(),
+		}}
+	}
+
+	file, _ := parser.ParseBytes([]byte(text), 0)
+
+	var diagnostics []EditorDiagnostic
+	for _, e := range flattenErrors(err) {
+		msg := e.Error()
+		diagnostics = append(diagnostics, EditorDiagnostic{
+			Range:   locate(file, lines, errorPath(msg)),
+			Message: msg,
+		})
+	}
+	return diagnostics
+}
+
+// flattenErrors returns the individual errors within (possibly nested) joined errors
+func flattenErrors(err error) []error {
+	var joined interface{ Unwrap() []error }
+	if !errors.As(err, &joined) {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, e := range joined.Unwrap() {
+		errs = append(errs, flattenErrors(e)...)
+	}
+	return errs
+}
+
+var (
+	// matches validation errors such as `.tasks.default[0].uses "missing" not found`
+	validatePathPattern = regexp.MustCompile(`^\.tasks\.([^.\[\s:]+)((?:\[\d+\])?(?:\.[^.\[\s:]+)*)`)
+	// matches JSON schema errors such as `tasks.default.steps.0.shell: ...`
+	schemaPathPattern = regexp.MustCompile(`^([^\s:()]+): `)
+	// matches validation errors such as `task name "foo bar" does not satisfy ...`
+	taskNamePattern = regexp.MustCompile(`^task name "([^"]+)"`)
+)
+
+// errorPath converts the location within a validation error to a list of path segments
+//
+// Integer segments are sequence indexes
+func errorPath(msg string) []string {
+	if m := validatePathPattern.FindStringSubmatch(msg); m != nil {
+		path := []string{"tasks", m[1]}
+		rest := m[2]
+		if strings.HasPrefix(rest, "[") {
+			end := strings.Index(rest, "]")
+			path = append(path, "steps", rest[1:end])
+			rest = rest[end+1:]
+		}
+		for seg := range strings.SplitSeq(strings.TrimPrefix(rest, "."), ".") {
+			if seg != "" {
+				path = append(path, seg)
+			}
+		}
+		return path
+	}
+
+	if m := taskNamePattern.FindStringSubmatch(msg); m != nil {
+		return []string{"tasks", m[1]}
+	}
+
+	if m := schemaPathPattern.FindStringSubmatch(msg); m != nil {
+		return strings.Split(m[1], ".")
+	}
+
+	return nil
+}
+
+// locate finds the range of the deepest node in the document matching the path
+func locate(file *ast.File, lines []string, path []string) EditorRange {
+	if file == nil || len(file.Docs) == 0 || file.Docs[0].Body == nil {
+		return EditorRange{}
+	}
+
+	var found *token.Token
+	node := file.Docs[0].Body
+	for i, seg := range path {
+		next, key := child(node, seg)
+		if next == nil {
+			break
+		}
+		found = key
+		node = next
+
+		// highlight the value rather than the key when the error is for a scalar value
+		if _, ok := node.(ast.ScalarNode); ok && i == len(path)-1 && node.GetToken() != nil {
+			found = node.GetToken()
+		}
+	}
+
+	return tokenRange(lines, found)
+}
+
+// child returns the value for a mapping key or sequence index, along with the token to highlight
+func child(node ast.Node, seg string) (ast.Node, *token.Token) {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		for _, kv := range n.Values {
+			if kv.Key.GetToken().Value == seg {
+				return kv.Value, kv.Key.GetToken()
+			}
+		}
+	case *ast.MappingValueNode:
+		if n.Key.GetToken().Value == seg {
+			return n.Value, n.Key.GetToken()
+		}
+	case *ast.SequenceNode:
+		idx, err := strconv.Atoi(seg)
+		if err != nil || idx < 0 || idx >= len(n.Values) {
+			return nil, nil
+		}
+		value := n.Values[idx]
+		tk := value.GetToken()
+		if mv, ok := value.(*ast.MappingNode); ok && len(mv.Values) > 0 {
+			tk = mv.Values[0].Key.GetToken()
+		}
+		if mv, ok := value.(*ast.MappingValueNode); ok {
+			tk = mv.Key.GetToken()
+		}
+		return value, tk
+	}
+	return nil, nil
+}
+
+// tokenRange returns the range of a token, or the start of the document if the token is nil
+func tokenRange(lines []string, tk *token.Token) EditorRange {
+	if tk == nil || tk.Position == nil || tk.Position.Line < 1 || tk.Position.Line > len(lines) {
+		return EditorRange{}
+	}
+
+	line := tk.Position.Line - 1
+	runes := []rune(lines[line])
+	start := min(max(tk.Position.Column-1, 0), len(runes))
+	width := len([]rune(tk.Value))
+	if tk.Type == token.DoubleQuoteType || tk.Type == token.SingleQuoteType {
+		width += 2
+	}
+	end := min(start+width, len(runes))
+
+	return EditorRange{
+		Start: EditorPosition{Line: line, Character: len(utf16.Encode(runes[:start]))},
+		End:   EditorPosition{Line: line, Character: len(utf16.Encode(runes[:end]))},
+	}
+}
+
+// cursor returns the text of the line at the position (which must be within the document), and the text before the cursor
+func cursor(lines []string, pos EditorPosition) (string, string) {
+	line := lines[pos.Line]
+	units := utf16.Encode([]rune(line))
+	character := min(max(pos.Character, 0), len(units))
+	return line, string(utf16.Decode(units[:character]))
+}
+
+var (
+	// matches the text before the cursor when editing a uses: value
+	usesValuePattern = regexp.MustCompile(`^\s*(?:-\s+)?uses:\s*['"]?([^\s'"]*)$`)
+	// matches the text before the cursor when editing a mapping key
+	keyPattern = regexp.MustCompile(`^(\s*)([\w-]*)$`)
+	// matches a uses: line
+	usesLinePattern = regexp.MustCompile(`^(\s*)(?:-\s+)?uses:\s*['"]?([^\s'"#]*)`)
+	// matches a mapping key line
+	keyLinePattern = regexp.MustCompile(`^(\s*)(?:-\s+)?([\w-]+):`)
+)
+
+// EditorComplete returns completion candidates at the cursor
+//
+// Task names, aliases and builtins are completed for uses:, and input names are completed within with:
+func EditorComplete(text string, pos EditorPosition) []EditorCompletion {
+	lines := strings.Split(text, "\n")
+	if pos.Line < 0 || pos.Line >= len(lines) {
+		return nil
+	}
+	_, before := cursor(lines, pos)
+
+	// a document that is being edited is often invalid, complete from whatever can be read
+	wf, _ := v1.Read(strings.NewReader(text))
+
+	if m := usesValuePattern.FindStringSubmatch(before); m != nil {
+		prefix := m[1]
+		var completions []EditorCompletion
+		for name, task := range wf.Tasks.OrderedSeq() {
+			completions = append(completions, EditorCompletion{Label: name, Kind: "task", Detail: task.Description})
+		}
+		for _, name := range builtins.Names() {
+			completions = append(completions, EditorCompletion{Label: "builtin:" + name, Kind: "builtin"})
+		}
+		for name, alias := range wf.Aliases.OrderedSeq() {
+			detail := alias.Path
+			if detail == "" {
+				detail = alias.Type
+			}
+			completions = append(completions, EditorCompletion{Label: name + ":", Kind: "alias", Detail: detail})
+		}
+		return filterCompletions(completions, prefix)
+	}
+
+	if m := keyPattern.FindStringSubmatch(before); m != nil {
+		uses, ok := withUses(lines, pos.Line, len(m[1]))
+		if !ok {
+			return nil
+		}
+		return filterCompletions(inputCompletions(wf, uses), m[2])
+	}
+
+	return nil
+}
+
+// filterCompletions returns the completions whose label starts with the prefix
+func filterCompletions(completions []EditorCompletion, prefix string) []EditorCompletion {
+	var filtered []EditorCompletion
+	for _, c := range completions {
+		if strings.HasPrefix(c.Label, prefix) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// withUses returns the uses: value of the step whose with: block contains the line, given the indent of the line
+func withUses(lines []string, line, indent int) (string, bool) {
+	// find the parent key of the line
+	withLine := -1
+	for i := line - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) == "" || keyColumn(lines[i]) >= indent {
+			continue
+		}
+		m := keyLinePattern.FindStringSubmatch(lines[i])
+		if m == nil || m[2] != "with" {
+			return "", false
+		}
+		withLine = i
+		break
+	}
+	if withLine == -1 {
+		return "", false
+	}
+
+	// the keys of the step are at the same column as with:, search up to the start of the step then down to its end
+	column := keyColumn(lines[withLine])
+	uses := func(i int) (string, bool) {
+		if m := usesLinePattern.FindStringSubmatch(lines[i]); m != nil {
+			return m[2], true
+		}
+		return "", false
+	}
+	isItem := func(i int) bool {
+		return strings.HasPrefix(strings.TrimSpace(lines[i]), "- ")
+	}
+
+	for i := withLine; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) == "" || keyColumn(lines[i]) > column {
+			continue
+		}
+		if keyColumn(lines[i]) < column {
+			break
+		}
+		if u, ok := uses(i); ok {
+			return u, true
+		}
+		if isItem(i) {
+			break
+		}
+	}
+	for i := withLine + 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" || keyColumn(lines[i]) > column {
+			continue
+		}
+		if keyColumn(lines[i]) < column || isItem(i) {
+			break
+		}
+		if u, ok := uses(i); ok {
+			return u, true
+		}
+	}
+	return "", false
+}
+
+// keyColumn returns the column of the first key on a line, after any sequence indicator
+func keyColumn(line string) int {
+	trimmed := strings.TrimLeft(line, " ")
+	column := len(line) - len(trimmed)
+	for strings.HasPrefix(trimmed, "- ") {
+		rest := strings.TrimLeft(trimmed[1:], " ")
+		column += len(trimmed) - len(rest)
+		trimmed = rest
+	}
+	return column
+}
+
+// inputCompletions returns the inputs accepted by a local task or builtin
+func inputCompletions(wf v1.Workflow, uses string) []EditorCompletion {
+	if name, ok := strings.CutPrefix(uses, "builtin:"); ok {
+		return builtinInputs(name)
+	}
+
+	task, ok := wf.Tasks.Find(uses)
+	if !ok {
+		return nil
+	}
+
+	var completions []EditorCompletion
+	for name, input := range task.Inputs.OrderedSeq() {
+		completions = append(completions, EditorCompletion{Label: name, Kind: "input", Detail: input.Description})
+	}
+	return completions
+}
+
+// builtinInputs returns the inputs accepted by a builtin
+func builtinInputs(name string) []EditorCompletion {
+	name, _, _ = strings.Cut(name, "@")
+	b := builtins.Get(name)
+	if b == nil {
+		return nil
+	}
+
+	reflector := jsonschema.Reflector{DoNotReference: true}
+	s := reflector.Reflect(b)
+	if s == nil || s.Properties == nil {
+		return nil
+	}
+
+	var completions []EditorCompletion
+	for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		completions = append(completions, EditorCompletion{Label: pair.Key, Kind: "input", Detail: pair.Value.Description})
+	}
+	return completions
+}
+
+// EditorHover returns markdown documentation for the task, builtin or input at the cursor
+func EditorHover(text string, pos EditorPosition) string {
+	lines := strings.Split(text, "\n")
+	if pos.Line < 0 || pos.Line >= len(lines) {
+		return ""
+	}
+	line, before := cursor(lines, pos)
+
+	wf, _ := v1.Read(strings.NewReader(text))
+
+	if m := usesLinePattern.FindStringSubmatch(line); m != nil && strings.Contains(before, "uses:") {
+		uses := m[2]
+		if name, ok := strings.CutPrefix(uses, "builtin:"); ok {
+			return builtinHover(name)
+		}
+		if _, ok := wf.Tasks.Find(uses); ok {
+			return wf.Explain(uses)
+		}
+		return ""
+	}
+
+	m := keyLinePattern.FindStringSubmatch(line)
+	if m == nil || len(before) > len(m[0]) {
+		return ""
+	}
+	key := m[2]
+
+	if uses, ok := withUses(lines, pos.Line, keyColumn(line)); ok {
+		for _, input := range inputCompletions(wf, uses) {
+			if input.Label == key {
+				return fmt.Sprintf("`%s`: %s", key, input.Detail)
+			}
+		}
+		return ""
+	}
+
+	if _, ok := wf.Tasks[key]; ok && parentKey(lines, pos.Line) == "tasks" {
+		return wf.Explain(key)
+	}
+
+	return ""
+}
+
+// parentKey returns the key of the mapping that contains the line
+func parentKey(lines []string, line int) string {
+	column := keyColumn(lines[line])
+	for i := line - 1; i >= 0; i-- {
+		m := keyLinePattern.FindStringSubmatch(lines[i])
+		if m != nil && keyColumn(lines[i]) < column {
+			return m[2]
+		}
+	}
+	return ""
+}
+
+// builtinHover returns markdown documentation for a builtin
+func builtinHover(name string) string {
+	inputs := builtinInputs(name)
+	if builtins.Get(strings.Split(name, "@")[0]) == nil {
+		return ""
+	}
+
+	var hover strings.Builder
+	fmt.Fprintf(&hover, "### `builtin:%s`\n", name)
+	if len(inputs) > 0 {
+		hover.WriteString("\n**With:**\n\n")
+		for _, input := range inputs {
+			if input.Detail != "" {
+				fmt.Fprintf(&hover, "- `%s`: %s\n", input.Label, input.Detail)
+			} else {
+				fmt.Fprintf(&hover, "- `%s`\n", input.Label)
+			}
+		}
+	}
+	return hover.String()
+}
@@ -0,0 +1,253 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const editorWorkflow = `schema-version: v1
+aliases:
+  common:
+    path: common.yaml
+tasks:
+  build:
+    description: Build the project
+    inputs:
+      target:
+        description: Target to build
+    steps:
+      - run: make
+  default:
+    steps:
+      - uses: build
+        with:
+          target: all
+      - with:
+          text: hello
+        uses: builtin:echo
+      - uses: 
+`
+
+func TestEditorDiagnostics(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []EditorDiagnostic
+	}{
+		{
+			name: "valid",
+			text: `schema-version: v1
+tasks:
+  default:
+    steps:
+      - run: echo hello
+`,
+		},
+		{
+			name: "invalid reference",
+			text: `schema-version: v1
+tasks:
+  default:
+    steps:
+      - run: echo hello
+      - uses: missing
+`,
+			expected: []EditorDiagnostic{{
+				Range:   EditorRange{Start: EditorPosition{Line: 5, Character: 14}, End: EditorPosition{Line: 5, Character: 21}},
+				Message: `.tasks.default[1].uses "missing" not found`,
+			}},
+		},
+		{
+			name: "multiple schema errors",
+			text: `schema-version: v1
+tasks:
+  default:
+    steps:
+      - run: echo hello
+        shell: fish
+      - run: echo hello
+        shell: zsh
+      - run: echo hello
+        shell: csh
+`,
+			expected: []EditorDiagnostic{
+				{
+					Range:   EditorRange{Start: EditorPosition{Line: 5, Character: 15}, End: EditorPosition{Line: 5, Character: 19}},
+					Message: `tasks.default.steps.0.shell: tasks.default.steps.0.shell must be one of the following: "sh", "bash", "pwsh", "powershell"`,
+				},
+				{
+					Range:   EditorRange{Start: EditorPosition{Line: 7, Character: 15}, End: EditorPosition{Line: 7, Character: 18}},
+					Message: `tasks.default.steps.1.shell: tasks.default.steps.1.shell must be one of the following: "sh", "bash", "pwsh", "powershell"`,
+				},
+				{
+					Range:   EditorRange{Start: EditorPosition{Line: 9, Character: 15}, End: EditorPosition{Line: 9, Character: 18}},
+					Message: `tasks.default.steps.2.shell: tasks.default.steps.2.shell must be one of the following: "sh", "bash", "pwsh", "powershell"`,
+				},
+			},
+		},
+		{
+			name: "invalid task name",
+			text: `schema-version: v1
+tasks:
+  "föö":
+    steps:
+      - run: echo hello
+`,
+			expected: []EditorDiagnostic{{
+				Range:   EditorRange{Start: EditorPosition{Line: 2, Character: 2}, End: EditorPosition{Line: 2, Character: 7}},
+				Message: `task name "föö" does not satisfy "^[_a-zA-Z][a-zA-Z0-9_-]*$"`,
+			}},
+		},
+		{
+			name: "invalid yaml",
+			text: "schema-version: v1\ntasks: [\n",
+			expected: []EditorDiagnostic{{
+				Range:   EditorRange{Start: EditorPosition{Line: 1, Character: 7}, End: EditorPosition{Line: 1, Character: 8}},
+				Message: "sequence end token ']' not found",
+			}},
+		},
+		{
+			name: "unlocated error",
+			text: "schema-version: v1\n",
+			expected: []EditorDiagnostic{{
+				Message: "no tasks available",
+			}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, EditorDiagnostics(tc.text))
+		})
+	}
+}
+
+func TestEditorComplete(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		position EditorPosition
+		expected []string
+	}{
+		{
+			name:     "uses",
+			text:     editorWorkflow,
+			position: EditorPosition{Line: 20, Character: 14},
+			expected: []string{"default", "build", "builtin:cache-restore", "builtin:cache-save", "builtin:confirm", "builtin:echo", "builtin:fetch", "builtin:fs", "builtin:http-request", "builtin:maru2", "builtin:notify", "builtin:prompt", "builtin:push-artifact", "builtin:query", "builtin:template", "builtin:wacky-structs", "builtin:wait-for", "common:"},
+		},
+		{
+			name:     "uses with prefix",
+			text:     editorWorkflow,
+			position: EditorPosition{Line: 14, Character: 16},
+			expected: []string{"build", "builtin:cache-restore", "builtin:cache-save", "builtin:confirm", "builtin:echo", "builtin:fetch", "builtin:fs", "builtin:http-request", "builtin:maru2", "builtin:notify", "builtin:prompt", "builtin:push-artifact", "builtin:query", "builtin:template", "builtin:wacky-structs", "builtin:wait-for"},
+		},
+		{
+			name:     "task inputs",
+			text:     editorWorkflow,
+			position: EditorPosition{Line: 16, Character: 10},
+			expected: []string{"target"},
+		},
+		{
+			name:     "builtin inputs with uses after with",
+			text:     editorWorkflow,
+			position: EditorPosition{Line: 18, Character: 10},
+			expected: []string{"text"},
+		},
+		{
+			name:     "builtin inputs with prefix",
+			text:     strings.Replace(editorWorkflow, "      - uses: build\n", "      - uses: builtin:fetch\n", 1),
+			position: EditorPosition{Line: 16, Character: 11},
+			expected: []string{"timeout"},
+		},
+		{
+			name:     "not within with",
+			text:     editorWorkflow,
+			position: EditorPosition{Line: 11, Character: 6},
+		},
+		{
+			name:     "out of range",
+			text:     editorWorkflow,
+			position: EditorPosition{Line: 100, Character: 0},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var labels []string
+			for _, c := range EditorComplete(tc.text, tc.position) {
+				labels = append(labels, c.Label)
+			}
+			assert.Equal(t, tc.expected, labels)
+		})
+	}
+}
+
+func TestEditorHover(t *testing.T) {
+	tests := []struct {
+		name     string
+		position EditorPosition
+		expected string
+	}{
+		{
+			name:     "task reference",
+			position: EditorPosition{Line: 14, Character: 16},
+			expected: "### `build`\n\nBuild the project\n\n",
+		},
+		{
+			name:     "task definition",
+			position: EditorPosition{Line: 5, Character: 3},
+			expected: "### `build`\n\nBuild the project\n\n",
+		},
+		{
+			name:     "builtin",
+			position: EditorPosition{Line: 19, Character: 20},
+			expected: "### `builtin:echo`\n\n**With:**\n\n- `text`: Text to echo\n",
+		},
+		{
+			name:     "input",
+			position: EditorPosition{Line: 16, Character: 11},
+			expected: "`target`: Target to build",
+		},
+		{
+			name:     "nothing",
+			position: EditorPosition{Line: 0, Character: 3},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hover := EditorHover(editorWorkflow, tc.position)
+			if tc.expected == "" {
+				assert.Empty(t, hover)
+				return
+			}
+			assert.True(t, strings.HasPrefix(hover, tc.expected), hover)
+		})
+	}
+}
+
+func TestServeEditor(t *testing.T) {
+	in := strings.NewReader(`{"id":1,"method":"diagnostics","text":"schema-version: v1\n"}
+
+{"id":"two","method":"complete","text":"schema-version: v1\ntasks:\n  a:\n    steps:\n      - uses: \n","position":{"line":4,"character":14}}
+{"method":"unknown"}
+not json
+`)
+	var out bytes.Buffer
+
+	require.NoError(t, ServeEditor(t.Context(), in, &out))
+
+	expected := `{"api-version":"v0","id":1,"method":"diagnostics","diagnostics":[{"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":0}},"message":"no tasks available"}]}
+{"api-version":"v0","id":"two","method":"complete","completions":[{"label":"a","kind":"task"},{"label":"builtin:cache-restore","kind":"builtin"},{"label":"builtin:cache-save","kind":"builtin"},{"label":"builtin:confirm","kind":"builtin"},{"label":"builtin:echo","kind":"builtin"},{"label":"builtin:fetch","kind":"builtin"},{"label":"builtin:fs","kind":"builtin"},{"label":"builtin:http-request","kind":"builtin"},{"label":"builtin:maru2","kind":"builtin"},{"label":"builtin:notify","kind":"builtin"},{"label":"builtin:prompt","kind":"builtin"},{"label":"builtin:push-artifact","kind":"builtin"},{"label":"builtin:query","kind":"builtin"},{"label":"builtin:template","kind":"builtin"},{"label":"builtin:wacky-structs","kind":"builtin"},{"label":"builtin:wait-for","kind":"builtin"}]}
+{"api-version":"v0","method":"unknown","error":"unsupported method \"unknown\""}
+{"api-version":"v0","method":"","error":"invalid request: invalid character 'o' in literal null (expecting 'u')"}
+`
+	assert.Equal(t, expected, out.String())
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+// Explain generates a markdown explanation of the workflow or task(s), along with every task they use
+//
+// uses: references are resolved and fetched the same as when running, so the call tree and the inputs
+// of tasks from local files, aliases and remote workflows are all documented
+func Explain(ctx context.Context, svc *uses.FetcherService, wf v1.Workflow, origin *url.URL, taskNames ...string) (string, error) {
+	roots := []string{}
+	for _, name := range wf.Tasks.OrderedTaskNames() {
+		// asking to explain a task that does not exist is not an error, same as v1.Workflow.Explain
+		if len(taskNames) == 0 || slices.Contains(taskNames, name) {
+			roots = append(roots, name)
+		}
+	}
+
+	if len(roots) == 0 {
+		return wf.Explain(taskNames...), nil
+	}
+
+	g, err := NewGraph(ctx, svc, wf, origin, roots...)
+	if err != nil {
+		return "", err
+	}
+
+	var explanation strings.Builder
+	explanation.WriteString(wf.Explain(taskNames...))
+
+	if len(g.Edges) == 0 {
+		return explanation.String(), nil
+	}
+
+	nodes := make(map[string]GraphNode, len(g.Nodes))
+	for _, n := range g.Nodes {
+		nodes[n.ID] = n
+	}
+	children := map[string][]string{}
+	for _, e := range g.Edges {
+		children[e.From] = append(children[e.From], e.To)
+	}
+
+	// tasks may call each other recursively, so stop at any task that is already being expanded
+	var writeTree func(id string, ancestors []string)
+	writeTree = func(id string, ancestors []string) {
+		n := nodes[id]
+		item := fmt.Sprintf("`%s`", n.Task)
+		if n.Location != "" && n.Location != g.origin {
+			item += fmt.Sprintf(" (`%s`)", n.Location)
+		}
+		indent := strings.Repeat("  ", len(ancestors))
+
+		if slices.Contains(ancestors, id) {
+			fmt.Fprintf(&explanation, "%s- %s *(recursive)*\n", indent, item)
+			return
+		}
+		fmt.Fprintf(&explanation, "%s- %s\n", indent, item)
+
+		for _, child := range children[id] {
+			writeTree(child, append(slices.Clip(ancestors), id))
+		}
+	}
+
+	explanation.WriteString("## Call Tree\n\n")
+	for _, name := range roots {
+		writeTree(g.ids[graphKey{g.origin, name}], nil)
+	}
+	explanation.WriteString("\n")
+
+	var referenced strings.Builder
+	for _, n := range g.Nodes {
+		if n.Location == "" || (n.Location == g.origin && slices.Contains(roots, n.Task)) {
+			continue
+		}
+		location := n.Location
+		if location == g.origin {
+			location = ""
+		}
+		referenced.WriteString(g.workflows[n.Location].ExplainTask(n.Task, location))
+	}
+
+	if referenced.Len() > 0 {
+		explanation.WriteString("## Referenced Tasks\n\n")
+		explanation.WriteString(referenced.String())
+	}
+
+	return explanation.String(), nil
+}
@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"io"
+	"net/url"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+func TestExplain(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "lib/tasks.yaml", []byte(`schema-version: v1
+tasks:
+  test:
+    description: Run the tests
+    inputs:
+      short:
+        description: Short mode
+        default: false
+    steps:
+      - uses: helper
+  helper:
+    steps:
+      - uses: file:tasks.yaml?task=test
+`), 0o644))
+
+	svc, err := uses.NewFetcherService(uses.WithFS(fs))
+	require.NoError(t, err)
+
+	wf := v1.Workflow{
+		SchemaVersion: v1.SchemaVersion,
+		Aliases: v1.AliasMap{
+			"lib": v1.Alias{Path: "lib/tasks.yaml"},
+		},
+		Tasks: v1.TaskMap{
+			"default": v1.Task{Steps: []v1.Step{
+				{Uses: "build"},
+				{Uses: "lib:test"},
+			}},
+			"build": v1.Task{
+				Inputs: v1.InputMap{"output": v1.InputParameter{Description: "Output path", Default: "bin/app"}},
+				Steps:  []v1.Step{{Uses: "builtin:echo"}},
+			},
+			"lint": v1.Task{Steps: []v1.Step{{Run: "golangci-lint run"}}},
+		},
+	}
+
+	origin := &url.URL{Scheme: "file", Opaque: "tasks.yaml"}
+	ctx := log.WithContext(t.Context(), log.New(io.Discard))
+
+	t.Run("task with references", func(t *testing.T) {
+		explanation, err := Explain(ctx, svc, wf, origin, "default")
+		require.NoError(t, err)
+
+		assert.Equal(t, wf.Explain("default")+"## Call Tree\n\n"+
+			"- `default`\n"+
+			"  - `build`\n"+
+			"    - `builtin:echo`\n"+
+			"  - `test` (`file:lib/tasks.yaml`)\n"+
+			"    - `helper` (`file:lib/tasks.yaml`)\n"+
+			"      - `test` (`file:lib/tasks.yaml`) *(recursive)*\n"+
+			"\n"+
+			"## Referenced Tasks\n\n"+
+			"### `build`\n\n"+
+			"**Input Parameters:**\n\n"+
+			"| Name | Description | Required | Default | Validation | Notes |\n"+
+			"|------|-------------|----------|---------|------------|-------|\n"+
+			"| `output` | Output path | Yes | `bin/app` | - | - |\n\n"+
+			"**Uses:**\n\n"+
+			"- `builtin:echo`\n\n\n"+
+			"### `test` (`file:lib/tasks.yaml`)\n\n"+
+			"Run the tests\n\n"+
+			"**Input Parameters:**\n\n"+
+			"| Name | Description | Required | Default | Validation | Notes |\n"+
+			"|------|-------------|----------|---------|------------|-------|\n"+
+			"| `short` | Short mode | Yes | `false` | - | - |\n\n"+
+			"**Uses:**\n\n"+
+			"- `helper`\n\n\n"+
+			"### `helper` (`file:lib/tasks.yaml`)\n\n"+
+			"**Uses:**\n\n"+
+			"- `file:tasks.yaml?task=test`\n\n\n", explanation)
+	})
+
+	t.Run("task without references", func(t *testing.T) {
+		explanation, err := Explain(ctx, svc, wf, origin, "lint")
+		require.NoError(t, err)
+		assert.Equal(t, wf.Explain("lint"), explanation)
+	})
+
+	t.Run("task that does not exist", func(t *testing.T) {
+		explanation, err := Explain(ctx, svc, wf, origin, "nonexistent")
+		require.NoError(t, err)
+		assert.Empty(t, explanation)
+	})
+
+	t.Run("unresolvable reference", func(t *testing.T) {
+		broken := v1.Workflow{
+			SchemaVersion: v1.SchemaVersion,
+			Tasks: v1.TaskMap{
+				"default": v1.Task{Steps: []v1.Step{{Uses: "file:missing.yaml"}}},
+			},
+		}
+		_, err := Explain(ctx, svc, broken, origin)
+		require.ErrorContains(t, err, "missing.yaml")
+	})
+}
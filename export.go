@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cast"
+
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+)
+
+// GitHubAction is a GitHub Actions composite action
+//
+// https://docs.github.com/en/actions/sharing-automations/creating-actions/metadata-syntax-for-github-actions
+type GitHubAction struct {
+	Name        string                       `json:"name"`
+	Description string                       `json:"description"`
+	Inputs      map[string]GitHubActionInput `json:"inputs,omitempty"`
+	Runs        GitHubActionRuns             `json:"runs"`
+}
+
+// GitHubActionInput is a single input to a GitHub Action
+type GitHubActionInput struct {
+	Description        string `json:"description"`
+	Required           bool   `json:"required"`
+	Default            string `json:"default,omitempty"`
+	DeprecationMessage string `json:"deprecationMessage,omitempty"`
+}
+
+// GitHubActionRuns configures how a composite action is run
+type GitHubActionRuns struct {
+	Using string             `json:"using"`
+	Steps []GitHubActionStep `json:"steps"`
+}
+
+// GitHubActionStep is a single step within a composite action
+type GitHubActionStep struct {
+	Name  string            `json:"name"`
+	Shell string            `json:"shell"`
+	Env   map[string]string `json:"env,omitempty"`
+	Run   string            `json:"run"`
+}
+
+// ExportGitHubAction wraps a task in a GitHub Actions composite action
+//
+// The action installs maru2 (at the given version, or the latest release if empty) using the install script,
+// then runs the task from the given workflow location. Task inputs are mapped 1:1 to action inputs,
+// only inputs that are set are passed along so default-from-env and task defaults are still respected
+func ExportGitHubAction(wf v1.Workflow, taskName, from, version string) (GitHubAction, error) {
+	task, ok := wf.Tasks.Find(taskName)
+	if !ok {
+		return GitHubAction{}, fmt.Errorf("task %q not found%s", taskName, v1.DidYouMean(taskName, wf.Tasks.OrderedTaskNames()))
+	}
+
+	description := task.Description
+	if description == "" {
+		description = fmt.Sprintf("Run the maru2 task %q", taskName)
+	}
+
+	action := GitHubAction{
+		Name:        taskName,
+		Description: description,
+		Runs: GitHubActionRuns{
+			Using: "composite",
+		},
+	}
+
+	install := GitHubActionStep{
+		Name:  "Install maru2",
+		Shell: "bash",
+		Env: map[string]string{
+			"MARU2_INSTALL_DIR": "${{ runner.temp }}/maru2/bin",
+			"USE_SUDO":          "false",
+		},
+		Run: strings.Join([]string{
+			`mkdir -p "$MARU2_INSTALL_DIR"`,
+			"curl -sSfL https://raw.githubusercontent.com/defenseunicorns/maru2/main/install.sh | bash",
+			`echo "$MARU2_INSTALL_DIR" >> "$GITHUB_PATH"`,
+		}, "\n"),
+	}
+	if version != "" {
+		install.Env["TAG"] = version
+	}
+
+	run := GitHubActionStep{
+		Name:  fmt.Sprintf("Run %s", taskName),
+		Shell: "bash",
+		Env:   map[string]string{},
+	}
+
+	var script strings.Builder
+	script.WriteString("args=()\n")
+
+	for name, param := range task.Inputs.OrderedSeq() {
+		required := param.Required == nil || *param.Required
+
+		input := GitHubActionInput{
+			Description:        param.Description,
+			Required:           required && param.Default == nil && param.DefaultFromEnv == "",
+			DeprecationMessage: param.DeprecatedMessage,
+		}
+		if param.Default != nil {
+			input.Default = cast.ToString(param.Default)
+		}
+
+		if action.Inputs == nil {
+			action.Inputs = make(map[string]GitHubActionInput, len(task.Inputs))
+		}
+		action.Inputs[name] = input
+
+		env := "INPUT_" + toEnvVar(name)
+		run.Env[env] = fmt.Sprintf("${{ inputs.%s }}", name)
+		fmt.Fprintf(&script, "if [ -n \"$%s\" ]; then args+=(--with \"%s=$%s\"); fi\n", env, name, env)
+	}
+
+	fmt.Fprintf(&script, "maru2 --from %q %s \"${args[@]}\"", from, taskName)
+	run.Run = script.String()
+
+	if len(run.Env) == 0 {
+		run.Env = nil
+	}
+
+	action.Runs.Steps = []GitHubActionStep{install, run}
+
+	return action, nil
+}
@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+)
+
+func TestExportGitHubAction(t *testing.T) {
+	install := func(env map[string]string) GitHubActionStep {
+		return GitHubActionStep{
+			Name:  "Install maru2",
+			Shell: "bash",
+			Env:   env,
+			Run:   "mkdir -p \"$MARU2_INSTALL_DIR\"\ncurl -sSfL https://raw.githubusercontent.com/defenseunicorns/maru2/main/install.sh | bash\necho \"$MARU2_INSTALL_DIR\" >> \"$GITHUB_PATH\"",
+		}
+	}
+
+	tests := []struct {
+		name        string
+		wf          v1.Workflow
+		task        string
+		from        string
+		version     string
+		expected    GitHubAction
+		expectedErr string
+	}{
+		{
+			name: "no inputs",
+			wf: v1.Workflow{
+				Tasks: v1.TaskMap{
+					"build": v1.Task{Steps: []v1.Step{{Run: "go build"}}},
+				},
+			},
+			task: "build",
+			from: "file:${{ github.action_path }}/tasks.yaml",
+			expected: GitHubAction{
+				Name:        "build",
+				Description: `Run the maru2 task "build"`,
+				Runs: GitHubActionRuns{
+					Using: "composite",
+					Steps: []GitHubActionStep{
+						install(map[string]string{
+							"MARU2_INSTALL_DIR": "${{ runner.temp }}/maru2/bin",
+							"USE_SUDO":          "false",
+						}),
+						{
+							Name:  "Run build",
+							Shell: "bash",
+							Run:   "args=()\nmaru2 --from \"file:${{ github.action_path }}/tasks.yaml\" build \"${args[@]}\"",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "inputs and pinned version",
+			wf: v1.Workflow{
+				Tasks: v1.TaskMap{
+					"deploy": v1.Task{
+						Description: "Deploy the app",
+						Inputs: v1.InputMap{
+							"env": v1.InputParameter{
+								Description: "Environment",
+							},
+							"replicas": v1.InputParameter{
+								Description: "Replica count",
+								Default:     3,
+							},
+							"token": v1.InputParameter{
+								Description:       "Token",
+								DefaultFromEnv:    "TOKEN",
+								DeprecatedMessage: "use OIDC",
+							},
+							"dry": v1.InputParameter{
+								Description: "Dry run",
+								Required:    new(bool),
+							},
+						},
+						Steps: []v1.Step{{Run: "echo deploy"}},
+					},
+				},
+			},
+			task:    "deploy",
+			from:    "pkg:github/defenseunicorns/maru2@main#tasks.yaml",
+			version: "v1.2.3",
+			expected: GitHubAction{
+				Name:        "deploy",
+				Description: "Deploy the app",
+				Inputs: map[string]GitHubActionInput{
+					"dry":      {Description: "Dry run"},
+					"env":      {Description: "Environment", Required: true},
+					"replicas": {Description: "Replica count", Default: "3"},
+					"token":    {Description: "Token", DeprecationMessage: "use OIDC"},
+				},
+				Runs: GitHubActionRuns{
+					Using: "composite",
+					Steps: []GitHubActionStep{
+						install(map[string]string{
+							"MARU2_INSTALL_DIR": "${{ runner.temp }}/maru2/bin",
+							"USE_SUDO":          "false",
+							"TAG":               "v1.2.3",
+						}),
+						{
+							Name:  "Run deploy",
+							Shell: "bash",
+							Env: map[string]string{
+								"INPUT_DRY":      "${{ inputs.dry }}",
+								"INPUT_ENV":      "${{ inputs.env }}",
+								"INPUT_REPLICAS": "${{ inputs.replicas }}",
+								"INPUT_TOKEN":    "${{ inputs.token }}",
+							},
+							Run: `args=()
+if [ -n "$INPUT_DRY" ]; then args+=(--with "dry=$INPUT_DRY"); fi
+if [ -n "$INPUT_ENV" ]; then args+=(--with "env=$INPUT_ENV"); fi
+if [ -n "$INPUT_REPLICAS" ]; then args+=(--with "replicas=$INPUT_REPLICAS"); fi
+if [ -n "$INPUT_TOKEN" ]; then args+=(--with "token=$INPUT_TOKEN"); fi
+maru2 --from "pkg:github/defenseunicorns/maru2@main#tasks.yaml" deploy "${args[@]}"`,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "task not found",
+			wf: v1.Workflow{
+				Tasks: v1.TaskMap{
+					"build": v1.Task{Steps: []v1.Step{{Run: "go build"}}},
+				},
+			},
+			task:        "deploy",
+			expectedErr: `task "deploy" not found`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			action, err := ExportGitHubAction(tc.wf, tc.task, tc.from, tc.version)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, action)
+		})
+	}
+}
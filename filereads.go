@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type remoteFileReadsKey struct{}
+
+// WithoutRemoteFileReads returns a context where the file and hashFiles template functions, along with builtins that
+// read local files, fail in workflows fetched from remote locations (http, https, pkg, oci), so untrusted workflows
+// cannot read local files into their steps
+func WithoutRemoteFileReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, remoteFileReadsKey{}, true)
+}
+
+// checkFileRead returns an error if the workflow being templated is not allowed to read local files
+func checkFileRead(ctx context.Context, path string) error {
+	if disabled, _ := ctx.Value(remoteFileReadsKey{}).(bool); !disabled {
+		return nil
+	}
+	origin := workflowOrigin(ctx)
+	if origin == nil || origin.Scheme == "file" {
+		return nil
+	}
+	return fmt.Errorf("unable to read %q: file reads are disabled for remote workflows (%s)", path, origin)
+}
+
+// hashTemplateFiles returns the digest of the files matching the patterns for the hashFiles template function
+func hashTemplateFiles(ctx context.Context, patterns ...string) (string, error) {
+	for _, pattern := range patterns {
+		if err := checkFileRead(ctx, pattern); err != nil {
+			return "", err
+		}
+	}
+	return hashFiles(patterns...)
+}
+
+// readTemplateFile returns the contents of a file for the file template function, without trailing newlines
+func readTemplateFile(ctx context.Context, path string) (string, error) {
+	if err := checkFileRead(ctx, path); err != nil {
+		return "", err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/defenseunicorns/maru2/schema"
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+// GraphNode is a task within a task graph
+type GraphNode struct {
+	// ID of the node, unique within the graph
+	ID string
+	// Resolved location of the workflow the task is defined in, empty for builtins
+	Location string
+	Task     string
+}
+
+// GraphEdge is a uses: reference from one task to another
+type GraphEdge struct {
+	From string
+	To   string
+}
+
+// Graph is the dependency graph of tasks and their uses: references
+type Graph struct {
+	Nodes []GraphNode
+	Edges []GraphEdge
+
+	origin    string
+	ids       map[graphKey]string
+	edges     map[GraphEdge]struct{}
+	workflows map[string]v1.Workflow
+}
+
+type graphKey struct {
+	location string
+	task     string
+}
+
+// NewGraph builds the dependency graph of the given tasks, or every task in the workflow if none are given
+//
+// uses: references are resolved and fetched the same as when running, so remote workflows are included
+func NewGraph(ctx context.Context, svc *uses.FetcherService, wf v1.Workflow, origin *url.URL, tasks ...string) (*Graph, error) {
+	g := &Graph{
+		Nodes:     []GraphNode{},
+		Edges:     []GraphEdge{},
+		origin:    graphLocation(origin),
+		ids:       map[graphKey]string{},
+		edges:     map[GraphEdge]struct{}{},
+		workflows: map[string]v1.Workflow{},
+	}
+
+	if len(tasks) == 0 {
+		tasks = wf.Tasks.OrderedTaskNames()
+	}
+
+	for _, name := range tasks {
+		if _, err := g.walk(ctx, svc, wf, origin, name); err != nil {
+			return nil, err
+		}
+	}
+
+	return g, nil
+}
+
+// walk adds a task and everything it uses to the graph, returning the ID of the task's node
+func (g *Graph) walk(ctx context.Context, svc *uses.FetcherService, wf v1.Workflow, origin *url.URL, name string) (string, error) {
+	if name == "" {
+		name = schema.DefaultTaskName
+	}
+
+	location := graphLocation(origin)
+	id, seen := g.node(location, name)
+	if seen {
+		return id, nil
+	}
+	g.workflows[location] = wf
+
+	task, ok := wf.Tasks.Find(name)
+	if !ok {
+		return "", fmt.Errorf("task %q not found in %s%s", name, origin, v1.DidYouMean(name, wf.Tasks.OrderedTaskNames()))
+	}
+
+	for _, step := range task.Steps {
+		if step.Uses == "" {
+			continue
+		}
+
+		var next string
+		switch _, local := wf.Tasks.Find(step.Uses); {
+		case strings.HasPrefix(step.Uses, "builtin:"):
+			next, _ = g.node("", step.Uses)
+		case local:
+			var err error
+			next, err = g.walk(ctx, svc, wf, origin, step.Uses)
+			if err != nil {
+				return "", err
+			}
+		default:
+			resolved, err := svc.ResolveRelative(origin, step.Uses, wf.Aliases)
+			if err != nil {
+				return "", fmt.Errorf("failed to resolve %q: %w", step.Uses, err)
+			}
+			nextWf, err := Fetch(ctx, svc, resolved)
+			if err != nil {
+				return "", err
+			}
+			next, err = g.walk(ctx, svc, nextWf, resolved, resolved.Query().Get(uses.QualifierTask))
+			if err != nil {
+				return "", err
+			}
+		}
+
+		edge := GraphEdge{From: id, To: next}
+		if _, ok := g.edges[edge]; !ok {
+			g.edges[edge] = struct{}{}
+			g.Edges = append(g.Edges, edge)
+		}
+	}
+
+	return id, nil
+}
+
+// node returns the ID of the node for a task, adding it to the graph if it was not already seen
+func (g *Graph) node(location, task string) (string, bool) {
+	key := graphKey{location, task}
+	if id, ok := g.ids[key]; ok {
+		return id, true
+	}
+	id := fmt.Sprintf("n%d", len(g.Nodes))
+	g.ids[key] = id
+	g.Nodes = append(g.Nodes, GraphNode{ID: id, Location: location, Task: task})
+	return id, false
+}
+
+// label returns the display label of a node, tasks outside of the graph's origin workflow include their location
+func (g *Graph) label(n GraphNode) string {
+	if n.Location == "" || n.Location == g.origin {
+		return n.Task
+	}
+	return n.Task + "\n" + n.Location
+}
+
+// DOT renders the graph in the Graphviz DOT language
+func (g *Graph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph maru2 {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box];\n")
+	for _, n := range g.Nodes {
+		attrs := ""
+		if n.Location == "" {
+			attrs = ", style=dashed"
+		}
+		label := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(g.label(n))
+		fmt.Fprintf(&sb, "  %s [label=\"%s\"%s];\n", n.ID, label, attrs)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&sb, "  %s -> %s;\n", e.From, e.To)
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart
+func (g *Graph) Mermaid() string {
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	for _, n := range g.Nodes {
+		label := strings.NewReplacer(`"`, "#quot;", "\n", "<br/>").Replace(g.label(n))
+		if n.Location == "" {
+			fmt.Fprintf(&sb, "  %s([\"%s\"])\n", n.ID, label)
+			continue
+		}
+		fmt.Fprintf(&sb, "  %s[\"%s\"]\n", n.ID, label)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&sb, "  %s --> %s\n", e.From, e.To)
+	}
+	return sb.String()
+}
+
+// graphLocation returns the location of a workflow without its task qualifier
+func graphLocation(uri *url.URL) string {
+	clone := *uri
+	q := clone.Query()
+	q.Del(uses.QualifierTask)
+	clone.RawQuery = q.Encode()
+	return clone.String()
+}
@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+func TestGraph(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "lib/tasks.yaml", []byte(`schema-version: v1
+tasks:
+  test:
+    steps:
+      - run: echo test
+      - uses: helper
+  helper:
+    steps:
+      - uses: file:tasks.yaml?task=test
+`), 0o644))
+
+	svc, err := uses.NewFetcherService(uses.WithFS(fs))
+	require.NoError(t, err)
+
+	wf := v1.Workflow{
+		SchemaVersion: v1.SchemaVersion,
+		Tasks: v1.TaskMap{
+			"default": v1.Task{Steps: []v1.Step{
+				{Uses: "build"},
+				{Uses: "file:lib/?task=test"},
+				{Uses: "build"},
+			}},
+			"build": v1.Task{Steps: []v1.Step{
+				{Uses: "builtin:echo"},
+			}},
+			"lint": v1.Task{Steps: []v1.Step{
+				{Run: "golangci-lint run"},
+			}},
+		},
+	}
+
+	origin := &url.URL{Scheme: "file", Opaque: "tasks.yaml"}
+	ctx := log.WithContext(t.Context(), log.New(nil))
+
+	t.Run("all tasks", func(t *testing.T) {
+		g, err := NewGraph(ctx, svc, wf, origin)
+		require.NoError(t, err)
+
+		assert.Equal(t, []GraphNode{
+			{ID: "n0", Location: "file:tasks.yaml", Task: "default"},
+			{ID: "n1", Location: "file:tasks.yaml", Task: "build"},
+			{ID: "n2", Task: "builtin:echo"},
+			{ID: "n3", Location: "file:lib/tasks.yaml", Task: "test"},
+			{ID: "n4", Location: "file:lib/tasks.yaml", Task: "helper"},
+			{ID: "n5", Location: "file:tasks.yaml", Task: "lint"},
+		}, g.Nodes)
+		assert.Equal(t, []GraphEdge{
+			{From: "n1", To: "n2"},
+			{From: "n0", To: "n1"},
+			{From: "n4", To: "n3"},
+			{From: "n3", To: "n4"},
+			{From: "n0", To: "n3"},
+		}, g.Edges)
+
+		assert.Equal(t, `digraph maru2 {
+  rankdir=LR;
+  node [shape=box];
+  n0 [label="default"];
+  n1 [label="build"];
+  n2 [label="builtin:echo", style=dashed];
+  n3 [label="test\nfile:lib/tasks.yaml"];
+  n4 [label="helper\nfile:lib/tasks.yaml"];
+  n5 [label="lint"];
+  n1 -> n2;
+  n0 -> n1;
+  n4 -> n3;
+  n3 -> n4;
+  n0 -> n3;
+}
+`, g.DOT())
+
+		assert.Equal(t, `flowchart LR
+  n0["default"]
+  n1["build"]
+  n2(["builtin:echo"])
+  n3["test<br/>file:lib/tasks.yaml"]
+  n4["helper<br/>file:lib/tasks.yaml"]
+  n5["lint"]
+  n1 --> n2
+  n0 --> n1
+  n4 --> n3
+  n3 --> n4
+  n0 --> n3
+`, g.Mermaid())
+	})
+
+	t.Run("single task", func(t *testing.T) {
+		g, err := NewGraph(ctx, svc, wf, origin, "build")
+		require.NoError(t, err)
+
+		assert.Equal(t, []GraphNode{
+			{ID: "n0", Location: "file:tasks.yaml", Task: "build"},
+			{ID: "n1", Task: "builtin:echo"},
+		}, g.Nodes)
+		assert.Equal(t, []GraphEdge{{From: "n0", To: "n1"}}, g.Edges)
+	})
+
+	t.Run("task not found", func(t *testing.T) {
+		_, err := NewGraph(ctx, svc, wf, origin, "buld")
+		require.EqualError(t, err, `task "buld" not found in file:tasks.yaml, did you mean "build"?`)
+	})
+
+	t.Run("missing workflow", func(t *testing.T) {
+		broken := v1.Workflow{
+			SchemaVersion: v1.SchemaVersion,
+			Tasks: v1.TaskMap{
+				"default": v1.Task{Steps: []v1.Step{{Uses: "file:missing.yaml"}}},
+			},
+		}
+		_, err := NewGraph(ctx, svc, broken, origin)
+		require.ErrorContains(t, err, "missing.yaml")
+	})
+}
@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cast"
+
+	"github.com/defenseunicorns/maru2/schema"
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+type mocksKey struct{}
+
+// withMocks returns a context whose uses: steps are replaced by the given mocks
+func withMocks(ctx context.Context, mocks map[string]v1.Mock) context.Context {
+	return context.WithValue(ctx, mocksKey{}, mocks)
+}
+
+// mocksFromContext returns the mocked uses: steps of the current test, or nil outside of a test
+func mocksFromContext(ctx context.Context) map[string]v1.Mock {
+	mocks, _ := ctx.Value(mocksKey{}).(map[string]v1.Mock)
+	return mocks
+}
+
+// handleMockedStep returns the mocked result of a uses: step instead of calling it
+func handleMockedStep(ctx context.Context, step v1.Step, mock v1.Mock) (map[string]any, error) {
+	log.FromContext(ctx).Debug("mocked", "uses", step.Uses)
+	if mock.Error != "" {
+		return nil, errors.New(mock.Error)
+	}
+	return maps.Clone(mock.Outputs), nil
+}
+
+// TestResult is the result of a single workflow test
+type TestResult struct {
+	Name     string
+	Task     string
+	Duration time.Duration
+	// Failures lists every expectation the task did not meet, empty if the test passed
+	Failures []string
+}
+
+// Passed returns whether the task met every expectation of the test
+func (tr TestResult) Passed() bool {
+	return len(tr.Failures) == 0
+}
+
+// RunTests runs the tests declared in a workflow, in alphabetical order
+//
+// When names are given, only those tests are run. An error is returned only if a test could not be found,
+// failing tests are reported in their results.
+func RunTests(
+	ctx context.Context,
+	svc *uses.FetcherService,
+	wf v1.Workflow,
+	origin *url.URL,
+	ro RuntimeOptions,
+	names ...string,
+) ([]TestResult, error) {
+	for _, name := range names {
+		if _, ok := wf.Tests[name]; !ok {
+			return nil, fmt.Errorf("test %q not found%s", name, v1.DidYouMean(name, slices.Sorted(maps.Keys(wf.Tests))))
+		}
+	}
+
+	// tests never prompt, tasks that set confirm always run
+	ctx = WithConfirm(ctx, AlwaysConfirm)
+
+	var results []TestResult
+	for name, test := range wf.Tests.OrderedSeq() {
+		if len(names) > 0 && !slices.Contains(names, name) {
+			continue
+		}
+
+		tro := ro
+		tro.Dry = ro.Dry || test.DryRun
+
+		start := time.Now()
+		outputs, err := Run(withMocks(ctx, test.Mocks), svc, wf, test.Task, test.With, origin, tro)
+
+		results = append(results, TestResult{
+			Name:     name,
+			Task:     cmp.Or(test.Task, schema.DefaultTaskName),
+			Duration: time.Since(start),
+			Failures: checkExpectations(test.Expect, outputs, err),
+		})
+	}
+	return results, nil
+}
+
+// checkExpectations compares the result of a task against what a test expects
+func checkExpectations(expect v1.Expect, outputs map[string]any, err error) []string {
+	var failures []string
+
+	// expecting an error without an exit code accepts any failure
+	if code := exitCode(err); code != expect.ExitCode && (expect.Error == "" || expect.ExitCode != 0) {
+		failure := fmt.Sprintf("exited with %d, expected %d", code, expect.ExitCode)
+		if err != nil {
+			failure += ": " + err.Error()
+		}
+		failures = append(failures, failure)
+	}
+
+	if expect.Error != "" {
+		if err == nil {
+			failures = append(failures, fmt.Sprintf("no error, expected error containing %q", expect.Error))
+		} else if !strings.Contains(err.Error(), expect.Error) {
+			failures = append(failures, fmt.Sprintf("error %q does not contain %q", err.Error(), expect.Error))
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(expect.Outputs)) {
+		got, ok := outputs[key]
+		if !ok {
+			failures = append(failures, fmt.Sprintf("output %q was not set", key))
+			continue
+		}
+		if want := cast.ToString(expect.Outputs[key]); cast.ToString(got) != want {
+			failures = append(failures, fmt.Sprintf("output %q is %q, expected %q", key, cast.ToString(got), want))
+		}
+	}
+
+	return failures
+}
+
+// exitCode returns the exit code a task would exit maru2 with, 1 if the error was not caused by a command
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var eErr *exec.ExitError
+	if errors.As(err, &eErr) && eErr.ExitCode() > 0 {
+		return eErr.ExitCode()
+	}
+	return 1
+}
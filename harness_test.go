@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defenseunicorns/maru2/schema"
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+)
+
+func TestRunTests(t *testing.T) {
+	// failing steps are annotated, so reset state of the check to be "blank" after tests are done, this function must EXACTLY match its counterpart
+	t.Cleanup(func() {
+		isGitHubActions = sync.OnceValue(func() bool {
+			return os.Getenv(GitHubActionsEnvVar) == "true"
+		})
+	})
+	isGitHubActions = func() bool { return false }
+
+	wf := v1.Workflow{
+		Tasks: v1.TaskMap{
+			"default": v1.Task{
+				Inputs: v1.InputMap{"version": {Default: "1.0"}},
+				Steps: []v1.Step{
+					{Uses: "fetch", ID: "fetch"},
+					{Run: `echo "tag=v${{ input "version" }}${{ from "fetch" "suffix" }}" >> $MARU2_OUTPUT`},
+				},
+			},
+			"fetch": v1.Task{Steps: []v1.Step{{Run: "exit 3"}}},
+		},
+		Tests: v1.TestMap{
+			"mocked": v1.Test{
+				With:   schema.With{"version": 2},
+				Mocks:  map[string]v1.Mock{"fetch": {Outputs: map[string]any{"suffix": "-rc"}}},
+				Expect: v1.Expect{Outputs: map[string]any{"tag": "v2-rc"}},
+			},
+			"exit-code":  v1.Test{Expect: v1.Expect{ExitCode: 3}},
+			"mock-error": v1.Test{Mocks: map[string]v1.Mock{"fetch": {Error: "no network"}}, Expect: v1.Expect{Error: "no network"}},
+			"dry-run":    v1.Test{DryRun: true},
+			"fails": v1.Test{
+				Task:   "fetch",
+				Expect: v1.Expect{Outputs: map[string]any{"tag": "v1.0"}},
+			},
+		},
+	}
+
+	ctx := log.WithContext(t.Context(), log.New(io.Discard))
+	origin := &url.URL{Scheme: "file", Opaque: "tasks.yaml"}
+	ro := RuntimeOptions{Stdout: io.Discard, Stderr: io.Discard}
+
+	results, err := RunTests(ctx, nil, wf, origin, ro)
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+
+	for i, name := range []string{"dry-run", "exit-code", "fails", "mock-error", "mocked"} {
+		assert.Equal(t, name, results[i].Name)
+	}
+
+	assert.Equal(t, "default", results[0].Task)
+	for _, result := range results {
+		if result.Name == "fails" {
+			assert.False(t, result.Passed())
+			assert.Equal(t, "fetch", result.Task)
+			assert.Equal(t, []string{
+				"exited with 3, expected 0: exit status 3",
+				`output "tag" was not set`,
+			}, result.Failures)
+			continue
+		}
+		assert.True(t, result.Passed(), "%s: %v", result.Name, result.Failures)
+	}
+
+	results, err = RunTests(ctx, nil, wf, origin, ro, "mocked")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "mocked", results[0].Name)
+
+	_, err = RunTests(ctx, nil, wf, origin, ro, "mocks")
+	require.EqualError(t, err, `test "mocks" not found, did you mean "mocked"?`)
+}
+
+func TestCheckExpectations(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expect   v1.Expect
+		outputs  map[string]any
+		err      error
+		expected []string
+	}{
+		{
+			name: "success",
+		},
+		{
+			name:     "unexpected error",
+			err:      errors.New("boom"),
+			expected: []string{"exited with 1, expected 0: boom"},
+		},
+		{
+			name:   "any failure with error",
+			expect: v1.Expect{Error: "boom"},
+			err:    fmt.Errorf("wrapped: %w", errors.New("boom")),
+		},
+		{
+			name:     "error not contained",
+			expect:   v1.Expect{Error: "bang"},
+			err:      errors.New("boom"),
+			expected: []string{`error "boom" does not contain "bang"`},
+		},
+		{
+			name:   "expected error without one",
+			expect: v1.Expect{Error: "boom"},
+			expected: []string{
+				`no error, expected error containing "boom"`,
+			},
+		},
+		{
+			name:     "exit code and error",
+			expect:   v1.Expect{ExitCode: 2, Error: "boom"},
+			err:      errors.New("boom"),
+			expected: []string{"exited with 1, expected 2: boom"},
+		},
+		{
+			name:    "outputs compared as strings",
+			expect:  v1.Expect{Outputs: map[string]any{"count": 3, "ok": true, "name": "a"}},
+			outputs: map[string]any{"count": "3", "ok": "true", "name": "b"},
+			expected: []string{
+				`output "name" is "b", expected "a"`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, checkExpectations(tc.expect, tc.outputs, tc.err))
+		})
+	}
+}
@@ -0,0 +1,208 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+
+	"github.com/defenseunicorns/maru2/schema"
+)
+
+// HistoryRun is a run recorded to the history
+type HistoryRun struct {
+	RunID string `json:"run-id"`
+	// Resolved location of the workflow the run was started from
+	From  string   `json:"from"`
+	Tasks []string `json:"tasks"`
+	// SHA-256 digest of the inputs passed to the called task(s), the inputs themselves are not recorded as they may hold secrets
+	InputsHash string    `json:"inputs-hash"`
+	DryRun     bool      `json:"dry-run"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	Started    time.Time `json:"started"`
+	Duration   string    `json:"duration"`
+	// Report of the run without the outputs of its tasks and steps, as they may hold secrets
+	Report *Report `json:"report"`
+}
+
+// NewHistoryRun creates a history entry from the finished report of a run
+func NewHistoryRun(from string, tasks []string, with schema.With, r *Report) HistoryRun {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := &Report{
+		RunID:    r.RunID,
+		DryRun:   r.DryRun,
+		Status:   r.Status,
+		Error:    r.Error,
+		Started:  r.Started,
+		Duration: r.Duration,
+		Tasks:    make([]*ReportTask, 0, len(r.Tasks)),
+		Fetched:  slices.Clone(r.Fetched),
+		elapsed:  r.elapsed,
+	}
+	for _, task := range r.Tasks {
+		t := *task
+		t.Outputs = nil
+		t.Steps = make([]*ReportStep, 0, len(task.Steps))
+		for _, step := range task.Steps {
+			s := *step
+			s.Outputs = nil
+			t.Steps = append(t.Steps, &s)
+		}
+		report.Tasks = append(report.Tasks, &t)
+	}
+
+	return HistoryRun{
+		RunID:      r.RunID,
+		From:       from,
+		Tasks:      tasks,
+		InputsHash: HashInputs(with),
+		DryRun:     r.DryRun,
+		Status:     r.Status,
+		Error:      r.Error,
+		Started:    r.Started,
+		Duration:   r.Duration,
+		Report:     report,
+	}
+}
+
+// HashInputs returns the hex encoded SHA-256 digest of inputs, equal inputs always have the same digest
+func HashInputs(with schema.With) string {
+	if with == nil {
+		with = schema.With{}
+	}
+	// maps are marshaled with sorted keys
+	b, _ := json.Marshal(with)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// History is a record of past runs, stored as a JSON file per run named by its run ID, readable only by the user
+//
+// Run IDs sort by the time the run started, so the oldest runs are removed first once the limit is reached
+type History struct {
+	fs    afero.Fs
+	limit int
+}
+
+// NewHistory creates a history stored in the base directory of fsys, keeping at most limit runs
+func NewHistory(fsys afero.Fs, limit int) *History {
+	return &History{fs: fsys, limit: limit}
+}
+
+// Record adds a run to the history, removing the oldest runs beyond the limit
+func (h *History) Record(run HistoryRun) error {
+	b, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := afero.WriteFile(h.fs, run.RunID+".json", append(b, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to record run %q: %w", run.RunID, err)
+	}
+
+	ids, err := h.ids()
+	if err != nil {
+		return err
+	}
+	for len(ids) > h.limit {
+		if err := h.fs.Remove(ids[0] + ".json"); err != nil {
+			return err
+		}
+		ids = ids[1:]
+	}
+	return nil
+}
+
+// List returns the recorded runs, newest first
+func (h *History) List() ([]HistoryRun, error) {
+	ids, err := h.ids()
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]HistoryRun, 0, len(ids))
+	for _, id := range slices.Backward(ids) {
+		run, err := h.read(id)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// Get returns a recorded run by its ID, or a unique prefix of its ID
+func (h *History) Get(id string) (HistoryRun, error) {
+	ids, err := h.ids()
+	if err != nil {
+		return HistoryRun{}, err
+	}
+
+	var matches []string
+	for _, candidate := range ids {
+		if candidate == id {
+			return h.read(id)
+		}
+		if strings.HasPrefix(candidate, strings.ToUpper(id)) {
+			matches = append(matches, candidate)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return HistoryRun{}, fmt.Errorf("run %q not found", id)
+	case 1:
+		return h.read(matches[0])
+	default:
+		return HistoryRun{}, fmt.Errorf("run %q is ambiguous, matches %d runs", id, len(matches))
+	}
+}
+
+// ids returns the IDs of the recorded runs, oldest first
+func (h *History) ids() ([]string, error) {
+	entries, err := afero.ReadDir(h.fs, ".")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		if id, ok := strings.CutSuffix(entry.Name(), ".json"); ok && !entry.IsDir() {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// read reads a recorded run, restoring the durations of its report
+func (h *History) read(id string) (HistoryRun, error) {
+	b, err := afero.ReadFile(h.fs, id+".json")
+	if err != nil {
+		return HistoryRun{}, fmt.Errorf("failed to read run %q: %w", id, err)
+	}
+
+	var run HistoryRun
+	if err := json.Unmarshal(b, &run); err != nil {
+		return HistoryRun{}, fmt.Errorf("failed to parse run %q: %w", id, err)
+	}
+	if run.Report != nil {
+		run.Report.restoreElapsed()
+	}
+	return run, nil
+}
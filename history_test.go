@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defenseunicorns/maru2/schema"
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+)
+
+func TestHashInputs(t *testing.T) {
+	assert.Equal(t, HashInputs(nil), HashInputs(schema.With{}))
+	assert.Equal(t, HashInputs(schema.With{"a": 1, "b": "two"}), HashInputs(schema.With{"b": "two", "a": 1}))
+	assert.NotEqual(t, HashInputs(schema.With{"a": 1}), HashInputs(schema.With{"a": 2}))
+	assert.Len(t, HashInputs(nil), 64)
+}
+
+func TestHistory(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	h := NewHistory(fs, 2)
+
+	runs, err := h.List()
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+
+	record := func(id string, err error) {
+		t.Helper()
+		r := NewReport(id, false)
+		task := r.startTask("build", &url.URL{Scheme: "file", Opaque: "tasks.yaml"}, 1)
+		step := r.startStep(task, 0, v1.Step{ID: "compile"})
+		r.finishStep(t.Context(), step, false, map[string]any{"password": "hunter2"}, err)
+		r.finishTask(t.Context(), task, map[string]any{"password": "hunter2"}, err)
+		r.Finish(t.Context(), err)
+		require.NoError(t, h.Record(NewHistoryRun("file:tasks.yaml", []string{"build"}, schema.With{"a": 1}, r)))
+	}
+
+	record("01AAAAAAAAAAAAAAAAAAAAAAAA", nil)
+	record("01BBBBBBBBBBBBBBBBBBBBBBBB", errors.New("boom"))
+	record("01BBBBBBBBBBBBBBBBBBBBBBBC", nil)
+
+	// the oldest run is removed once the limit is reached
+	runs, err = h.List()
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, "01BBBBBBBBBBBBBBBBBBBBBBBC", runs[0].RunID)
+	assert.Equal(t, "01BBBBBBBBBBBBBBBBBBBBBBBB", runs[1].RunID)
+	assert.Equal(t, ReportStatusFailure, runs[1].Status)
+	assert.Equal(t, "boom", runs[1].Error)
+	assert.Equal(t, []string{"build"}, runs[1].Tasks)
+	assert.Equal(t, HashInputs(schema.With{"a": 1}), runs[1].InputsHash)
+
+	run, err := h.Get("01bbbbbbbbbbbbbbbbbbbbbbbc")
+	require.NoError(t, err)
+	assert.Equal(t, "01BBBBBBBBBBBBBBBBBBBBBBBC", run.RunID)
+	require.NotNil(t, run.Report)
+	require.Len(t, run.Report.Tasks, 1)
+	assert.Equal(t, run.Report.Tasks[0].Duration, run.Report.Tasks[0].elapsed.String(), "durations are restored so the report can be summarized")
+	assert.Nil(t, run.Report.Tasks[0].Outputs, "outputs may hold secrets")
+	require.Len(t, run.Report.Tasks[0].Steps, 1)
+	assert.Nil(t, run.Report.Tasks[0].Steps[0].Outputs, "outputs may hold secrets")
+
+	info, err := fs.Stat("01BBBBBBBBBBBBBBBBBBBBBBBC.json")
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	_, err = h.Get("01B")
+	require.EqualError(t, err, `run "01B" is ambiguous, matches 2 runs`)
+
+	_, err = h.Get("01AAAAAAAAAAAAAAAAAAAAAAAA")
+	require.EqualError(t, err, `run "01AAAAAAAAAAAAAAAAAAAAAAAA" not found`)
+
+	require.NoError(t, afero.WriteFile(fs, "01C.json", []byte("{"), 0o644))
+	_, err = h.Get("01C")
+	require.ErrorContains(t, err, `failed to parse run "01C"`)
+}
@@ -8,16 +8,48 @@ import (
 	"errors"
 	"fmt"
 	"runtime"
+	"slices"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/vm"
 
 	"github.com/defenseunicorns/maru2/schema"
 )
 
+// Limits applied to if expressions, so an expression from an untrusted workflow cannot hang or exhaust the runner
+const (
+	// ifMaxNodes is the maximum number of nodes in a compiled expression
+	ifMaxNodes = 1000
+	// ifMemoryBudget is the maximum number of elements an expression can allocate (e.g. ranges, arrays and maps)
+	ifMemoryBudget = 100_000
+	// ifTimeout is the maximum time an expression can be evaluated for
+	ifTimeout = time.Second
+)
+
+// ifDisabledBuiltins are expr builtins that can allocate without bound, outside of the memory budget
+var ifDisabledBuiltins = []string{"repeat", "reduce"}
+
+// disabledBuiltins records the first disabled builtin used within an expression
+type disabledBuiltins struct {
+	name string
+}
+
+// Visit implements ast.Visitor
+func (d *disabledBuiltins) Visit(node *ast.Node) {
+	if b, ok := (*node).(*ast.BuiltinNode); ok && d.name == "" && slices.Contains(ifDisabledBuiltins, b.Name) {
+		d.name = b.Name
+	}
+}
+
 // ShouldRun evaluates if expressions using the expr engine
 //
-// Provides built-in functions: failure(), always(), cancelled(), input("name"), from("step-id", "key")
+// Provides built-in functions: failure(), always(), cancelled(), input("name"), from("step-id", "key"),
+// fromJSON(value), fromYAML(value) and toJSON(value), along with the outputs of every previous step as steps
+//
+// Expressions are limited in size, memory and time, and cannot use the repeat() or reduce() builtins.
 //
 // Returns false for failed steps when no expression is provided
 func ShouldRun(ctx context.Context, expression string, err error, with schema.With, previousOutputs CommandOutputs, dry bool) (bool, error) {
@@ -85,24 +117,76 @@ func ShouldRun(ctx context.Context, expression string, err error, with schema.Wi
 		new(func(string, string) any),
 	)
 
+	fromJSONFunc := expr.Function(
+		"fromJSON",
+		func(params ...any) (any, error) {
+			return fromJSON(params[0])
+		},
+		new(func(any) any),
+	)
+
+	fromYAMLFunc := expr.Function(
+		"fromYAML",
+		func(params ...any) (any, error) {
+			return fromYAML(params[0])
+		},
+		new(func(any) any),
+	)
+
+	toJSONFunc := expr.Function(
+		"toJSON",
+		func(params ...any) (any, error) {
+			return toJSON(params[0])
+		},
+		new(func(any) string),
+	)
+
 	// mirrors TemplateString presets
 	type env struct {
-		OS       string `expr:"os"`
-		Arch     string `expr:"arch"`
-		Platform string `expr:"platform"`
+		OS       string         `expr:"os"`
+		Arch     string         `expr:"arch"`
+		Platform string         `expr:"platform"`
+		Steps    CommandOutputs `expr:"steps"`
 	}
 
-	program, err := expr.Compile(expression, expr.Env(env{}), expr.AsBool(), failure, cancelled, always, inputFunc, fromFunc)
+	program, err := expr.Compile(expression, expr.Env(env{}), expr.AsBool(), expr.MaxNodes(ifMaxNodes), failure, cancelled, always, inputFunc, fromFunc, fromJSONFunc, fromYAMLFunc, toJSONFunc)
 	if err != nil {
 		return false, err
 	}
 
-	out, err := expr.Run(
-		program,
-		env{OS: runtime.GOOS, Arch: runtime.GOARCH, Platform: fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH)},
-	)
-	if err != nil {
-		return false, err
+	// reduce() is a parser level builtin and cannot be removed with expr.DisableBuiltin, so reject disabled builtins from the tree
+	disabled := &disabledBuiltins{}
+	root := program.Node()
+	ast.Walk(&root, disabled)
+	if disabled.name != "" {
+		return false, fmt.Errorf("%s() is not allowed in if expressions", disabled.name)
+	}
+
+	type result struct {
+		out any
+		err error
+	}
+	done := make(chan result, 1)
+
+	// the vm cannot be interrupted, if the timeout is reached the evaluation is abandoned, bounded by the memory budget
+	go func() {
+		machine := vm.VM{MemoryBudget: ifMemoryBudget}
+		out, err := machine.Run(
+			program,
+			env{OS: runtime.GOOS, Arch: runtime.GOARCH, Platform: fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH), Steps: previousOutputs},
+		)
+		done <- result{out, err}
+	}()
+
+	var out any
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return false, r.err
+		}
+		out = r.out
+	case <-time.After(ifTimeout):
+		return false, fmt.Errorf("expression did not complete within %s", ifTimeout)
 	}
 
 	if alwaysTriggered { // always short circuits any other logic
@@ -116,6 +200,8 @@ func ShouldRun(ctx context.Context, expression string, err error, with schema.Wi
 
 	if dry && !val {
 		log.FromContext(ctx).Warnf("step would be skipped (condition '%s' is false) but executing anyway in dry-run mode", expression)
+		// the step is still planned, so its script or with can be reviewed
+		planFromContext(ctx).skipStep(planStep(ctx), fmt.Sprintf("condition '%s' is false", expression))
 		return true, nil
 	}
 
@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"testing"
 
 	"github.com/charmbracelet/log"
@@ -239,6 +240,67 @@ func TestIf(t *testing.T) {
 			previousOutputs: CommandOutputs{"step": map[string]any{"key": "value"}},
 			expected:        true,
 		},
+		{
+			name:        "expression exceeding the memory budget",
+			inputExpr:   `len(1..1000000) > 0`,
+			expectedErr: "memory budget exceeded (1:6)\n | len(1..1000000) > 0\n | .....^",
+		},
+		{
+			name:        "nested loops exceeding the memory budget",
+			inputExpr:   `all(1..1000, {all(1..1000, {all(1..1000, {true})})})`,
+			expectedErr: "memory budget exceeded (1:34)\n | all(1..1000, {all(1..1000, {all(1..1000, {true})})})\n | .................................^",
+		},
+		{
+			name:        "repeat is disabled",
+			inputExpr:   `len(repeat("a", 1000000000)) > 0`,
+			expectedErr: "repeat() is not allowed in if expressions",
+		},
+		{
+			name:        "reduce is disabled",
+			inputExpr:   `len(reduce(1..100, #acc + #acc, "a")) > 0`,
+			expectedErr: "reduce() is not allowed in if expressions",
+		},
+		{
+			name:            "fromJSON of a step output",
+			inputExpr:       `fromJSON(from("status", "json")).ready && len(fromJSON(from("status", "json")).nodes) == 2`,
+			previousOutputs: CommandOutputs{"status": map[string]any{"json": `{"ready": true, "nodes": ["a", "b"]}`}},
+			expected:        true,
+		},
+		{
+			name:            "fromYAML of a step output",
+			inputExpr:       `fromYAML(from("status", "yaml")).phase == "Running"`,
+			previousOutputs: CommandOutputs{"status": map[string]any{"yaml": "phase: Running\nreplicas: 3\n"}},
+			expected:        true,
+		},
+		{
+			name:      "toJSON of an input",
+			inputExpr: `toJSON(input("tags")) == '["a","b"]'`,
+			with:      schema.With{"tags": []any{"a", "b"}},
+			expected:  true,
+		},
+		{
+			name:      "fromJSON of a missing output",
+			inputExpr: `fromJSON(from("status", "json"))?.ready == nil`,
+			dry:       true,
+			expected:  true,
+		},
+		{
+			name:            "fromJSON of invalid JSON",
+			inputExpr:       `fromJSON(from("status", "json")).ready`,
+			previousOutputs: CommandOutputs{"status": map[string]any{"json": "not json"}},
+			expectedErr:     "fromJSON: invalid character 'o' in literal null (expecting 'u') (1:1)\n | fromJSON(from(\"status\", \"json\")).ready\n | ^",
+		},
+		{
+			name:            "steps exposes every output",
+			inputExpr:       `steps.build.version == "1.0" && len(steps) == 2 && "ready" in keys(steps.status)`,
+			previousOutputs: CommandOutputs{"build": map[string]any{"version": "1.0"}, "status": map[string]any{"ready": "true"}},
+			expected:        true,
+		},
+		{
+			name:      "steps of a missing step",
+			inputExpr: `steps?.build?.version == nil`,
+			expected:  true,
+		},
 		{
 			name:      "nil context with cancelled function",
 			inputExpr: "cancelled()",
@@ -265,3 +327,13 @@ func TestIf(t *testing.T) {
 		})
 	}
 }
+
+func TestIfMaxNodes(t *testing.T) {
+	ctx := log.WithContext(t.Context(), log.New(io.Discard))
+
+	_, err := ShouldRun(ctx, strings.Repeat("true and ", ifMaxNodes/4)+"true", nil, nil, nil, false)
+	require.NoError(t, err)
+
+	_, err = ShouldRun(ctx, strings.Repeat("true and ", ifMaxNodes)+"true", nil, nil, nil, false)
+	require.ErrorContains(t, err, "compilation failed: expression exceeds maximum allowed nodes")
+}
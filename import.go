@@ -0,0 +1,504 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/spf13/cast"
+
+	"github.com/defenseunicorns/maru2/schema"
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+)
+
+var (
+	makeAssignmentPattern = regexp.MustCompile(`^(?:export\s+|override\s+)?([A-Za-z_][A-Za-z0-9_]*)\s*(\?=|::=|:=|\+=|!=|=)\s*(.*)$`)
+	makeRulePattern       = regexp.MustCompile(`^([^:=#\t][^:=#]*?)\s*::?(?:[^=]|$)`)
+	makeVariablePattern   = regexp.MustCompile(`\$[({]([A-Za-z_][A-Za-z0-9_]*)[)}]`)
+	makeShellPattern      = regexp.MustCompile(`\$[({]shell\s+([^)}]*)[)}]`)
+	taskfileVarPattern    = regexp.MustCompile(`{{\s*\.([A-Za-z_][A-Za-z0-9_]*)\s*}}`)
+	invalidTaskNameChars  = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+)
+
+type makeRule struct {
+	targets     []string
+	prereqs     []string
+	recipe      []string
+	description string
+}
+
+// ImportMakefile converts a Makefile into a maru2 workflow
+//
+// Targets become tasks, prerequisites become `uses` steps and recipes become a single `run` step.
+// Variables referenced within a recipe are converted into task inputs, using their Makefile value as the default.
+//
+// Conditionals, functions other than $(shell ...), pattern rules and special targets are not evaluated
+func ImportMakefile(r io.Reader) (v1.Workflow, error) {
+	scanner := bufio.NewScanner(r)
+
+	vars := map[string]v1.InputParameter{}
+	rules := []*makeRule{}
+	var current *makeRule
+	var defaultGoal string
+	var inDefine bool
+
+	var pending strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// line continuations, recipes keep them as-is for the shell while make joins everything else
+		if pending.Len() > 0 {
+			if strings.HasPrefix(pending.String(), "\t") {
+				line = strings.TrimPrefix(line, "\t")
+			} else {
+				line = strings.TrimLeft(line, " \t")
+			}
+		}
+		if strings.HasSuffix(line, `\`) {
+			if strings.HasPrefix(pending.String(), "\t") || (pending.Len() == 0 && strings.HasPrefix(line, "\t")) {
+				pending.WriteString(line + "\n")
+			} else {
+				pending.WriteString(strings.TrimSuffix(line, `\`) + " ")
+			}
+			continue
+		}
+		if pending.Len() > 0 {
+			pending.WriteString(line)
+			line = pending.String()
+			pending.Reset()
+		}
+
+		trimmed := strings.TrimSpace(line)
+
+		if inDefine {
+			if trimmed == "endef" {
+				inDefine = false
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "\t") {
+			if current != nil {
+				current.recipe = append(current.recipe, strings.TrimPrefix(line, "\t"))
+			}
+			continue
+		}
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		directive, _, _ := strings.Cut(trimmed, " ")
+		switch directive {
+		case "define":
+			inDefine = true
+			continue
+		case "ifeq", "ifneq", "ifdef", "ifndef", "else", "endif", "include", "-include", "sinclude", "vpath", "unexport":
+			continue
+		}
+
+		if m := makeAssignmentPattern.FindStringSubmatch(trimmed); m != nil {
+			current = nil
+			name, op, value := m[1], m[2], strings.TrimSpace(m[3])
+			if comment := strings.Index(value, " #"); comment != -1 {
+				value = strings.TrimSpace(value[:comment])
+			}
+			if name == ".DEFAULT_GOAL" {
+				defaultGoal = value
+				continue
+			}
+			switch op {
+			case "!=":
+				// shell assignments cannot be evaluated ahead of time
+				continue
+			case "+=":
+				existing := vars[name]
+				existing.Default = strings.TrimSpace(cast.ToString(existing.Default) + " " + value)
+				vars[name] = existing
+			case "?=":
+				vars[name] = v1.InputParameter{
+					Description:    fmt.Sprintf("Imported from Makefile variable %s", name),
+					Default:        value,
+					DefaultFromEnv: name,
+				}
+			default:
+				vars[name] = v1.InputParameter{
+					Description: fmt.Sprintf("Imported from Makefile variable %s", name),
+					Default:     value,
+				}
+			}
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, ".DEFAULT_GOAL") {
+			_, value, _ := strings.Cut(trimmed, "=")
+			defaultGoal = strings.TrimSpace(value)
+			continue
+		}
+
+		if m := makeRulePattern.FindStringSubmatch(line); m != nil {
+			rest := strings.TrimSpace(line[strings.Index(line, ":")+1:])
+			rest = strings.TrimPrefix(rest, ":")
+
+			rule := &makeRule{targets: strings.Fields(m[1])}
+
+			if before, after, ok := strings.Cut(rest, "##"); ok {
+				rule.description = strings.TrimSpace(after)
+				rest = before
+			}
+			if before, after, ok := strings.Cut(rest, ";"); ok {
+				rest = before
+				if recipe := strings.TrimSpace(after); recipe != "" {
+					rule.recipe = append(rule.recipe, recipe)
+				}
+			}
+			if before, _, ok := strings.Cut(rest, "#"); ok {
+				rest = before
+			}
+			if before, _, ok := strings.Cut(rest, "|"); ok {
+				rest = before // order-only prerequisites are treated the same as normal ones
+			}
+			rule.prereqs = strings.Fields(rest)
+
+			rules = append(rules, rule)
+			current = rule
+			continue
+		}
+
+		current = nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return v1.Workflow{}, err
+	}
+
+	isImportable := func(target string) bool {
+		return !strings.HasPrefix(target, ".") && !strings.Contains(target, "%") && !strings.Contains(target, "$")
+	}
+
+	names := map[string]string{}
+	order := []string{}
+	for _, rule := range rules {
+		for _, target := range rule.targets {
+			if !isImportable(target) {
+				continue
+			}
+			if _, ok := names[target]; !ok {
+				names[target] = sanitizeTaskName(target)
+				order = append(order, target)
+			}
+		}
+	}
+
+	wf := v1.Workflow{
+		SchemaVersion: v1.SchemaVersion,
+		Tasks:         v1.TaskMap{},
+	}
+
+	for _, rule := range rules {
+		for _, target := range rule.targets {
+			if !isImportable(target) {
+				continue
+			}
+
+			name := names[target]
+			task := wf.Tasks[name]
+			if rule.description != "" {
+				task.Description = rule.description
+			}
+
+			for _, prereq := range rule.prereqs {
+				dep, ok := names[prereq]
+				if !ok || dep == name {
+					continue // files or other non-task prerequisites
+				}
+				task.Steps = append(task.Steps, v1.Step{Uses: dep})
+			}
+
+			if len(rule.recipe) > 0 {
+				lines := make([]string, 0, len(rule.recipe))
+				for _, line := range rule.recipe {
+					lines = append(lines, convertMakeRecipeLine(line, target, rule.prereqs, vars, &task))
+				}
+				task.Steps = append(task.Steps, v1.Step{Run: strings.Join(lines, "\n")})
+			}
+
+			wf.Tasks[name] = task
+		}
+	}
+
+	// prune targets that do nothing (e.g. file targets) along with any references to them
+	for pruned := true; pruned; {
+		pruned = false
+		for name, task := range wf.Tasks {
+			if len(task.Steps) == 0 {
+				delete(wf.Tasks, name)
+				pruned = true
+			}
+		}
+		for name, task := range wf.Tasks {
+			task.Steps = slices.DeleteFunc(task.Steps, func(step v1.Step) bool {
+				_, ok := wf.Tasks[step.Uses]
+				return step.Uses != "" && !ok
+			})
+			wf.Tasks[name] = task
+		}
+	}
+
+	if len(wf.Tasks) == 0 {
+		return v1.Workflow{}, fmt.Errorf("no targets with recipes or prerequisites were found")
+	}
+
+	if _, ok := wf.Tasks[schema.DefaultTaskName]; !ok {
+		if defaultGoal == "" {
+			for _, target := range order {
+				if _, ok := wf.Tasks[names[target]]; ok {
+					defaultGoal = target
+					break
+				}
+			}
+		}
+		if goal, ok := names[defaultGoal]; ok {
+			if _, exists := wf.Tasks[goal]; exists {
+				wf.Tasks[schema.DefaultTaskName] = v1.Task{
+					Steps: []v1.Step{{Uses: goal}},
+				}
+			}
+		}
+	}
+
+	return wf, v1.Validate(wf)
+}
+
+// convertMakeRecipeLine translates a single recipe line from make syntax into a maru2 script line
+func convertMakeRecipeLine(line, target string, prereqs []string, vars map[string]v1.InputParameter, task *v1.Task) string {
+	ignoreErrors := false
+	for len(line) > 0 && strings.ContainsRune("@-+", rune(line[0])) {
+		if line[0] == '-' {
+			ignoreErrors = true
+		}
+		line = line[1:]
+	}
+
+	// protect escaped dollar signs from the variable substitutions below
+	const escaped = "\x00"
+	line = strings.ReplaceAll(line, "$$", escaped)
+
+	line = makeShellPattern.ReplaceAllString(line, escaped+"($1)")
+
+	first := ""
+	if len(prereqs) > 0 {
+		first = prereqs[0]
+	}
+	line = strings.NewReplacer(
+		"$@", target,
+		"$<", first,
+		"$^", strings.Join(prereqs, " "),
+		"$(MAKE)", "make",
+		"${MAKE}", "make",
+	).Replace(line)
+
+	line = makeVariablePattern.ReplaceAllStringFunc(line, func(match string) string {
+		name := makeVariablePattern.FindStringSubmatch(match)[1]
+		param, ok := vars[name]
+		if !ok {
+			// make falls back to environment variables for undefined variables
+			return "${" + name + "}"
+		}
+		input := toInputName(name)
+		if task.Inputs == nil {
+			task.Inputs = v1.InputMap{}
+		}
+		task.Inputs[input] = param
+		return fmt.Sprintf(`${{ input "%s" }}`, input)
+	})
+
+	line = strings.ReplaceAll(line, escaped, "$")
+
+	if ignoreErrors {
+		line += " || true"
+	}
+
+	return line
+}
+
+type taskfile struct {
+	Vars  map[string]any          `json:"vars"`
+	Env   map[string]any          `json:"env"`
+	Tasks map[string]taskfileTask `json:"tasks"`
+}
+
+type taskfileTask struct {
+	Desc    string         `json:"desc"`
+	Summary string         `json:"summary"`
+	Deps    []any          `json:"deps"`
+	Cmds    []any          `json:"cmds"`
+	Vars    map[string]any `json:"vars"`
+	Env     map[string]any `json:"env"`
+	Dir     string         `json:"dir"`
+}
+
+// ImportTaskfile converts a Taskfile (https://taskfile.dev) into a maru2 workflow
+//
+// Tasks and their descriptions are mapped 1:1, dependencies and `task:` commands become `uses` steps,
+// and variables referenced within commands are converted into task inputs.
+//
+// Dynamic (sh:) variables, includes and task-specific features like sources/generates are not evaluated
+func ImportTaskfile(r io.Reader) (v1.Workflow, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return v1.Workflow{}, err
+	}
+
+	var tf taskfile
+	if err := yaml.Unmarshal(data, &tf); err != nil {
+		return v1.Workflow{}, err
+	}
+
+	if len(tf.Tasks) == 0 {
+		return v1.Workflow{}, fmt.Errorf("no tasks were found")
+	}
+
+	wf := v1.Workflow{
+		SchemaVersion: v1.SchemaVersion,
+		Tasks:         make(v1.TaskMap, len(tf.Tasks)),
+	}
+
+	names := make(map[string]string, len(tf.Tasks))
+	for name := range tf.Tasks {
+		names[name] = sanitizeTaskName(name)
+	}
+
+	taskRef := func(ref string) string {
+		if name, ok := names[ref]; ok {
+			return name
+		}
+		return sanitizeTaskName(ref)
+	}
+
+	for name, t := range tf.Tasks {
+		task := v1.Task{
+			Description: t.Desc,
+		}
+		if task.Description == "" {
+			task.Description = strings.TrimSpace(t.Summary)
+		}
+
+		vars := make(map[string]any, len(tf.Vars)+len(t.Vars))
+		for k, v := range tf.Vars {
+			vars[k] = v
+		}
+		for k, v := range t.Vars {
+			vars[k] = v
+		}
+
+		env := schema.Env{}
+		for k, v := range tf.Env {
+			if _, ok := v.(map[string]any); !ok {
+				env[k] = v
+			}
+		}
+		for k, v := range t.Env {
+			if _, ok := v.(map[string]any); !ok {
+				env[k] = v
+			}
+		}
+		if len(env) == 0 {
+			env = nil
+		}
+
+		convert := func(s string) string {
+			return taskfileVarPattern.ReplaceAllStringFunc(s, func(match string) string {
+				varName := taskfileVarPattern.FindStringSubmatch(match)[1]
+				input := toInputName(varName)
+				if task.Inputs == nil {
+					task.Inputs = v1.InputMap{}
+				}
+				param := v1.InputParameter{
+					Description: fmt.Sprintf("Imported from Taskfile variable %s", varName),
+				}
+				switch v := vars[varName].(type) {
+				case nil, map[string]any:
+					// unset or dynamic variables must be provided at runtime
+				default:
+					param.Default = cast.ToString(v)
+				}
+				task.Inputs[input] = param
+				return fmt.Sprintf(`${{ input "%s" }}`, input)
+			})
+		}
+
+		usesStep := func(ref string, with map[string]any) v1.Step {
+			step := v1.Step{Uses: taskRef(ref)}
+			if len(with) > 0 {
+				step.With = make(schema.With, len(with))
+				for k, v := range with {
+					if s, ok := v.(string); ok {
+						v = convert(s)
+					}
+					step.With[toInputName(k)] = v
+				}
+			}
+			return step
+		}
+
+		for _, dep := range t.Deps {
+			switch d := dep.(type) {
+			case string:
+				task.Steps = append(task.Steps, usesStep(d, nil))
+			case map[string]any:
+				if ref, ok := d["task"].(string); ok {
+					with, _ := d["vars"].(map[string]any)
+					task.Steps = append(task.Steps, usesStep(ref, with))
+				}
+			}
+		}
+
+		for _, cmd := range t.Cmds {
+			switch c := cmd.(type) {
+			case string:
+				task.Steps = append(task.Steps, v1.Step{Run: convert(c), Env: env, Dir: t.Dir})
+			case map[string]any:
+				if ref, ok := c["task"].(string); ok {
+					with, _ := c["vars"].(map[string]any)
+					task.Steps = append(task.Steps, usesStep(ref, with))
+					continue
+				}
+				if run, ok := c["cmd"].(string); ok {
+					step := v1.Step{Run: convert(run), Env: env, Dir: t.Dir}
+					if ignore, ok := c["ignore_error"].(bool); ok && ignore {
+						step.Run += " || true"
+					}
+					task.Steps = append(task.Steps, step)
+				}
+			}
+		}
+
+		wf.Tasks[names[name]] = task
+	}
+
+	return wf, v1.Validate(wf)
+}
+
+// toInputName converts environment/make style variable names into input names (e.g., "MY_VAR" -> "my-var")
+//
+// This is the inverse of toEnvVar
+func toInputName(s string) string {
+	return strings.ToLower(strings.ReplaceAll(s, "_", "-"))
+}
+
+// sanitizeTaskName replaces any characters that are not allowed in task names with "-"
+func sanitizeTaskName(s string) string {
+	name := invalidTaskNameChars.ReplaceAllString(s, "-")
+	if name == "" || !v1.TaskNamePattern.MatchString(name) {
+		name = "_" + name
+	}
+	return name
+}
@@ -0,0 +1,260 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+)
+
+func TestImportMakefile(t *testing.T) {
+	tests := []struct {
+		name        string
+		makefile    string
+		expected    v1.Workflow
+		expectedErr string
+	}{
+		{
+			name: "simple targets",
+			makefile: `.PHONY: build test
+
+all: build test ## Build and test
+
+build: ## Build the binary
+	@go build -o bin/app .
+
+test:
+	go test ./...
+`,
+			expected: v1.Workflow{
+				SchemaVersion: v1.SchemaVersion,
+				Tasks: v1.TaskMap{
+					"default": v1.Task{Steps: []v1.Step{{Uses: "all"}}},
+					"all": v1.Task{
+						Description: "Build and test",
+						Steps:       []v1.Step{{Uses: "build"}, {Uses: "test"}},
+					},
+					"build": v1.Task{
+						Description: "Build the binary",
+						Steps:       []v1.Step{{Run: "go build -o bin/app ."}},
+					},
+					"test": v1.Task{
+						Steps: []v1.Step{{Run: "go test ./..."}},
+					},
+				},
+			},
+		},
+		{
+			name: "variables become inputs",
+			makefile: `GO_FLAGS ?= -v
+BIN := bin/app
+UNUSED = foo
+
+build:
+	go build $(GO_FLAGS) -o ${BIN} .
+	echo $(HOME)
+`,
+			expected: v1.Workflow{
+				SchemaVersion: v1.SchemaVersion,
+				Tasks: v1.TaskMap{
+					"default": v1.Task{Steps: []v1.Step{{Uses: "build"}}},
+					"build": v1.Task{
+						Inputs: v1.InputMap{
+							"go-flags": v1.InputParameter{
+								Description:    "Imported from Makefile variable GO_FLAGS",
+								Default:        "-v",
+								DefaultFromEnv: "GO_FLAGS",
+							},
+							"bin": v1.InputParameter{
+								Description: "Imported from Makefile variable BIN",
+								Default:     "bin/app",
+							},
+						},
+						Steps: []v1.Step{{Run: "go build ${{ input \"go-flags\" }} -o ${{ input \"bin\" }} .\necho ${HOME}"}},
+					},
+				},
+			},
+		},
+		{
+			name: "recipe prefixes, automatic variables and continuations",
+			makefile: `clean: dist
+	-rm -rf $@ $<
+	+echo $$HOME \
+	  done
+	echo $(shell date)
+`,
+			expected: v1.Workflow{
+				SchemaVersion: v1.SchemaVersion,
+				Tasks: v1.TaskMap{
+					"default": v1.Task{Steps: []v1.Step{{Uses: "clean"}}},
+					"clean": v1.Task{
+						Steps: []v1.Step{{Run: "rm -rf clean dist || true\necho $HOME \\\n  done\necho $(date)"}},
+					},
+				},
+			},
+		},
+		{
+			name: "default goal, sanitized names and skipped targets",
+			makefile: `.DEFAULT_GOAL := lint
+%.o: %.c
+	cc -c $<
+
+docker/build: ; docker build .
+
+lint: docker/build
+	golangci-lint run
+
+README.md:
+`,
+			expected: v1.Workflow{
+				SchemaVersion: v1.SchemaVersion,
+				Tasks: v1.TaskMap{
+					"default": v1.Task{Steps: []v1.Step{{Uses: "lint"}}},
+					"docker-build": v1.Task{
+						Steps: []v1.Step{{Run: "docker build ."}},
+					},
+					"lint": v1.Task{
+						Steps: []v1.Step{{Uses: "docker-build"}, {Run: "golangci-lint run"}},
+					},
+				},
+			},
+		},
+		{
+			name: "existing default target",
+			makefile: `build:
+	go build
+
+default: build
+`,
+			expected: v1.Workflow{
+				SchemaVersion: v1.SchemaVersion,
+				Tasks: v1.TaskMap{
+					"default": v1.Task{Steps: []v1.Step{{Uses: "build"}}},
+					"build":   v1.Task{Steps: []v1.Step{{Run: "go build"}}},
+				},
+			},
+		},
+		{
+			name:        "no targets",
+			makefile:    "FOO = bar\n",
+			expectedErr: "no targets with recipes or prerequisites were found",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			wf, err := ImportMakefile(strings.NewReader(tc.makefile))
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, wf)
+		})
+	}
+}
+
+func TestImportTaskfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		taskfile    string
+		expected    v1.Workflow
+		expectedErr string
+	}{
+		{
+			name: "tasks, deps and vars",
+			taskfile: `version: '3'
+vars:
+  GREETING: hello
+tasks:
+  default:
+    deps: [greet]
+  greet:
+    desc: Say hi
+    env:
+      FOO: bar
+    cmds:
+      - echo {{.GREETING}} {{ .NAME }}
+      - task: lint:go
+        vars: {LEVEL: high}
+  lint:go:
+    summary: |
+      Lint go code
+    dir: src
+    cmds:
+      - cmd: golangci-lint run --level {{.LEVEL}}
+        ignore_error: true
+`,
+			expected: v1.Workflow{
+				SchemaVersion: v1.SchemaVersion,
+				Tasks: v1.TaskMap{
+					"default": v1.Task{Steps: []v1.Step{{Uses: "greet"}}},
+					"greet": v1.Task{
+						Description: "Say hi",
+						Inputs: v1.InputMap{
+							"greeting": v1.InputParameter{
+								Description: "Imported from Taskfile variable GREETING",
+								Default:     "hello",
+							},
+							"name": v1.InputParameter{
+								Description: "Imported from Taskfile variable NAME",
+							},
+						},
+						Steps: []v1.Step{
+							{Run: `echo ${{ input "greeting" }} ${{ input "name" }}`, Env: map[string]any{"FOO": "bar"}},
+							{Uses: "lint-go", With: map[string]any{"level": "high"}},
+						},
+					},
+					"lint-go": v1.Task{
+						Description: "Lint go code",
+						Inputs: v1.InputMap{
+							"level": v1.InputParameter{
+								Description: "Imported from Taskfile variable LEVEL",
+							},
+						},
+						Steps: []v1.Step{
+							{Run: `golangci-lint run --level ${{ input "level" }} || true`, Dir: "src"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "unknown dependency",
+			taskfile: `version: '3'
+tasks:
+  default:
+    deps: [missing]
+`,
+			expectedErr: `.tasks.default[0].uses "missing" not found`,
+		},
+		{
+			name:        "no tasks",
+			taskfile:    "version: '3'\n",
+			expectedErr: "no tasks were found",
+		},
+		{
+			name:        "invalid yaml",
+			taskfile:    "tasks: [",
+			expectedErr: "[1:8] sequence end token ']' not found\n>  1 | tasks: [\n              ^\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			wf, err := ImportTaskfile(strings.NewReader(tc.taskfile))
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, wf)
+		})
+	}
+}
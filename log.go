@@ -4,6 +4,7 @@
 package maru2
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"io"
@@ -77,6 +78,21 @@ var (
 	}
 )
 
+type structuredLogsKey struct{}
+
+// WithStructuredLogs returns a context that logs through logger with task and step fields attached to every line logged within a step
+//
+// Scripts and builtins are logged without highlighting, so the output of a machine readable formatter (e.g. log.JSONFormatter) stays clean
+func WithStructuredLogs(ctx context.Context, logger *log.Logger) context.Context {
+	return context.WithValue(ctx, structuredLogsKey{}, logger)
+}
+
+// structuredLogger returns the logger set by WithStructuredLogs, or nil if logs are not structured
+func structuredLogger(ctx context.Context) *log.Logger {
+	logger, _ := ctx.Value(structuredLogsKey{}).(*log.Logger)
+	return logger
+}
+
 // TaskList is a prettier way to display a workflow's entrypoints from a CLI's perspective
 type TaskList struct {
 	col0max int
@@ -89,22 +105,31 @@ type TaskList struct {
 func NewDetailedTaskList(ctx context.Context, svc *uses.FetcherService, origin *url.URL, wf v1.Workflow) (*TaskList, error) {
 	t := &TaskList{}
 	for name, task := range wf.Tasks.OrderedSeq() {
+		// an alias is listed with the inputs of the task it is an alias of
+		target, _ := wf.Tasks.Find(name)
+
 		var comment string
-		if desc := task.Description; desc != "" {
+		if desc := cmp.Or(task.Description, target.Description); desc != "" {
 			comment = "# " + desc
 		}
+		if msg := cmp.Or(task.DeprecatedMessage, target.DeprecatedMessage); msg != "" {
+			comment = strings.TrimSpace(comment + " " + lipgloss.NewStyle().Foreground(WarnColor).Render("(deprecated: "+msg+")"))
+		}
 
 		msg := strings.Builder{}
 		msg.WriteString(name)
+		if task.AliasOf != "" {
+			msg.WriteString(lipgloss.NewStyle().Faint(true).Render(" → " + task.AliasOf))
+		}
 
-		renderInputMap(&msg, task.Inputs)
+		renderInputMap(&msg, target.Inputs)
 
 		t.Row(msg.String(), comment)
 	}
 
 	for name, alias := range wf.Aliases.OrderedSeq() {
 		if alias.Path != "" {
-			next, err := uses.ResolveRelative(origin, strings.Join([]string{"file", alias.Path}, ":"), wf.Aliases)
+			next, err := svc.ResolveRelative(origin, strings.Join([]string{"file", alias.Path}, ":"), wf.Aliases)
 			if err != nil {
 				return nil, err
 			}
@@ -205,15 +230,15 @@ func renderInputMap(w *strings.Builder, inputs v1.InputMap) {
 // printScript renders shell script content with syntax highlighting
 //
 // Uses chroma for syntax highlighting with adaptive color schemes (light/dark theme support)
-// Falls back to plain text output when NO_COLOR is set or highlighting fails
-func printScript(logger *log.Logger, lang, script string) {
+// Falls back to plain text output when NO_COLOR is set, highlight is false or highlighting fails
+func printScript(logger *log.Logger, lang, script string, highlight bool) {
 	if logger.GetLevel() > log.InfoLevel {
 		return
 	}
 
 	script = strings.TrimSpace(script)
 
-	if termenv.EnvNoColor() {
+	if termenv.EnvNoColor() || !highlight {
 		// this is essentially the same behavior/rendering as make
 		logger.Print(script)
 		return
@@ -249,7 +274,7 @@ func printScript(logger *log.Logger, lang, script string) {
 //
 // Marshals the builtin With map as YAML and applies syntax highlighting for better readability
 // Used in dry-run mode to preview builtin task execution without running commands
-func printBuiltin(logger *log.Logger, builtin schema.With) {
+func printBuiltin(logger *log.Logger, builtin schema.With, highlight bool) {
 	if logger.GetLevel() > log.InfoLevel {
 		return
 	}
@@ -260,7 +285,7 @@ func printBuiltin(logger *log.Logger, builtin schema.With) {
 		return
 	}
 
-	if termenv.EnvNoColor() {
+	if termenv.EnvNoColor() || !highlight {
 		logger.Printf("%s", strings.TrimSpace(string(b)))
 		return
 	}
@@ -133,7 +133,7 @@ func TestPrintScript(t *testing.T) {
 			var buf strings.Builder
 			logger := log.New(&buf)
 			logger.SetLevel(tc.logLevel)
-			printScript(logger, "", tc.script)
+			printScript(logger, "", tc.script, true)
 			assert.Equal(t, tc.expected, buf.String(), "this test fails when run w/ `go test`, run w/ `make test` instead as that will use maru2, which uses a true shell env")
 		})
 	}
@@ -146,7 +146,7 @@ func TestPrintScript(t *testing.T) {
 	lexers.Register(&errLexer{name: "shell"}) // overrides shell lexer
 
 	var buf strings.Builder
-	printScript(log.New(&buf), "", "echo hello")
+	printScript(log.New(&buf), "", "echo hello", true)
 	assert.Equal(t, "  echo hello\n", buf.String())
 }
 
@@ -242,7 +242,7 @@ func TestPrintBuiltin(t *testing.T) {
 			var buf strings.Builder
 			logger := log.New(&buf)
 			logger.SetLevel(tc.logLevel)
-			printBuiltin(logger, tc.builtin)
+			printBuiltin(logger, tc.builtin, true)
 			assert.Equal(t, tc.expected, buf.String())
 		})
 	}
@@ -255,7 +255,7 @@ func TestPrintBuiltin(t *testing.T) {
 	lexers.Register(&errLexer{name: "yaml"}) // overrides yaml lexer
 
 	var buf strings.Builder
-	printBuiltin(log.New(&buf), schema.With{"text": "echo hello"})
+	printBuiltin(log.New(&buf), schema.With{"text": "echo hello"}, true)
 	assert.Equal(t, `with:
   text: echo hello
 `, buf.String())
@@ -268,7 +268,7 @@ func TestPrintBuiltinMarshalError(t *testing.T) {
 
 	builtin := schema.With{"func": func() {}}
 
-	printBuiltin(logger, builtin)
+	printBuiltin(logger, builtin, true)
 
 	output := buf.String()
 	assert.Contains(t, output, "failed to marshal builtin")
@@ -949,3 +949,33 @@ func TestRenderInputMap(t *testing.T) {
 		})
 	}
 }
+
+func TestWithStructuredLogs(t *testing.T) {
+	var buf strings.Builder
+	logger := log.NewWithOptions(&buf, log.Options{Formatter: log.JSONFormatter})
+	ctx := log.WithContext(t.Context(), logger)
+	ctx = WithStructuredLogs(ctx, logger)
+
+	assert.Same(t, logger, structuredLogger(ctx))
+	assert.Nil(t, structuredLogger(t.Context()))
+
+	wf := v1.Workflow{
+		Tasks: v1.TaskMap{
+			"default": v1.Task{Steps: []v1.Step{
+				{Uses: "builtin:echo", With: schema.With{"text": "hello"}},
+				{Uses: "other"},
+			}},
+			"other": v1.Task{Steps: []v1.Step{
+				{Run: "echo other"},
+			}},
+		},
+	}
+
+	_, err := Run(ctx, nil, wf, "default", schema.With{}, &url.URL{Scheme: "file", Opaque: "tasks.yaml"}, RuntimeOptions{Stdout: io.Discard, Stderr: io.Discard})
+	require.NoError(t, err)
+
+	// the fields of the calling step are replaced by the called task's
+	assert.Equal(t, `{"msg":"hello","task":"default","step":"default[0]"}
+{"msg":"echo other","task":"other","step":"other[0]"}
+`, buf.String())
+}
@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+type manifestKey struct{}
+
+type manifestStepKey struct{}
+
+// Manifest is an inventory of every workflow fetched and command executed during a run
+//
+// It is safe for concurrent use
+type Manifest struct {
+	RunID     string             `json:"run-id"`
+	DryRun    bool               `json:"dry-run"`
+	Started   time.Time          `json:"started"`
+	Finished  time.Time          `json:"finished,omitzero"`
+	Workflows []ManifestWorkflow `json:"workflows"`
+	Commands  []ManifestCommand  `json:"commands"`
+	Builtins  []ManifestBuiltin  `json:"builtins"`
+
+	mu sync.Mutex
+}
+
+// ManifestStep identifies the step that executed a command or builtin
+type ManifestStep struct {
+	// Location of the workflow the step is defined in
+	From string `json:"from"`
+	Task string `json:"task"`
+	Step int    `json:"step"`
+}
+
+// ManifestWorkflow is a workflow fetched during a run
+type ManifestWorkflow struct {
+	// Resolved location of the workflow
+	URL string `json:"url"`
+	// Digest of the workflow's content (sha256:<hex>)
+	Digest string `json:"digest"`
+}
+
+// ManifestCommand is a command executed by a run step
+type ManifestCommand struct {
+	ManifestStep
+	// Resolved command line, the last argument is the rendered script
+	Args []string `json:"args"`
+	Dir  string   `json:"dir"`
+	// Names of the environment variables set by the step, values are never recorded
+	Env      []string  `json:"env,omitempty"`
+	Started  time.Time `json:"started"`
+	Duration string    `json:"duration"`
+	ExitCode int       `json:"exit-code"`
+}
+
+// ManifestBuiltin is a builtin executed by a uses step
+type ManifestBuiltin struct {
+	ManifestStep
+	Name string `json:"name"`
+}
+
+// NewManifest creates an empty manifest for a run starting now
+func NewManifest(runID string, dry bool) *Manifest {
+	return &Manifest{
+		RunID:     runID,
+		DryRun:    dry,
+		Started:   time.Now().UTC(),
+		Workflows: []ManifestWorkflow{},
+		Commands:  []ManifestCommand{},
+		Builtins:  []ManifestBuiltin{},
+	}
+}
+
+// WithManifest returns a context that records everything fetched and executed into m
+func WithManifest(ctx context.Context, m *Manifest) context.Context {
+	return context.WithValue(ctx, manifestKey{}, m)
+}
+
+// manifestFromContext returns the manifest being recorded to, or nil if there is none
+func manifestFromContext(ctx context.Context) *Manifest {
+	m, _ := ctx.Value(manifestKey{}).(*Manifest)
+	return m
+}
+
+// withManifestStep returns a context identifying the step being run, for recording into the manifest
+func withManifestStep(ctx context.Context, from *url.URL, task string, idx int) context.Context {
+	if manifestFromContext(ctx) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, manifestStepKey{}, ManifestStep{From: from.String(), Task: task, Step: idx})
+}
+
+// manifestStep returns the step being run
+func manifestStep(ctx context.Context) ManifestStep {
+	step, _ := ctx.Value(manifestStepKey{}).(ManifestStep)
+	return step
+}
+
+// recordWorkflow records a fetched workflow, workflows fetched more than once are only recorded once
+func (m *Manifest) recordWorkflow(uri *url.URL, digest string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, wf := range m.Workflows {
+		if wf.URL == uri.String() && wf.Digest == digest {
+			return
+		}
+	}
+	m.Workflows = append(m.Workflows, ManifestWorkflow{URL: uri.String(), Digest: digest})
+}
+
+// recordCommand records an executed command
+func (m *Manifest) recordCommand(cmd ManifestCommand) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Commands = append(m.Commands, cmd)
+}
+
+// recordBuiltin records an executed builtin
+func (m *Manifest) recordBuiltin(builtin ManifestBuiltin) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Builtins = append(m.Builtins, builtin)
+}
+
+// WriteFile marks the run as finished and writes the manifest as JSON to path
+func (m *Manifest) WriteFile(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Finished = time.Now().UTC()
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
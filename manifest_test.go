@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defenseunicorns/maru2/schema"
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+func TestManifest(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "tasks.yaml", []byte(`schema-version: v1
+tasks:
+  default:
+    steps:
+      - run: echo "hello ${{ input "name" }}"
+        env:
+          GREETING: hi
+          COLOR: blue
+      - uses: builtin:echo
+        with:
+          text: hi
+      - uses: file:other.yaml?task=fail
+  unused:
+    steps:
+      - run: echo "never run"
+`), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "other.yaml", []byte(`schema-version: v1
+tasks:
+  fail:
+    steps:
+      - run: exit 3
+`), 0o644))
+
+	svc, err := uses.NewFetcherService(uses.WithFS(fs))
+	require.NoError(t, err)
+
+	m := NewManifest("01ARZ3NDEKTSV4RRFFQ69G5FAV", false)
+	ctx := WithManifest(log.WithContext(t.Context(), log.New(io.Discard)), m)
+
+	origin, err := url.Parse("file:tasks.yaml")
+	require.NoError(t, err)
+
+	wf, err := Fetch(ctx, svc, origin)
+	require.NoError(t, err)
+
+	_, err = Run(ctx, svc, wf, "", schema.With{"name": "world"}, origin, RuntimeOptions{Stdout: io.Discard, Stderr: io.Discard})
+	require.EqualError(t, err, "exit status 3")
+
+	require.Len(t, m.Workflows, 2)
+	assert.Equal(t, "file:tasks.yaml", m.Workflows[0].URL)
+	assert.Equal(t, "file:other.yaml?task=fail", m.Workflows[1].URL)
+	for _, wf := range m.Workflows {
+		assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, wf.Digest)
+	}
+
+	require.Len(t, m.Commands, 2)
+	assert.Equal(t, ManifestStep{From: "file:tasks.yaml", Task: "default", Step: 0}, m.Commands[0].ManifestStep)
+	assert.Equal(t, []string{"sh", "-e", "-c", `echo "hello world"`}, m.Commands[0].Args)
+	assert.Equal(t, []string{"COLOR", "GREETING"}, m.Commands[0].Env)
+	assert.Equal(t, 0, m.Commands[0].ExitCode)
+	assert.Equal(t, ManifestStep{From: "file:other.yaml?task=fail", Task: "fail", Step: 0}, m.Commands[1].ManifestStep)
+	assert.Equal(t, 3, m.Commands[1].ExitCode)
+
+	assert.Equal(t, []ManifestBuiltin{{ManifestStep: ManifestStep{From: "file:tasks.yaml", Task: "default", Step: 1}, Name: "echo"}}, m.Builtins)
+
+	// fetching the same workflow again is only recorded once
+	_, err = Fetch(ctx, svc, origin)
+	require.NoError(t, err)
+	assert.Len(t, m.Workflows, 2)
+
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	require.NoError(t, m.WriteFile(path))
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var written map[string]any
+	require.NoError(t, json.Unmarshal(b, &written))
+	assert.Equal(t, "01ARZ3NDEKTSV4RRFFQ69G5FAV", written["run-id"])
+	assert.Equal(t, false, written["dry-run"])
+	assert.NotEmpty(t, written["finished"])
+	assert.False(t, strings.Contains(string(b), "hi\""), "env values are never recorded")
+}
+
+func TestManifestDryRun(t *testing.T) {
+	m := NewManifest("", true)
+	ctx := WithManifest(log.WithContext(t.Context(), log.New(io.Discard)), m)
+
+	wf, err := v1.Read(strings.NewReader(`schema-version: v1
+tasks:
+  default:
+    steps:
+      - run: echo "hello"
+      - uses: builtin:echo
+        with:
+          text: hi
+`))
+	require.NoError(t, err)
+
+	svc, err := uses.NewFetcherService()
+	require.NoError(t, err)
+
+	_, err = Run(ctx, svc, wf, "", nil, &url.URL{Scheme: "file", Opaque: "tasks.yaml"}, RuntimeOptions{Dry: true, Stdout: io.Discard, Stderr: io.Discard})
+	require.NoError(t, err)
+
+	assert.True(t, m.DryRun)
+	assert.Empty(t, m.Commands)
+	assert.Empty(t, m.Builtins)
+}
+
+func TestManifestNotRecording(t *testing.T) {
+	ctx := t.Context()
+	assert.Nil(t, manifestFromContext(ctx))
+	assert.Equal(t, ctx, withManifestStep(ctx, &url.URL{}, "default", 0))
+	assert.Equal(t, ManifestStep{}, manifestStep(ctx))
+
+	// recording into a nil manifest is a no-op
+	var m *Manifest
+	m.recordWorkflow(&url.URL{}, "")
+	m.recordCommand(ManifestCommand{})
+	m.recordBuiltin(ManifestBuiltin{})
+}
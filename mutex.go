@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+type mutexesKey struct{}
+
+type heldMutexesKey struct{}
+
+// Mutexes acquires named mutexes shared between maru2 processes, see uses.LocalStore.Mutex
+type Mutexes interface {
+	Mutex(ctx context.Context, name string) (func(), error)
+}
+
+// WithMutexes returns a context that acquires the mutexes of tasks and steps from m
+//
+// Without it, mutexes are ignored
+func WithMutexes(ctx context.Context, m Mutexes) context.Context {
+	return context.WithValue(ctx, mutexesKey{}, m)
+}
+
+// acquireMutex acquires the named mutex, returning a context that marks it as held and a function to release it
+//
+// A mutex already held further up the call stack is not acquired again, so a step or called task
+// can share the mutex of its caller
+func acquireMutex(ctx context.Context, name string) (context.Context, func(), error) {
+	noop := func() {}
+	if name == "" {
+		return ctx, noop, nil
+	}
+
+	held, _ := ctx.Value(heldMutexesKey{}).([]string)
+	if slices.Contains(held, name) {
+		return ctx, noop, nil
+	}
+
+	m, ok := ctx.Value(mutexesKey{}).(Mutexes)
+	if !ok {
+		return ctx, noop, nil
+	}
+
+	unlock, err := m.Mutex(ctx, name)
+	if err != nil {
+		return ctx, nil, fmt.Errorf("failed to acquire mutex %q: %w", name, err)
+	}
+
+	return context.WithValue(ctx, heldMutexesKey{}, append(slices.Clone(held), name)), unlock, nil
+}
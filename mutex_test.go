@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+// recordingMutexes records the order mutexes are acquired and released
+type recordingMutexes struct {
+	mu     sync.Mutex
+	events []string
+	err    error
+}
+
+func (r *recordingMutexes) Mutex(_ context.Context, name string) (func(), error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	r.record("lock " + name)
+	return func() { r.record("unlock " + name) }, nil
+}
+
+func (r *recordingMutexes) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func TestAcquireMutex(t *testing.T) {
+	t.Run("no name", func(t *testing.T) {
+		m := &recordingMutexes{}
+		ctx := WithMutexes(t.Context(), m)
+		_, unlock, err := acquireMutex(ctx, "")
+		require.NoError(t, err)
+		unlock()
+		assert.Empty(t, m.events)
+	})
+
+	t.Run("no mutexes", func(t *testing.T) {
+		_, unlock, err := acquireMutex(t.Context(), "deploy")
+		require.NoError(t, err)
+		unlock()
+	})
+
+	t.Run("reentrant", func(t *testing.T) {
+		m := &recordingMutexes{}
+		ctx := WithMutexes(t.Context(), m)
+
+		ctx, unlock, err := acquireMutex(ctx, "deploy")
+		require.NoError(t, err)
+
+		nested, unlockNested, err := acquireMutex(ctx, "deploy")
+		require.NoError(t, err)
+		_, unlockOther, err := acquireMutex(nested, "other")
+		require.NoError(t, err)
+
+		unlockOther()
+		unlockNested()
+		unlock()
+
+		assert.Equal(t, []string{"lock deploy", "lock other", "unlock other", "unlock deploy"}, m.events)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		ctx := WithMutexes(t.Context(), &recordingMutexes{err: errors.New("boom")})
+		_, _, err := acquireMutex(ctx, "deploy")
+		require.EqualError(t, err, `failed to acquire mutex "deploy": boom`)
+	})
+}
+
+func TestRunMutex(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "tasks.yaml", []byte(`schema-version: v1
+tasks:
+  default:
+    mutex: deploy
+    steps:
+      - run: "true"
+        mutex: deploy
+      - run: "true"
+        mutex: migrate
+      - uses: other
+  other:
+    mutex: other
+    steps:
+      - run: "true"
+`), 0o644))
+
+	svc, err := uses.NewFetcherService(uses.WithFS(fs))
+	require.NoError(t, err)
+
+	origin, err := url.Parse("file:tasks.yaml")
+	require.NoError(t, err)
+
+	ctx := log.WithContext(t.Context(), log.New(io.Discard))
+	wf, err := Fetch(ctx, svc, origin)
+	require.NoError(t, err)
+
+	m := &recordingMutexes{}
+	_, err = Run(WithMutexes(ctx, m), svc, wf, "default", nil, origin, RuntimeOptions{Stdout: io.Discard, Stderr: io.Discard})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"lock deploy",
+		"lock migrate",
+		"unlock migrate",
+		"lock other",
+		"unlock other",
+		"unlock deploy",
+	}, m.events)
+
+	// dry runs do not acquire mutexes
+	m = &recordingMutexes{}
+	_, err = Run(WithMutexes(ctx, m), svc, wf, "default", nil, origin, RuntimeOptions{Dry: true, Stdout: io.Discard, Stderr: io.Discard})
+	require.NoError(t, err)
+	assert.Empty(t, m.events)
+}
@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+)
+
+type planKey struct{}
+
+type planStepKey struct{}
+
+// Plan statuses of steps
+const (
+	PlanStatusRun     = "run"
+	PlanStatusSkipped = "skipped"
+)
+
+// Plan is the execution plan of a dry run: every step in the order it would run, whether it would be skipped,
+// and its templated script or with
+//
+// It is safe for concurrent use
+type Plan struct {
+	RunID string      `json:"run-id"`
+	Steps []*PlanStep `json:"steps"`
+
+	mu sync.Mutex
+}
+
+// PlanStep is a step of a dry run, steps of tasks called with uses follow the step that called them
+type PlanStep struct {
+	// Location of the workflow the step is defined in
+	From  string `json:"from"`
+	Task  string `json:"task"`
+	Index int    `json:"index"`
+	ID    string `json:"id,omitempty"`
+	Name  string `json:"name,omitempty"`
+	// Description of the step, as written in the workflow
+	Description string `json:"description,omitempty"`
+	// Whether the step would run or be skipped
+	Status string `json:"status"`
+	// Why the step would be skipped
+	Reason string `json:"reason,omitempty"`
+	// Templated script of a run step, secrets are masked
+	Script string `json:"script,omitempty"`
+	Shell  string `json:"shell,omitempty"`
+	Uses   string `json:"uses,omitempty"`
+	// Templated with of a uses step, secrets are masked
+	With  map[string]any `json:"with,omitempty"`
+	Error string         `json:"error,omitempty"`
+}
+
+// NewPlan creates an empty plan for a dry run
+func NewPlan(runID string) *Plan {
+	return &Plan{
+		RunID: runID,
+		Steps: []*PlanStep{},
+	}
+}
+
+// WithPlan returns a context that records every step of a dry run into p
+func WithPlan(ctx context.Context, p *Plan) context.Context {
+	return context.WithValue(ctx, planKey{}, p)
+}
+
+// planFromContext returns the plan being recorded to, or nil if there is none
+func planFromContext(ctx context.Context) *Plan {
+	p, _ := ctx.Value(planKey{}).(*Plan)
+	return p
+}
+
+// withPlanStep returns a context identifying the planned step being run, for recording its script or with
+func withPlanStep(ctx context.Context, step *PlanStep) context.Context {
+	if step == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, planStepKey{}, step)
+}
+
+// planStep returns the planned step being run, or nil if there is none
+func planStep(ctx context.Context) *PlanStep {
+	step, _ := ctx.Value(planStepKey{}).(*PlanStep)
+	return step
+}
+
+// startStep records a step that would run
+func (p *Plan) startStep(from *url.URL, task string, idx int, step v1.Step) *PlanStep {
+	if p == nil {
+		return nil
+	}
+	s := &PlanStep{
+		From:        from.String(),
+		Task:        task,
+		Index:       idx,
+		ID:          step.ID,
+		Name:        step.Name,
+		Description: step.Description,
+		Status:      PlanStatusRun,
+		Uses:        step.Uses,
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.Steps = append(p.Steps, s)
+	return s
+}
+
+// skipStep records why a step would be skipped
+func (p *Plan) skipStep(step *PlanStep, reason string) {
+	if p == nil || step == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	step.Status = PlanStatusSkipped
+	step.Reason = reason
+}
+
+// recordScript records the templated script of the planned step being run
+func (p *Plan) recordScript(ctx context.Context, shell, script string) {
+	step := planStep(ctx)
+	if p == nil || step == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	step.Shell = shell
+	step.Script = strings.TrimSpace(secretsFromContext(ctx).Mask(script))
+}
+
+// recordWith records the templated with of the planned step being run
+func (p *Plan) recordWith(ctx context.Context, with map[string]any) {
+	step := planStep(ctx)
+	if p == nil || step == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	step.With = maskOutputs(secretsFromContext(ctx), with)
+}
+
+// finishStep records the error of a step that failed to plan, e.g. an invalid template
+func (p *Plan) finishStep(ctx context.Context, step *PlanStep, err error) {
+	if p == nil || step == nil || err == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, step.Error = reportStatus(ctx, err)
+}
+
+// WriteJSON writes the plan as JSON to w
+func (p *Plan) WriteJSON(w io.Writer) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+func TestPlan(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "tasks.yaml", []byte(`schema-version: v1
+tasks:
+  default:
+    inputs:
+      env:
+        description: Environment
+        default: staging
+    steps:
+      - run: echo "deploying to ${{ input "env" }} with ${{ secret "token" }}"
+        id: deploy
+        description: Deploy the app
+      - uses: builtin:echo
+        with:
+          text: hello ${{ input "env" }}
+      - run: echo never
+        if: input("env") == "production"
+      - run: echo filtered
+        id: filtered
+      - uses: other
+        with:
+          target: ${{ input "env" }}
+  other:
+    inputs:
+      target:
+        description: Target
+    steps:
+      - run: echo ${{ input "target" }}
+        shell: bash
+`), 0o644))
+
+	svc, err := uses.NewFetcherService(uses.WithFS(fs))
+	require.NoError(t, err)
+
+	origin, err := url.Parse("file:tasks.yaml")
+	require.NoError(t, err)
+
+	ctx := log.WithContext(t.Context(), log.New(io.Discard))
+	wf, err := Fetch(ctx, svc, origin)
+	require.NoError(t, err)
+
+	p := NewPlan("run-id")
+	ctx = WithPlan(ctx, p)
+	ctx = WithSecrets(ctx, Secrets{"token": "hunter2"})
+	ctx = WithStepFilter(ctx, &StepFilter{Skip: []string{"filtered"}})
+
+	_, err = Run(ctx, svc, wf, "default", nil, origin, RuntimeOptions{Dry: true, Stdout: io.Discard, Stderr: io.Discard})
+	require.NoError(t, err)
+
+	assert.Equal(t, []*PlanStep{
+		{From: "file:tasks.yaml", Task: "default", Index: 0, ID: "deploy", Description: "Deploy the app", Status: PlanStatusRun, Script: `echo "deploying to staging with ❯ secret token ❮"`},
+		{From: "file:tasks.yaml", Task: "default", Index: 1, Status: PlanStatusRun, Uses: "builtin:echo", With: map[string]any{"text": "hello staging"}},
+		{From: "file:tasks.yaml", Task: "default", Index: 2, Status: PlanStatusSkipped, Reason: `condition 'input("env") == "production"' is false`, Script: "echo never"},
+		{From: "file:tasks.yaml", Task: "default", Index: 3, ID: "filtered", Status: PlanStatusSkipped, Reason: "filtered by --skip or --only"},
+		{From: "file:tasks.yaml", Task: "default", Index: 4, Status: PlanStatusRun, Uses: "other", With: map[string]any{"target": "staging"}},
+		{From: "file:tasks.yaml", Task: "other", Index: 0, Status: PlanStatusRun, Script: "echo staging", Shell: "bash"},
+	}, p.Steps)
+
+	var buf bytes.Buffer
+	require.NoError(t, p.WriteJSON(&buf))
+	assert.Contains(t, buf.String(), `"run-id": "run-id"`)
+	assert.NotContains(t, buf.String(), "hunter2")
+}
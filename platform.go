@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/pflag"
+)
+
+type unsupportedPlatformKey struct{}
+
+// UnsupportedPlatform is what Run does with a task whose platforms do not include the platform it runs on
+type UnsupportedPlatform string
+
+const (
+	// UnsupportedPlatformSkip skips the task with a warning, the default
+	UnsupportedPlatformSkip UnsupportedPlatform = "skip"
+	// UnsupportedPlatformError fails the task without running any of its steps
+	UnsupportedPlatformError UnsupportedPlatform = "error"
+)
+
+// validate that UnsupportedPlatform implements pflag.Value interface
+var _ pflag.Value = (*UnsupportedPlatform)(nil)
+
+// AvailableUnsupportedPlatforms returns a list of the ways tasks on unsupported platforms can be handled
+func AvailableUnsupportedPlatforms() []string {
+	return []string{
+		string(UnsupportedPlatformSkip),
+		string(UnsupportedPlatformError),
+	}
+}
+
+// String implements the pflag.Value and fmt.Stringer interfaces
+func (p *UnsupportedPlatform) String() string {
+	return string(*p)
+}
+
+// Set implements the pflag.Value interface
+func (p *UnsupportedPlatform) Set(value string) error {
+	switch value {
+	case string(UnsupportedPlatformSkip):
+		*p = UnsupportedPlatformSkip
+	case string(UnsupportedPlatformError):
+		*p = UnsupportedPlatformError
+	default:
+		return fmt.Errorf("invalid unsupported platform handling: %s", value)
+	}
+	return nil
+}
+
+// Type implements the pflag.Value interface
+func (p *UnsupportedPlatform) Type() string {
+	return "string"
+}
+
+// WithUnsupportedPlatform returns a context that handles tasks that do not support the current platform with p
+func WithUnsupportedPlatform(ctx context.Context, p UnsupportedPlatform) context.Context {
+	return context.WithValue(ctx, unsupportedPlatformKey{}, p)
+}
+
+// checkPlatform reports whether a task should be skipped because its platforms do not include goos/goarch,
+// or returns an error if the context is set to fail such tasks
+func checkPlatform(ctx context.Context, task string, platforms []string, goos, goarch string) (bool, error) {
+	if len(platforms) == 0 || platformSupported(platforms, goos, goarch) {
+		return false, nil
+	}
+
+	if p, _ := ctx.Value(unsupportedPlatformKey{}).(UnsupportedPlatform); p == UnsupportedPlatformError {
+		return false, fmt.Errorf("task %q does not support %s/%s, only [%s]", task, goos, goarch, strings.Join(platforms, ", "))
+	}
+
+	log.FromContext(ctx).Warnf("skipping task %q as it does not support %s/%s, only [%s]", task, goos, goarch, strings.Join(platforms, ", "))
+	return true, nil
+}
+
+// platformSupported reports whether any of the platforms, as os or os/arch, matches goos/goarch
+func platformSupported(platforms []string, goos, goarch string) bool {
+	for _, platform := range platforms {
+		os, arch, ok := strings.Cut(platform, "/")
+		if os == goos && (!ok || arch == goarch) {
+			return true
+		}
+	}
+	return false
+}
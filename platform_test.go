@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlatformSupported(t *testing.T) {
+	assert.True(t, platformSupported([]string{"linux"}, "linux", "arm64"))
+	assert.True(t, platformSupported([]string{"darwin/arm64", "linux/amd64"}, "linux", "amd64"))
+	assert.False(t, platformSupported([]string{"linux/amd64"}, "linux", "arm64"))
+	assert.False(t, platformSupported([]string{"darwin", "windows"}, "linux", "amd64"))
+	assert.False(t, platformSupported(nil, "linux", "amd64"))
+}
+
+func TestCheckPlatform(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := log.WithContext(t.Context(), log.New(&buf))
+
+	skip, err := checkPlatform(ctx, "build", nil, "linux", "amd64")
+	require.NoError(t, err)
+	assert.False(t, skip)
+
+	skip, err = checkPlatform(ctx, "build", []string{"linux"}, "linux", "amd64")
+	require.NoError(t, err)
+	assert.False(t, skip)
+	assert.Empty(t, buf.String())
+
+	skip, err = checkPlatform(ctx, "build", []string{"darwin/arm64", "windows"}, "linux", "amd64")
+	require.NoError(t, err)
+	assert.True(t, skip)
+	assert.Contains(t, buf.String(), `skipping task "build" as it does not support linux/amd64, only [darwin/arm64, windows]`)
+
+	_, err = checkPlatform(WithUnsupportedPlatform(ctx, UnsupportedPlatformError), "build", []string{"darwin/arm64", "windows"}, "linux", "amd64")
+	require.EqualError(t, err, `task "build" does not support linux/amd64, only [darwin/arm64, windows]`)
+}
+
+func TestUnsupportedPlatformSet(t *testing.T) {
+	var p UnsupportedPlatform
+	require.NoError(t, p.Set("error"))
+	assert.Equal(t, UnsupportedPlatformError, p)
+	require.NoError(t, p.Set("skip"))
+	assert.Equal(t, UnsupportedPlatformSkip, p)
+	require.EqualError(t, p.Set("ignore"), "invalid unsupported platform handling: ignore")
+}
@@ -71,7 +71,7 @@ func Publish(ctx context.Context, dst *remote.Repository, entrypoints []string)
 	localPaths := []string{}
 
 	for _, point := range entrypoints {
-		src, err := uses.ResolveRelative(nil, point, nil)
+		src, err := svc.ResolveRelative(nil, point, nil)
 		if err != nil {
 			return err
 		}
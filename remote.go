@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+type runsOnKey struct{}
+
+// withRunsOn returns a context whose run steps execute on target, a nil target executes them locally
+func withRunsOn(ctx context.Context, target *url.URL) context.Context {
+	return context.WithValue(ctx, runsOnKey{}, target)
+}
+
+// runsOn returns the target run steps execute on, or nil if they execute locally
+func runsOn(ctx context.Context) *url.URL {
+	target, _ := ctx.Value(runsOnKey{}).(*url.URL)
+	return target
+}
+
+// sshCommand builds the command that runs a script on an ssh:// target
+//
+// The script is run by the target's shell, with only the given environment, from the path of the target
+// joined with dir. Uses the ssh client in $PATH, so host aliases, keys and agents from the user's ssh config apply
+func sshCommand(ctx context.Context, target *url.URL, shell string, args []string, env []string, dir string) *exec.Cmd {
+	var remote strings.Builder
+
+	if p := path.Join(target.Path, dir); p != "" {
+		remote.WriteString("cd " + shellQuote(p) + " && ")
+	}
+
+	remote.WriteString("env")
+	for _, e := range env {
+		remote.WriteString(" " + shellQuote(e))
+	}
+
+	remote.WriteString(" " + shell)
+	for _, arg := range args {
+		remote.WriteString(" " + shellQuote(arg))
+	}
+
+	return exec.CommandContext(ctx, "ssh", append(sshArgs(target), remote.String())...)
+}
+
+// collectSSHOutput reads then removes the output file of a step from an ssh:// target
+func collectSSHOutput(ctx context.Context, target *url.URL, outFile string, env []string) (map[string]string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ssh", append(sshArgs(target), fmt.Sprintf("cat %[1]s; rm -f %[1]s", shellQuote(outFile)))...)
+	cmd.Env = env
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to collect outputs from %s: %w: %s", target.Host, err, strings.TrimSpace(stderr.String()))
+	}
+
+	// same limits as local output files
+	if stdout.Len() > 50*1024*1024 {
+		return nil, fmt.Errorf("output file too large")
+	}
+
+	return ParseOutput(bytes.NewReader(stdout.Bytes()))
+}
+
+// sshOutputFile returns a unique path for the output file of a step on an ssh:// target
+func sshOutputFile(runID string) string {
+	return fmt.Sprintf("/tmp/maru2-output-%s-%s", runID, rand.Text())
+}
+
+// sshArgs returns the ssh client arguments to connect to target
+func sshArgs(target *url.URL) []string {
+	// never prompt, there is no terminal to prompt on when output is captured
+	args := []string{"-o", "BatchMode=yes"}
+	if port := target.Port(); port != "" {
+		args = append(args, "-p", port)
+	}
+	if user := target.User.Username(); user != "" {
+		args = append(args, "-l", user)
+	}
+	// the host is templated, so it must never be taken as an option
+	return append(args, "--", target.Hostname())
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
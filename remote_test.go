@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defenseunicorns/maru2/schema"
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+)
+
+// fakeSSH puts an ssh client in $PATH that records its arguments and runs the remote command locally
+func fakeSSH(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	bin := t.TempDir()
+	log := filepath.Join(bin, "ssh.log")
+	// like a real ssh server, the local environment is not passed to the remote command
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "ssh"), []byte(`#!/bin/sh
+opts=""
+while [ "$1" != "--" ]; do opts="$opts $1"; shift; done
+shift
+host=$1
+shift
+echo "${opts# } -- $host $(echo "$*" | head -n 1 | cut -d ' ' -f 1-2)" >> `+log+`
+exec env -i PATH="$PATH" sh -c "$*"
+`), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return log
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, `'hello'`, shellQuote("hello"))
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+	assert.Equal(t, `''`, shellQuote(""))
+}
+
+func TestSSHCommand(t *testing.T) {
+	target, err := v1.ParseRunsOn("ssh://deploy@example.com:2222/srv/app")
+	require.NoError(t, err)
+
+	cmd := sshCommand(t.Context(), target, "sh", []string{"-e", "-c", "echo 'hi'"}, []string{"INPUT_NAME=a b"}, "sub")
+	assert.Equal(t, []string{
+		"ssh", "-o", "BatchMode=yes", "-p", "2222", "-l", "deploy", "--", "example.com",
+		`cd '/srv/app/sub' && env 'INPUT_NAME=a b' sh '-e' '-c' 'echo '\''hi'\'''`,
+	}, cmd.Args)
+
+	target, err = v1.ParseRunsOn("ssh://example.com")
+	require.NoError(t, err)
+
+	cmd = sshCommand(t.Context(), target, "bash", []string{"-c", "true"}, nil, "")
+	assert.Equal(t, []string{"ssh", "-o", "BatchMode=yes", "--", "example.com", `env bash '-c' 'true'`}, cmd.Args)
+}
+
+func TestHandleRunStepRunsOn(t *testing.T) {
+	sshLog := fakeSSH(t)
+
+	dir := t.TempDir()
+	target, err := url.Parse("ssh://deploy@example.com" + filepath.ToSlash(dir))
+	require.NoError(t, err)
+
+	t.Setenv("MARU2_TEST_LOCAL_ONLY", "secret")
+
+	var stdout bytes.Buffer
+	ctx := log.WithContext(t.Context(), log.New(io.Discard))
+	ctx = WithRunID(ctx, "01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	ctx = withRunsOn(ctx, target)
+
+	step := v1.Step{
+		Run: `echo "name=$INPUT_NAME" >> $MARU2_OUTPUT
+echo "pwd=$(pwd)" >> $MARU2_OUTPUT
+echo "local=${MARU2_TEST_LOCAL_ONLY:-unset}"
+echo "run=$MARU2_RUN_ID"`,
+		Env: schema.Env{"COLOR": "blue"},
+	}
+
+	result, err := handleRunStep(ctx, step, schema.With{"name": "it's me"}, nil, RuntimeOptions{Env: os.Environ(), Stdout: &stdout, Stderr: io.Discard})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "it's me", "pwd": dir}, result)
+	assert.Equal(t, "local=unset\nrun=01ARZ3NDEKTSV4RRFFQ69G5FAV\n", stdout.String())
+
+	b, err := os.ReadFile(sshLog)
+	require.NoError(t, err)
+	calls := strings.Split(strings.TrimSpace(string(b)), "\n")
+	require.Len(t, calls, 2)
+	assert.Equal(t, "-o BatchMode=yes -l deploy -- example.com cd '"+dir+"'", calls[0])
+	assert.True(t, strings.HasPrefix(calls[1], "-o BatchMode=yes -l deploy -- example.com cat '/tmp/maru2-output-01ARZ3NDEKTSV4RRFFQ69G5FAV-"), calls[1])
+
+	// the output file is removed from the target, even on failure
+	matches, err := filepath.Glob("/tmp/maru2-output-01ARZ3NDEKTSV4RRFFQ69G5FAV-*")
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	_, err = handleRunStep(ctx, v1.Step{Run: "echo 'x=y' >> $MARU2_OUTPUT; exit 3"}, nil, nil, RuntimeOptions{Stdout: io.Discard, Stderr: io.Discard})
+	require.EqualError(t, err, "exit status 3")
+	matches, err = filepath.Glob("/tmp/maru2-output-01ARZ3NDEKTSV4RRFFQ69G5FAV-*")
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	_, err = handleRunStep(ctx, v1.Step{Run: "echo", Shell: "pwsh"}, nil, nil, RuntimeOptions{Stdout: io.Discard, Stderr: io.Discard})
+	require.EqualError(t, err, "shell pwsh is not supported on ssh://deploy@example.com"+filepath.ToSlash(dir))
+}
@@ -0,0 +1,390 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"sync"
+	"time"
+
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+)
+
+type reportKey struct{}
+
+// Report statuses of runs, tasks and steps
+const (
+	ReportStatusSuccess = "success"
+	ReportStatusFailure = "failure"
+	ReportStatusSkipped = "skipped"
+)
+
+// Report is a summary of a run, written after it finishes for pipelines to consume
+//
+// It is safe for concurrent use
+type Report struct {
+	RunID    string           `json:"run-id"`
+	DryRun   bool             `json:"dry-run"`
+	Status   string           `json:"status"`
+	Error    string           `json:"error,omitempty"`
+	Started  time.Time        `json:"started"`
+	Duration string           `json:"duration"`
+	Tasks    []*ReportTask    `json:"tasks"`
+	Fetched  []ReportWorkflow `json:"fetched"`
+
+	mu      sync.Mutex
+	elapsed time.Duration
+}
+
+// ReportTask is a task executed during a run, in the order they started
+type ReportTask struct {
+	Name string `json:"name"`
+	// Location of the workflow the task is defined in
+	From     string        `json:"from"`
+	Status   string        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Started  time.Time     `json:"started"`
+	Duration string        `json:"duration"`
+	Steps    []*ReportStep `json:"steps"`
+	// Outputs of the task's final step
+	Outputs map[string]any `json:"outputs,omitempty"`
+
+	elapsed time.Duration
+}
+
+// ReportStep is a step of an executed task
+type ReportStep struct {
+	Index    int            `json:"index"`
+	ID       string         `json:"id,omitempty"`
+	Name     string         `json:"name,omitempty"`
+	Uses     string         `json:"uses,omitempty"`
+	Status   string         `json:"status"`
+	Error    string         `json:"error,omitempty"`
+	Started  time.Time      `json:"started,omitzero"`
+	Duration string         `json:"duration,omitempty"`
+	Outputs  map[string]any `json:"outputs,omitempty"`
+	// Paths of the files the step's stdout and stderr were written to, see RuntimeOptions.LogDir
+	StdoutLog string `json:"stdout-log,omitempty"`
+	StderrLog string `json:"stderr-log,omitempty"`
+
+	elapsed time.Duration
+}
+
+// ReportWorkflow is a workflow fetched during a run
+type ReportWorkflow struct {
+	// Resolved location of the workflow
+	URL string `json:"url"`
+	// Whether the workflow was read from the store instead of downloaded
+	Cached bool `json:"cached"`
+}
+
+// NewReport creates an empty report for a run starting now
+func NewReport(runID string, dry bool) *Report {
+	return &Report{
+		RunID:   runID,
+		DryRun:  dry,
+		Started: time.Now().UTC(),
+		Tasks:   []*ReportTask{},
+		Fetched: []ReportWorkflow{},
+	}
+}
+
+// WithReport returns a context that records every task and step executed into r
+func WithReport(ctx context.Context, r *Report) context.Context {
+	return context.WithValue(ctx, reportKey{}, r)
+}
+
+// reportFromContext returns the report being recorded to, or nil if there is none
+func reportFromContext(ctx context.Context) *Report {
+	r, _ := ctx.Value(reportKey{}).(*Report)
+	return r
+}
+
+// recordFetch records a fetched workflow, workflows fetched more than once are only recorded the first time
+func (r *Report) recordFetch(uri *url.URL, cached bool) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, wf := range r.Fetched {
+		if wf.URL == uri.String() {
+			return
+		}
+	}
+	r.Fetched = append(r.Fetched, ReportWorkflow{URL: uri.String(), Cached: cached})
+}
+
+// startTask records a task starting
+func (r *Report) startTask(name string, from *url.URL, steps int) *ReportTask {
+	if r == nil {
+		return nil
+	}
+	task := &ReportTask{
+		Name:    name,
+		From:    from.String(),
+		Started: time.Now().UTC(),
+		Steps:   make([]*ReportStep, 0, steps),
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Tasks = append(r.Tasks, task)
+	return task
+}
+
+// finishTask records a task finishing, secrets are masked in its outputs
+func (r *Report) finishTask(ctx context.Context, task *ReportTask, outputs map[string]any, err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	task.elapsed = time.Since(task.Started)
+	task.Duration = task.elapsed.String()
+	task.Status, task.Error = reportStatus(ctx, err)
+	task.Outputs = maskOutputs(secretsFromContext(ctx), outputs)
+}
+
+// startStep records a step starting
+func (r *Report) startStep(task *ReportTask, idx int, step v1.Step) *ReportStep {
+	if r == nil {
+		return nil
+	}
+	s := &ReportStep{
+		Index:   idx,
+		ID:      step.ID,
+		Name:    step.Name,
+		Uses:    step.Uses,
+		Status:  ReportStatusSkipped,
+		Started: time.Now().UTC(),
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	task.Steps = append(task.Steps, s)
+	return s
+}
+
+// recordLogs records the files a step's output is written to
+func (r *Report) recordLogs(step *ReportStep, stdout, stderr string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	step.StdoutLog = stdout
+	step.StderrLog = stderr
+}
+
+// finishStep records a step finishing, or being skipped, secrets are masked in its outputs
+func (r *Report) finishStep(ctx context.Context, step *ReportStep, skipped bool, outputs map[string]any, err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if skipped {
+		step.Started = time.Time{}
+		return
+	}
+	step.elapsed = time.Since(step.Started)
+	step.Duration = step.elapsed.String()
+	step.Status, step.Error = reportStatus(ctx, err)
+	step.Outputs = maskOutputs(secretsFromContext(ctx), outputs)
+}
+
+// Finish records the result of the run
+func (r *Report) Finish(ctx context.Context, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.elapsed = time.Since(r.Started)
+	r.Duration = r.elapsed.String()
+	r.Status, r.Error = reportStatus(ctx, err)
+}
+
+// restoreElapsed parses the durations of a report read from JSON, so it can be summarized again
+func (r *Report) restoreElapsed() {
+	parse := func(d string) time.Duration {
+		elapsed, _ := time.ParseDuration(d)
+		return elapsed
+	}
+
+	r.elapsed = parse(r.Duration)
+	for _, task := range r.Tasks {
+		task.elapsed = parse(task.Duration)
+		for _, step := range task.Steps {
+			step.elapsed = parse(step.Duration)
+		}
+	}
+}
+
+// WriteJSON writes the report as JSON to w
+func (r *Report) WriteJSON(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML to w, with a test suite for every task and a test case for every step
+func (r *Report) WriteJUnit(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	suites := junitTestSuites{
+		Name:   "maru2",
+		Time:   junitSeconds(r.elapsed),
+		Suites: make([]junitTestSuite, 0, len(r.Tasks)),
+	}
+
+	for _, task := range r.Tasks {
+		suite := junitTestSuite{
+			Name:      task.Name + " (" + task.From + ")",
+			Tests:     len(task.Steps),
+			Time:      junitSeconds(task.elapsed),
+			Timestamp: task.Started.Format(time.RFC3339),
+			Cases:     make([]junitTestCase, 0, len(task.Steps)),
+		}
+		for _, step := range task.Steps {
+			tc := junitTestCase{
+				Name:      reportStepName(step),
+				Classname: task.Name,
+				Time:      junitSeconds(step.elapsed),
+			}
+			switch step.Status {
+			case ReportStatusFailure:
+				tc.Failure = &junitFailure{Message: step.Error, Text: step.Error}
+				suite.Failures++
+			case ReportStatusSkipped:
+				tc.Skipped = &struct{}{}
+				suite.Skipped++
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	b, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// Summary renders the wall-clock duration and status of every task and step in the report
+//
+// Tasks are listed in the order they started, with their steps indented beneath them
+func (r *Report) Summary() *TaskList {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := &TaskList{}
+	for _, task := range r.Tasks {
+		t.Row(task.Name, reportTiming(task.Status, task.elapsed))
+		for _, step := range task.Steps {
+			t.Row("  "+reportStepName(step), reportTiming(step.Status, step.elapsed))
+		}
+	}
+	return t
+}
+
+// reportTiming returns the status and duration of a task or step for a summary, skipped steps have no duration
+//
+// The leading spaces keep the longest name from running into its status
+func reportTiming(status string, d time.Duration) string {
+	if status == ReportStatusSkipped {
+		return "  " + status
+	}
+	return fmt.Sprintf("  %-8s %s", status, d.Round(time.Millisecond))
+}
+
+// reportStepName returns the display name of a step, falling back to its ID, uses: reference, then index
+func reportStepName(step *ReportStep) string {
+	switch {
+	case step.Name != "":
+		return step.Name
+	case step.ID != "":
+		return step.ID
+	case step.Uses != "":
+		return step.Uses
+	default:
+		return fmt.Sprintf("step %d", step.Index)
+	}
+}
+
+// junitSeconds formats a duration as the decimal seconds JUnit expects
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// reportStatus returns the status and masked error message for the result of a run, task or step
+func reportStatus(ctx context.Context, err error) (string, string) {
+	if err != nil {
+		return ReportStatusFailure, secretsFromContext(ctx).Mask(err.Error())
+	}
+	return ReportStatusSuccess, ""
+}
+
+// maskOutputs masks secrets within string outputs
+func maskOutputs(secrets Secrets, outputs map[string]any) map[string]any {
+	if len(outputs) == 0 {
+		return nil
+	}
+	masked := make(map[string]any, len(outputs))
+	for k, v := range outputs {
+		if s, ok := v.(string); ok {
+			v = secrets.Mask(s)
+		}
+		masked[k] = v
+	}
+	return masked
+}
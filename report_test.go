@@ -0,0 +1,213 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+func TestReport(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "tasks.yaml", []byte(`schema-version: v1
+tasks:
+  default:
+    steps:
+      - run: echo "token=${{ secret "token" }}" >> $MARU2_OUTPUT
+        id: first
+        name: Write token
+      - uses: file:other.yaml?task=fail
+      - run: echo "cleaning up"
+        if: failure()
+      - run: echo "never run"
+`), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "other.yaml", []byte(`schema-version: v1
+tasks:
+  fail:
+    steps:
+      - run: exit 3
+`), 0o644))
+
+	svc, err := uses.NewFetcherService(uses.WithFS(fs))
+	require.NoError(t, err)
+
+	r := NewReport("01ARZ3NDEKTSV4RRFFQ69G5FAV", false)
+	ctx := log.WithContext(t.Context(), log.New(io.Discard))
+	ctx = WithSecrets(ctx, Secrets{"token": "hunter2"})
+	ctx = WithReport(ctx, r)
+
+	origin, err := url.Parse("file:tasks.yaml")
+	require.NoError(t, err)
+
+	wf, err := Fetch(ctx, svc, origin)
+	require.NoError(t, err)
+
+	_, err = Run(ctx, svc, wf, "", nil, origin, RuntimeOptions{Stdout: io.Discard, Stderr: io.Discard})
+	require.EqualError(t, err, "exit status 3")
+	r.Finish(ctx, err)
+
+	assert.Equal(t, ReportStatusFailure, r.Status)
+	assert.Equal(t, "exit status 3", r.Error)
+	assert.NotEmpty(t, r.Duration)
+
+	assert.Equal(t, []ReportWorkflow{
+		{URL: "file:tasks.yaml"},
+		{URL: "file:other.yaml?task=fail"},
+	}, r.Fetched)
+
+	require.Len(t, r.Tasks, 2)
+
+	task := r.Tasks[0]
+	assert.Equal(t, "default", task.Name)
+	assert.Equal(t, "file:tasks.yaml", task.From)
+	assert.Equal(t, ReportStatusFailure, task.Status)
+	require.Len(t, task.Steps, 4)
+
+	assert.Equal(t, 0, task.Steps[0].Index)
+	assert.Equal(t, "first", task.Steps[0].ID)
+	assert.Equal(t, "Write token", task.Steps[0].Name)
+	assert.Equal(t, ReportStatusSuccess, task.Steps[0].Status)
+	assert.Equal(t, map[string]any{"token": SecretMask}, task.Steps[0].Outputs)
+	assert.NotEmpty(t, task.Steps[0].Duration)
+
+	assert.Equal(t, "file:other.yaml?task=fail", task.Steps[1].Uses)
+	assert.Equal(t, ReportStatusFailure, task.Steps[1].Status)
+	assert.Equal(t, "exit status 3", task.Steps[1].Error)
+
+	assert.Equal(t, ReportStatusSuccess, task.Steps[2].Status)
+
+	assert.Equal(t, ReportStatusSkipped, task.Steps[3].Status)
+	assert.True(t, task.Steps[3].Started.IsZero())
+	assert.Empty(t, task.Steps[3].Duration)
+
+	called := r.Tasks[1]
+	assert.Equal(t, "fail", called.Name)
+	assert.Equal(t, "file:other.yaml?task=fail", called.From)
+	assert.Equal(t, ReportStatusFailure, called.Status)
+	require.Len(t, called.Steps, 1)
+	assert.Equal(t, ReportStatusFailure, called.Steps[0].Status)
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteJSON(&buf))
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "01ARZ3NDEKTSV4RRFFQ69G5FAV", decoded["run-id"])
+	assert.Equal(t, ReportStatusFailure, decoded["status"])
+	assert.NotContains(t, buf.String(), "hunter2")
+}
+
+func TestReportFinish(t *testing.T) {
+	r := NewReport("01ARZ3NDEKTSV4RRFFQ69G5FAV", true)
+	ctx := WithSecrets(t.Context(), Secrets{"token": "hunter2"})
+
+	r.Finish(ctx, nil)
+	assert.Equal(t, ReportStatusSuccess, r.Status)
+	assert.Empty(t, r.Error)
+
+	r.Finish(ctx, errors.New("bad token hunter2"))
+	assert.Equal(t, ReportStatusFailure, r.Status)
+	assert.Equal(t, "bad token ***", r.Error)
+}
+
+func TestReportNil(t *testing.T) {
+	var r *Report
+	task := r.startTask("default", &url.URL{Scheme: "file", Opaque: "tasks.yaml"}, 1)
+	assert.Nil(t, task)
+	r.finishTask(t.Context(), task, nil, nil)
+	r.recordFetch(&url.URL{Scheme: "file", Opaque: "tasks.yaml"}, false)
+	step := r.startStep(task, 0, v1.Step{Run: "echo"})
+	assert.Nil(t, step)
+	r.finishStep(t.Context(), step, false, nil, nil)
+}
+
+func TestReportWriteJUnit(t *testing.T) {
+	started := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	r := &Report{
+		elapsed: 1500 * time.Millisecond,
+		Tasks: []*ReportTask{
+			{
+				Name:    "build",
+				From:    "file:tasks.yaml",
+				Started: started,
+				elapsed: time.Second,
+				Steps: []*ReportStep{
+					{Index: 0, Name: "Compile", Status: ReportStatusSuccess, elapsed: 250 * time.Millisecond},
+					{Index: 1, ID: "lint", Status: ReportStatusFailure, Error: `exit status 1 <"&">`, elapsed: 750 * time.Millisecond},
+					{Index: 2, Uses: "builtin:echo", Status: ReportStatusSkipped},
+					{Index: 3, Status: ReportStatusSkipped},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteJUnit(&buf))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="maru2" tests="4" failures="1" skipped="2" time="1.500">
+  <testsuite name="build (file:tasks.yaml)" tests="4" failures="1" skipped="2" time="1.000" timestamp="2025-01-02T03:04:05Z">
+    <testcase name="Compile" classname="build" time="0.250"></testcase>
+    <testcase name="lint" classname="build" time="0.750">
+      <failure message="exit status 1 &lt;&#34;&amp;&#34;&gt;">exit status 1 &lt;&#34;&amp;&#34;&gt;</failure>
+    </testcase>
+    <testcase name="builtin:echo" classname="build" time="0.000">
+      <skipped></skipped>
+    </testcase>
+    <testcase name="step 3" classname="build" time="0.000">
+      <skipped></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+}
+
+func TestReportSummary(t *testing.T) {
+	t.Setenv("NO_COLOR", "true") // format matters more than ensuring colors are correct
+
+	r := &Report{
+		Tasks: []*ReportTask{
+			{
+				Name:    "build",
+				Status:  ReportStatusFailure,
+				elapsed: 1234567 * time.Microsecond,
+				Steps: []*ReportStep{
+					{Index: 0, Name: "Compile", Status: ReportStatusSuccess, elapsed: 250 * time.Millisecond},
+					{Index: 1, Uses: "file:lint.yaml", Status: ReportStatusFailure, elapsed: 984567 * time.Microsecond},
+					{Index: 2, Status: ReportStatusSkipped},
+				},
+			},
+			{
+				Name:    "lint",
+				Status:  ReportStatusFailure,
+				elapsed: 980 * time.Millisecond,
+				Steps: []*ReportStep{
+					{Index: 0, ID: "vet", Status: ReportStatusFailure, elapsed: 980 * time.Millisecond},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, strings.Join([]string{
+		"    build             failure  1.235s",
+		"      Compile         success  250ms",
+		"      file:lint.yaml  failure  985ms",
+		"      step 2          skipped",
+		"    lint              failure  980ms",
+		"      vet             failure  980ms",
+		"",
+	}, "\n"), r.Summary().String())
+}
@@ -5,6 +5,7 @@
 package maru2
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
@@ -15,6 +16,8 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"syscall"
@@ -48,6 +51,8 @@ type RuntimeOptions struct {
 	Stderr io.Writer
 	// See `go doc exec.Cmd.Stdin`
 	Stdin io.Reader
+	// Directory to write the stdout and stderr of every run step to, in addition to Stdout and Stderr, leave blank to disable
+	LogDir string
 }
 
 /*
@@ -57,19 +62,19 @@ It is implemented as a recursive function instead of a DAG for simplicity (debat
 
 Run follows the following general pattern:
 
- 1. Find the called task in the provided workflow
+ 1. Find the called task in the provided workflow, skipping it (or failing) if its `platforms` do not include the current platform
 
- 2. Merge the provided inputs w/ the default workflow inputs
+ 2. Merge the provided inputs w/ the default workflow inputs, resolve the task's `runs-on`, ask for confirmation if `confirm` is set, then acquire the task's `mutex` if set
 
  3. Create a child context to listen for SIGINT
 
  4. For each step in the task:
 
-    4a. Compile `if` conditionals and determine if the step should run
+    4a. Apply the `--skip` and `--only` step filters, then compile `if` conditionals and determine if the step should run
 
     4b. Soft reset the context if a previous step was cancelled, timed out, etc...
 
-    4c. Wrap the current context in a timeout if `timeout` was set
+    4c. Acquire the step's `mutex` if set, then wrap the current context in a timeout if `timeout` was set
 
     4d. If `uses` is set, resolve & fetch, then goto Step 1
 
@@ -89,22 +94,91 @@ func Run(
 	outer schema.With,
 	origin *url.URL,
 	ro RuntimeOptions,
-) (map[string]any, error) {
+) (result map[string]any, err error) {
 	if taskName == "" {
 		taskName = schema.DefaultTaskName
 	}
 
+	runID := RunID(parent)
+	if runID == "" {
+		runID = NewRunID()
+		parent = WithRunID(parent, runID)
+	}
+	parent = withWorkflowOrigin(parent, origin)
+	parent = withTaskName(parent, taskName)
+	parent = withTemplateMode(parent, wf.StepTemplateMode(v1.Step{}))
+
 	task, ok := wf.Tasks.Find(taskName)
 	if !ok {
-		return nil, addTrace(fmt.Errorf("task %q not found", taskName), fmt.Sprintf("at (%s)", origin))
+		return nil, addTrace(fmt.Errorf("task %q not found%s", taskName, v1.DidYouMean(taskName, wf.Tasks.OrderedTaskNames())), fmt.Sprintf("at (%s)", origin))
+	}
+
+	// an alias can be deprecated on its own, or through the task it is an alias of
+	if msg := cmp.Or(wf.Tasks[taskName].DeprecatedMessage, task.DeprecatedMessage); msg != "" {
+		log.FromContext(parent).Warnf("task %q is deprecated: %s", taskName, msg)
+	}
+
+	skip, err := checkPlatform(parent, taskName, task.Platforms, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return nil, addTrace(err, fmt.Sprintf("at %s.platforms (%s)", taskName, origin))
+	}
+	if skip {
+		return nil, nil
 	}
 
+	report := reportFromContext(parent)
+	plan := planFromContext(parent)
+	reported := report.startTask(taskName, origin, len(task.Steps))
+	defer func() {
+		report.finishTask(parent, reported, result, err)
+	}()
+
 	withDefaults, err := MergeWithAndParams(parent, outer, task.Inputs)
 	if err != nil {
+		err = annotate(parent, ro.Stdout, "error", origin, fmt.Sprintf("$.tasks.%s.inputs", taskName), taskName, err)
 		return nil, addTrace(err, fmt.Sprintf("at %s.inputs (%s)", taskName, origin))
 	}
 
+	var target *url.URL
+	if task.RunsOn != "" && !ro.Dry {
+		runsOn, err := TemplateString(parent, task.RunsOn, withDefaults, nil, ro.Dry)
+		if err != nil {
+			return nil, addTrace(err, fmt.Sprintf("at %s.runs-on (%s)", taskName, origin))
+		}
+		target, err = v1.ParseRunsOn(runsOn)
+		if err != nil {
+			return nil, addTrace(err, fmt.Sprintf("at %s.runs-on (%s)", taskName, origin))
+		}
+	}
+	// run steps of called tasks execute locally unless they set their own runs-on
+	parent = withRunsOn(parent, target)
+
+	// dry runs do not run anything, so there is nothing to confirm
+	if task.Confirm != "" && !ro.Dry {
+		message, err := TemplateString(parent, task.Confirm, withDefaults, nil, ro.Dry)
+		if err != nil {
+			return nil, addTrace(err, fmt.Sprintf("at %s.confirm (%s)", taskName, origin))
+		}
+		if err := confirmTask(parent, taskName, message); err != nil {
+			return nil, addTrace(err, fmt.Sprintf("at %s.confirm (%s)", taskName, origin))
+		}
+	}
+
+	if !ro.Dry {
+		var unlock func()
+		parent, unlock, err = acquireMutex(parent, task.Mutex)
+		if err != nil {
+			return nil, addTrace(err, fmt.Sprintf("at %s.mutex (%s)", taskName, origin))
+		}
+		defer unlock()
+	}
+
 	logger := log.FromContext(parent)
+	structured := structuredLogger(parent)
+	if structured != nil {
+		// drop the fields of the calling step, they are replaced by this task's
+		logger = structured
+	}
 	outputs := make(CommandOutputs)
 	var firstError error
 	var lastStepOutput map[string]any
@@ -117,9 +191,9 @@ func Run(
 		ro.Collapsed = true
 	}
 
-	logger.Debug("run", "task", taskName, "from", origin, "dry-run", ro.Dry)
+	logger.Debug("run", "task", taskName, "from", origin, "dry-run", ro.Dry, "run-id", runID)
 	defer func() {
-		logger.Debug("ran", "task", taskName, "from", origin, "duration", time.Since(start))
+		logger.Debug("ran", "task", taskName, "from", origin, "duration", time.Since(start), "run-id", runID)
 	}()
 
 	sigCtx, cancel := signal.NotifyContext(parent, syscall.SIGINT)
@@ -128,20 +202,50 @@ func Run(
 	var taskCancelledLogOnce sync.Once
 
 	for i, step := range task.Steps {
+		step = step.ForOS(runtime.GOOS)
 		sub := logger.With("step", fmt.Sprintf("%s[%d]", taskName, i))
+		if structured != nil {
+			sub = logger.With("task", taskName, "step", fmt.Sprintf("%s[%d]", taskName, i))
+		}
+		reportedStep := report.startStep(reported, i, step)
+		planned := plan.startStep(origin, taskName, i, step)
+		var skipped bool
+		var stepResult map[string]any
 		err := func(ctx context.Context) error {
+			ctx = withManifestStep(ctx, origin, taskName, i)
+			ctx = withPlanStep(ctx, planned)
+			if structured != nil {
+				ctx = log.WithContext(ctx, sub)
+			}
+
+			ctx, filtered := stepFilterFromContext(ctx).filter(ctx, step)
+			if filtered {
+				sub.Debug("completed", "skipped", true, "filtered", true, "run-id", runID)
+				plan.skipStep(planned, "filtered by --skip or --only")
+				skipped = true
+				return nil
+			}
+
 			shouldRun, err := ShouldRun(ctx, step.If, firstError, withDefaults, outputs, ro.Dry)
 			if err != nil {
 				if firstError != nil {
 					// if there was an error calculating if we should run during the error path
 					// log the error, but don't return it
 					sub.Error("invalid", "if", step.If, "error", err)
+					plan.skipStep(planned, fmt.Sprintf("invalid condition: %s", err))
+					skipped = true
 					return nil
 				}
 				return err
 			}
 			if !shouldRun {
-				sub.Debug("completed", "skipped", true)
+				sub.Debug("completed", "skipped", true, "run-id", runID)
+				if step.If != "" {
+					plan.skipStep(planned, fmt.Sprintf("condition '%s' is false", step.If))
+				} else {
+					plan.skipStep(planned, "a previous step failed")
+				}
+				skipped = true
 				return nil
 			}
 
@@ -160,6 +264,23 @@ func Run(
 				ctx = context.WithoutCancel(parent)
 			}
 
+			// steps within a collapsed step do not open their own groups, but later steps of this task still can
+			ro := ro
+			if !ro.Collapsed && wf.CollapseStep(step) {
+				closeGroup := printGroup(ro.Stdout, stepGroupName(taskName, i, step), step.Name)
+				defer closeGroup()
+				ro.Collapsed = true
+			}
+
+			if !ro.Dry {
+				var unlock func()
+				ctx, unlock, err = acquireMutex(ctx, step.Mutex)
+				if err != nil {
+					return err
+				}
+				defer unlock()
+			}
+
 			if step.Timeout != "" {
 				timeout, err := time.ParseDuration(step.Timeout)
 				if err != nil {
@@ -170,19 +291,33 @@ func Run(
 				defer cancel()
 			}
 
-			var stepResult map[string]any
+			if ro.LogDir != "" && step.Run != "" && !ro.Dry {
+				logs, err := openStepLogs(ro.LogDir, taskName, i)
+				if err != nil {
+					return err
+				}
+				defer logs.Close()
+				report.recordLogs(reportedStep, logs.stdout.Name(), logs.stderr.Name())
+				ctx = withStepLogs(ctx, logs)
+			}
 
-			if step.Uses != "" {
+			ctx = withTemplateMode(ctx, wf.StepTemplateMode(step))
+
+			if mock, ok := mocksFromContext(ctx)[step.Uses]; ok && step.Uses != "" {
+				stepResult, err = handleMockedStep(ctx, step, mock)
+			} else if step.Uses != "" {
 				stepResult, err = handleUsesStep(ctx, svc, step, wf, withDefaults, outputs, origin, ro)
 			} else if step.Run != "" {
 				stepResult, err = handleRunStep(ctx, step, withDefaults, outputs, ro)
+			} else {
+				err = fmt.Errorf("no run or run-%s", runtime.GOOS)
 			}
 
 			if err != nil {
 				return err
 			}
 
-			sub.Debug("completed", "outputs", len(stepResult), "duration", time.Since(start))
+			sub.Debug("completed", "outputs", len(stepResult), "duration", time.Since(start), "run-id", runID)
 
 			isLastStep := i == len(task.Steps)-1
 			if isLastStep {
@@ -197,15 +332,28 @@ func Run(
 			return nil
 		}(sigCtx)
 
+		report.finishStep(sigCtx, reportedStep, skipped, stepResult, err)
+		plan.finishStep(sigCtx, planned, err)
+
 		if err != nil {
+			path, title := fmt.Sprintf("$.tasks.%s.steps[%d]", taskName, i), fmt.Sprintf("%s[%d]", taskName, i)
 			if firstError == nil {
+				// only the innermost step of a failed uses: call is annotated
+				if !isAnnotated(err) {
+					err = annotate(sigCtx, ro.Stdout, "error", origin, path, title, err)
+				}
 				firstError = addTrace(err, fmt.Sprintf("at %s[%d] (%s)", taskName, i, origin))
 				// log the first error if it was caused by a command execution
-				if step.Run != "" {
+				if step.Run != "" && structured != nil {
+					sub.Error(err)
+				} else if step.Run != "" {
 					logger.Error(err)
 				}
 			} else {
 				sub.Warn("failure during error handling", "err", err)
+				if !isAnnotated(err) {
+					_ = annotate(sigCtx, ro.Stdout, "warning", origin, path, title, fmt.Errorf("failure during error handling: %w", err))
+				}
 			}
 		}
 	}
@@ -222,44 +370,30 @@ func handleRunStep(
 ) (map[string]any, error) {
 
 	logger := log.FromContext(ctx)
+	secrets := secretsFromContext(ctx)
 
 	script, err := TemplateString(ctx, step.Run, withDefaults, outputs, ro.Dry)
 	if err != nil {
 		if ro.Dry {
-			printScript(logger, step.Shell, script)
+			printScript(logger, step.Shell, secrets.Mask(script), structuredLogger(ctx) == nil)
+			planFromContext(ctx).recordScript(ctx, step.Shell, script)
 		}
 		return nil, err
 	}
 
 	if ro.Dry || step.Show == nil || *step.Show {
-		printScript(logger, step.Shell, script)
+		printScript(logger, step.Shell, secrets.Mask(script), structuredLogger(ctx) == nil)
 	}
 	if ro.Dry {
+		planFromContext(ctx).recordScript(ctx, step.Shell, script)
 		return nil, nil
 	}
 
-	// using os.CreateTemp w/ an empty string as the first argument
-	// leverages the TMPDIR environment variable, otherwise OS specific defaults
-	// see `go doc os.TempDir`
-	outFile, err := os.CreateTemp("", "maru2-output-*")
-	if err != nil {
-		return nil, err
-	}
-	defer func() {
-		outFile.Close()
-		os.Remove(outFile.Name())
-	}()
-
 	templatedEnv, err := TemplateWithMap(ctx, step.Env, withDefaults, outputs, ro.Dry)
 	if err != nil {
 		return nil, err
 	}
 
-	env, err := prepareEnvironment(ro.Env, withDefaults, outFile.Name(), templatedEnv)
-	if err != nil {
-		return nil, err
-	}
-
 	shell := step.Shell
 	var args []string
 
@@ -276,9 +410,57 @@ func handleRunStep(
 		return nil, fmt.Errorf("unsupported shell: %s", step.Shell)
 	}
 
-	cmd := exec.CommandContext(ctx, shell, args...)
-	cmd.Env = env
-	cmd.Dir = filepath.Join(ro.WorkingDir, step.Dir)
+	target := runsOn(ctx)
+
+	var cmd *exec.Cmd
+	var outFile *os.File
+	var remoteOutFile string
+
+	if target != nil {
+		if shell != "sh" && shell != "bash" {
+			return nil, fmt.Errorf("shell %s is not supported on %s", shell, target.Redacted())
+		}
+
+		// only inputs and the step's env are sent, the local environment is not
+		remoteOutFile = sshOutputFile(RunID(ctx))
+		env, err := prepareEnvironment(nil, withDefaults, remoteOutFile, templatedEnv, secrets)
+		if err != nil {
+			return nil, err
+		}
+		if runID := RunID(ctx); runID != "" {
+			env = append(env, fmt.Sprintf("MARU2_RUN_ID=%s", runID))
+		}
+
+		logger.Debug("running on", "target", target.Redacted())
+		cmd = sshCommand(ctx, target, shell, args, env, step.Dir)
+		cmd.Env = ro.Env
+		cmd.Dir = ro.WorkingDir
+	} else {
+		// using os.CreateTemp w/ an empty string as the first argument
+		// leverages the TMPDIR environment variable, otherwise OS specific defaults
+		// see `go doc os.TempDir`
+		outFile, err = os.CreateTemp("", "maru2-output-*")
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			outFile.Close()
+			os.Remove(outFile.Name())
+		}()
+
+		env, err := prepareEnvironment(ro.Env, withDefaults, outFile.Name(), templatedEnv, secrets)
+		if err != nil {
+			return nil, err
+		}
+		if runID := RunID(ctx); runID != "" {
+			env = append(env, fmt.Sprintf("MARU2_RUN_ID=%s", runID))
+		}
+
+		cmd = exec.CommandContext(ctx, shell, args...)
+		cmd.Env = env
+		cmd.Dir = filepath.Join(ro.WorkingDir, step.Dir)
+	}
+
 	cmd.Stdout = ro.Stdout
 	cmd.Stderr = ro.Stderr
 	cmd.Stdin = ro.Stdin
@@ -288,11 +470,49 @@ func handleRunStep(
 		cmd.Stderr = nil
 	}
 
-	if err := cmd.Run(); err != nil {
-		return nil, err
+	// muted steps are still logged, so they can be inspected afterward
+	if logs := stepLogsFromContext(ctx); logs != nil {
+		cmd.Stdout = teeLog(cmd.Stdout, logs.stdout)
+		cmd.Stderr = teeLog(cmd.Stderr, logs.stderr)
 	}
 
-	out, err := ParseOutput(outFile)
+	if len(secrets) > 0 {
+		for _, w := range []*io.Writer{&cmd.Stdout, &cmd.Stderr} {
+			if *w == nil {
+				continue
+			}
+			mw := NewMaskWriter(*w, secrets)
+			defer mw.Flush()
+			*w = mw
+		}
+	}
+
+	started := time.Now()
+	err = cmd.Run()
+
+	if m := manifestFromContext(ctx); m != nil {
+		m.recordCommand(ManifestCommand{
+			ManifestStep: manifestStep(ctx),
+			Args:         maskAll(secrets, cmd.Args),
+			Dir:          cmd.Dir,
+			Env:          slices.Sorted(maps.Keys(templatedEnv)),
+			Started:      started.UTC(),
+			Duration:     time.Since(started).String(),
+			ExitCode:     cmd.ProcessState.ExitCode(),
+		})
+	}
+
+	var out map[string]string
+	if target != nil {
+		// always collected, so the output file is removed from the target
+		var collectErr error
+		out, collectErr = collectSSHOutput(ctx, target, remoteOutFile, ro.Env)
+		if err == nil {
+			err = collectErr
+		}
+	} else if err == nil {
+		out, err = ParseOutput(outFile)
+	}
 	if err != nil || len(out) == 0 {
 		return nil, err
 	}
@@ -308,8 +528,9 @@ func handleRunStep(
 // prepareEnvironment builds the final environment variable list for command execution
 //
 // Combines system env vars, input parameters as env vars, step-level env vars,
-// and the output file path for step communication
-func prepareEnvironment(envVars []string, withDefaults schema.With, outFileName string, stepEnv schema.Env) ([]string, error) {
+// and the output file path for step communication. Inputs holding a secret are not set,
+// as secrets passed with `with:` must stay out of the environment the same as any other secret
+func prepareEnvironment(envVars []string, withDefaults schema.With, outFileName string, stepEnv schema.Env, secrets Secrets) ([]string, error) {
 	env := make([]string, len(envVars), len(envVars)+len(withDefaults)+len(stepEnv)+1)
 	copy(env, envVars)
 
@@ -318,6 +539,9 @@ func prepareEnvironment(envVars []string, withDefaults schema.With, outFileName
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert input %q to string: %w", k, err)
 		}
+		if secrets.Mask(val) != val {
+			continue
+		}
 		env = append(env, fmt.Sprintf("INPUT_%s=%s", toEnvVar(k), val))
 	}
 
@@ -348,6 +572,16 @@ func toEnvVar(s string) string {
 	return strings.ToUpper(strings.ReplaceAll(s, "-", "_"))
 }
 
+// stepGroupName returns the name of a step's collapsible group in CI, keyed by its ID or index within the task
+//
+// GitLab section names may only contain letters, numbers, underscores, periods and dashes
+func stepGroupName(taskName string, idx int, step v1.Step) string {
+	if step.ID != "" {
+		return taskName + "." + step.ID
+	}
+	return fmt.Sprintf("%s.%d", taskName, idx)
+}
+
 // TraceError is an error with a logical stack trace
 type TraceError struct {
 	err   error    // The original error
@@ -81,6 +81,20 @@ func TestRun(t *testing.T) {
 			expectedError: "task \"nonexistent\" not found",
 			expectedOut:   nil,
 		},
+		{
+			name: "task not found with suggestions",
+			workflow: v1.Workflow{
+				Tasks: v1.TaskMap{
+					"deploy-staging":    v1.Task{},
+					"deploy-production": v1.Task{},
+					"build":             v1.Task{},
+				},
+			},
+			taskName:      "deploy-stagign",
+			with:          schema.With{},
+			expectedError: "task \"deploy-stagign\" not found, did you mean \"deploy-staging\"?",
+			expectedOut:   nil,
+		},
 		{
 			name: "uses step",
 			workflow: v1.Workflow{
@@ -405,6 +419,37 @@ func TestRun_PrintGroup(t *testing.T) {
 
 		assert.Regexp(t, `^\\e\[0Ksection_start:\d+:default\[collapsed=true\]\\r\\e\[0Kdefault\nfoo\n\\e\[0Ksection_end:\d+:default\\r\\e\[0K\n$`, stdout.String())
 	})
+
+	t.Run("steps", func(t *testing.T) {
+		isGitHubActions = syncTrue
+		isGitLabCI = syncFalse
+
+		wf := v1.Workflow{
+			Defaults: &v1.Defaults{CollapseSteps: true},
+			Tasks: v1.TaskMap{
+				"default": v1.Task{
+					Steps: []v1.Step{
+						{Run: "echo 'foo'", ID: "foo", Name: "Print foo"},
+						{Run: "echo 'bar'", Collapse: &[]bool{false}[0]},
+						{Uses: "nested"},
+					},
+				},
+				"nested": v1.Task{
+					Collapse: true,
+					Steps:    []v1.Step{{Run: "echo 'baz'"}},
+				},
+			},
+		}
+
+		stdout := strings.Builder{}
+
+		ctx := log.WithContext(t.Context(), log.New(io.Discard))
+		_, err := Run(ctx, nil, wf, "", nil, nil, RuntimeOptions{Stdout: &stdout})
+		require.NoError(t, err)
+
+		// the nested task does not open a group within the step's group
+		assert.Equal(t, "::group::default.foo: Print foo\nfoo\n::endgroup::\nbar\n::group::default.2\nbaz\n::endgroup::\n", stdout.String())
+	})
 }
 
 func TestRunContext(t *testing.T) {
@@ -724,7 +769,9 @@ func TestPrepareEnvironment(t *testing.T) {
 		startingEnv     []string
 		withDefaults    schema.With
 		stepEnv         schema.Env
+		secrets         Secrets
 		expectedEnvVars []string
+		missingEnvVars  []string
 		expectedError   string
 	}{
 		{
@@ -743,6 +790,22 @@ func TestPrepareEnvironment(t *testing.T) {
 				"INPUT_TEST_INPUT=test-value",
 			},
 		},
+		{
+			name: "inputs holding a secret are not set",
+			withDefaults: schema.With{
+				"token":  "s3cret",
+				"header": "Bearer s3cret",
+				"name":   "test-value",
+			},
+			secrets: Secrets{"token": "s3cret"},
+			expectedEnvVars: []string{
+				"INPUT_NAME=test-value",
+			},
+			missingEnvVars: []string{
+				"INPUT_TOKEN=s3cret",
+				"INPUT_HEADER=Bearer s3cret",
+			},
+		},
 		{
 			name: "integer input value",
 			withDefaults: schema.With{
@@ -1000,7 +1063,7 @@ func TestPrepareEnvironment(t *testing.T) {
 				actualOutFileName = ""
 			}
 
-			env, err := prepareEnvironment(tc.startingEnv, tc.withDefaults, actualOutFileName, tc.stepEnv)
+			env, err := prepareEnvironment(tc.startingEnv, tc.withDefaults, actualOutFileName, tc.stepEnv, tc.secrets)
 
 			if tc.expectedError != "" {
 				require.Error(t, err)
@@ -1022,6 +1085,9 @@ func TestPrepareEnvironment(t *testing.T) {
 			for _, expectedEnv := range tc.expectedEnvVars {
 				assert.Contains(t, env, expectedEnv, "Expected environment variable not found: %s", expectedEnv)
 			}
+			for _, missingEnv := range tc.missingEnvVars {
+				assert.NotContains(t, env, missingEnv)
+			}
 		})
 	}
 }
@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"time"
+)
+
+// crockford is the Crockford base32 alphabet used to encode ULIDs
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+type runIDKey struct{}
+
+// NewRunID generates a unique ID for a run
+//
+// IDs are ULIDs (https://github.com/ulid/spec): 26 characters that sort by the time they were generated
+func NewRunID() string {
+	return newULID(time.Now())
+}
+
+// newULID encodes a 48 bit millisecond timestamp followed by 80 random bits as a ULID
+func newULID(t time.Time) string {
+	var b [16]byte
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(t.UnixMilli()))
+	copy(b[:6], ts[2:])
+	_, _ = rand.Read(b[6:])
+
+	// 26 characters of 5 bits each hold 130 bits, the first character only holds the top 3 bits
+	var out [26]byte
+	for i := range out {
+		var v byte
+		for j := range 5 {
+			bit := (25-i)*5 + j
+			if bit < 128 && b[15-bit/8]>>(bit%8)&1 == 1 {
+				v |= 1 << j
+			}
+		}
+		out[i] = crockford[v]
+	}
+	return string(out[:])
+}
+
+// WithRunID returns a context carrying the ID of the current run
+//
+// The ID is shared by every task and nested uses: call within the run
+func WithRunID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, runIDKey{}, id)
+}
+
+// RunID returns the ID of the current run, or an empty string if there is none
+func RunID(ctx context.Context) string {
+	id, _ := ctx.Value(runIDKey{}).(string)
+	return id
+}
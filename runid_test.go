@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRunID(t *testing.T) {
+	pattern := regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+
+	id := NewRunID()
+	assert.Regexp(t, pattern, id)
+	assert.NotEqual(t, id, NewRunID())
+
+	// the timestamp is encoded in the first 10 characters
+	// examples from https://github.com/ulid/spec
+	assert.True(t, strings.HasPrefix(newULID(time.UnixMilli(1469918176385)), "01ARYZ6S41"))
+	assert.True(t, strings.HasPrefix(newULID(time.UnixMilli(0)), "0000000000"))
+	assert.True(t, strings.HasPrefix(newULID(time.UnixMilli(1<<48-1)), "7ZZZZZZZZZ"))
+
+	earlier := newULID(time.UnixMilli(1469918176385))
+	later := newULID(time.UnixMilli(1469918176386))
+	assert.Less(t, earlier, later)
+}
+
+func TestRunIDContext(t *testing.T) {
+	ctx := t.Context()
+	assert.Empty(t, RunID(ctx))
+
+	ctx = WithRunID(ctx, "01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	assert.Equal(t, "01ARZ3NDEKTSV4RRFFQ69G5FAV", RunID(ctx))
+}
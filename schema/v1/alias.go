@@ -48,6 +48,8 @@ type Alias struct {
 	BaseURL      string `json:"base-url,omitempty"`
 	TokenFromEnv string `json:"token-from-env,omitempty"`
 	Path         string `json:"path,omitempty"`
+	// Headers to send with every request to the alias's base URL, only supported in the system config
+	Headers map[string]string `json:"headers,omitempty"`
 }
 
 // JSONSchemaExtend extends the JSON schema for an alias
@@ -87,6 +89,14 @@ https://github.com/package-url/purl-spec#purl`,
 		Pattern:     EnvVariablePattern.String(),
 	})
 
+	remoteProps.Set("headers", &jsonschema.Schema{
+		Type:        "object",
+		Description: "Headers to send with every request to the base URL, values are expanded using environment variables (only supported in the system config)",
+		AdditionalProperties: &jsonschema.Schema{
+			Type: "string",
+		},
+	})
+
 	schema.OneOf = []*jsonschema.Schema{
 		{
 			// Local file alias - only path is allowed
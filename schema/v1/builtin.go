@@ -0,0 +1,217 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/defenseunicorns/maru2/builtins"
+	"github.com/defenseunicorns/maru2/schema"
+)
+
+// BuiltinName returns the name of the builtin referenced by uses, and whether uses references a builtin
+//
+// Any version suffix (builtin:name@version) is removed
+func BuiltinName(uses string) (string, bool) {
+	name, ok := strings.CutPrefix(uses, "builtin:")
+	if !ok {
+		return "", false
+	}
+	name, _, _ = strings.Cut(name, "@")
+	return name, true
+}
+
+// BuiltinWithSchema returns the JSON schema of a builtin's with, derived from its struct tags
+//
+// Unlike the workflow schema, fields are described by their Go types and do not also accept template strings.
+// Returns nil if the builtin is not registered
+func BuiltinWithSchema(name string) *jsonschema.Schema {
+	b := builtins.Get(name)
+	if b == nil {
+		return nil
+	}
+	reflector := jsonschema.Reflector{DoNotReference: true}
+	s := reflector.Reflect(b)
+	s.Version = ""
+	s.ID = jsonschema.EmptyID
+	return s
+}
+
+// ValidateBuiltinWith validates the with of a builtin step, returning an error for every invalid field
+//
+// Strings are converted to the types the builtin expects the same as when with is decoded, so rendered templates
+// are validated by their values. Unless rendered, values holding a template are only checked to be known inputs,
+// as they are not known until runtime
+func ValidateBuiltinWith(name string, with schema.With, rendered bool) error {
+	s := BuiltinWithSchema(name)
+	if s == nil {
+		return fmt.Errorf("builtin:%s not found%s", name, DidYouMean(name, builtins.Names()))
+	}
+
+	v := builtinValidator{rendered: rendered}
+	value := v.object("with", map[string]any(with), s)
+	if len(v.errs) > 0 {
+		return errors.Join(v.errs...)
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(b), gojsonschema.NewGoLoader(value))
+	if err != nil {
+		return fmt.Errorf("with: %w", err)
+	}
+
+	// errors are sorted, as gojsonschema visits properties in map order
+	msgs := make([]string, 0, len(result.Errors()))
+	for _, re := range result.Errors() {
+		field := "with"
+		if re.Field() != gojsonschema.STRING_ROOT_SCHEMA_PROPERTY {
+			field += "." + re.Field()
+		}
+		msgs = append(msgs, fmt.Sprintf("%s: %s", field, re.Description()))
+	}
+	slices.Sort(msgs)
+	for _, msg := range msgs {
+		v.errs = append(v.errs, errors.New(msg))
+	}
+	return errors.Join(v.errs...)
+}
+
+// builtinValidator converts values to the types of their schema and removes values holding a template,
+// collecting the errors of values that cannot be converted or are not known
+type builtinValidator struct {
+	rendered bool
+	errs     []error
+}
+
+// object converts the values of an object
+//
+// Values that were removed are no longer required by s, so s must not be shared
+func (v *builtinValidator) object(path string, obj map[string]any, s *jsonschema.Schema) map[string]any {
+	result := make(map[string]any, len(obj))
+	var removed []string
+
+	for _, key := range slices.Sorted(maps.Keys(obj)) {
+		val := obj[key]
+		fieldPath := path + "." + key
+
+		var field *jsonschema.Schema
+		if s.Properties != nil {
+			// keys are matched case-insensitively, the same as when with is decoded
+			for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+				if strings.EqualFold(pair.Key, key) {
+					key, field = pair.Key, pair.Value
+					break
+				}
+			}
+		}
+		if field == nil && s.AdditionalProperties != jsonschema.FalseSchema {
+			if s.AdditionalProperties == nil {
+				result[key] = val
+				continue
+			}
+			field = s.AdditionalProperties
+		}
+		if field == nil {
+			var known []string
+			if s.Properties != nil {
+				for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+					known = append(known, pair.Key)
+				}
+			}
+			v.errs = append(v.errs, fmt.Errorf("%s is not a known input%s", fieldPath, DidYouMean(key, known)))
+			continue
+		}
+
+		converted, ok := v.value(fieldPath, val, field)
+		if !ok {
+			removed = append(removed, key)
+			continue
+		}
+		result[key] = converted
+	}
+
+	s.Required = slices.DeleteFunc(s.Required, func(r string) bool {
+		return slices.Contains(removed, r)
+	})
+	return result
+}
+
+// value converts a value to the type of its schema, returning false if the value was removed
+func (v *builtinValidator) value(path string, val any, s *jsonschema.Schema) (any, bool) {
+	if str, ok := val.(string); ok && !v.rendered && strings.Contains(str, "${{") {
+		return nil, false
+	}
+
+	switch s.Type {
+	case "string":
+		switch val.(type) {
+		case bool, int, int64, uint64, float64:
+			return fmt.Sprint(val), true
+		}
+	case "integer":
+		if str, ok := val.(string); ok {
+			i, err := strconv.ParseInt(strings.TrimSpace(str), 0, 64)
+			if err != nil {
+				v.errs = append(v.errs, fmt.Errorf("%s: %q is not an integer", path, str))
+				return nil, false
+			}
+			return i, true
+		}
+	case "number":
+		if str, ok := val.(string); ok {
+			f, err := strconv.ParseFloat(strings.TrimSpace(str), 64)
+			if err != nil {
+				v.errs = append(v.errs, fmt.Errorf("%s: %q is not a number", path, str))
+				return nil, false
+			}
+			return f, true
+		}
+	case "boolean":
+		if str, ok := val.(string); ok {
+			b, err := strconv.ParseBool(strings.TrimSpace(str))
+			if err != nil {
+				v.errs = append(v.errs, fmt.Errorf("%s: %q is not a boolean", path, str))
+				return nil, false
+			}
+			return b, true
+		}
+	case "array":
+		arr, ok := val.([]any)
+		if !ok {
+			// a single value is decoded as a slice of one
+			arr = []any{val}
+		}
+		if s.Items == nil {
+			return arr, true
+		}
+		result := make([]any, 0, len(arr))
+		for i, item := range arr {
+			converted, ok := v.value(fmt.Sprintf("%s[%d]", path, i), item, s.Items)
+			if ok {
+				result = append(result, converted)
+			}
+		}
+		return result, true
+	case "object":
+		obj, ok := val.(map[string]any)
+		if !ok {
+			return val, true
+		}
+		return v.object(path, obj, s), true
+	}
+	return val, true
+}
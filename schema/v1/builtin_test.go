@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defenseunicorns/maru2/schema"
+)
+
+func TestBuiltinName(t *testing.T) {
+	name, ok := BuiltinName("builtin:echo")
+	assert.True(t, ok)
+	assert.Equal(t, "echo", name)
+
+	name, ok = BuiltinName("builtin:fetch@v1")
+	assert.True(t, ok)
+	assert.Equal(t, "fetch", name)
+
+	_, ok = BuiltinName("file:tasks.yaml")
+	assert.False(t, ok)
+}
+
+func TestBuiltinWithSchema(t *testing.T) {
+	s := BuiltinWithSchema("http-request")
+	require.NotNil(t, s)
+	assert.Equal(t, []string{"url"}, s.Required)
+
+	status, ok := s.Properties.Get("status")
+	require.True(t, ok)
+	assert.Equal(t, "integer", status.Type)
+
+	assert.Nil(t, BuiltinWithSchema("missing"))
+}
+
+func TestValidateBuiltinWith(t *testing.T) {
+	testCases := []struct {
+		name          string
+		builtin       string
+		with          schema.With
+		rendered      bool
+		expectedError string
+	}{
+		{
+			name:    "valid",
+			builtin: "http-request",
+			with:    schema.With{"url": "https://example.com", "status": 201, "headers": map[string]any{"Accept": "application/json"}},
+		},
+		{
+			name:    "strings are converted",
+			builtin: "http-request",
+			with:    schema.With{"url": "https://example.com", "status": "201"},
+		},
+		{
+			name:     "values are converted to strings",
+			builtin:  "echo",
+			with:     schema.With{"text": 42},
+			rendered: true,
+		},
+		{
+			name:    "keys match case-insensitively",
+			builtin: "http-request",
+			with:    schema.With{"URL": "https://example.com"},
+		},
+		{
+			name:    "single value as a slice",
+			builtin: "cache-save",
+			with:    schema.With{"key": "go", "paths": "vendor"},
+		},
+		{
+			name:    "templates are skipped",
+			builtin: "http-request",
+			with:    schema.With{"url": "${{ input \"url\" }}", "status": "${{ input \"status\" }}"},
+		},
+		{
+			name:          "rendered templates are validated",
+			builtin:       "http-request",
+			with:          schema.With{"url": "${{ input \"url\" }}", "status": "${{ input \"status\" }}"},
+			rendered:      true,
+			expectedError: `with.status: "${{ input \"status\" }}" is not an integer`,
+		},
+		{
+			name:          "not found",
+			builtin:       "fecth",
+			expectedError: `builtin:fecth not found, did you mean "fetch"?`,
+		},
+		{
+			name:          "unknown input",
+			builtin:       "fetch",
+			with:          schema.With{"url": "https://example.com", "mehtod": "POST"},
+			expectedError: `with.mehtod is not a known input, did you mean "method"?`,
+		},
+		{
+			name:          "required input",
+			builtin:       "cache-save",
+			with:          schema.With{"paths": []any{"vendor"}},
+			expectedError: "with: key is required",
+		},
+		{
+			name:          "invalid inputs",
+			builtin:       "http-request",
+			with:          schema.With{"url": "https://example.com", "status": "ok", "timeout": []any{"1s"}},
+			expectedError: "with.status: \"ok\" is not an integer",
+		},
+		{
+			name:          "constraints",
+			builtin:       "http-request",
+			with:          schema.With{"url": "https://example.com", "status": 600, "timeout": []any{"1s"}},
+			expectedError: "with.status: Must be less than or equal to 599\nwith.timeout: Invalid type. Expected: string, given: array",
+		},
+		{
+			name:          "empty slice",
+			builtin:       "cache-save",
+			with:          schema.With{"key": "go", "paths": []any{}},
+			expectedError: "with.paths: Array must have at least 1 items",
+		},
+		{
+			name:          "nested input",
+			builtin:       "http-request",
+			with:          schema.With{"url": "https://example.com", "headers": map[string]any{"Accept": []any{"application/json"}}},
+			expectedError: "with.headers.Accept: Invalid type. Expected: string, given: array",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateBuiltinWith(tc.builtin, tc.with, tc.rendered)
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package v1
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+)
+
+// templateStub stands in for a template function, only its name is needed to parse templates
+func templateStub(...any) any { return nil }
+
+// templateFuncs mirrors the functions of maru2.TemplateString
+var templateFuncs = template.FuncMap{
+	"input":     templateStub,
+	"from":      templateStub,
+	"which":     templateStub,
+	"hashFiles": templateStub,
+	"secret":    templateStub,
+	"env":       templateStub,
+	"file":      templateStub,
+	"fromJSON":  templateStub,
+	"fromYAML":  templateStub,
+	"toJSON":    templateStub,
+	"steps":     templateStub,
+}
+
+// templateFields mirrors the data of maru2.TemplateString
+var templateFields = []string{"OS", "ARCH", "PLATFORM", "RUN_ID", "TASK_NAME", "WORKFLOW_ORIGIN", "DRY_RUN"}
+
+// ifStub stands in for an if expression function, only its signature is needed to compile expressions
+func ifStub(...any) (any, error) { return nil, nil }
+
+// ifOptions mirror the environment and functions of maru2.ShouldRun
+var ifOptions = []expr.Option{
+	expr.Env(struct {
+		OS       string                    `expr:"os"`
+		Arch     string                    `expr:"arch"`
+		Platform string                    `expr:"platform"`
+		Steps    map[string]map[string]any `expr:"steps"`
+	}{}),
+	expr.AsBool(),
+	expr.Function("failure", ifStub, new(func() bool)),
+	expr.Function("cancelled", ifStub, new(func() bool)),
+	expr.Function("always", ifStub, new(func() bool)),
+	expr.Function("input", ifStub, new(func(string) any)),
+	expr.Function("from", ifStub, new(func(string, string) any)),
+	expr.Function("fromJSON", ifStub, new(func(any) any)),
+	expr.Function("fromYAML", ifStub, new(func(any) any)),
+	expr.Function("toJSON", ifStub, new(func(any) string)),
+}
+
+// references are the inputs and step IDs an expression can refer to
+//
+// A nil references only checks syntax, as in lenient templates where missing references are allowed.
+// A task without declared inputs accepts any with values, so its input references are not checked
+type references struct {
+	task   string
+	inputs []string
+	steps  []string
+	// used records the inputs referenced instead of checking them, as done by Lint
+	used map[string]bool
+}
+
+// check returns an error if the input or step ID a function is called with cannot be referenced
+func (r *references) check(fn, arg string) error {
+	if r == nil {
+		return nil
+	}
+	if r.used != nil {
+		if fn == "input" {
+			r.used[arg] = true
+		}
+		return nil
+	}
+	switch fn {
+	case "input":
+		if len(r.inputs) > 0 && !slices.Contains(r.inputs, arg) {
+			return fmt.Errorf("input %q is not declared by task %q%s", arg, r.task, DidYouMean(arg, r.inputs))
+		}
+	case "from":
+		if !slices.Contains(r.steps, arg) {
+			return fmt.Errorf("step %q is not an earlier step of task %q%s", arg, r.task, DidYouMean(arg, r.steps))
+		}
+	}
+	return nil
+}
+
+// checkTemplate parses a template, returning an error for syntax errors, unknown functions and fields,
+// and references to inputs and steps that do not exist
+func checkTemplate(path, str string, refs *references) error {
+	if !strings.Contains(str, "${{") {
+		return nil
+	}
+
+	tmpl, err := template.New(path).Funcs(templateFuncs).Delims("${{", "}}").Parse(str)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		if err := walkTemplate(t.Root, true, refs); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// walkTemplate checks the nodes of a parsed template, top is false within range and with blocks where dot is rebound
+func walkTemplate(node parse.Node, top bool, refs *references) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := walkTemplate(child, top, refs); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return walkTemplate(n.Pipe, top, refs)
+	case *parse.IfNode:
+		return walkBranch(&n.BranchNode, top, top, refs)
+	case *parse.RangeNode:
+		return walkBranch(&n.BranchNode, top, false, refs)
+	case *parse.WithNode:
+		return walkBranch(&n.BranchNode, top, false, refs)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			if err := walkTemplate(cmd, top, refs); err != nil {
+				return err
+			}
+		}
+	case *parse.CommandNode:
+		if len(n.Args) > 1 {
+			if fn, ok := n.Args[0].(*parse.IdentifierNode); ok {
+				if arg, ok := n.Args[1].(*parse.StringNode); ok {
+					if err := refs.check(fn.Ident, arg.Text); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		for _, arg := range n.Args {
+			if err := walkTemplate(arg, top, refs); err != nil {
+				return err
+			}
+		}
+	case *parse.ChainNode:
+		return walkTemplate(n.Node, top, refs)
+	case *parse.FieldNode:
+		if top && !slices.Contains(templateFields, n.Ident[0]) {
+			return fmt.Errorf("field .%s does not exist%s", n.Ident[0], DidYouMean(n.Ident[0], templateFields))
+		}
+	}
+	return nil
+}
+
+// walkBranch checks the pipeline and lists of an if, range or with block
+func walkBranch(n *parse.BranchNode, top, inner bool, refs *references) error {
+	if err := walkTemplate(n.Pipe, top, refs); err != nil {
+		return err
+	}
+	if err := walkTemplate(n.List, inner, refs); err != nil {
+		return err
+	}
+	return walkTemplate(n.ElseList, top, refs)
+}
+
+// checkTemplates checks every template within the values of a with or env map
+func checkTemplates(path string, values map[string]any, refs *references) error {
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		if err := checkTemplateValue(path+"."+key, values[key], refs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkTemplateValue checks every template within a value, descending into maps and slices
+func checkTemplateValue(path string, value any, refs *references) error {
+	switch v := value.(type) {
+	case string:
+		return checkTemplate(path, v, refs)
+	case map[string]any:
+		return checkTemplates(path, v, refs)
+	case []any:
+		for i, item := range v {
+			if err := checkTemplateValue(fmt.Sprintf("%s[%d]", path, i), item, refs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkIf compiles an if expression, returning an error for syntax errors, unknown functions and variables,
+// and references to inputs and steps that do not exist
+func checkIf(expression string, refs *references) error {
+	program, err := expr.Compile(expression, ifOptions...)
+	if err != nil {
+		return err
+	}
+
+	v := &ifReferences{refs: refs}
+	root := program.Node()
+	ast.Walk(&root, v)
+	return v.err
+}
+
+// ifReferences records the first invalid reference within an if expression
+type ifReferences struct {
+	refs *references
+	err  error
+}
+
+// Visit implements ast.Visitor
+func (v *ifReferences) Visit(node *ast.Node) {
+	call, ok := (*node).(*ast.CallNode)
+	if !ok || v.err != nil || len(call.Arguments) == 0 {
+		return
+	}
+	fn, ok := call.Callee.(*ast.IdentifierNode)
+	if !ok {
+		return
+	}
+	if arg, ok := call.Arguments[0].(*ast.StringNode); ok {
+		v.err = v.refs.check(fn.Value, arg.Value)
+	}
+}
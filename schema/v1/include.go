@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package v1
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// Include merges the tasks of another local workflow file into a workflow when it is fetched
+type Include struct {
+	// Path to the workflow file, relative to the including workflow
+	Path string `json:"path"`
+	// Prefix is prepended to the name of every included task
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// JSONSchemaExtend extends the JSON schema for an include
+func (Include) JSONSchemaExtend(schema *jsonschema.Schema) {
+	schema.Description = "A local workflow file whose tasks are merged into this workflow"
+
+	var one uint64 = 1
+
+	if p, ok := schema.Properties.Get("path"); ok && p != nil {
+		p.Description = "Relative path to the workflow file"
+		p.MinLength = &one
+	}
+	if prefix, ok := schema.Properties.Get("prefix"); ok && prefix != nil {
+		prefix.Description = `Prepended to the name of every included task (e.g. "ci-" includes build as ci-build)`
+		prefix.Pattern = TaskNamePattern.String()
+	}
+}
+
+// Include returns wf with the tasks of an included workflow merged into it, their names prefixed with inc.Prefix
+//
+// uses: references of included tasks are rewritten to call the same tasks and files as they did from the included
+// workflow, and the aliases of the included workflow are added to wf. The included workflow's inputs are already
+// inherited by its tasks, while its tests are not included
+func (wf Workflow) Include(inc Include, included Workflow) (Workflow, error) {
+	dir := path.Dir(inc.Path)
+
+	wf.Tasks = maps.Clone(wf.Tasks)
+	if wf.Tasks == nil {
+		wf.Tasks = TaskMap{}
+	}
+	for name, task := range included.Tasks.OrderedSeq() {
+		if _, ok := wf.Tasks[inc.Prefix+name]; ok {
+			return wf, fmt.Errorf("task %q already exists", inc.Prefix+name)
+		}
+
+		steps := make([]Step, len(task.Steps))
+		for idx, step := range task.Steps {
+			steps[idx] = includeStep(step, inc.Prefix, dir, included.Tasks)
+		}
+		task.Steps = steps
+		if task.AliasOf != "" {
+			task.AliasOf = inc.Prefix + task.AliasOf
+		}
+		wf.Tasks[inc.Prefix+name] = task
+	}
+
+	if len(included.Aliases) > 0 {
+		wf.Aliases = maps.Clone(wf.Aliases)
+		if wf.Aliases == nil {
+			wf.Aliases = AliasMap{}
+		}
+	}
+	for name, alias := range included.Aliases.OrderedSeq() {
+		if alias.Path != "" {
+			alias.Path = path.Join(dir, alias.Path)
+		}
+		if existing, ok := wf.Aliases[name]; ok {
+			if existing.Type != alias.Type || existing.BaseURL != alias.BaseURL || existing.TokenFromEnv != alias.TokenFromEnv || existing.Path != alias.Path {
+				return wf, fmt.Errorf("alias %q is already defined differently", name)
+			}
+			continue
+		}
+		wf.Aliases[name] = alias
+	}
+
+	return wf, nil
+}
+
+// includeStep rewrites the uses: reference of an included step relative to the including workflow
+func includeStep(step Step, prefix, dir string, tasks TaskMap) Step {
+	if _, ok := tasks.Find(step.Uses); ok {
+		step.Uses = prefix + step.Uses
+		return step
+	}
+
+	// builtin:maru2 without a from runs a task from the current workflow
+	if step.Uses == "builtin:maru2" && step.With["from"] == nil {
+		if called, ok := step.With["task"].(string); ok {
+			if _, ok := tasks.Find(called); ok {
+				step.With = maps.Clone(step.With)
+				step.With["task"] = prefix + called
+			}
+		}
+		return step
+	}
+
+	u, err := url.Parse(step.Uses)
+	if err != nil || u.Scheme != "file" || u.Opaque == "" || path.IsAbs(u.Opaque) {
+		return step
+	}
+	rel := path.Join(dir, u.Opaque)
+	if strings.HasSuffix(u.Opaque, "/") {
+		rel += "/"
+	}
+	u.Opaque = rel
+	step.Uses = u.String()
+	return step
+}
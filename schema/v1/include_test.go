@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowInclude(t *testing.T) {
+	included := Workflow{
+		SchemaVersion: SchemaVersion,
+		Aliases: AliasMap{
+			"scripts": Alias{Path: "scripts/tasks.yaml"},
+		},
+		Tasks: TaskMap{
+			"build": Task{Steps: []Step{
+				{Uses: "lint"},
+				{Uses: "file:release.yaml?task=release"},
+				{Uses: "builtin:maru2", With: map[string]any{"task": "lint"}},
+				{Uses: "builtin:maru2", With: map[string]any{"from": "file:other.yaml", "task": "lint"}},
+				{Uses: "scripts:setup"},
+				{Run: "echo build"},
+			}},
+			"lint": Task{Steps: []Step{{Run: "echo lint"}}},
+		},
+	}
+
+	wf := Workflow{
+		SchemaVersion: SchemaVersion,
+		Tasks: TaskMap{
+			"default": Task{Steps: []Step{{Uses: "ci-build"}}},
+		},
+	}
+
+	merged, err := wf.Include(Include{Path: "ci/tasks.yaml", Prefix: "ci-"}, included)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"default", "ci-build", "ci-lint"}, merged.Tasks.OrderedTaskNames())
+	assert.Equal(t, []Step{
+		{Uses: "ci-lint"},
+		{Uses: "file:ci/release.yaml?task=release"},
+		{Uses: "builtin:maru2", With: map[string]any{"task": "ci-lint"}},
+		{Uses: "builtin:maru2", With: map[string]any{"from": "file:other.yaml", "task": "lint"}},
+		{Uses: "scripts:setup"},
+		{Run: "echo build"},
+	}, merged.Tasks["ci-build"].Steps)
+	assert.Equal(t, AliasMap{"scripts": Alias{Path: "ci/scripts/tasks.yaml"}}, merged.Aliases)
+
+	// the included workflow and the including workflow are left untouched
+	assert.Equal(t, "lint", included.Tasks["build"].Steps[0].Uses)
+	assert.Equal(t, "lint", included.Tasks["build"].Steps[2].With["task"])
+	assert.Len(t, wf.Tasks, 1)
+
+	_, err = merged.Include(Include{Path: "ci/tasks.yaml", Prefix: "ci-"}, included)
+	require.EqualError(t, err, `task "ci-build" already exists`)
+
+	_, err = wf.Include(Include{Path: "other/tasks.yaml", Prefix: "other-"}, Workflow{
+		Aliases: AliasMap{"scripts": Alias{Path: "scripts/tasks.yaml"}},
+	})
+	require.NoError(t, err)
+
+	_, err = merged.Include(Include{Path: "other/tasks.yaml", Prefix: "other-"}, Workflow{
+		Aliases: AliasMap{"scripts": Alias{Path: "scripts/tasks.yaml"}},
+	})
+	require.EqualError(t, err, `alias "scripts" is already defined differently`)
+}
@@ -59,6 +59,45 @@ type InputParameter struct {
 	DefaultFromEnv string `json:"default-from-env,omitempty"`
 	// Regular expression to validate the value of the parameter
 	Validate string `json:"validate,omitempty"`
+	// Inputs that cannot be provided along with this one
+	ConflictsWith []string `json:"conflicts-with,omitempty"`
+	// Inputs that must have a value when this one is provided
+	Requires []string `json:"requires,omitempty"`
+	// Type of the parameter's value, "path" for a file or directory path
+	Type string `json:"type,omitempty"`
+	// Checks on the value of a path parameter
+	Path *PathOptions `json:"path,omitempty"`
+}
+
+// InputTypePath is the type of an input parameter whose value is a file or directory path
+const InputTypePath = "path"
+
+// PathOptions are the checks applied to the value of a path input parameter
+type PathOptions struct {
+	// Whether the path must exist
+	MustExist bool `json:"must-exist,omitempty"`
+	// Whether the path must be a "file" or a "directory", implies must-exist
+	Kind string `json:"kind,omitempty"`
+	// File extensions the path must end with, one of
+	Extensions []string `json:"extensions,omitempty"`
+}
+
+// JSONSchemaExtend extends the JSON schema for path options
+func (PathOptions) JSONSchemaExtend(schema *jsonschema.Schema) {
+	schema.Description = "Checks on the value of a path parameter"
+
+	if mustExist, ok := schema.Properties.Get("must-exist"); ok && mustExist != nil {
+		mustExist.Description = "Whether the path must exist"
+	}
+	if kind, ok := schema.Properties.Get("kind"); ok && kind != nil {
+		kind.Description = "Whether the path must be a file or a directory, implies must-exist"
+		kind.Enum = []any{"file", "directory"}
+	}
+	if extensions, ok := schema.Properties.Get("extensions"); ok && extensions != nil {
+		extensions.Description = "File extensions the path must end with, one of"
+		extensions.Items.Pattern = `^\.[^/\\]+$`
+		extensions.Examples = []any{[]any{".yaml", ".yml"}, []any{".tar.gz"}}
+	}
 }
 
 // JSONSchemaExtend generates detailed schema documentation for input parameters
@@ -105,6 +144,45 @@ See https://github.com/defenseunicorns/maru2/blob/main/docs/syntax.md#input-vali
 			},
 		},
 	})
+	schema.Properties.Set("conflicts-with", &jsonschema.Schema{
+		Type: "array",
+		Items: &jsonschema.Schema{
+			Type:    "string",
+			Pattern: InputNamePattern.String(),
+		},
+		Description: `Inputs that cannot be provided along with this one, a required input is also satisfied by providing an input it conflicts with
+
+See https://github.com/defenseunicorns/maru2/blob/main/docs/syntax.md#input-relationships`,
+	})
+
+	schema.Properties.Set("requires", &jsonschema.Schema{
+		Type: "array",
+		Items: &jsonschema.Schema{
+			Type:    "string",
+			Pattern: InputNamePattern.String(),
+		},
+		Description: `Inputs that must have a value when this one is provided
+
+See https://github.com/defenseunicorns/maru2/blob/main/docs/syntax.md#input-relationships`,
+	})
+
+	schema.Properties.Set("type", &jsonschema.Schema{
+		Type: "string",
+		Enum: []any{InputTypePath},
+		Description: `Type of the parameter's value, values of "path" parameters are checked with the path options and completed as paths
+
+See https://github.com/defenseunicorns/maru2/blob/main/docs/syntax.md#path-inputs`,
+	})
+
+	pathOptions, _ := schema.Properties.Get("path")
+	schema.Properties.Set("path", pathOptions)
+
+	schema.DependentSchemas = map[string]*jsonschema.Schema{
+		"path": {
+			Required: []string{"type"},
+		},
+	}
+
 	schema.Properties.Set("default-from-env", &jsonschema.Schema{
 		Type: "string",
 		Description: `Environment variable to use as default value for the parameter
@@ -113,3 +191,14 @@ See https://github.com/defenseunicorns/maru2/blob/main/docs/syntax.md#default-va
 		Pattern: EnvVariablePattern.String(),
 	})
 }
+
+// Conflicts returns the inputs that conflict with an input, whichever of the two declares the conflict
+func (im InputMap) Conflicts(name string) []string {
+	conflicts := slices.Clone(im[name].ConflictsWith)
+	for other, param := range im.OrderedSeq() {
+		if slices.Contains(param.ConflictsWith, name) && !slices.Contains(conflicts, other) {
+			conflicts = append(conflicts, other)
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package v1
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/defenseunicorns/maru2/schema"
+)
+
+// Lint returns warnings for a valid workflow that do not stop it from running
+//
+// Inputs are flagged when no template, if expression or INPUT_ environment variable references them,
+// uses: of deprecated tasks and uses: that call back into a calling task are flagged, and tasks are flagged when they cannot be reached from an entrypoint:
+// the default task, a task with a description, or a task run by a test
+func Lint(wf Workflow) []string {
+	var warnings []string
+
+	workflowUsed := map[string]bool{}
+	for name, task := range wf.Tasks.OrderedSeq() {
+		used := inputsUsed(task)
+		for inputName := range used {
+			workflowUsed[inputName] = true
+		}
+		for _, inputName := range slices.Sorted(maps.Keys(task.Inputs)) {
+			// inputs inherited from the workflow are only required to be used by one task
+			if _, ok := wf.Inputs[inputName]; ok || used[inputName] {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf(".tasks.%s.inputs.%s is never referenced", name, inputName))
+		}
+	}
+	for _, inputName := range slices.Sorted(maps.Keys(wf.Inputs)) {
+		if !workflowUsed[inputName] {
+			warnings = append(warnings, fmt.Sprintf(".inputs.%s is never referenced", inputName))
+		}
+	}
+
+	for name, task := range wf.Tasks.OrderedSeq() {
+		for idx, step := range task.Steps {
+			called, ok := wf.Tasks.Find(step.Uses)
+			if !ok {
+				continue
+			}
+			if msg := cmp.Or(wf.Tasks[step.Uses].DeprecatedMessage, called.DeprecatedMessage); msg != "" {
+				warnings = append(warnings, fmt.Sprintf(".tasks.%s[%d].uses %q is deprecated: %s", name, idx, step.Uses, msg))
+			}
+		}
+	}
+
+	warnings = append(warnings, usesCycles(wf)...)
+
+	reachable := reachableTasks(wf)
+	for _, name := range wf.Tasks.OrderedTaskNames() {
+		if !reachable[name] {
+			warnings = append(warnings, fmt.Sprintf(".tasks.%s is not used by the default task, a task with a description or a test", name))
+		}
+	}
+
+	return warnings
+}
+
+// usesCycles returns a warning for each uses: that calls back into a task that is still running
+//
+// The recursion is allowed as long as an if: ends it, cycles through other workflows are reported
+// by maru2.FetchAll once those workflows are fetched
+func usesCycles(wf Workflow) []string {
+	var warnings []string
+	done := make(map[string]bool, len(wf.Tasks))
+	var path []string
+
+	var visit func(name string)
+	visit = func(name string) {
+		if done[name] {
+			return
+		}
+		path = append(path, name)
+		task, _ := wf.Tasks.Find(name)
+		for idx, step := range task.Steps {
+			if _, ok := wf.Tasks.Find(step.Uses); !ok {
+				continue
+			}
+			// an alias calls the task it is an alias of
+			called := cmp.Or(wf.Tasks[step.Uses].AliasOf, step.Uses)
+			if i := slices.Index(path, called); i >= 0 {
+				cycle := append(slices.Clone(path[i:]), called)
+				warnings = append(warnings, fmt.Sprintf(".tasks.%s[%d].uses %q forms a cycle: %s", name, idx, step.Uses, strings.Join(cycle, " -> ")))
+				continue
+			}
+			visit(called)
+		}
+		path = path[:len(path)-1]
+		done[name] = true
+	}
+
+	for name, task := range wf.Tasks.OrderedSeq() {
+		if task.AliasOf == "" {
+			visit(name)
+		}
+	}
+	return warnings
+}
+
+// inputsUsed returns the inputs a task references in its templates, if expressions and run scripts
+func inputsUsed(task Task) map[string]bool {
+	refs := &references{used: map[string]bool{}}
+
+	// errors are reported by Validate, only references are of interest here
+	_ = checkTemplate("runs-on", task.RunsOn, refs)
+	_ = checkTemplate("confirm", task.Confirm, refs)
+	for _, step := range task.Steps {
+		if step.If != "" {
+			_ = checkIf(step.If, refs)
+		}
+		_ = checkTemplates("env", step.Env, refs)
+		_ = checkTemplates("with", step.With, refs)
+
+		for _, run := range append(slices.Collect(maps.Values(step.RunVariants())), step.Run) {
+			_ = checkTemplate("run", run, refs)
+
+			// every input is also set as an INPUT_ environment variable for run steps
+			for inputName := range task.Inputs {
+				if strings.Contains(run, "INPUT_"+strings.ToUpper(strings.ReplaceAll(inputName, "-", "_"))) {
+					refs.used[inputName] = true
+				}
+			}
+		}
+	}
+	return refs.used
+}
+
+// reachableTasks returns the tasks called, directly or through other tasks, by the default task,
+// tasks with a description, task aliases, deprecated tasks and tasks run by tests
+func reachableTasks(wf Workflow) map[string]bool {
+	reachable := map[string]bool{}
+
+	var visit func(name string)
+	visit = func(name string) {
+		task, ok := wf.Tasks.Find(name)
+		if !ok || reachable[name] {
+			return
+		}
+		reachable[name] = true
+		if aliasOf := wf.Tasks[name].AliasOf; aliasOf != "" {
+			visit(aliasOf)
+			return
+		}
+		for _, step := range task.Steps {
+			visit(step.Uses)
+			// builtin:maru2 without a from runs a task from the current workflow
+			if step.Uses == "builtin:maru2" && step.With["from"] == nil {
+				if called, ok := step.With["task"].(string); ok {
+					visit(called)
+				}
+			}
+		}
+	}
+
+	for name, task := range wf.Tasks.OrderedSeq() {
+		if name == schema.DefaultTaskName || task.Description != "" || task.AliasOf != "" || task.DeprecatedMessage != "" {
+			visit(name)
+		}
+	}
+	for _, test := range wf.Tests.OrderedSeq() {
+		visit(cmp.Or(test.Task, schema.DefaultTaskName))
+	}
+	return reachable
+}
@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	testCases := []struct {
+		name     string
+		wf       Workflow
+		expected []string
+	}{
+		{
+			name: "no warnings",
+			wf: Workflow{
+				Inputs: InputMap{"env": InputParameter{}},
+				Tasks: TaskMap{
+					"default": Task{
+						Inputs:  InputMap{"name": InputParameter{}, "force": InputParameter{}, "dry-run": InputParameter{}, "host": InputParameter{}},
+						RunsOn:  `ssh://${{ input "host" }}`,
+						Confirm: `Deploy to ${{ input "env" }}?`,
+						Steps: []Step{
+							{Run: `echo ${{ input "name" }}`, If: `input("force") == true`},
+							{Run: `./deploy.sh --dry-run=$INPUT_DRY_RUN`},
+							{Uses: "helper"},
+						},
+					},
+					"helper": Task{Steps: []Step{{Uses: "builtin:maru2", With: map[string]any{"task": "nested"}}}},
+					"nested": Task{Steps: []Step{{Run: "echo"}}},
+					"lint": Task{
+						Description: "Lint the code",
+						Steps:       []Step{{Run: "golangci-lint run"}},
+					},
+					"tested": Task{Steps: []Step{{Run: "echo"}}},
+				},
+				Tests: TestMap{"runs-tested": Test{Task: "tested"}},
+			},
+		},
+		{
+			name: "unused inputs",
+			wf: Workflow{
+				Inputs: InputMap{"env": InputParameter{}, "region": InputParameter{}},
+				Tasks: TaskMap{
+					"default": Task{
+						Inputs: InputMap{"name": InputParameter{}, "env": InputParameter{}, "region": InputParameter{}},
+						Steps:  []Step{{Run: `echo ${{ input "env" }}`}},
+					},
+				},
+			},
+			expected: []string{
+				".tasks.default.inputs.name is never referenced",
+				".inputs.region is never referenced",
+			},
+		},
+		{
+			name: "unreachable tasks",
+			wf: Workflow{
+				Tasks: TaskMap{
+					"build":  Task{Steps: []Step{{Uses: "helper"}}},
+					"helper": Task{Steps: []Step{{Run: "echo"}}},
+					"remote": Task{Steps: []Step{{Uses: "builtin:maru2", With: map[string]any{"from": "file:other.yaml", "task": "helper"}}}},
+				},
+			},
+			expected: []string{
+				".tasks.build is not used by the default task, a task with a description or a test",
+				".tasks.helper is not used by the default task, a task with a description or a test",
+				".tasks.remote is not used by the default task, a task with a description or a test",
+			},
+		},
+		{
+			name: "uses of deprecated tasks",
+			wf: Workflow{
+				Tasks: TaskMap{
+					"default": Task{Steps: []Step{{Uses: "old-build"}, {Uses: "b"}, {Uses: "build"}}},
+					"build":   Task{Steps: []Step{{Run: "go build"}}},
+					"b":       Task{AliasOf: "build", DeprecatedMessage: "use build"},
+					"old-build": Task{
+						DeprecatedMessage: "use build",
+						Steps:             []Step{{Uses: "build"}},
+					},
+					"older-build": Task{
+						DeprecatedMessage: "use build",
+						Steps:             []Step{{Uses: "build"}},
+					},
+				},
+			},
+			expected: []string{
+				`.tasks.default[0].uses "old-build" is deprecated: use build`,
+				`.tasks.default[1].uses "b" is deprecated: use build`,
+			},
+		},
+		{
+			name: "uses cycles",
+			wf: Workflow{
+				Tasks: TaskMap{
+					"default": Task{Steps: []Step{{Uses: "b"}, {Uses: "t"}}},
+					"b":       Task{Steps: []Step{{Run: "echo"}, {Uses: "c"}}},
+					"c":       Task{Steps: []Step{{Uses: "default", If: "failure()"}}},
+					"t":       Task{AliasOf: "retry"},
+					"retry":   Task{Steps: []Step{{Run: "exit 1"}, {Uses: "t", If: "failure()"}}},
+				},
+			},
+			expected: []string{
+				`.tasks.c[0].uses "default" forms a cycle: default -> b -> c -> default`,
+				`.tasks.retry[1].uses "t" forms a cycle: retry -> retry`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Lint(tc.wf))
+		})
+	}
+}
@@ -13,3 +13,9 @@ var InputNamePattern = TaskNamePattern // regexp.MustCompile("^\\$[A-Z_]+[A-Z0-9
 
 // EnvVariablePattern is a regular expression for valid environment variable names
 var EnvVariablePattern = regexp.MustCompile("^[a-zA-Z_]+[a-zA-Z0-9_]*$")
+
+// MutexNamePattern is a regular expression for valid task and step mutex names
+var MutexNamePattern = TaskNamePattern
+
+// PlatformPattern is a regular expression for valid task platforms, as os or os/arch
+var PlatformPattern = regexp.MustCompile("^[a-z0-9]+(/[a-z0-9]+)?$")
@@ -21,6 +21,12 @@ import (
 type Step struct {
 	// Run is the command/script to run
 	Run string `json:"run,omitempty"`
+	// RunLinux replaces Run on Linux
+	RunLinux string `json:"run-linux,omitempty"`
+	// RunDarwin replaces Run on macOS
+	RunDarwin string `json:"run-darwin,omitempty"`
+	// RunWindows replaces Run on Windows
+	RunWindows string `json:"run-windows,omitempty"`
 	// Env is a map of environment variables
 	Env schema.Env `json:"env,omitempty"`
 	// Uses is a reference to another task
@@ -31,6 +37,8 @@ type Step struct {
 	ID string `json:"id,omitempty"`
 	// Name is a human-readable name for the step, pure sugar
 	Name string `json:"name,omitempty"`
+	// Description explains what the step does, shown by Explain, generated docs and dry run plans
+	Description string `json:"description,omitempty"`
 	// If controls whether the step is executed
 	If string `json:"if,omitempty"`
 	// Dir is the directory to run the step in
@@ -45,6 +53,12 @@ type Step struct {
 	Mute bool `json:"mute,omitempty"`
 	// Show controls whether the rendered script is printed
 	Show *bool `json:"show,omitempty"`
+	// Mutex is the name of a mutex held while the step runs
+	Mutex string `json:"mutex,omitempty"`
+	// Collapse controls whether the step's output is grouped in CI environments, overriding the workflow's defaults
+	Collapse *bool `json:"collapse,omitempty"`
+	// TemplateMode controls how templates referencing missing inputs and outputs are rendered, overriding the workflow's defaults
+	TemplateMode string `json:"template-mode,omitempty"`
 }
 
 // JSONSchemaExtend extends the JSON schema for a step
@@ -58,6 +72,14 @@ func (Step) JSONSchemaExtend(schema *jsonschema.Schema) {
 		Type:        "string",
 		Description: "Command/script to run",
 	})
+	for _, goos := range []string{"linux", "darwin", "windows"} {
+		props.Set("run-"+goos, &jsonschema.Schema{
+			Type: "string",
+			Description: fmt.Sprintf(`Command/script to run instead of run on %s
+
+See https://github.com/defenseunicorns/maru2/blob/main/docs/syntax.md#per-platform-steps`, goos),
+		})
+	}
 	props.Set("env", &jsonschema.Schema{
 		Description: "Extra environment variables for this step",
 		Type:        "object",
@@ -88,6 +110,7 @@ Calling tasks from remote files: https://github.com/defenseunicorns/maru2/blob/m
 		Examples: []any{
 			"local-task",
 			"file:testdata/simple.yaml?task=echo",
+			"file:packages/api?task=build",
 			"builtin:echo",
 			"pkg:github/defenseunicorns/maru2@main?task=echo",
 			"https://raw.githubusercontent.com/defenseunicorns/maru2/main/testdata/simple.yaml?task=echo",
@@ -103,6 +126,10 @@ See https://github.com/defenseunicorns/maru2/blob/main/docs/syntax.md#passing-ou
 		Type:        "string",
 		Description: "Human-readable name for the step, pure sugar",
 	})
+	props.Set("description", &jsonschema.Schema{
+		Type:        "string",
+		Description: "Human-readable description of what the step does, shown when explaining the task and in generated docs",
+	})
 	props.Set("if", &jsonschema.Schema{
 		Type: "string",
 		Description: `Expression that controls whether the step is executed
@@ -138,6 +165,22 @@ See https://pkg.go.dev/time#ParseDuration for more information.`,
 		Description: "Show the rendered script before execution. Has no effect on uses.",
 		Default:     true,
 	})
+	props.Set("mutex", &jsonschema.Schema{
+		Type: "string",
+		Description: `Name of a mutex held while the step runs, so concurrent maru2 processes sharing a store do not run it at the same time
+
+See https://github.com/defenseunicorns/maru2/blob/main/docs/syntax.md#mutual-exclusion-with-mutex`,
+		Pattern: MutexNamePattern.String(),
+	})
+
+	props.Set("collapse", &jsonschema.Schema{
+		Type: "boolean",
+		Description: `Group the step's output in CI environments (GitHub Actions, GitLab CI), overrides defaults.collapse-steps
+
+See https://github.com/defenseunicorns/maru2/blob/main/docs/syntax.md#output-grouping-with-collapse`,
+	})
+
+	props.Set("template-mode", templateModeSchema("How templates referencing missing inputs and outputs are rendered in this step, overrides defaults.template-mode"))
 
 	runProps := jsonschema.NewProperties()
 	runProps.Set("run", &jsonschema.Schema{
@@ -145,12 +188,20 @@ See https://pkg.go.dev/time#ParseDuration for more information.`,
 	})
 	runProps.Set("uses", not)
 	oneOfRun := &jsonschema.Schema{
-		Required:   []string{"run"},
 		Properties: runProps,
+		AnyOf: []*jsonschema.Schema{
+			{Required: []string{"run"}},
+			{Required: []string{"run-linux"}},
+			{Required: []string{"run-darwin"}},
+			{Required: []string{"run-windows"}},
+		},
 	}
 
 	usesProps := jsonschema.NewProperties()
 	usesProps.Set("run", not)
+	usesProps.Set("run-linux", not)
+	usesProps.Set("run-darwin", not)
+	usesProps.Set("run-windows", not)
 	usesProps.Set("uses", &jsonschema.Schema{
 		Type: "string",
 	})
@@ -190,7 +241,8 @@ See https://pkg.go.dev/time#ParseDuration for more information.`,
 			// processSchema allows schema types to be either string or their original type for templating
 			var processSchema func(schema *jsonschema.Schema)
 			processSchema = func(schema *jsonschema.Schema) {
-				if schema.Type == "string" {
+				// untyped (any) values already accept strings
+				if schema.Type == "string" || schema.Type == "" {
 					return
 				}
 
@@ -215,7 +267,7 @@ See https://pkg.go.dev/time#ParseDuration for more information.`,
 			}
 
 			for pair := withSchema.Properties.Oldest(); pair != nil; pair = pair.Next() {
-				if pair.Value.Type == "string" {
+				if pair.Value.Type == "string" || pair.Value.Type == "" {
 					continue
 				}
 
@@ -326,3 +378,23 @@ See https://pkg.go.dev/time#ParseDuration for more information.`,
 		oneOfUses,
 	}
 }
+
+// RunVariants returns the runs a step has for specific operating systems, keyed by GOOS
+func (s Step) RunVariants() map[string]string {
+	variants := make(map[string]string, 3)
+	for goos, run := range map[string]string{"linux": s.RunLinux, "darwin": s.RunDarwin, "windows": s.RunWindows} {
+		if run != "" {
+			variants[goos] = run
+		}
+	}
+	return variants
+}
+
+// ForOS returns the step as run on an operating system, with Run replaced by its variant for goos if it has one
+func (s Step) ForOS(goos string) Step {
+	if run, ok := s.RunVariants()[goos]; ok {
+		s.Run = run
+	}
+	s.RunLinux, s.RunDarwin, s.RunWindows = "", "", ""
+	return s
+}
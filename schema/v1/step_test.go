@@ -0,0 +1,22 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStepForOS(t *testing.T) {
+	step := Step{Run: "sha256sum file", RunDarwin: "shasum -a 256 file", RunWindows: "Get-FileHash file", ID: "sum"}
+
+	assert.Equal(t, map[string]string{"darwin": "shasum -a 256 file", "windows": "Get-FileHash file"}, step.RunVariants())
+
+	assert.Equal(t, Step{Run: "sha256sum file", ID: "sum"}, step.ForOS("linux"))
+	assert.Equal(t, Step{Run: "shasum -a 256 file", ID: "sum"}, step.ForOS("darwin"))
+	assert.Equal(t, Step{Run: "Get-FileHash file", ID: "sum"}, step.ForOS("windows"))
+
+	assert.Equal(t, Step{}, Step{RunLinux: "sha256sum file"}.ForOS("darwin"))
+}
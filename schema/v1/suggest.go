@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package v1
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// maxSuggestions is the maximum number of suggestions returned by Suggestions
+const maxSuggestions = 3
+
+// Suggestions returns the candidates closest to name, closest first
+//
+// A candidate is close if its Levenshtein distance to name is at most 2, or a third of name's length for long names
+func Suggestions(name string, candidates []string) []string {
+	threshold := max(2, len(name)/3)
+
+	type suggestion struct {
+		name     string
+		distance int
+	}
+
+	var suggestions []suggestion
+	for _, candidate := range candidates {
+		if candidate == name {
+			continue
+		}
+		if d := levenshtein(strings.ToLower(name), strings.ToLower(candidate)); d <= threshold {
+			suggestions = append(suggestions, suggestion{candidate, d})
+		}
+	}
+
+	slices.SortFunc(suggestions, func(a, b suggestion) int {
+		return cmp.Or(cmp.Compare(a.distance, b.distance), cmp.Compare(a.name, b.name))
+	})
+
+	names := make([]string, 0, min(len(suggestions), maxSuggestions))
+	for _, s := range suggestions[:min(len(suggestions), maxSuggestions)] {
+		names = append(names, s.name)
+	}
+	return names
+}
+
+// DidYouMean formats the suggestions for name to be appended to an error message, it is empty if there are none
+func DidYouMean(name string, candidates []string) string {
+	suggestions := Suggestions(name, candidates)
+	switch len(suggestions) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf(", did you mean %q?", suggestions[0])
+	default:
+		quoted := make([]string, len(suggestions))
+		for i, s := range suggestions {
+			quoted[i] = fmt.Sprintf("%q", s)
+		}
+		return fmt.Sprintf(", did you mean one of [%s]?", strings.Join(quoted, ", "))
+	}
+}
+
+// levenshtein returns the number of single rune edits needed to change a into b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestions(t *testing.T) {
+	candidates := []string{"default", "build", "build-image", "test", "lint", "deploy-staging", "deploy-production"}
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "typo",
+			input:    "bulid",
+			expected: []string{"build"},
+		},
+		{
+			name:     "case insensitive",
+			input:    "BUILD",
+			expected: []string{"build"},
+		},
+		{
+			name:     "closest first",
+			input:    "lest",
+			expected: []string{"test", "lint"},
+		},
+		{
+			name:     "long names allow more edits",
+			input:    "deplyo-prodcution",
+			expected: []string{"deploy-production"},
+		},
+		{
+			name:     "exact match is not suggested",
+			input:    "lint",
+			expected: []string{},
+		},
+		{
+			name:     "nothing close",
+			input:    "release",
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Suggestions(tc.input, candidates))
+		})
+	}
+}
+
+func TestDidYouMean(t *testing.T) {
+	assert.Empty(t, DidYouMean("release", []string{"build"}))
+	assert.Equal(t, `, did you mean "build"?`, DidYouMean("bulid", []string{"build", "test"}))
+	assert.Equal(t, `, did you mean one of ["test", "tests"]?`, DidYouMean("tst", []string{"test", "tests"}))
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("", ""))
+	assert.Equal(t, 3, levenshtein("", "abc"))
+	assert.Equal(t, 3, levenshtein("kitten", "sitting"))
+	assert.Equal(t, 1, levenshtein("héllo", "hello"))
+}
@@ -5,8 +5,11 @@ package v1
 
 import (
 	"cmp"
+	"fmt"
 	"iter"
+	"net/url"
 	"slices"
+	"strings"
 
 	"github.com/invopop/jsonschema"
 
@@ -15,10 +18,16 @@ import (
 
 // Task is a list of steps and input parameters
 type Task struct {
-	Description string   `json:"description,omitempty"`
-	Collapse    bool     `json:"collapse,omitempty"`
-	Inputs      InputMap `json:"inputs,omitempty"`
-	Steps       []Step   `json:"steps"`
+	Description       string   `json:"description,omitempty"`
+	AliasOf           string   `json:"alias-of,omitempty"`
+	DeprecatedMessage string   `json:"deprecated-message,omitempty"`
+	Collapse          bool     `json:"collapse,omitempty"`
+	Mutex             string   `json:"mutex,omitempty"`
+	RunsOn            string   `json:"runs-on,omitempty"`
+	Confirm           string   `json:"confirm,omitempty"`
+	Platforms         []string `json:"platforms,omitempty"`
+	Inputs            InputMap `json:"inputs,omitempty"`
+	Steps             []Step   `json:"steps,omitempty"`
 }
 
 // JSONSchemaExtend extends the JSON schema for a task
@@ -29,10 +38,73 @@ func (Task) JSONSchemaExtend(schema *jsonschema.Schema) {
 		desc.Description = "Human-readable description of the task"
 	}
 
+	if deprecated, ok := schema.Properties.Get("deprecated-message"); ok && deprecated != nil {
+		deprecated.Description = `Message to display when the task is deprecated, shown as a warning whenever the task is run
+
+See https://github.com/defenseunicorns/maru2/blob/main/docs/syntax.md#deprecating-tasks`
+	}
+
+	if aliasOf, ok := schema.Properties.Get("alias-of"); ok && aliasOf != nil {
+		aliasOf.Description = `Name of another task in the workflow that this task is a shorthand for, a task with alias-of cannot set steps or any other field besides description and deprecated-message
+
+See https://github.com/defenseunicorns/maru2/blob/main/docs/syntax.md#task-aliases-with-alias-of`
+		aliasOf.Pattern = TaskNamePattern.String()
+	}
+
+	// a task either has steps, or is an alias of another task
+	//
+	// steps are not required by the schema as an empty list is omitted when a workflow is validated, Validate checks them instead
+	var notAlias []*jsonschema.Schema
+	for pair := schema.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		if pair.Key == "description" || pair.Key == "alias-of" || pair.Key == "deprecated-message" {
+			continue
+		}
+		notAlias = append(notAlias, &jsonschema.Schema{Required: []string{pair.Key}})
+	}
+	schema.Required = nil
+	schema.OneOf = []*jsonschema.Schema{
+		{
+			Not: &jsonschema.Schema{Required: []string{"alias-of"}},
+		},
+		{
+			Required: []string{"alias-of"},
+			Not:      &jsonschema.Schema{AnyOf: notAlias},
+		},
+	}
+
 	if collapse, ok := schema.Properties.Get("collapse"); ok && collapse != nil {
 		collapse.Description = "Group task output in CI environments (GitHub Actions, GitLab CI)"
 	}
 
+	if mutex, ok := schema.Properties.Get("mutex"); ok && mutex != nil {
+		mutex.Description = `Name of a mutex held while the task runs, so concurrent maru2 processes sharing a store do not run it at the same time
+
+See https://github.com/defenseunicorns/maru2/blob/main/docs/syntax.md#mutual-exclusion-with-mutex`
+		mutex.Pattern = MutexNamePattern.String()
+	}
+
+	if runsOn, ok := schema.Properties.Get("runs-on"); ok && runsOn != nil {
+		runsOn.Description = `Remote host the task's run steps execute on, as ssh://[user@]host[:port][/path]
+
+See https://github.com/defenseunicorns/maru2/blob/main/docs/syntax.md#running-on-a-remote-host-with-runs-on`
+		runsOn.Examples = []any{"ssh://deploy@example.com", "ssh://example.com:2222/srv/app", "ssh://${{ input \"host\" }}"}
+	}
+
+	if confirm, ok := schema.Properties.Get("confirm"); ok && confirm != nil {
+		confirm.Description = `Message shown when asking for confirmation before the task runs, tasks that set confirm only run once confirmed interactively or with --yes
+
+See https://github.com/defenseunicorns/maru2/blob/main/docs/syntax.md#confirming-dangerous-tasks-with-confirm`
+		confirm.Examples = []any{"Destroy the cluster?", "Rotate credentials for ${{ input \"env\" }}?"}
+	}
+
+	if platforms, ok := schema.Properties.Get("platforms"); ok && platforms != nil {
+		platforms.Description = `Platforms the task supports, as os or os/arch, every step with run-<os> variants and no run must have a variant for each
+
+See https://github.com/defenseunicorns/maru2/blob/main/docs/syntax.md#per-platform-steps`
+		platforms.Items.Pattern = PlatformPattern.String()
+		platforms.Examples = []any{[]any{"linux", "darwin"}, []any{"linux/amd64", "darwin/arm64"}}
+	}
+
 	if inputs, ok := schema.Properties.Get("inputs"); ok && inputs != nil {
 		inputs.Description = "Input parameters for the task"
 	}
@@ -53,9 +125,12 @@ func (TaskMap) JSONSchemaExtend(schema *jsonschema.Schema) {
 
 // Find returns a task by name
 //
-// Yes, this function is essentially syntactic sugar for Go map functionality, but I like it, so I'm keeping it
+// A task with alias-of returns the task it is an alias of, under its own name
 func (tm TaskMap) Find(call string) (Task, bool) {
 	task, ok := tm[call]
+	if ok && task.AliasOf != "" {
+		task, ok = tm[task.AliasOf]
+	}
 	return task, ok
 }
 
@@ -92,3 +167,22 @@ func (tm TaskMap) OrderedSeq() iter.Seq2[string, Task] {
 		}
 	}
 }
+
+// ParseRunsOn parses the remote host of a task, only ssh://[user@]host[:port][/path] is supported
+func ParseRunsOn(runsOn string) (*url.URL, error) {
+	u, err := url.Parse(runsOn)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "ssh" || u.Hostname() == "" || u.Opaque != "" || u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("%q must be of the form ssh://[user@]host[:port][/path]", runsOn)
+	}
+	if _, ok := u.User.Password(); ok {
+		return nil, fmt.Errorf("%q must not contain a password, use an ssh key or agent", u.Redacted())
+	}
+	// would be taken as options by ssh
+	if strings.HasPrefix(u.Hostname(), "-") || strings.HasPrefix(u.User.Username(), "-") {
+		return nil, fmt.Errorf("%q must not have a host or user starting with -", runsOn)
+	}
+	return u, nil
+}
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package v1
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+
+	"github.com/invopop/jsonschema"
+
+	"github.com/defenseunicorns/maru2/schema"
+)
+
+// TestMap is a map of workflow tests, where the key is the test name
+type TestMap map[string]Test
+
+// JSONSchemaExtend extends the JSON schema for a test map
+func (TestMap) JSONSchemaExtend(schema *jsonschema.Schema) {
+	schema.PropertyNames = &jsonschema.Schema{
+		Pattern: TaskNamePattern.String(),
+	}
+}
+
+// OrderedSeq returns an iterator over test names and values in alphabetical order by name
+func (tm TestMap) OrderedSeq() iter.Seq2[string, Test] {
+	names := make([]string, 0, len(tm))
+	for name := range tm {
+		names = append(names, name)
+	}
+	slices.SortStableFunc(names, cmp.Compare)
+	return func(yield func(string, Test) bool) {
+		for _, name := range names {
+			if !yield(name, tm[name]) {
+				return
+			}
+		}
+	}
+}
+
+// Test calls a task with the given inputs and checks its result, run with `maru2 test`
+type Test struct {
+	// Description of what the test checks
+	Description string `json:"description,omitempty"`
+	// Task is the name of the task to call, the default task if empty
+	Task string `json:"task,omitempty"`
+	// With is a map of inputs passed to the task
+	With schema.With `json:"with,omitempty"`
+	// DryRun calls the task as a dry run
+	DryRun bool `json:"dry-run,omitempty"`
+	// Mocks replace uses: steps, keyed by the uses: reference exactly as written in the step
+	Mocks map[string]Mock `json:"mocks,omitempty"`
+	// Expect is the result the task must have for the test to pass
+	Expect Expect `json:"expect,omitempty"`
+}
+
+// JSONSchemaExtend extends the JSON schema for a test
+func (Test) JSONSchemaExtend(schema *jsonschema.Schema) {
+	schema.Description = "A call to a task with the given inputs and its expected result"
+
+	if description, ok := schema.Properties.Get("description"); ok && description != nil {
+		description.Description = "Description of what the test checks"
+	}
+	if task, ok := schema.Properties.Get("task"); ok && task != nil {
+		task.Description = "Name of the task to call, defaults to the default task"
+		task.Pattern = TaskNamePattern.String()
+	}
+	if with, ok := schema.Properties.Get("with"); ok && with != nil {
+		with.Description = "Inputs passed to the task"
+	}
+	if dry, ok := schema.Properties.Get("dry-run"); ok && dry != nil {
+		dry.Description = "Call the task as a dry run, nothing is executed"
+	}
+	if mocks, ok := schema.Properties.Get("mocks"); ok && mocks != nil {
+		mocks.Description = "Replace uses: steps, keyed by the uses: reference exactly as written in the step"
+	}
+	if expect, ok := schema.Properties.Get("expect"); ok && expect != nil {
+		expect.Description = "The result the task must have for the test to pass, success with any outputs if not set"
+	}
+}
+
+// Mock replaces a uses: step with a fixed result
+type Mock struct {
+	// Outputs returned by the step
+	Outputs map[string]any `json:"outputs,omitempty"`
+	// Error fails the step with the given message
+	Error string `json:"error,omitempty"`
+}
+
+// JSONSchemaExtend extends the JSON schema for a mock
+func (Mock) JSONSchemaExtend(schema *jsonschema.Schema) {
+	schema.Description = "A fixed result returned instead of calling a uses: step"
+
+	if outputs, ok := schema.Properties.Get("outputs"); ok && outputs != nil {
+		outputs.Description = "Outputs returned by the step"
+	}
+	if e, ok := schema.Properties.Get("error"); ok && e != nil {
+		e.Description = "Fail the step with the given message"
+	}
+}
+
+// Expect is the expected result of a task called by a test
+type Expect struct {
+	// Outputs that must be returned by the task, compared as strings
+	Outputs map[string]any `json:"outputs,omitempty"`
+	// ExitCode the task must exit with (default: 0, or any failure if Error is set)
+	ExitCode int `json:"exit-code,omitempty"`
+	// Error must be contained in the task's error message
+	Error string `json:"error,omitempty"`
+}
+
+// JSONSchemaExtend extends the JSON schema for a test's expectations
+func (Expect) JSONSchemaExtend(schema *jsonschema.Schema) {
+	schema.Description = "The result the task must have for the test to pass"
+
+	if outputs, ok := schema.Properties.Get("outputs"); ok && outputs != nil {
+		outputs.Description = "Outputs that must be returned by the task, compared as strings"
+	}
+	if code, ok := schema.Properties.Get("exit-code"); ok && code != nil {
+		code.Description = "Exit code the task must exit with, 0 (success) if not set, or any failure if error is set"
+	}
+	if e, ok := schema.Properties.Get("error"); ok && e != nil {
+		e.Description = "Text that must be contained in the task's error message"
+	}
+}
@@ -4,10 +4,12 @@
 package v1
 
 import (
+	"cmp"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/url"
 	"path/filepath"
 	"regexp"
@@ -19,6 +21,7 @@ import (
 	"github.com/goccy/go-yaml"
 	"github.com/xeipuuv/gojsonschema"
 
+	"github.com/defenseunicorns/maru2/builtins"
 	"github.com/defenseunicorns/maru2/schema"
 	v0 "github.com/defenseunicorns/maru2/schema/v0"
 )
@@ -26,6 +29,7 @@ import (
 // Read parses and validates a workflow from YAML/JSON input
 //
 // Handles v0->v1 migration automatically and performs JSON Schema validation.
+// Workflow-level input parameters are inherited by every task.
 // Returns a fully validated Workflow struct ready for execution
 func Read(r io.Reader) (Workflow, error) {
 	if rs, ok := r.(io.Seeker); ok {
@@ -48,7 +52,11 @@ func Read(r io.Reader) (Workflow, error) {
 	switch version := versioned.SchemaVersion; version {
 	case SchemaVersion:
 		var wf Workflow
-		return wf, yaml.Unmarshal(data, &wf)
+		if err := yaml.Unmarshal(data, &wf); err != nil {
+			return Workflow{}, err
+		}
+		wf.inheritInputs()
+		return wf, nil
 	case v0.SchemaVersion:
 		var v0Workflow v0.Workflow
 		if err := yaml.Unmarshal(data, &v0Workflow); err != nil {
@@ -79,7 +87,7 @@ func Validate(wf Workflow) error {
 	}
 
 	namespaces := []string{}
-	for ns, alias := range wf.Aliases {
+	for ns, alias := range wf.Aliases.OrderedSeq() {
 		namespaces = append(namespaces, ns)
 		if filepath.IsAbs(alias.Path) {
 			return fmt.Errorf(".aliases.%s cannot be an absolute path: %s", ns, alias.Path)
@@ -87,26 +95,131 @@ func Validate(wf Workflow) error {
 		if slices.Contains(SupportedSchemes(), ns) {
 			return fmt.Errorf(".aliases.%s cannot be one of [%s]", ns, strings.Join(SupportedSchemes(), ", "))
 		}
+		if len(alias.Headers) > 0 {
+			return fmt.Errorf(".aliases.%s.headers can only be set in the system config", ns)
+		}
+	}
+
+	for idx, inc := range wf.Includes {
+		if filepath.IsAbs(inc.Path) {
+			return fmt.Errorf(".includes[%d].path cannot be an absolute path: %s", idx, inc.Path)
+		}
+		if inc.Prefix != "" && !TaskNamePattern.MatchString(inc.Prefix) {
+			return fmt.Errorf(".includes[%d].prefix %q does not satisfy %q", idx, inc.Prefix, TaskNamePattern.String())
+		}
+	}
+
+	for inputName, param := range wf.Inputs.OrderedSeq() {
+		if ok := InputNamePattern.MatchString(inputName); !ok {
+			return fmt.Errorf(".inputs.%s %q does not satisfy %q", inputName, inputName, InputNamePattern.String())
+		}
+
+		if param.Validate != "" {
+			_, err := regexp.Compile(param.Validate)
+			if err != nil {
+				return fmt.Errorf(".inputs.%s: %v", inputName, err)
+			}
+		}
+	}
+
+	if err := checkInputs(".inputs", wf.Inputs); err != nil {
+		return err
 	}
 
-	for name, task := range wf.Tasks {
+	for name, task := range wf.Tasks.OrderedSeq() {
 		if ok := TaskNamePattern.MatchString(name); !ok {
 			return fmt.Errorf("task name %q does not satisfy %q", name, TaskNamePattern.String())
 		}
 
+		if task.AliasOf != "" {
+			if len(task.Steps) > 0 || len(task.Inputs) > 0 || task.Collapse || task.Mutex != "" || task.RunsOn != "" || task.Confirm != "" || len(task.Platforms) > 0 {
+				return fmt.Errorf(".tasks.%s.alias-of cannot be set with steps, inputs or any other field besides description and deprecated-message", name)
+			}
+			target, ok := wf.Tasks[task.AliasOf]
+			if !ok {
+				// the task may come from an include that has not been merged yet
+				if len(wf.Includes) > 0 {
+					continue
+				}
+				return fmt.Errorf(".tasks.%s.alias-of %q not found%s", name, task.AliasOf, DidYouMean(task.AliasOf, wf.Tasks.OrderedTaskNames()))
+			}
+			if target.AliasOf != "" {
+				return fmt.Errorf(".tasks.%s.alias-of %q is itself an alias of %q", name, task.AliasOf, target.AliasOf)
+			}
+			continue
+		}
+
+		if task.Steps == nil {
+			return fmt.Errorf(".tasks.%s must set steps or alias-of", name)
+		}
+
+		if task.Mutex != "" && !MutexNamePattern.MatchString(task.Mutex) {
+			return fmt.Errorf(".tasks.%s.mutex %q does not satisfy %q", name, task.Mutex, MutexNamePattern.String())
+		}
+
+		if task.RunsOn != "" && !strings.Contains(task.RunsOn, "${{") {
+			if _, err := ParseRunsOn(task.RunsOn); err != nil {
+				return fmt.Errorf(".tasks.%s.runs-on %w", name, err)
+			}
+		}
+
+		for _, platform := range task.Platforms {
+			if !PlatformPattern.MatchString(platform) {
+				return fmt.Errorf(".tasks.%s.platforms %q does not satisfy %q", name, platform, PlatformPattern.String())
+			}
+		}
+
+		if err := checkInputs(fmt.Sprintf(".tasks.%s.inputs", name), task.Inputs); err != nil {
+			return err
+		}
+
 		ids := make(map[string]int, len(task.Steps))
 
+		inputs := slices.Collect(maps.Keys(task.Inputs))
+		for inputName := range wf.Inputs {
+			if _, ok := task.Inputs[inputName]; !ok {
+				inputs = append(inputs, inputName)
+			}
+		}
+		slices.Sort(inputs)
+
+		// runs-on and confirm are rendered before any step runs, so they can only reference inputs
+		var taskRefs *references
+		if wf.StepTemplateMode(Step{}) != TemplateModeLenient {
+			taskRefs = &references{task: name, inputs: inputs}
+		}
+		if err := checkTemplate(fmt.Sprintf(".tasks.%s.runs-on", name), task.RunsOn, taskRefs); err != nil {
+			return err
+		}
+		if err := checkTemplate(fmt.Sprintf(".tasks.%s.confirm", name), task.Confirm, taskRefs); err != nil {
+			return err
+		}
+
+		var earlier []string
+
 		for idx, step := range task.Steps {
+			variants := step.RunVariants()
+
 			// ensure that only one of run or uses fields is set
 			switch {
 			// both
-			case step.Uses != "" && step.Run != "":
+			case step.Uses != "" && (step.Run != "" || len(variants) > 0):
 				return fmt.Errorf(".tasks.%s[%d] has both run and uses fields set", name, idx)
 			// neither
-			case step.Uses == "" && step.Run == "":
+			case step.Uses == "" && step.Run == "" && len(variants) == 0:
 				return fmt.Errorf(".tasks.%s[%d] must have one of [run, uses] fields set", name, idx)
 			}
 
+			// without a run to fall back to, every platform of the task needs its own variant
+			if step.Run == "" && len(variants) > 0 {
+				for _, platform := range task.Platforms {
+					goos, _, _ := strings.Cut(platform, "/")
+					if _, ok := variants[goos]; !ok {
+						return fmt.Errorf(".tasks.%s[%d] has no run-%s or run for platform %q", name, idx, goos, platform)
+					}
+				}
+			}
+
 			if step.ID != "" {
 				if ok := TaskNamePattern.MatchString(step.ID); !ok {
 					return fmt.Errorf(".tasks.%s[%d].id %q does not satisfy %q", name, idx, step.ID, TaskNamePattern.String())
@@ -128,16 +241,24 @@ func Validate(wf Workflow) error {
 					if step.Uses == name {
 						return fmt.Errorf(".tasks.%s[%d].uses cannot reference itself", name, idx)
 					}
+					// the task may come from an include that has not been merged yet
 					_, ok := wf.Tasks.Find(step.Uses)
-					if !ok {
-						return fmt.Errorf(".tasks.%s[%d].uses %q not found", name, idx, step.Uses)
+					if !ok && len(wf.Includes) == 0 {
+						return fmt.Errorf(".tasks.%s[%d].uses %q not found%s", name, idx, step.Uses, DidYouMean(step.Uses, wf.Tasks.OrderedTaskNames()))
+					}
+				} else if builtin, ok := BuiltinName(step.Uses); ok {
+					if builtins.Get(builtin) == nil {
+						return fmt.Errorf(".tasks.%s[%d].uses %q not found%s", name, idx, step.Uses, DidYouMean(builtin, builtins.Names()))
+					}
+					if err := ValidateBuiltinWith(builtin, step.With, false); err != nil {
+						return fmt.Errorf(".tasks.%s[%d].%w", name, idx, err)
 					}
 				} else {
 					schemes := append(SupportedSchemes(), "builtin")
 					schemes = append(schemes, namespaces...)
 
 					if !slices.Contains(schemes, u.Scheme) {
-						return fmt.Errorf(".tasks.%s[%d].uses %q is not one of [%s]", name, idx, u.Scheme, strings.Join(schemes, ", "))
+						return fmt.Errorf(".tasks.%s[%d].uses %q is not one of [%s]%s", name, idx, u.Scheme, strings.Join(schemes, ", "), DidYouMean(u.Scheme, namespaces))
 					}
 
 					if slices.Contains(namespaces, u.Scheme) {
@@ -149,6 +270,10 @@ func Validate(wf Workflow) error {
 				}
 			}
 
+			if step.Mutex != "" && !MutexNamePattern.MatchString(step.Mutex) {
+				return fmt.Errorf(".tasks.%s[%d].mutex %q does not satisfy %q", name, idx, step.Mutex, MutexNamePattern.String())
+			}
+
 			if step.Dir != "" {
 				if filepath.IsAbs(step.Dir) {
 					return fmt.Errorf(".tasks.%s[%d].dir %q must not be absolute", name, idx, step.Dir)
@@ -162,12 +287,39 @@ func Validate(wf Workflow) error {
 				}
 			}
 
-			for envName := range step.Env {
+			for _, envName := range slices.Sorted(maps.Keys(step.Env)) {
 				if ok := EnvVariablePattern.MatchString(envName); !ok {
 					return fmt.Errorf(".tasks.%s[%d].env %q does not satisfy %q", name, idx, envName, EnvVariablePattern.String())
 				}
 			}
-			for inputName, param := range task.Inputs {
+
+			refs := &references{task: name, inputs: inputs, steps: slices.Clone(earlier)}
+			if step.If != "" {
+				if err := checkIf(step.If, refs); err != nil {
+					return fmt.Errorf(".tasks.%s[%d].if %w", name, idx, err)
+				}
+			}
+			if wf.StepTemplateMode(step) == TemplateModeLenient {
+				refs = nil
+			}
+			if err := checkTemplate(fmt.Sprintf(".tasks.%s[%d].run", name, idx), step.Run, refs); err != nil {
+				return err
+			}
+			for _, goos := range slices.Sorted(maps.Keys(variants)) {
+				if err := checkTemplate(fmt.Sprintf(".tasks.%s[%d].run-%s", name, idx, goos), variants[goos], refs); err != nil {
+					return err
+				}
+			}
+			if err := checkTemplates(fmt.Sprintf(".tasks.%s[%d].env", name, idx), step.Env, refs); err != nil {
+				return err
+			}
+			if err := checkTemplates(fmt.Sprintf(".tasks.%s[%d].with", name, idx), step.With, refs); err != nil {
+				return err
+			}
+			if step.ID != "" {
+				earlier = append(earlier, step.ID)
+			}
+			for inputName, param := range task.Inputs.OrderedSeq() {
 				if ok := InputNamePattern.MatchString(inputName); !ok {
 					return fmt.Errorf(".tasks.%s.inputs.%s %q does not satisfy %q", name, inputName, inputName, InputNamePattern.String())
 				}
@@ -182,6 +334,17 @@ func Validate(wf Workflow) error {
 		}
 	}
 
+	for name, test := range wf.Tests.OrderedSeq() {
+		if ok := TaskNamePattern.MatchString(name); !ok {
+			return fmt.Errorf("test name %q does not satisfy %q", name, TaskNamePattern.String())
+		}
+
+		task := cmp.Or(test.Task, schema.DefaultTaskName)
+		if _, ok := wf.Tasks.Find(task); !ok {
+			return fmt.Errorf(".tests.%s.task %q not found%s", name, task, DidYouMean(task, wf.Tasks.OrderedTaskNames()))
+		}
+	}
+
 	schema, err := schemaOnce()
 	if err != nil {
 		return err
@@ -206,6 +369,52 @@ func Validate(wf Workflow) error {
 	return resErr
 }
 
+// checkInputs checks the path options of inputs, and that the inputs named by conflicts-with and requires are other inputs of the same map
+func checkInputs(path string, inputs InputMap) error {
+	for inputName, param := range inputs.OrderedSeq() {
+		if param.Type != "" && param.Type != InputTypePath {
+			return fmt.Errorf("%s.%s.type %q must be %q", path, inputName, param.Type, InputTypePath)
+		}
+		if param.Path != nil {
+			if param.Type != InputTypePath {
+				return fmt.Errorf("%s.%s.path can only be set when type is %q", path, inputName, InputTypePath)
+			}
+			if kind := param.Path.Kind; kind != "" && kind != "file" && kind != "directory" {
+				return fmt.Errorf("%s.%s.path.kind %q must be one of [file, directory]", path, inputName, kind)
+			}
+			for _, ext := range param.Path.Extensions {
+				if !strings.HasPrefix(ext, ".") {
+					return fmt.Errorf("%s.%s.path.extensions %q must start with a .", path, inputName, ext)
+				}
+			}
+		}
+
+		relations := []struct {
+			field string
+			names []string
+		}{
+			{"conflicts-with", param.ConflictsWith},
+			{"requires", param.Requires},
+		}
+		for _, rel := range relations {
+			for _, other := range rel.names {
+				if other == inputName {
+					return fmt.Errorf("%s.%s.%s cannot reference itself", path, inputName, rel.field)
+				}
+				if _, ok := inputs[other]; !ok {
+					return fmt.Errorf("%s.%s.%s %q not found%s", path, inputName, rel.field, other, DidYouMean(other, slices.Sorted(maps.Keys(inputs))))
+				}
+			}
+		}
+		for _, other := range param.Requires {
+			if slices.Contains(inputs.Conflicts(inputName), other) {
+				return fmt.Errorf("%s.%s cannot both require and conflict with %q", path, inputName, other)
+			}
+		}
+	}
+	return nil
+}
+
 // ReadAndValidate combines Read and Validate for one-step workflow processing
 //
 // Convenience function for parsing and validating workflows in a single call
@@ -169,6 +169,40 @@ func TestValidate(t *testing.T) {
 			},
 			expectedError: ".tasks.task[0].uses \"non-existent-task\" not found",
 		},
+		{
+			name: "uses with misspelled task",
+			wf: Workflow{
+				Tasks: TaskMap{
+					"build": Task{
+						Steps: []Step{{
+							Run: "echo",
+						}},
+					},
+					"task": Task{
+						Steps: []Step{{
+							Uses: "bulid",
+						}},
+					},
+				},
+			},
+			expectedError: ".tasks.task[0].uses \"bulid\" not found, did you mean \"build\"?",
+		},
+		{
+			name: "uses with misspelled alias",
+			wf: Workflow{
+				Aliases: AliasMap{
+					"tools": Alias{Path: "tools.yaml"},
+				},
+				Tasks: TaskMap{
+					"task": Task{
+						Steps: []Step{{
+							Uses: "tols:lint",
+						}},
+					},
+				},
+			},
+			expectedError: fmt.Sprintf(".tasks.task[0].uses %q is not one of [%s], did you mean %q?", "tols", strings.Join(append(SupportedSchemes(), "builtin", "tools"), ", "), "tools"),
+		},
 		{
 			name: "uses cannot reference itself",
 			wf: Workflow{
@@ -183,6 +217,85 @@ func TestValidate(t *testing.T) {
 			},
 			expectedError: ".tasks.self-task[0].uses cannot reference itself",
 		},
+		{
+			name: "uses cycle guarded by if",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"a": Task{Steps: []Step{{Uses: "b"}}},
+					"b": Task{Steps: []Step{{Run: "echo"}, {Uses: "c"}}},
+					"c": Task{Steps: []Step{{Uses: "a", If: `input("again") == true`}}},
+				},
+			},
+		},
+		{
+			name: "uses shared task is not a cycle",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"a": Task{Steps: []Step{{Uses: "b"}, {Uses: "c"}}},
+					"b": Task{Steps: []Step{{Uses: "c"}}},
+					"c": Task{Steps: []Step{{Run: "echo"}}},
+				},
+			},
+		},
+		{
+			name: "run variants for every platform",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"task": Task{
+						Platforms: []string{"linux/amd64", "darwin"},
+						Steps: []Step{
+							{RunLinux: "sha256sum file", RunDarwin: "shasum -a 256 file"},
+							{Run: "echo done", RunWindows: "Write-Output done"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "run variant missing for a platform",
+			wf: Workflow{
+				Tasks: TaskMap{
+					"task": Task{
+						Platforms: []string{"linux", "windows/amd64"},
+						Steps:     []Step{{RunLinux: "sha256sum file", RunDarwin: "shasum -a 256 file"}},
+					},
+				},
+			},
+			expectedError: `.tasks.task[0] has no run-windows or run for platform "windows/amd64"`,
+		},
+		{
+			name: "run variant with uses",
+			wf: Workflow{
+				Tasks: TaskMap{
+					"task": Task{Steps: []Step{{Uses: "builtin:echo", RunLinux: "echo"}}},
+				},
+			},
+			expectedError: ".tasks.task[0] has both run and uses fields set",
+		},
+		{
+			name: "invalid platform",
+			wf: Workflow{
+				Tasks: TaskMap{
+					"task": Task{
+						Platforms: []string{"Linux"},
+						Steps:     []Step{{Run: "echo"}},
+					},
+				},
+			},
+			expectedError: fmt.Sprintf(`.tasks.task.platforms "Linux" does not satisfy %q`, PlatformPattern.String()),
+		},
+		{
+			name: "run variant template",
+			wf: Workflow{
+				Tasks: TaskMap{
+					"task": Task{Steps: []Step{{RunWindows: `echo ${{ .OSS }}`}}},
+				},
+			},
+			expectedError: `.tasks.task[0].run-windows: field .OSS does not exist, did you mean "OS"?`,
+		},
 		{
 			name: "uses with invalid scheme",
 			wf: Workflow{
@@ -365,6 +478,192 @@ func TestValidate(t *testing.T) {
 			},
 			expectedError: ".tasks.task[0] has both run and uses fields set",
 		},
+		{
+			name: "unknown builtin",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"task": Task{Steps: []Step{{
+						Uses: "builtin:ecoh",
+					}}},
+				},
+			},
+			expectedError: `.tasks.task[0].uses "builtin:ecoh" not found, did you mean "echo"?`,
+		},
+		{
+			name: "unknown builtin input",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"task": Task{Steps: []Step{{
+						Uses: "builtin:wait-for",
+						With: schema.With{"http": "https://example.com", "timout": "1m"},
+					}}},
+				},
+			},
+			expectedError: `.tasks.task[0].with.timout is not a known input, did you mean "timeout"?`,
+		},
+		{
+			name: "invalid builtin input",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"task": Task{Steps: []Step{{
+						Uses: "builtin:http-request",
+						With: schema.With{"url": "${{ input \"url\" }}", "status": "created"},
+					}}},
+				},
+			},
+			expectedError: `.tasks.task[0].with.status: "created" is not an integer`,
+		},
+		{
+			name: "templated builtin inputs",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"task": Task{Steps: []Step{{
+						Uses: "builtin:http-request",
+						With: schema.With{"url": "${{ input \"url\" }}", "status": "${{ input \"status\" }}"},
+					}}},
+				},
+			},
+		},
+		{
+			name: "template syntax error",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"task": Task{Steps: []Step{{Run: `echo ${{ input "name" `}}},
+				},
+			},
+			expectedError: `template: .tasks.task[0].run:1: unclosed action`,
+		},
+		{
+			name: "template unknown function",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"task": Task{Steps: []Step{{Run: `echo ${{ inptu "name" }}`}}},
+				},
+			},
+			expectedError: `template: .tasks.task[0].run:1: function "inptu" not defined`,
+		},
+		{
+			name: "template unknown field",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"task": Task{Steps: []Step{{Run: `echo ${{ .OSS }}`}}},
+				},
+			},
+			expectedError: `.tasks.task[0].run: field .OSS does not exist, did you mean "OS"?`,
+		},
+		{
+			name: "template undeclared input",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"task": Task{
+						Inputs: InputMap{"name": InputParameter{}},
+						Steps:  []Step{{Run: "echo", Env: map[string]any{"NAME": `${{ input "nmae" }}`}}},
+					},
+				},
+			},
+			expectedError: `.tasks.task[0].env.NAME: input "nmae" is not declared by task "task", did you mean "name"?`,
+		},
+		{
+			name: "template workflow input",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Inputs:        InputMap{"name": InputParameter{}},
+				Tasks: TaskMap{
+					"task": Task{Steps: []Step{{Run: `echo ${{ input "name" }} ${{ .OS }} ${{ range $k, $v := steps }}${{ $k }}${{ end }}`}}},
+				},
+			},
+		},
+		{
+			name: "task without inputs accepts any input",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"task": Task{Steps: []Step{{Run: `echo ${{ input "name" }}`, If: `input("name") == "prod"`}}},
+				},
+			},
+		},
+		{
+			name: "template output from later step",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"task": Task{Steps: []Step{
+						{Run: `echo ${{ from "second" "out" }}`, ID: "first"},
+						{Run: "echo", ID: "second"},
+					}},
+				},
+			},
+			expectedError: `.tasks.task[0].run: step "second" is not an earlier step of task "task"`,
+		},
+		{
+			name: "template lenient mode allows missing references",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Defaults:      &Defaults{TemplateMode: TemplateModeLenient},
+				Tasks: TaskMap{
+					"task": Task{
+						Inputs: InputMap{"env": InputParameter{}},
+						Steps:  []Step{{Run: `echo ${{ input "name" }} ${{ from "missing" "out" }}`}},
+					},
+				},
+			},
+		},
+		{
+			name: "if unknown function",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"task": Task{Steps: []Step{{Run: "echo", If: "sucess()"}}},
+				},
+			},
+			expectedError: ".tasks.task[0].if unknown name sucess (1:1)\n | sucess()\n | ^",
+		},
+		{
+			name: "if undeclared input",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"task": Task{
+						Inputs: InputMap{"env": InputParameter{}},
+						Steps:  []Step{{Run: "echo", If: `input("name") == "prod"`}},
+					},
+				},
+			},
+			expectedError: `.tasks.task[0].if input "name" is not declared by task "task"`,
+		},
+		{
+			name: "if output from earlier step",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"task": Task{Steps: []Step{
+						{Run: "echo", ID: "first"},
+						{Run: "echo", If: `from("first", "out") == "yes" && steps.first.out == "yes"`},
+					}},
+				},
+			},
+		},
+		{
+			name: "confirm can only reference inputs",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"task": Task{
+						Confirm: `Deploy ${{ from "first" "out" }}?`,
+						Steps:   []Step{{Run: "echo", ID: "first"}},
+					},
+				},
+			},
+			expectedError: `.tasks.task.confirm: step "first" is not an earlier step of task "task"`,
+		},
 		{
 			name: "invalid task input schema validation",
 			wf: Workflow{
@@ -436,6 +735,79 @@ func TestValidate(t *testing.T) {
 			},
 			expectedError: ".tasks.task[0].dir \"/tmp\" must not be absolute",
 		},
+		{
+			name: "task with mutex",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"task": Task{
+						Mutex: "deploy",
+						Steps: []Step{{
+							Run:   "echo",
+							Mutex: "migrate",
+						}},
+					},
+				},
+			},
+		},
+		{
+			name: "task with invalid mutex",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"task": Task{
+						Mutex: "../deploy",
+						Steps: []Step{{Run: "echo"}},
+					},
+				},
+			},
+			expectedError: ".tasks.task.mutex \"../deploy\" does not satisfy \"^[_a-zA-Z][a-zA-Z0-9_-]*$\"",
+		},
+		{
+			name: "step with invalid mutex",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"task": Task{
+						Steps: []Step{{
+							Run:   "echo",
+							Mutex: "deploy prod",
+						}},
+					},
+				},
+			},
+			expectedError: ".tasks.task[0].mutex \"deploy prod\" does not satisfy \"^[_a-zA-Z][a-zA-Z0-9_-]*$\"",
+		},
+		{
+			name: "task with runs-on",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"task": Task{
+						RunsOn: "ssh://deploy@example.com",
+						Steps:  []Step{{Run: "echo"}},
+					},
+					"templated": Task{
+						RunsOn: `ssh://${{ input "host" }}`,
+						Inputs: InputMap{"host": InputParameter{Description: "Host to deploy to"}},
+						Steps:  []Step{{Run: "echo"}},
+					},
+				},
+			},
+		},
+		{
+			name: "task with invalid runs-on",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"task": Task{
+						RunsOn: "example.com",
+						Steps:  []Step{{Run: "echo"}},
+					},
+				},
+			},
+			expectedError: ".tasks.task.runs-on \"example.com\" must be of the form ssh://[user@]host[:port][/path]",
+		},
 		{
 			name: "step with invalid timeout",
 			wf: Workflow{
@@ -577,6 +949,43 @@ func TestValidate(t *testing.T) {
 			},
 			expectedError: fmt.Sprintf(".tasks.task.inputs.2-invalid \"2-invalid\" does not satisfy %q", InputNamePattern.String()),
 		},
+		{
+			name: "invalid workflow input name",
+			wf: Workflow{
+				Inputs: InputMap{
+					"2-invalid": InputParameter{
+						Description: "Invalid input name",
+					},
+				},
+				Tasks: TaskMap{
+					"task": Task{
+						Steps: []Step{{
+							Run: "echo",
+						}},
+					},
+				},
+			},
+			expectedError: fmt.Sprintf(".inputs.2-invalid \"2-invalid\" does not satisfy %q", InputNamePattern.String()),
+		},
+		{
+			name: "invalid workflow input validation",
+			wf: Workflow{
+				Inputs: InputMap{
+					"name": InputParameter{
+						Description: "Invalid regex",
+						Validate:    "[",
+					},
+				},
+				Tasks: TaskMap{
+					"task": Task{
+						Steps: []Step{{
+							Run: "echo",
+						}},
+					},
+				},
+			},
+			expectedError: ".inputs.name: error parsing regexp: missing closing ]: `[`",
+		},
 		{
 			name: "valid alias with path",
 			wf: Workflow{
@@ -593,6 +1002,202 @@ func TestValidate(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "input relationships",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"checkout": Task{
+						Inputs: InputMap{
+							"tag":    {Description: "Tag", ConflictsWith: []string{"branch"}},
+							"branch": {Description: "Branch", Requires: []string{"remote"}},
+							"remote": {Description: "Remote", Default: "origin"},
+						},
+						Steps: []Step{{Run: "echo"}},
+					},
+				},
+			},
+		},
+		{
+			name: "input conflicts with unknown input",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"checkout": Task{
+						Inputs: InputMap{
+							"tag":    {Description: "Tag", ConflictsWith: []string{"brnach"}},
+							"branch": {Description: "Branch"},
+						},
+						Steps: []Step{{Run: "echo"}},
+					},
+				},
+			},
+			expectedError: `.tasks.checkout.inputs.tag.conflicts-with "brnach" not found, did you mean "branch"?`,
+		},
+		{
+			name: "input requires itself",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Inputs: InputMap{
+					"tag": {Description: "Tag", Requires: []string{"tag"}},
+				},
+				Tasks: TaskMap{
+					"checkout": Task{Steps: []Step{{Run: "echo"}}},
+				},
+			},
+			expectedError: ".inputs.tag.requires cannot reference itself",
+		},
+		{
+			name: "input requires and conflicts with the same input",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"checkout": Task{
+						Inputs: InputMap{
+							"tag":    {Description: "Tag", Requires: []string{"branch"}},
+							"branch": {Description: "Branch", ConflictsWith: []string{"tag"}},
+						},
+						Steps: []Step{{Run: "echo"}},
+					},
+				},
+			},
+			expectedError: `.tasks.checkout.inputs.tag cannot both require and conflict with "branch"`,
+		},
+		{
+			name: "path input",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"apply": Task{
+						Inputs: InputMap{
+							"manifest": {Description: "Manifest", Type: InputTypePath, Path: &PathOptions{Kind: "file", Extensions: []string{".yaml"}}},
+						},
+						Steps: []Step{{Run: "echo"}},
+					},
+				},
+			},
+		},
+		{
+			name: "path options without type path",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"apply": Task{
+						Inputs: InputMap{
+							"manifest": {Description: "Manifest", Path: &PathOptions{MustExist: true}},
+						},
+						Steps: []Step{{Run: "echo"}},
+					},
+				},
+			},
+			expectedError: `.tasks.apply.inputs.manifest.path can only be set when type is "path"`,
+		},
+		{
+			name: "path extension without a dot",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Inputs: InputMap{
+					"manifest": {Description: "Manifest", Type: InputTypePath, Path: &PathOptions{Extensions: []string{"yaml"}}},
+				},
+				Tasks: TaskMap{
+					"apply": Task{Steps: []Step{{Run: "echo"}}},
+				},
+			},
+			expectedError: `.inputs.manifest.path.extensions "yaml" must start with a .`,
+		},
+		{
+			name: "task alias",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"test": Task{Steps: []Step{{Run: "go test ./..."}}},
+					"t":    Task{AliasOf: "test", Description: "Shorthand for test"},
+					"all":  Task{Steps: []Step{{Uses: "t"}}},
+				},
+			},
+		},
+		{
+			name: "task alias not found",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"test": Task{Steps: []Step{{Run: "go test ./..."}}},
+					"t":    Task{AliasOf: "tset"},
+				},
+			},
+			expectedError: `.tasks.t.alias-of "tset" not found, did you mean "test"?`,
+		},
+		{
+			name: "task alias of an alias",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"test": Task{Steps: []Step{{Run: "go test ./..."}}},
+					"t":    Task{AliasOf: "test"},
+					"tt":   Task{AliasOf: "t"},
+				},
+			},
+			expectedError: `.tasks.tt.alias-of "t" is itself an alias of "test"`,
+		},
+		{
+			name: "task alias with steps",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"test": Task{Steps: []Step{{Run: "go test ./..."}}},
+					"t":    Task{AliasOf: "test", Steps: []Step{{Run: "echo"}}},
+				},
+			},
+			expectedError: ".tasks.t.alias-of cannot be set with steps, inputs or any other field besides description and deprecated-message",
+		},
+		{
+			name: "task without steps or alias",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"test": Task{Description: "Run tests"},
+				},
+			},
+			expectedError: ".tasks.test must set steps or alias-of",
+		},
+		{
+			name: "uses a task from an include",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Includes:      []Include{{Path: "ci/tasks.yaml", Prefix: "ci-"}},
+				Tasks: TaskMap{
+					"test": Task{
+						Steps: []Step{{Uses: "ci-build"}},
+					},
+				},
+			},
+		},
+		{
+			name: "include with absolute path",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Includes:      []Include{{Path: "/ci/tasks.yaml"}},
+				Tasks: TaskMap{
+					"test": Task{
+						Steps: []Step{{Run: "echo test"}},
+					},
+				},
+			},
+			expectedError: ".includes[0].path cannot be an absolute path: /ci/tasks.yaml",
+		},
+		{
+			name: "include with invalid prefix",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Includes:      []Include{{Path: "ci/tasks.yaml", Prefix: "ci."}},
+				Tasks: TaskMap{
+					"test": Task{
+						Steps: []Step{{Run: "echo test"}},
+					},
+				},
+			},
+			expectedError: `.includes[0].prefix "ci." does not satisfy "^[_a-zA-Z][a-zA-Z0-9_-]*$"`,
+		},
 		{
 			name: "invalid alias with absolute path",
 			wf: Workflow{
@@ -802,6 +1407,24 @@ func TestValidate(t *testing.T) {
 			},
 			expectedError: fmt.Sprintf(".aliases.file cannot be one of [%s]", strings.Join(SupportedSchemes(), ", ")),
 		},
+		{
+			name: "alias headers are not allowed in workflows",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Aliases: AliasMap{
+					"remote": {
+						Type:    "github",
+						Headers: map[string]string{"Authorization": "Bearer ${TOKEN}"},
+					},
+				},
+				Tasks: TaskMap{
+					"test": Task{
+						Steps: []Step{{Run: "echo test"}},
+					},
+				},
+			},
+			expectedError: ".aliases.remote.headers can only be set in the system config",
+		},
 		{
 			name: "invalid alias name using http scheme",
 			wf: Workflow{
@@ -870,6 +1493,65 @@ func TestValidate(t *testing.T) {
 			},
 			expectedError: fmt.Sprintf(".aliases.oci cannot be one of [%s]", strings.Join(SupportedSchemes(), ", ")),
 		},
+		{
+			name: "errors are reported in task name order",
+			wf: Workflow{
+				Tasks: TaskMap{
+					"d": Task{Steps: []Step{{Run: "echo", Timeout: "d"}}},
+					"b": Task{Steps: []Step{{Run: "echo", Timeout: "b"}}},
+					"c": Task{Steps: []Step{{Run: "echo", Timeout: "c"}}},
+					"a": Task{Steps: []Step{{Run: "echo", Timeout: "a"}}},
+				},
+			},
+			expectedError: ".tasks.a[0].timeout \"a\" is not a valid time duration",
+		},
+		{
+			name: "env errors are reported in name order",
+			wf: Workflow{
+				Tasks: TaskMap{
+					"a": Task{Steps: []Step{{Run: "echo", Env: schema.Env{"3": "", "1": "", "2": ""}}}},
+				},
+			},
+			expectedError: fmt.Sprintf(".tasks.a[0].env \"1\" does not satisfy %q", EnvVariablePattern.String()),
+		},
+		{
+			name: "valid tests",
+			wf: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"default": Task{Steps: []Step{{Uses: "build"}}},
+					"build":   Task{Steps: []Step{{Run: "echo"}}},
+				},
+				Tests: TestMap{
+					"calls-default": Test{Mocks: map[string]Mock{"build": {Outputs: map[string]any{"a": "b"}}}},
+					"calls-build":   Test{Task: "build", With: schema.With{"a": 1}, Expect: Expect{ExitCode: 1, Error: "boom"}},
+				},
+			},
+		},
+		{
+			name: "invalid test name",
+			wf: Workflow{
+				Tasks: TaskMap{"build": Task{Steps: []Step{{Run: "echo"}}}},
+				Tests: TestMap{"2-build": Test{Task: "build"}},
+			},
+			expectedError: fmt.Sprintf("test name \"2-build\" does not satisfy %q", TaskNamePattern.String()),
+		},
+		{
+			name: "test task not found",
+			wf: Workflow{
+				Tasks: TaskMap{"build": Task{Steps: []Step{{Run: "echo"}}}},
+				Tests: TestMap{"builds": Test{Task: "biuld"}},
+			},
+			expectedError: ".tests.builds.task \"biuld\" not found, did you mean \"build\"?",
+		},
+		{
+			name: "test of missing default task",
+			wf: Workflow{
+				Tasks: TaskMap{"build": Task{Steps: []Step{{Run: "echo"}}}},
+				Tests: TestMap{"builds": Test{}},
+			},
+			expectedError: ".tests.builds.task \"default\" not found",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -945,6 +1627,63 @@ tasks:
 				},
 			},
 		},
+		{
+			name: "workflow with workflow inputs",
+			r: strings.NewReader(`
+schema-version: v1
+inputs:
+  name:
+    description: "string"
+    default: "default name"
+  log-level:
+    description: "log level"
+    default-from-env: LOG_LEVEL
+tasks:
+  echo:
+    steps:
+      - run: echo
+  greet:
+    inputs:
+      name:
+        description: "who to greet"
+      greeting:
+        description: "greeting"
+        default: "hello"
+      log-level:
+        description: "log level"
+        default: "debug"
+    steps:
+      - run: echo
+`),
+			expected: Workflow{
+				SchemaVersion: SchemaVersion,
+				Inputs: InputMap{
+					"name":      InputParameter{Description: "string", Default: "default name"},
+					"log-level": InputParameter{Description: "log level", DefaultFromEnv: "LOG_LEVEL"},
+				},
+				Tasks: TaskMap{
+					"echo": Task{
+						Inputs: InputMap{
+							"name":      InputParameter{Description: "string", Default: "default name"},
+							"log-level": InputParameter{Description: "log level", DefaultFromEnv: "LOG_LEVEL"},
+						},
+						Steps: []Step{{
+							Run: "echo",
+						}},
+					},
+					"greet": Task{
+						Inputs: InputMap{
+							"name":      InputParameter{Description: "who to greet", Default: "default name"},
+							"greeting":  InputParameter{Description: "greeting", Default: "hello"},
+							"log-level": InputParameter{Description: "log level", Default: "debug"},
+						},
+						Steps: []Step{{
+							Run: "echo",
+						}},
+					},
+				},
+			},
+		},
 		{
 			name: "workflow with task inputs and aliases",
 			r: strings.NewReader(`
@@ -1284,7 +2023,7 @@ func TestValidateSchemaOnce(t *testing.T) {
 			schemaOnce = sync.OnceValues(tt.setupSchema)
 
 			err := Validate(Workflow{
-				Tasks: TaskMap{"default": Task{}},
+				Tasks: TaskMap{"default": Task{Steps: []Step{{Run: "echo"}}}},
 			})
 			require.ErrorContains(t, err, tt.expectedErrMsg)
 		})
@@ -5,6 +5,7 @@ package v1
 
 import (
 	"fmt"
+	"maps"
 	"slices"
 	"strings"
 
@@ -19,9 +20,70 @@ const SchemaURL = "https://raw.githubusercontent.com/defenseunicorns/maru2/main/
 
 // Workflow represents a "tasks.yaml" file
 type Workflow struct {
-	SchemaVersion string   `json:"schema-version"`
-	Aliases       AliasMap `json:"aliases,omitempty"`
-	Tasks         TaskMap  `json:"tasks,omitempty"`
+	SchemaVersion string    `json:"schema-version"`
+	Aliases       AliasMap  `json:"aliases,omitempty"`
+	Includes      []Include `json:"includes,omitempty"`
+	Inputs        InputMap  `json:"inputs,omitempty"`
+	Defaults      *Defaults `json:"defaults,omitempty"`
+	Tasks         TaskMap   `json:"tasks,omitempty"`
+	Tests         TestMap   `json:"tests,omitempty"`
+}
+
+// Defaults are settings applied to every step in a workflow, unless a step sets its own
+type Defaults struct {
+	// CollapseSteps groups the output of every step in CI environments
+	CollapseSteps bool `json:"collapse-steps,omitempty"`
+	// TemplateMode controls how templates referencing missing inputs and outputs are rendered
+	TemplateMode string `json:"template-mode,omitempty"`
+}
+
+// Template modes control how templates referencing missing inputs and outputs are rendered
+const (
+	// TemplateModeStrict fails to render templates referencing missing inputs and outputs
+	TemplateModeStrict = "strict"
+	// TemplateModeLenient renders missing inputs and outputs as empty strings, logging a warning
+	TemplateModeLenient = "lenient"
+)
+
+// templateModeSchema returns the JSON schema of a template mode
+func templateModeSchema(description string) *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type: "string",
+		Description: description + `
+
+See https://github.com/defenseunicorns/maru2/blob/main/docs/syntax.md#lenient-templates`,
+		Enum: []any{TemplateModeStrict, TemplateModeLenient},
+	}
+}
+
+// JSONSchemaExtend extends the JSON schema for workflow defaults
+func (Defaults) JSONSchemaExtend(schema *jsonschema.Schema) {
+	schema.Description = "Settings applied to every step in the workflow, unless a step sets its own"
+
+	if collapse, ok := schema.Properties.Get("collapse-steps"); ok && collapse != nil {
+		collapse.Description = "Group the output of every step in CI environments (GitHub Actions, GitLab CI), unless the step sets collapse"
+	}
+
+	schema.Properties.Set("template-mode", templateModeSchema("How templates referencing missing inputs and outputs are rendered in every step, unless the step sets template-mode (default: strict)"))
+}
+
+// CollapseStep returns whether a step's output is grouped in CI environments
+func (wf Workflow) CollapseStep(step Step) bool {
+	if step.Collapse != nil {
+		return *step.Collapse
+	}
+	return wf.Defaults != nil && wf.Defaults.CollapseSteps
+}
+
+// StepTemplateMode returns how templates in a step are rendered, an empty step returns the workflow's default
+func (wf Workflow) StepTemplateMode(step Step) string {
+	if step.TemplateMode != "" {
+		return step.TemplateMode
+	}
+	if wf.Defaults != nil && wf.Defaults.TemplateMode != "" {
+		return wf.Defaults.TemplateMode
+	}
+	return TemplateModeStrict
 }
 
 // JSONSchemaExtend extends the JSON schema for a workflow
@@ -34,6 +96,26 @@ func (Workflow) JSONSchemaExtend(schema *jsonschema.Schema) {
 	if tasks, ok := schema.Properties.Get("tasks"); ok && tasks != nil {
 		tasks.Description = "Map of tasks where the key is the task name, the task named 'default' is called when no task is specified"
 	}
+	if inputs, ok := schema.Properties.Get("inputs"); ok && inputs != nil {
+		inputs.Description = `Input parameters inherited by every task, a task's own input parameter with the same name takes priority
+See https://github.com/defenseunicorns/maru2/blob/main/docs/syntax.md#workflow-inputs
+`
+	}
+	if tests, ok := schema.Properties.Get("tests"); ok && tests != nil {
+		tests.Description = `Map of tests where the key is the test name, run with 'maru2 test'
+See https://github.com/defenseunicorns/maru2/blob/main/docs/syntax.md#workflow-tests
+`
+	}
+	if defaults, ok := schema.Properties.Get("defaults"); ok && defaults != nil {
+		defaults.Description = `Settings applied to every step in the workflow, unless a step sets its own
+See https://github.com/defenseunicorns/maru2/blob/main/docs/syntax.md#output-grouping-with-collapse
+`
+	}
+	if includes, ok := schema.Properties.Get("includes"); ok && includes != nil {
+		includes.Description = `Local workflow files whose tasks are merged into this workflow when it is fetched, optionally under a prefix
+See https://github.com/defenseunicorns/maru2/blob/main/docs/syntax.md#including-workflow-files
+`
+	}
 	if aliases, ok := schema.Properties.Get("aliases"); ok && aliases != nil {
 		aliases.Description = `Aliases for package URLs or local file paths to create shorthand references
 See https://github.com/defenseunicorns/maru2/blob/main/docs/syntax.md#package-url-aliases
@@ -43,6 +125,34 @@ See https://github.com/defenseunicorns/maru2/blob/main/docs/syntax.md#local-file
 	}
 }
 
+// inheritInputs adds the workflow's input parameters to every task
+//
+// A task's own input parameter takes priority, falling back to the workflow's default values if it does not set any
+func (wf Workflow) inheritInputs() {
+	if len(wf.Inputs) == 0 {
+		return
+	}
+	for name, task := range wf.Tasks {
+		// an alias takes the inputs of the task it is an alias of
+		if task.AliasOf != "" {
+			continue
+		}
+		inputs := make(InputMap, len(wf.Inputs)+len(task.Inputs))
+		for inputName, param := range wf.Inputs {
+			inputs[inputName] = param
+		}
+		for inputName, param := range task.Inputs {
+			if inherited, ok := wf.Inputs[inputName]; ok && param.Default == nil && param.DefaultFromEnv == "" {
+				param.Default = inherited.Default
+				param.DefaultFromEnv = inherited.DefaultFromEnv
+			}
+			inputs[inputName] = param
+		}
+		task.Inputs = inputs
+		wf.Tasks[name] = task
+	}
+}
+
 // Explain generates a markdown explanation of the workflow and its tasks
 func (wf Workflow) Explain(taskNames ...string) string {
 	var explanation strings.Builder
@@ -84,76 +194,157 @@ func (wf Workflow) Explain(taskNames ...string) string {
 			continue
 		}
 
+		heading := fmt.Sprintf("`%s`", name)
 		if name == "default" {
-			explanation.WriteString("### `default` (Default Task)\n\n")
-		} else {
-			explanation.WriteString(fmt.Sprintf("### `%s`\n\n", name))
+			heading += " (Default Task)"
 		}
-
-		if task.Description != "" {
-			explanation.WriteString(fmt.Sprintf("%s\n\n", task.Description))
+		if task.AliasOf != "" {
+			heading += fmt.Sprintf(" → `%s`", task.AliasOf)
 		}
+		explainTask(&explanation, heading, task)
+	}
 
-		if task.Collapse {
-			explanation.WriteString("*Output will be grouped in CI environments (GitHub Actions, GitLab CI)*\n\n")
-		}
+	return explanation.String()
+}
+
+// ExplainTask generates a markdown explanation of a single task, with location appended to its heading when set
+//
+// Returns an empty string if the task does not exist in the workflow
+func (wf Workflow) ExplainTask(name, location string) string {
+	task, ok := wf.Tasks.Find(name)
+	if !ok {
+		return ""
+	}
 
-		if len(task.Inputs) > 0 {
-			explanation.WriteString("**Input Parameters:**\n\n")
-			explanation.WriteString("| Name | Description | Required | Default | Validation | Notes |\n")
-			explanation.WriteString("|------|-------------|----------|---------|------------|-------|\n")
+	heading := fmt.Sprintf("`%s`", name)
+	if aliasOf := wf.Tasks[name].AliasOf; aliasOf != "" {
+		heading += fmt.Sprintf(" → `%s`", aliasOf)
+	}
+	if location != "" {
+		heading += fmt.Sprintf(" (`%s`)", location)
+	}
 
-			for inputName, param := range task.Inputs.OrderedSeq() {
-				name := fmt.Sprintf("`%s`", inputName)
+	var explanation strings.Builder
+	explainTask(&explanation, heading, task)
+	return explanation.String()
+}
 
-				description := param.Description
+// explainTask writes the markdown explanation of a task under a level 3 heading
+func explainTask(explanation *strings.Builder, heading string, task Task) {
+	explanation.WriteString(fmt.Sprintf("### %s\n\n", heading))
 
-				required := "Yes"
-				if param.Required != nil && !*param.Required {
-					required = "No"
-				}
+	if task.Description != "" {
+		explanation.WriteString(fmt.Sprintf("%s\n\n", task.Description))
+	}
 
-				defaultValue := "-"
-				if param.Default != nil {
-					defaultValue = fmt.Sprintf("`%v`", param.Default)
-				} else if param.DefaultFromEnv != "" {
-					defaultValue = fmt.Sprintf("`$%s`", param.DefaultFromEnv)
-				}
+	if task.DeprecatedMessage != "" {
+		explanation.WriteString(fmt.Sprintf("⚠️ **Deprecated**: %s\n\n", task.DeprecatedMessage))
+	}
 
-				validation := "-"
-				if param.Validate != "" {
-					validation = fmt.Sprintf("`%s`", param.Validate)
-				}
+	if task.Collapse {
+		explanation.WriteString("*Output will be grouped in CI environments (GitHub Actions, GitLab CI)*\n\n")
+	}
 
-				notes := "-"
-				if param.DeprecatedMessage != "" {
-					notes = fmt.Sprintf("⚠️ **Deprecated**: %s", param.DeprecatedMessage)
-				}
+	if task.RunsOn != "" {
+		explanation.WriteString(fmt.Sprintf("*Runs on `%s`*\n\n", task.RunsOn))
+	}
 
-				explanation.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s |\n",
-					name, description, required, defaultValue, validation, notes))
+	if task.Mutex != "" {
+		explanation.WriteString(fmt.Sprintf("*Runs while holding the `%s` mutex*\n\n", task.Mutex))
+	}
+
+	if task.Confirm != "" {
+		explanation.WriteString(fmt.Sprintf("*Asks for confirmation before running: %s*\n\n", task.Confirm))
+	}
+
+	if len(task.Inputs) > 0 {
+		explanation.WriteString("**Input Parameters:**\n\n")
+		explanation.WriteString("| Name | Description | Required | Default | Validation | Notes |\n")
+		explanation.WriteString("|------|-------------|----------|---------|------------|-------|\n")
+
+		for inputName, param := range task.Inputs.OrderedSeq() {
+			name := fmt.Sprintf("`%s`", inputName)
+
+			description := param.Description
+
+			required := "Yes"
+			if param.Required != nil && !*param.Required {
+				required = "No"
 			}
-			explanation.WriteString("\n")
-		}
 
-		uses := []string{}
-		for _, step := range task.Steps {
-			if step.Uses != "" {
-				uses = append(uses, step.Uses)
+			defaultValue := "-"
+			if param.Default != nil {
+				defaultValue = fmt.Sprintf("`%v`", param.Default)
+			} else if param.DefaultFromEnv != "" {
+				defaultValue = fmt.Sprintf("`$%s`", param.DefaultFromEnv)
+			}
+
+			validation := "-"
+			if param.Validate != "" {
+				validation = fmt.Sprintf("`%s`", param.Validate)
 			}
-		}
-		uses = slices.Compact(uses)
 
-		if len(uses) > 0 {
-			explanation.WriteString("**Uses:**\n\n")
-			for _, u := range uses {
-				explanation.WriteString(fmt.Sprintf("- `%s`\n", u))
+			var notes []string
+			if param.DeprecatedMessage != "" {
+				notes = append(notes, fmt.Sprintf("⚠️ **Deprecated**: %s", param.DeprecatedMessage))
+			}
+			if conflicts := task.Inputs.Conflicts(inputName); len(conflicts) > 0 {
+				notes = append(notes, fmt.Sprintf("Conflicts with `%s`", strings.Join(conflicts, "`, `")))
+			}
+			if len(param.Requires) > 0 {
+				notes = append(notes, fmt.Sprintf("Requires `%s`", strings.Join(param.Requires, "`, `")))
 			}
-			explanation.WriteString("\n\n")
+			if len(notes) == 0 {
+				notes = append(notes, "-")
+			}
+
+			explanation.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s |\n",
+				name, description, required, defaultValue, validation, strings.Join(notes, "<br>")))
 		}
+		explanation.WriteString("\n")
 	}
 
-	return explanation.String()
+	if slices.ContainsFunc(task.Steps, func(step Step) bool { return step.Description != "" }) {
+		explanation.WriteString("**Steps:**\n\n")
+		for idx, step := range task.Steps {
+			explanation.WriteString(fmt.Sprintf("%d. %s\n", idx+1, explainStep(step)))
+		}
+		explanation.WriteString("\n")
+	}
+
+	uses := []string{}
+	for _, step := range task.Steps {
+		if step.Uses != "" {
+			uses = append(uses, step.Uses)
+		}
+	}
+	uses = slices.Compact(uses)
+
+	if len(uses) > 0 {
+		explanation.WriteString("**Uses:**\n\n")
+		for _, u := range uses {
+			explanation.WriteString(fmt.Sprintf("- `%s`\n", u))
+		}
+		explanation.WriteString("\n\n")
+	}
+}
+
+// explainStep summarizes a step by its description, falling back to its name, uses or the first line of its run
+func explainStep(step Step) string {
+	switch {
+	case step.Description != "":
+		return step.Description
+	case step.Name != "":
+		return step.Name
+	case step.Uses != "":
+		return fmt.Sprintf("`%s`", step.Uses)
+	}
+	run := step.Run
+	if variants := step.RunVariants(); run == "" && len(variants) > 0 {
+		run = variants[slices.Sorted(maps.Keys(variants))[0]]
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(run), "\n")
+	return fmt.Sprintf("`%s`", line)
 }
 
 // WorkFlowSchema returns a JSON schema for a maru2 workflow
@@ -200,6 +200,7 @@ func TestWorkflowExplain(t *testing.T) {
 			"default": Task{
 				Description: "Default build task",
 				Collapse:    true,
+				Mutex:       "build",
 				Inputs: InputMap{
 					"version": InputParameter{
 						Description:    "Version to build",
@@ -294,6 +295,59 @@ func TestWorkflowExplain(t *testing.T) {
 				"",
 			},
 		},
+		{
+			name: "deprecated task",
+			workflow: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"old-build": Task{
+						Description:       "Build the app",
+						DeprecatedMessage: "Use build instead",
+						Steps:             []Step{{Run: "go build"}},
+					},
+				},
+			},
+			taskNames: []string{"old-build"},
+			expected: []string{
+				"### `old-build`",
+				"",
+				"Build the app",
+				"",
+				"⚠️ **Deprecated**: Use build instead",
+				"",
+				"",
+			},
+		},
+		{
+			name: "input relationships",
+			workflow: Workflow{
+				SchemaVersion: SchemaVersion,
+				Tasks: TaskMap{
+					"checkout": Task{
+						Inputs: InputMap{
+							"tag":    {Description: "Tag", ConflictsWith: []string{"branch"}},
+							"branch": {Description: "Branch", Requires: []string{"remote"}},
+							"remote": {Description: "Remote", Default: "origin"},
+						},
+						Steps: []Step{{Run: "git checkout"}},
+					},
+				},
+			},
+			taskNames: []string{"checkout"},
+			expected: []string{
+				"### `checkout`",
+				"",
+				"**Input Parameters:**",
+				"",
+				"| Name | Description | Required | Default | Validation | Notes |",
+				"|------|-------------|----------|---------|------------|-------|",
+				"| `branch` | Branch | Yes | - | - | Conflicts with `tag`<br>Requires `remote` |",
+				"| `remote` | Remote | Yes | `origin` | - | - |",
+				"| `tag` | Tag | Yes | - | - | Conflicts with `branch` |",
+				"",
+				"",
+			},
+		},
 		{
 			name:     "complex workflow with all features",
 			workflow: complexWorkflow,
@@ -321,6 +375,8 @@ func TestWorkflowExplain(t *testing.T) {
 				"",
 				"*Output will be grouped in CI environments (GitHub Actions, GitLab CI)*",
 				"",
+				"*Runs while holding the `build` mutex*",
+				"",
 				"**Input Parameters:**",
 				"",
 				"| Name | Description | Required | Default | Validation | Notes |",
@@ -375,3 +431,64 @@ func TestWorkflowExplain(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkflowExplainTask(t *testing.T) {
+	wf := Workflow{
+		Tasks: TaskMap{
+			"build": Task{
+				Description: "Build it",
+				Inputs:      InputMap{"output": InputParameter{Description: "Output path"}},
+				Steps:       []Step{{Uses: "builtin:echo"}},
+			},
+		},
+	}
+
+	assert.Equal(t, "### `build`\n\nBuild it\n\n**Input Parameters:**\n\n"+
+		"| Name | Description | Required | Default | Validation | Notes |\n"+
+		"|------|-------------|----------|---------|------------|-------|\n"+
+		"| `output` | Output path | Yes | - | - | - |\n\n"+
+		"**Uses:**\n\n- `builtin:echo`\n\n\n", wf.ExplainTask("build", ""))
+
+	assert.Equal(t, "### `build` (`file:lib.yaml`)\n\nBuild it\n\n", Workflow{
+		Tasks: TaskMap{"build": Task{Description: "Build it"}},
+	}.ExplainTask("build", "file:lib.yaml"))
+
+	assert.Empty(t, wf.ExplainTask("missing", ""))
+
+	assert.Equal(t, "### `release`\n\n**Steps:**\n\n"+
+		"1. Build the binaries for every platform\n"+
+		"2. Checksum\n"+
+		"3. `builtin:echo`\n"+
+		"4. `gh release create`\n"+
+		"5. `shasum -a 256 dist/*`\n\n"+
+		"**Uses:**\n\n- `builtin:echo`\n\n\n", Workflow{
+		Tasks: TaskMap{"release": Task{Steps: []Step{
+			{Description: "Build the binaries for every platform", Run: "make build"},
+			{Name: "Checksum", Run: "sha256sum dist/*"},
+			{Uses: "builtin:echo"},
+			{Run: "\ngh release create\n--notes-from-tag\n"},
+			{RunDarwin: "shasum -a 256 dist/*", RunWindows: "Get-FileHash dist/*"},
+		}}},
+	}.ExplainTask("release", ""))
+}
+
+func TestWorkflowCollapseStep(t *testing.T) {
+	yes, no := true, false
+
+	assert.False(t, Workflow{}.CollapseStep(Step{}))
+	assert.True(t, Workflow{}.CollapseStep(Step{Collapse: &yes}))
+
+	wf := Workflow{Defaults: &Defaults{CollapseSteps: true}}
+	assert.True(t, wf.CollapseStep(Step{}))
+	assert.False(t, wf.CollapseStep(Step{Collapse: &no}))
+	assert.True(t, wf.CollapseStep(Step{Collapse: &yes}))
+}
+
+func TestWorkflowStepTemplateMode(t *testing.T) {
+	assert.Equal(t, TemplateModeStrict, Workflow{}.StepTemplateMode(Step{}))
+	assert.Equal(t, TemplateModeLenient, Workflow{}.StepTemplateMode(Step{TemplateMode: TemplateModeLenient}))
+
+	wf := Workflow{Defaults: &Defaults{TemplateMode: TemplateModeLenient}}
+	assert.Equal(t, TemplateModeLenient, wf.StepTemplateMode(Step{}))
+	assert.Equal(t, TemplateModeStrict, wf.StepTemplateMode(Step{TemplateMode: TemplateModeStrict}))
+}
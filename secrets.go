@@ -0,0 +1,229 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+)
+
+// SecretMask replaces the value of a secret in any output
+const SecretMask = "***"
+
+type secretsKey struct{}
+
+// Secrets are values only accessible via ${{ secret "name" }}, that are masked in all output
+//
+// Unlike inputs, secrets are never exposed as environment variables, not even when passed to a task as an input
+type Secrets map[string]string
+
+// WithSecrets returns a context carrying the secrets of the current run
+func WithSecrets(ctx context.Context, secrets Secrets) context.Context {
+	return context.WithValue(ctx, secretsKey{}, secrets)
+}
+
+// secretsFromContext returns the secrets of the current run, or nil if there are none
+func secretsFromContext(ctx context.Context) Secrets {
+	secrets, _ := ctx.Value(secretsKey{}).(Secrets)
+	return secrets
+}
+
+// LoadSecrets resolves the source of each secret
+//
+// Sources are one of:
+//
+//	env:NAME     the value of the environment variable NAME
+//	file:PATH    the contents of the file at PATH
+//	cmd:COMMAND  the output of COMMAND, run with sh -c
+//
+// A single trailing newline is removed from file contents and command output
+func LoadSecrets(ctx context.Context, sources map[string]string) (Secrets, error) {
+	secrets := make(Secrets, len(sources))
+	for _, name := range slices.Sorted(maps.Keys(sources)) {
+		if !v1.InputNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("secret name %q does not satisfy %q", name, v1.InputNamePattern)
+		}
+		value, err := loadSecret(ctx, sources[name])
+		if err != nil {
+			return nil, fmt.Errorf("secret %q: %w", name, err)
+		}
+		secrets[name] = value
+	}
+	return secrets, nil
+}
+
+// loadSecret resolves a single secret source
+func loadSecret(ctx context.Context, source string) (string, error) {
+	kind, ref, ok := strings.Cut(source, ":")
+	if !ok || ref == "" {
+		return "", fmt.Errorf("source %q must be one of env:NAME, file:PATH or cmd:COMMAND", source)
+	}
+
+	switch kind {
+	case "env":
+		value, ok := os.LookupEnv(ref)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", ref)
+		}
+		return value, nil
+	case "file":
+		b, err := os.ReadFile(ref)
+		if err != nil {
+			return "", err
+		}
+		return trimNewline(string(b)), nil
+	case "cmd":
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "sh", "-c", ref)
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return trimNewline(string(out)), nil
+	default:
+		return "", fmt.Errorf("source %q must be one of env:NAME, file:PATH or cmd:COMMAND", source)
+	}
+}
+
+// trimNewline removes a single trailing newline
+func trimNewline(s string) string {
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r")
+}
+
+// maskedPrefix names values that are masked without being a secret available by name, such as secret prompt answers
+const maskedPrefix = "masked:"
+
+// addMasked masks value in all output for the rest of the run
+func (s Secrets) addMasked(value string) {
+	s[maskedPrefix+value] = value
+}
+
+// Names returns the names of the secrets in alphabetical order
+func (s Secrets) Names() []string {
+	names := slices.Sorted(maps.Keys(s))
+	return slices.DeleteFunc(names, func(name string) bool {
+		return strings.HasPrefix(name, maskedPrefix)
+	})
+}
+
+// Mask replaces every secret value within str with SecretMask
+func (s Secrets) Mask(str string) string {
+	if len(s) == 0 {
+		return str
+	}
+	values := make([]string, 0, len(s))
+	for _, v := range s {
+		if v != "" {
+			values = append(values, v)
+		}
+	}
+	// mask longer values first, so a secret containing another is fully masked
+	slices.SortFunc(values, func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(b), len(a)), cmp.Compare(a, b))
+	})
+	for _, v := range values {
+		str = strings.ReplaceAll(str, v, SecretMask)
+	}
+	return str
+}
+
+// maxMaskLine is how much of a partial line MaskWriter buffers before writing it out
+const maxMaskLine = 64 << 10
+
+// MaskWriter masks secrets in everything written to an underlying writer
+//
+// Output is masked a line at a time, so a partial line is only written once it is completed, flushed or grows past maxMaskLine
+type MaskWriter struct {
+	w       io.Writer
+	secrets Secrets
+	buf     []byte
+	mu      sync.Mutex
+}
+
+// NewMaskWriter creates a writer that masks secrets before writing to w
+func NewMaskWriter(w io.Writer, secrets Secrets) *MaskWriter {
+	return &MaskWriter{w: w, secrets: secrets}
+}
+
+// Write implements io.Writer
+func (m *MaskWriter) Write(p []byte) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.buf = append(m.buf, p...)
+	n := bytes.LastIndexByte(m.buf, '\n') + 1
+	if n == 0 && len(m.buf) > maxMaskLine {
+		// keep enough of a long line to catch a secret cut off by the next write
+		n = m.secrets.cut(m.buf)
+	}
+	if n == 0 {
+		return len(p), nil
+	}
+
+	out := m.secrets.Mask(string(m.buf[:n]))
+	m.buf = append(m.buf[:0], m.buf[n:]...)
+	if _, err := io.WriteString(m.w, out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// cut returns where b can be written out, keeping a tail that could still grow into a secret and without splitting one
+func (s Secrets) cut(b []byte) int {
+	longest := 0
+	for _, v := range s {
+		longest = max(longest, len(v))
+	}
+	cut := max(len(b)-max(longest-1, 0), 0)
+
+	for moved := true; moved; {
+		moved = false
+		for _, v := range s {
+			if v == "" {
+				continue
+			}
+			from := max(cut-len(v)+1, 0)
+			if i := bytes.Index(b[from:], []byte(v)); i != -1 && from+i < cut {
+				cut = from + i
+				moved = true
+			}
+		}
+	}
+	return cut
+}
+
+// Flush writes any partial line
+func (m *MaskWriter) Flush() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.buf) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(m.w, m.secrets.Mask(string(m.buf)))
+	m.buf = m.buf[:0]
+	return err
+}
+
+// maskAll masks secrets within each string
+func maskAll(secrets Secrets, strs []string) []string {
+	masked := make([]string, len(strs))
+	for i, str := range strs {
+		masked[i] = secrets.Mask(str)
+	}
+	return masked
+}
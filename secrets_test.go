@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSecrets(t *testing.T) {
+	t.Setenv("MARU2_TEST_SECRET", "from-env")
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secret.txt")
+	require.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0o600))
+
+	tests := []struct {
+		name          string
+		sources       map[string]string
+		expected      Secrets
+		expectedError string
+	}{
+		{
+			name:     "none",
+			expected: Secrets{},
+		},
+		{
+			name: "env, file and cmd",
+			sources: map[string]string{
+				"env":  "env:MARU2_TEST_SECRET",
+				"file": "file:" + path,
+				"cmd":  "cmd:echo from-cmd",
+			},
+			expected: Secrets{
+				"env":  "from-env",
+				"file": "from-file",
+				"cmd":  "from-cmd",
+			},
+		},
+		{
+			name:          "invalid name",
+			sources:       map[string]string{"1bad": "env:MARU2_TEST_SECRET"},
+			expectedError: `secret name "1bad" does not satisfy "^[_a-zA-Z][a-zA-Z0-9_-]*$"`,
+		},
+		{
+			name:          "unset env",
+			sources:       map[string]string{"token": "env:MARU2_TEST_SECRET_UNSET"},
+			expectedError: `secret "token": environment variable MARU2_TEST_SECRET_UNSET is not set`,
+		},
+		{
+			name:          "missing file",
+			sources:       map[string]string{"token": "file:" + filepath.Join(dir, "missing")},
+			expectedError: "no such file or directory",
+		},
+		{
+			name:          "failed cmd",
+			sources:       map[string]string{"token": "cmd:echo oops >&2; exit 1"},
+			expectedError: `secret "token": command failed: exit status 1: oops`,
+		},
+		{
+			name:          "unknown source",
+			sources:       map[string]string{"token": "vault:foo"},
+			expectedError: `secret "token": source "vault:foo" must be one of env:NAME, file:PATH or cmd:COMMAND`,
+		},
+		{
+			name:          "empty source",
+			sources:       map[string]string{"token": "env:"},
+			expectedError: `secret "token": source "env:" must be one of env:NAME, file:PATH or cmd:COMMAND`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			secrets, err := LoadSecrets(t.Context(), tc.sources)
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, secrets)
+		})
+	}
+}
+
+func TestSecretsMask(t *testing.T) {
+	secrets := Secrets{
+		"short": "abc",
+		"long":  "abcdef",
+		"empty": "",
+	}
+
+	assert.Equal(t, "token=*** and ***", secrets.Mask("token=abcdef and abc"))
+	assert.Equal(t, "nothing to see", secrets.Mask("nothing to see"))
+	assert.Equal(t, "abc", Secrets(nil).Mask("abc"))
+	assert.Equal(t, []string{"empty", "long", "short"}, secrets.Names())
+
+	// masked values are not listed as secrets
+	secrets.addMasked("xyz")
+	assert.Equal(t, "token=*** and ***", secrets.Mask("token=abcdef and xyz"))
+	assert.Equal(t, []string{"empty", "long", "short"}, secrets.Names())
+}
+
+func TestMaskWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewMaskWriter(&buf, Secrets{"token": "s3cr3t"})
+
+	// a secret split across writes is still masked
+	_, err := w.Write([]byte("token is s3"))
+	require.NoError(t, err)
+	assert.Empty(t, buf.String())
+
+	_, err = w.Write([]byte("cr3t\nnext s3cr"))
+	require.NoError(t, err)
+	assert.Equal(t, "token is ***\n", buf.String())
+
+	require.NoError(t, w.Flush())
+	assert.Equal(t, "token is ***\nnext s3cr", buf.String())
+
+	require.NoError(t, w.Flush())
+	assert.Equal(t, "token is ***\nnext s3cr", buf.String())
+
+	t.Run("long lines", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewMaskWriter(&buf, Secrets{"token": "s3cr3t"})
+		long := strings.Repeat("a", maxMaskLine)
+
+		// the start of a secret is held back until the next write
+		_, err := w.Write([]byte(long + "s3c"))
+		require.NoError(t, err)
+		assert.Equal(t, long[:len(long)-2], buf.String())
+
+		_, err = w.Write([]byte("r3t"))
+		require.NoError(t, err)
+		require.NoError(t, w.Flush())
+		assert.Equal(t, long+"***", buf.String())
+
+		// a secret is never cut in two
+		buf.Reset()
+		_, err = w.Write([]byte(long + "s3cr3tb"))
+		require.NoError(t, err)
+		assert.Equal(t, long, buf.String())
+		require.NoError(t, w.Flush())
+		assert.Equal(t, long+"***b", buf.String())
+	})
+}
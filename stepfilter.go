@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+)
+
+type stepFilterKey struct{}
+
+type stepSelectedKey struct{}
+
+// StepFilter selects which steps run by their id, without editing the workflow
+//
+// Filters apply to the steps of every task in the run, including tasks called with uses
+type StepFilter struct {
+	// IDs of steps to skip, takes priority over Only
+	Skip []string
+	// IDs of the only steps to run, a listed step that calls another task runs all of its steps
+	//
+	// Steps that call another task (not a builtin) still run, so listed steps within them can run
+	Only []string
+
+	mu      sync.Mutex
+	matched map[string]bool
+}
+
+// WithStepFilter returns a context that only runs the steps selected by f
+func WithStepFilter(ctx context.Context, f *StepFilter) context.Context {
+	return context.WithValue(ctx, stepFilterKey{}, f)
+}
+
+// stepFilterFromContext returns the step filter of the run, nil runs every step
+func stepFilterFromContext(ctx context.Context) *StepFilter {
+	f, _ := ctx.Value(stepFilterKey{}).(*StepFilter)
+	return f
+}
+
+// filter reports whether a step is filtered out, returning a context that marks the steps
+// within a step listed in Only as selected
+func (f *StepFilter) filter(ctx context.Context, step v1.Step) (context.Context, bool) {
+	if f == nil {
+		return ctx, false
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if step.ID != "" && slices.Contains(f.Skip, step.ID) {
+		f.match(step.ID)
+		return ctx, true
+	}
+
+	if len(f.Only) == 0 {
+		return ctx, false
+	}
+
+	if selected, _ := ctx.Value(stepSelectedKey{}).(bool); selected {
+		return ctx, false
+	}
+
+	if step.ID != "" && slices.Contains(f.Only, step.ID) {
+		f.match(step.ID)
+		return context.WithValue(ctx, stepSelectedKey{}, true), false
+	}
+
+	callsTask := step.Uses != "" && !strings.HasPrefix(step.Uses, "builtin:")
+	return ctx, !callsTask
+}
+
+func (f *StepFilter) match(id string) {
+	if f.matched == nil {
+		f.matched = make(map[string]bool)
+	}
+	f.matched[id] = true
+}
+
+// Unmatched returns the IDs in Skip and Only that did not match any step that was reached during the run
+func (f *StepFilter) Unmatched() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var unmatched []string
+	for _, id := range slices.Concat(f.Skip, f.Only) {
+		if !f.matched[id] && !slices.Contains(unmatched, id) {
+			unmatched = append(unmatched, id)
+		}
+	}
+	return unmatched
+}
@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+	"github.com/defenseunicorns/maru2/uses"
+)
+
+func TestStepFilter(t *testing.T) {
+	var f *StepFilter
+	_, filtered := f.filter(t.Context(), v1.Step{ID: "build"})
+	assert.False(t, filtered)
+
+	f = &StepFilter{Skip: []string{"lint"}, Only: []string{"build", "lint", "missing"}}
+
+	_, filtered = f.filter(t.Context(), v1.Step{ID: "lint"})
+	assert.True(t, filtered, "skip takes priority over only")
+
+	_, filtered = f.filter(t.Context(), v1.Step{ID: "test", Run: "go test"})
+	assert.True(t, filtered)
+
+	_, filtered = f.filter(t.Context(), v1.Step{Uses: "builtin:echo"})
+	assert.True(t, filtered)
+
+	_, filtered = f.filter(t.Context(), v1.Step{Uses: "other"})
+	assert.False(t, filtered, "steps that call other tasks run so listed steps within them can run")
+
+	ctx, filtered := f.filter(t.Context(), v1.Step{ID: "build", Uses: "other"})
+	assert.False(t, filtered)
+
+	// every step within a listed step runs
+	_, filtered = f.filter(ctx, v1.Step{ID: "test", Run: "go test"})
+	assert.False(t, filtered)
+	_, filtered = f.filter(ctx, v1.Step{ID: "lint"})
+	assert.True(t, filtered)
+
+	assert.Equal(t, []string{"missing"}, f.Unmatched())
+}
+
+func TestRunStepFilter(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "tasks.yaml", []byte(`schema-version: v1
+tasks:
+  default:
+    steps:
+      - run: echo one
+        id: one
+      - run: echo two
+        id: two
+      - uses: nested
+      - run: echo unnamed
+  nested:
+    steps:
+      - run: echo three
+        id: three
+      - run: echo four
+`), 0o644))
+
+	svc, err := uses.NewFetcherService(uses.WithFS(fs))
+	require.NoError(t, err)
+
+	origin, err := url.Parse("file:tasks.yaml")
+	require.NoError(t, err)
+
+	ctx := log.WithContext(t.Context(), log.New(io.Discard))
+	wf, err := Fetch(ctx, svc, origin)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		filter   *StepFilter
+		expected string
+	}{
+		{
+			name:     "skip",
+			filter:   &StepFilter{Skip: []string{"two", "three"}},
+			expected: "one\nfour\nunnamed\n",
+		},
+		{
+			name:     "only",
+			filter:   &StepFilter{Only: []string{"two", "three"}},
+			expected: "two\nthree\n",
+		},
+		{
+			name:     "skip and only",
+			filter:   &StepFilter{Skip: []string{"two"}, Only: []string{"one", "two"}},
+			expected: "one\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			_, err := Run(WithStepFilter(ctx, tc.filter), svc, wf, "default", nil, origin, RuntimeOptions{Stdout: &buf, Stderr: io.Discard})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, buf.String())
+			assert.Empty(t, tc.filter.Unmatched())
+		})
+	}
+}
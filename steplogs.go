@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type stepLogsKey struct{}
+
+// stepLogs are the files a step's stdout and stderr are written to, in addition to the console
+type stepLogs struct {
+	stdout *os.File
+	stderr *os.File
+}
+
+// openStepLogs creates timestamped stdout and stderr log files for a step within dir
+func openStepLogs(dir, task string, idx int) (*stepLogs, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	prefix := filepath.Join(dir, fmt.Sprintf("%s-%s-%d", time.Now().UTC().Format("20060102T150405.000000Z"), task, idx))
+
+	stdout, err := os.Create(prefix + ".stdout.log")
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := os.Create(prefix + ".stderr.log")
+	if err != nil {
+		_ = stdout.Close()
+		return nil, err
+	}
+	return &stepLogs{stdout: stdout, stderr: stderr}, nil
+}
+
+// Close closes both log files
+func (l *stepLogs) Close() error {
+	return errors.Join(l.stdout.Close(), l.stderr.Close())
+}
+
+// withStepLogs returns a context whose run step writes its output to l
+func withStepLogs(ctx context.Context, l *stepLogs) context.Context {
+	return context.WithValue(ctx, stepLogsKey{}, l)
+}
+
+// stepLogsFromContext returns the log files of the step being run, or nil if there are none
+func stepLogsFromContext(ctx context.Context) *stepLogs {
+	l, _ := ctx.Value(stepLogsKey{}).(*stepLogs)
+	return l
+}
+
+// teeLog writes to both w and the log file f, w may be nil if the step is muted
+func teeLog(w io.Writer, f *os.File) io.Writer {
+	if w == nil {
+		return f
+	}
+	return io.MultiWriter(w, f)
+}
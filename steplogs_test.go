@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package maru2
+
+import (
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defenseunicorns/maru2/schema"
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+)
+
+func TestStepLogs(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+
+	wf := v1.Workflow{
+		Tasks: v1.TaskMap{
+			"default": v1.Task{Steps: []v1.Step{
+				{Run: `echo "token is ${{ secret "token" }}"; echo oops >&2`},
+				{Run: "echo quiet", Mute: true},
+				{Uses: "builtin:echo", With: schema.With{"text": ""}},
+			}},
+		},
+	}
+
+	r := NewReport("", false)
+	ctx := log.WithContext(t.Context(), log.New(io.Discard))
+	ctx = WithSecrets(ctx, Secrets{"token": "hunter2"})
+	ctx = WithReport(ctx, r)
+
+	var stdout strings.Builder
+	_, err := Run(ctx, nil, wf, "default", nil, &url.URL{Scheme: "file", Opaque: "tasks.yaml"}, RuntimeOptions{
+		Stdout: &stdout,
+		Stderr: io.Discard,
+		LogDir: dir,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "token is ***\n", stdout.String())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 4)
+
+	require.Len(t, r.Tasks, 1)
+	steps := r.Tasks[0].Steps
+	require.Len(t, steps, 3)
+
+	for _, tc := range []struct {
+		path     string
+		suffix   string
+		expected string
+	}{
+		{steps[0].StdoutLog, "-default-0.stdout.log", "token is ***\n"},
+		{steps[0].StderrLog, "-default-0.stderr.log", "oops\n"},
+		{steps[1].StdoutLog, "-default-1.stdout.log", "quiet\n"},
+		{steps[1].StderrLog, "-default-1.stderr.log", ""},
+	} {
+		assert.Equal(t, dir, filepath.Dir(tc.path))
+		assert.True(t, strings.HasSuffix(tc.path, tc.suffix), tc.path)
+		b, err := os.ReadFile(tc.path)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, string(b))
+	}
+
+	// builtins log through the logger, not to files
+	assert.Empty(t, steps[2].StdoutLog)
+	assert.Empty(t, steps[2].StderrLog)
+}
+
+func TestOpenStepLogsError(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o644))
+
+	_, err := openStepLogs(file, "default", 0)
+	require.Error(t, err)
+}
@@ -4,8 +4,13 @@
 package maru2
 
 import (
+	"bytes"
+	"cmp"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"net/url"
 	"path/filepath"
 	"slices"
@@ -13,7 +18,9 @@ import (
 
 	"github.com/charmbracelet/log"
 	"github.com/spf13/afero"
+	"golang.org/x/sync/errgroup"
 
+	"github.com/defenseunicorns/maru2/builtins"
 	"github.com/defenseunicorns/maru2/schema"
 	v1 "github.com/defenseunicorns/maru2/schema/v1"
 	"github.com/defenseunicorns/maru2/uses"
@@ -36,6 +43,13 @@ func handleUsesStep(
 	ro.WorkingDir = filepath.Join(ro.WorkingDir, step.Dir)
 
 	if strings.HasPrefix(step.Uses, "builtin:") {
+		ctx = builtins.WithInvoker(ctx, func(ctx context.Context, from, task string, with map[string]any) (map[string]any, error) {
+			return invoke(ctx, svc, wf, from, task, with, origin, ro)
+		})
+		ctx = builtins.WithHTTPClient(ctx, svc.Client(false))
+		ctx = builtins.WithHostHeadersClient(ctx, svc.Client(true))
+		ctx = builtins.WithCacheStore(ctx, svc.Storage())
+		ctx = builtins.WithWorkingDir(ctx, ro.WorkingDir)
 		return ExecuteBuiltin(ctx, step, withDefaults, outputs, ro.Dry)
 	}
 
@@ -49,13 +63,14 @@ func handleUsesStep(
 	}
 
 	logger.Debug("templated", "result", templatedWith)
+	planFromContext(ctx).recordWith(ctx, templatedWith)
 
 	templatedEnv, err := TemplateWithMap(ctx, step.Env, withDefaults, outputs, ro.Dry)
 	if err != nil {
 		return nil, err
 	}
 
-	env, err := prepareEnvironment(ro.Env, nil, "", templatedEnv)
+	env, err := prepareEnvironment(ro.Env, nil, "", templatedEnv, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -65,7 +80,7 @@ func handleUsesStep(
 		return Run(ctx, svc, wf, step.Uses, templatedWith, origin, ro)
 	}
 
-	next, err := uses.ResolveRelative(origin, step.Uses, wf.Aliases)
+	next, err := svc.ResolveRelative(origin, step.Uses, wf.Aliases)
 	if err != nil {
 		return nil, err
 	}
@@ -80,16 +95,82 @@ func handleUsesStep(
 	return Run(ctx, svc, nextWf, taskName, templatedWith, next, ro)
 }
 
+// invoke runs a task from a workflow location on behalf of builtin:maru2
+//
+// An empty from runs the task from the current workflow, otherwise from is resolved and fetched
+// the same as a uses: reference, with task taking priority over a task qualifier in from
+func invoke(
+	ctx context.Context,
+	svc *uses.FetcherService,
+	wf v1.Workflow,
+	from, task string,
+	with schema.With,
+	origin *url.URL,
+	ro RuntimeOptions,
+) (map[string]any, error) {
+	if from == "" {
+		return Run(ctx, svc, wf, task, with, origin, ro)
+	}
+
+	next, err := svc.ResolveRelative(origin, from, wf.Aliases)
+	if err != nil {
+		return nil, err
+	}
+
+	nextWf, err := Fetch(ctx, svc, next)
+	if err != nil {
+		return nil, err
+	}
+
+	if task == "" {
+		task = next.Query().Get(uses.QualifierTask)
+	}
+
+	return Run(ctx, svc, nextWf, task, with, next, ro)
+}
+
 // Fetch downloads and validates a workflow from a remote or local source
 //
 // Supports multiple fetcher types (GitHub, GitLab, OCI, HTTP, local files) with
 // automatic fetcher selection based on URL scheme and configuration
+//
+// The tasks of any included workflows are merged into the returned workflow before it is validated
 func Fetch(ctx context.Context, svc *uses.FetcherService, uri *url.URL) (v1.Workflow, error) {
+	b, err := fetchBytes(ctx, svc, uri)
+	if err != nil {
+		return v1.Workflow{}, err
+	}
+
+	wf, err := ReadWorkflow(ctx, svc, bytes.NewReader(b), uri)
+	if err != nil {
+		return wf, annotate(ctx, annotationWriter(ctx), "error", uri, validationErrorPath(err), "invalid workflow", err)
+	}
+	return wf, nil
+}
+
+// ReadWorkflow reads the workflow located at uri from r, merging the workflows it includes, and validates it
+//
+// Includes are resolved relative to uri the same as Fetch, so a workflow read from elsewhere
+// (e.g. an unsaved editor buffer on stdin) is loaded the same as when it is run
+func ReadWorkflow(ctx context.Context, svc *uses.FetcherService, r io.Reader, uri *url.URL) (v1.Workflow, error) {
+	wf, err := v1.Read(r)
+	if err != nil {
+		return wf, err
+	}
+	wf, err = includeAll(ctx, svc, wf, uri, []string{workflowLocation(uri)})
+	if err != nil {
+		return wf, err
+	}
+	return wf, v1.Validate(wf)
+}
+
+// fetchBytes downloads the raw contents of a workflow, recording it in the run report and manifest
+func fetchBytes(ctx context.Context, svc *uses.FetcherService, uri *url.URL) ([]byte, error) {
 	logger := log.FromContext(ctx)
 
 	fetcher, err := svc.GetFetcher(uri)
 	if err != nil {
-		return v1.Workflow{}, err
+		return nil, err
 	}
 
 	fetcherType := fmt.Sprintf("%T", fetcher)
@@ -99,67 +180,299 @@ func Fetch(ctx context.Context, svc *uses.FetcherService, uri *url.URL) (v1.Work
 
 	logger.Debug("fetching", "url", uri, "fetcher", fetcherType)
 
-	rc, err := fetcher.Fetch(ctx, uri)
+	var result uses.FetchResult
+	rc, err := fetcher.Fetch(uses.WithFetchResult(ctx, &result), uri)
 	if err != nil {
-		return v1.Workflow{}, err
+		return nil, err
 	}
 	defer rc.Close()
 
-	return v1.ReadAndValidate(rc)
+	reportFromContext(ctx).recordFetch(uri, result.Cached)
+
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+	if m := manifestFromContext(ctx); m != nil {
+		sum := sha256.Sum256(b)
+		m.recordWorkflow(uri, "sha256:"+hex.EncodeToString(sum[:]))
+	}
+	return b, nil
+}
+
+// includeAll merges the tasks of every workflow that wf includes, and the workflows those include
+//
+// chain holds the locations of the workflows including wf, so an include cycle is reported instead of recursing without end
+func includeAll(ctx context.Context, svc *uses.FetcherService, wf v1.Workflow, uri *url.URL, chain []string) (v1.Workflow, error) {
+	includes := wf.Includes
+	wf.Includes = nil
+
+	for idx, inc := range includes {
+		next, err := svc.ResolveRelative(uri, "file:"+inc.Path, nil)
+		if err != nil {
+			return wf, fmt.Errorf(".includes[%d] failed to resolve %q: %w", idx, inc.Path, err)
+		}
+
+		loc := workflowLocation(next)
+		if slices.Contains(chain, loc) {
+			return wf, fmt.Errorf(".includes[%d] include cycle: %s", idx, strings.Join(append(slices.Clone(chain), loc), " -> "))
+		}
+
+		b, err := fetchBytes(ctx, svc, next)
+		if err != nil {
+			return wf, fmt.Errorf(".includes[%d] %w", idx, err)
+		}
+
+		included, err := v1.Read(bytes.NewReader(b))
+		if err != nil {
+			return wf, fmt.Errorf(".includes[%d] %s: %w", idx, inc.Path, err)
+		}
+
+		included, err = includeAll(ctx, svc, included, next, append(chain, loc))
+		if err != nil {
+			return wf, fmt.Errorf(".includes[%d] %s: %w", idx, inc.Path, err)
+		}
+
+		wf, err = wf.Include(inc, included)
+		if err != nil {
+			return wf, fmt.Errorf(".includes[%d] %s: %w", idx, inc.Path, err)
+		}
+	}
+	return wf, nil
 }
 
 // FetchAll recursively downloads all remote workflow dependencies
 //
-// Scans the workflow for uses: references, resolves URLs relative to the source,
-// and pre-fetches all dependencies into the cache for offline execution
+// The workflows called by each workflow are fetched at once, up to the service's concurrency.
+// Returns an error naming the path of any cycle among the tasks that uses: references call
+// through other workflows, instead of recursing without end. Recursion within a workflow is
+// left to the if: that ends it and reported by v1.Lint
 func FetchAll(ctx context.Context, svc *uses.FetcherService, wf v1.Workflow, src *url.URL) error {
-	refs := []string{}
+	g := &usesGraph{
+		svc:       svc,
+		workflows: map[string]v1.Workflow{},
+		failed:    map[string]error{},
+		walked:    map[string]bool{},
+		done:      map[string]bool{},
+	}
+	if src != nil {
+		g.workflows[workflowLocation(src)] = wf
+	}
+
+	if err := g.walkWorkflow(ctx, wf, src); err != nil {
+		return err
+	}
+
+	// every task of a fetched workflow is walked from an empty path, so a cycle is only reported for tasks that call each other
+	for len(g.pending) > 0 {
+		next := g.pending[0]
+		g.pending = g.pending[1:]
+		if err := g.walkWorkflow(ctx, g.workflows[workflowLocation(next)], next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// usesGraph walks the tasks called by uses: references, fetching each workflow once
+type usesGraph struct {
+	svc *uses.FetcherService
+	// workflows are the fetched workflows, keyed by location without a task
+	workflows map[string]v1.Workflow
+	// failed are the errors of prefetched workflows, keyed by location without a task
+	failed map[string]error
+	// pending are fetched workflows whose tasks have yet to be walked
+	pending []*url.URL
+	// walked are the workflows whose tasks have been walked
+	walked map[string]bool
+	// done are the tasks whose calls have been walked
+	done map[string]bool
+	// path are the tasks being walked, each calling the next
+	path []pathTask
+}
+
+// pathTask is a task being walked by a usesGraph
+type pathTask struct {
+	// workflow is the location of the task's workflow without a task
+	workflow string
+	// node is the location of the task
+	node string
+}
+
+// walkWorkflow walks every task of a workflow
+func (g *usesGraph) walkWorkflow(ctx context.Context, wf v1.Workflow, src *url.URL) error {
+	loc := workflowLocation(src)
+	if g.walked[loc] {
+		return nil
+	}
+	g.walked[loc] = true
 
-	for _, task := range wf.Tasks {
+	g.prefetch(ctx, wf, src)
+
+	for _, name := range wf.Tasks.OrderedTaskNames() {
+		if err := g.walkTask(ctx, wf, src, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prefetch fetches the workflows called by a workflow's tasks at once, up to the service's concurrency
+//
+// Fetch errors are kept for walkTask, so they are reported in task order
+func (g *usesGraph) prefetch(ctx context.Context, wf v1.Workflow, src *url.URL) {
+	var refs []*url.URL
+	seen := map[string]bool{}
+	for _, task := range wf.Tasks.OrderedSeq() {
 		for _, step := range task.Steps {
-			if step.Uses == "" {
+			if step.Uses == "" || strings.HasPrefix(step.Uses, "builtin:") {
 				continue
 			}
-			_, found := wf.Tasks.Find(step.Uses)
-			if found {
+			if _, ok := wf.Tasks.Find(step.Uses); ok {
 				continue
 			}
-
-			if strings.HasPrefix(step.Uses, "builtin:") {
+			resolved, err := g.svc.ResolveRelative(src, step.Uses, wf.Aliases)
+			if err != nil {
 				continue
 			}
-
-			if slices.Contains(refs, step.Uses) { // could use a map[string] here, would also need to dedup same import but different tasks
+			loc := workflowLocation(resolved)
+			if _, ok := g.workflows[loc]; ok || seen[loc] {
 				continue
 			}
+			seen[loc] = true
+			refs = append(refs, resolved)
+		}
+	}
+	if len(refs) < 2 {
+		return
+	}
+
+	fetched := make([]v1.Workflow, len(refs))
+	errs := make([]error, len(refs))
+	eg := errgroup.Group{}
+	eg.SetLimit(g.svc.Concurrency())
+	for i, ref := range refs {
+		eg.Go(func() error {
+			fetched[i], errs[i] = Fetch(ctx, g.svc, ref)
+			return nil
+		})
+	}
+	_ = eg.Wait()
 
-			refs = append(refs, step.Uses)
+	for i, ref := range refs {
+		loc := workflowLocation(ref)
+		if errs[i] != nil {
+			g.failed[loc] = errs[i]
+			continue
 		}
+		g.workflows[loc] = fetched[i]
+		g.pending = append(g.pending, ref)
 	}
+}
 
-	for _, ref := range refs {
-		resolved, err := uses.ResolveRelative(src, ref, wf.Aliases)
-		if err != nil {
-			return fmt.Errorf("failed to resolve %q: %w", ref, err)
+// walkTask walks the tasks a task calls, fetching the workflows they are in
+func (g *usesGraph) walkTask(ctx context.Context, wf v1.Workflow, src *url.URL, name string) error {
+	node := taskLocation(src, name)
+	if i := slices.IndexFunc(g.path, func(t pathTask) bool { return t.node == node }); i >= 0 {
+		loc := workflowLocation(src)
+		if !slices.ContainsFunc(g.path[i:], func(t pathTask) bool { return t.workflow != loc }) {
+			// recursion within a workflow can be ended by an if:, so it is only linted
+			return nil
+		}
+		var cycle []string
+		for _, t := range g.path[i:] {
+			cycle = append(cycle, t.node)
 		}
-		wf, err = Fetch(ctx, svc, resolved)
+		return fmt.Errorf("uses cycle: %s", strings.Join(append(cycle, node), " -> "))
+	}
+	if g.done[node] {
+		return nil
+	}
+	task, ok := wf.Tasks.Find(name)
+	if !ok {
+		// a missing task is reported when it is run
+		return nil
+	}
+
+	g.path = append(g.path, pathTask{workflow: workflowLocation(src), node: node})
+	for _, step := range task.Steps {
+		if step.Uses == "" || strings.HasPrefix(step.Uses, "builtin:") {
+			continue
+		}
+
+		if _, ok := wf.Tasks.Find(step.Uses); ok {
+			if err := g.walkTask(ctx, wf, src, step.Uses); err != nil {
+				return err
+			}
+			continue
+		}
+
+		resolved, err := g.svc.ResolveRelative(src, step.Uses, wf.Aliases)
 		if err != nil {
+			return fmt.Errorf("failed to resolve %q: %w", step.Uses, err)
+		}
+
+		loc := workflowLocation(resolved)
+		if err, ok := g.failed[loc]; ok {
 			return err
 		}
-		err = FetchAll(ctx, svc, wf, resolved)
-		if err != nil {
+		next, ok := g.workflows[loc]
+		if !ok {
+			next, err = Fetch(ctx, g.svc, resolved)
+			if err != nil {
+				return err
+			}
+			g.workflows[loc] = next
+			g.pending = append(g.pending, resolved)
+		}
+
+		if err := g.walkTask(ctx, next, resolved, cmp.Or(resolved.Query().Get(uses.QualifierTask), schema.DefaultTaskName)); err != nil {
 			return err
 		}
 	}
-
+	g.path = g.path[:len(g.path)-1]
+	g.done[node] = true
 	return nil
 }
 
+// workflowLocation returns the location of a workflow without a task qualifier
+func workflowLocation(src *url.URL) string {
+	if src == nil {
+		return ""
+	}
+	u := *src
+	q := u.Query()
+	q.Del(uses.QualifierTask)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// taskLocation returns the location of a task within a workflow, as used by uses: references
+func taskLocation(src *url.URL, task string) string {
+	if src == nil {
+		return task
+	}
+	u := *src
+	q := u.Query()
+	q.Set(uses.QualifierTask, task)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
 // ListAllLocal recursively discovers all local file dependencies in a workflow tree
 //
 // Scans file:// workflows for local uses: references, validates them, and returns
 // the complete list of local files needed for execution
 func ListAllLocal(ctx context.Context, src *url.URL, fsys afero.Fs) ([]string, error) {
+	// only used to resolve references to directories within fsys
+	svc, err := uses.NewFetcherService(uses.WithFS(fsys))
+	if err != nil {
+		return nil, err
+	}
+	return listAllLocal(ctx, svc, src, fsys)
+}
+
+func listAllLocal(ctx context.Context, svc *uses.FetcherService, src *url.URL, fsys afero.Fs) ([]string, error) {
 	if src.Scheme != "file" {
 		return nil, nil
 	}
@@ -177,7 +490,7 @@ func ListAllLocal(ctx context.Context, src *url.URL, fsys afero.Fs) ([]string, e
 		return nil, err
 	}
 
-	for _, task := range wf.Tasks {
+	for _, task := range wf.Tasks.OrderedSeq() {
 		for _, step := range task.Steps {
 			if step.Uses == "" {
 				continue
@@ -194,18 +507,22 @@ func ListAllLocal(ctx context.Context, src *url.URL, fsys afero.Fs) ([]string, e
 		}
 	}
 
-	for _, alias := range wf.Aliases {
+	for _, alias := range wf.Aliases.OrderedSeq() {
 		if alias.Path != "" {
 			relativeRefs = append(relativeRefs, fmt.Sprintf("file:%s", alias.Path))
 		}
 	}
 
+	for _, inc := range wf.Includes {
+		relativeRefs = append(relativeRefs, fmt.Sprintf("file:%s", inc.Path))
+	}
+
 	clone := *src
 	clone.RawQuery = ""
 	fullRefs := []string{clone.String()}
 
 	for _, ref := range relativeRefs {
-		resolved, err := uses.ResolveRelative(src, ref, nil)
+		resolved, err := svc.ResolveRelative(src, ref, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to resolve %q: %w", ref, err)
 		}
@@ -227,7 +544,7 @@ func ListAllLocal(ctx context.Context, src *url.URL, fsys afero.Fs) ([]string, e
 		// now we know its a valid workflow, we can save the location
 		fullRefs = append(fullRefs, resolved.String())
 
-		sub, err := ListAllLocal(ctx, resolved, fsys)
+		sub, err := listAllLocal(ctx, svc, resolved, fsys)
 		if err != nil {
 			return nil, err
 		}
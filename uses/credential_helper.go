@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// CredentialHelper is a command, run with sh -c, that prints a token for a host to stdout
+//
+// The command is run with MARU2_CREDENTIAL_TYPE (github, gitlab or oci) and MARU2_CREDENTIAL_HOST set.
+// Printing nothing means the helper has no token for the host, and the fetcher's usual credentials are used
+type CredentialHelper string
+
+// Credential types passed to a credential helper in MARU2_CREDENTIAL_TYPE
+const (
+	CredentialTypeGitHub = "github"
+	CredentialTypeGitLab = "gitlab"
+	CredentialTypeOCI    = "oci"
+)
+
+// credentialHelperTimeout is the maximum time allowed for a credential helper to respond
+const credentialHelperTimeout = 30 * time.Second
+
+// WithCredentialHelper sets the command used to obtain tokens for pkg:github, pkg:gitlab and oci fetches
+//
+// Tokens from the helper take priority over the OS keychain, GITHUB_TOKEN, GITLAB_TOKEN, CI_JOB_TOKEN, the GitHub CLI and docker credentials,
+// an alias's token-from-env takes priority over the helper
+func WithCredentialHelper(helper CredentialHelper) FetcherServiceOption {
+	return func(s *FetcherService) {
+		s.credentialHelper = helper
+	}
+}
+
+// Token runs the helper for the given credential type and host, returning the trimmed output
+func (h CredentialHelper) Token(ctx context.Context, kind, host string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, credentialHelperTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", string(h))
+	cmd.Env = append(os.Environ(), "MARU2_CREDENTIAL_TYPE="+kind, "MARU2_CREDENTIAL_HOST="+host)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("credential helper failed for %s: %w: %s", host, err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// helperToken returns the credential helper's token for host, the helper is only run once per credential type and host
+func (s *FetcherService) helperToken(ctx context.Context, kind, host string) (string, error) {
+	if s.credentialHelper == "" || host == "" {
+		return "", nil
+	}
+	key := kind + "/" + host
+	if token, ok := s.helperTokens.Load(key); ok {
+		return token.(string), nil
+	}
+	token, err := s.credentialHelper.Token(ctx, kind, host)
+	if err != nil {
+		return "", err
+	}
+	s.helperTokens.Store(key, token)
+	return token, nil
+}
+
+// tokenCredential returns registry credentials from the credential helper or OS keychain, falling back to fallback when neither have a token
+//
+// Tokens in the form username:password are used for basic auth, anything else is used as a registry access token
+func (s *FetcherService) tokenCredential(fallback auth.CredentialFunc) auth.CredentialFunc {
+	return func(ctx context.Context, hostport string) (auth.Credential, error) {
+		token, err := s.token(ctx, CredentialTypeOCI, hostport)
+		if err != nil {
+			return auth.EmptyCredential, err
+		}
+		if token == "" {
+			if fallback == nil {
+				return auth.EmptyCredential, nil
+			}
+			return fallback(ctx, hostport)
+		}
+		if username, password, ok := strings.Cut(token, ":"); ok {
+			return auth.Credential{Username: username, Password: password}, nil
+		}
+		return auth.Credential{AccessToken: token}, nil
+	}
+}
+
+// urlHost returns the host of a base URL, or of def when base is empty
+func urlHost(base, def string) string {
+	if base == "" {
+		base = def
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
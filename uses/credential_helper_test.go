@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+func TestCredentialHelper(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("credential helpers are run with sh")
+	}
+
+	ctx := log.WithContext(t.Context(), log.New(io.Discard))
+
+	t.Run("token", func(t *testing.T) {
+		helper := CredentialHelper(`echo "token-for-$MARU2_CREDENTIAL_TYPE-$MARU2_CREDENTIAL_HOST"`)
+		token, err := helper.Token(ctx, CredentialTypeGitHub, "github.com")
+		require.NoError(t, err)
+		assert.Equal(t, "token-for-github-github.com", token)
+
+		token, err = CredentialHelper("true").Token(ctx, CredentialTypeOCI, "ghcr.io")
+		require.NoError(t, err)
+		assert.Empty(t, token)
+
+		_, err = CredentialHelper("echo denied >&2; exit 3").Token(ctx, CredentialTypeGitLab, "gitlab.com")
+		require.EqualError(t, err, "credential helper failed for gitlab.com: exit status 3: denied")
+	})
+
+	t.Run("run once per host", func(t *testing.T) {
+		count := filepath.Join(t.TempDir(), "count")
+		svc, err := NewFetcherService(WithCredentialHelper(CredentialHelper(fmt.Sprintf(`echo "$MARU2_CREDENTIAL_HOST" >> %s && echo token`, count))))
+		require.NoError(t, err)
+
+		for _, host := range []string{"a.example.com", "a.example.com", "b.example.com"} {
+			token, err := svc.helperToken(ctx, CredentialTypeGitHub, host)
+			require.NoError(t, err)
+			assert.Equal(t, "token", token)
+		}
+
+		b, err := os.ReadFile(count)
+		require.NoError(t, err)
+		assert.Equal(t, "a.example.com\nb.example.com\n", string(b))
+
+		// no helper, or no host
+		token, err := svc.helperToken(ctx, CredentialTypeGitHub, "")
+		require.NoError(t, err)
+		assert.Empty(t, token)
+		svc, err = NewFetcherService()
+		require.NoError(t, err)
+		token, err = svc.helperToken(ctx, CredentialTypeGitHub, "a.example.com")
+		require.NoError(t, err)
+		assert.Empty(t, token)
+	})
+
+	t.Run("github", func(t *testing.T) {
+		var authorization []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = append(authorization, r.Header.Get("Authorization"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"type": "file", "encoding": "base64", "content": %q}`, base64.StdEncoding.EncodeToString([]byte("schema-version: v1\n")))
+		}))
+		defer server.Close()
+
+		u, err := url.Parse(server.URL)
+		require.NoError(t, err)
+		helper := WithCredentialHelper(CredentialHelper(fmt.Sprintf(`[ "$MARU2_CREDENTIAL_HOST" = %q ] && echo "from-helper"`, u.Host)))
+
+		uri, err := ResolveRelative(nil, fmt.Sprintf("pkg:github/defenseunicorns/maru2@main?base-url=%s#tasks.yaml", url.QueryEscape(server.URL)), nil)
+		require.NoError(t, err)
+
+		fetch := func(t *testing.T, uri *url.URL, opts ...FetcherServiceOption) {
+			t.Helper()
+			svc, err := NewFetcherService(opts...)
+			require.NoError(t, err)
+			fetcher, err := svc.GetFetcher(uri)
+			require.NoError(t, err)
+			rc, err := fetcher.Fetch(ctx, uri)
+			require.NoError(t, err)
+			require.NoError(t, rc.Close())
+		}
+
+		t.Setenv("GITHUB_TOKEN", "from-env")
+		fetch(t, uri, helper)
+
+		// token-from-env takes priority over the helper
+		t.Setenv("CUSTOM_TOKEN", "from-custom-env")
+		withTokenEnv, err := url.Parse(strings.Replace(uri.String(), "?", "?token-from-env=CUSTOM_TOKEN&", 1))
+		require.NoError(t, err)
+		fetch(t, withTokenEnv, helper)
+
+		// the helper having no token falls back to GITHUB_TOKEN
+		fetch(t, uri, WithCredentialHelper("true"))
+
+		assert.Equal(t, []string{"Bearer from-helper", "Bearer from-custom-env", "Bearer from-env"}, authorization)
+
+		svc, err := NewFetcherService(WithCredentialHelper("exit 1"))
+		require.NoError(t, err)
+		_, err = svc.GetFetcher(uri)
+		require.ErrorContains(t, err, "credential helper failed for "+u.Host)
+	})
+
+	t.Run("gitlab", func(t *testing.T) {
+		var headers http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			headers = r.Header.Clone()
+			_, _ = w.Write([]byte("schema-version: v1\n"))
+		}))
+		defer server.Close()
+
+		t.Setenv("GITLAB_TOKEN", "from-env")
+		t.Setenv("GITLAB_CI", "")
+
+		uri, err := ResolveRelative(nil, fmt.Sprintf("pkg:gitlab/noxsios/vai@main?base-url=%s#vai.yaml", url.QueryEscape(server.URL)), nil)
+		require.NoError(t, err)
+
+		svc, err := NewFetcherService(WithCredentialHelper(`echo "from-helper"`))
+		require.NoError(t, err)
+		fetcher, err := svc.GetFetcher(uri)
+		require.NoError(t, err)
+		rc, err := fetcher.Fetch(ctx, uri)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		assert.Equal(t, "from-helper", headers.Get("PRIVATE-TOKEN"))
+	})
+
+	t.Run("oci", func(t *testing.T) {
+		svc, err := NewFetcherService(WithCredentialHelper(`case "$MARU2_CREDENTIAL_HOST" in
+  basic.example.com) echo "user:pass" ;;
+  token.example.com) echo "access-token" ;;
+esac`))
+		require.NoError(t, err)
+
+		fallback := func(_ context.Context, hostport string) (auth.Credential, error) {
+			return auth.Credential{Username: "docker", Password: hostport}, nil
+		}
+		credential := svc.tokenCredential(fallback)
+
+		cred, err := credential(ctx, "basic.example.com")
+		require.NoError(t, err)
+		assert.Equal(t, auth.Credential{Username: "user", Password: "pass"}, cred)
+
+		cred, err = credential(ctx, "token.example.com")
+		require.NoError(t, err)
+		assert.Equal(t, auth.Credential{AccessToken: "access-token"}, cred)
+
+		cred, err = credential(ctx, "other.example.com")
+		require.NoError(t, err)
+		assert.Equal(t, auth.Credential{Username: "docker", Password: "other.example.com"}, cred)
+
+		cred, err = svc.tokenCredential(nil)(ctx, "other.example.com")
+		require.NoError(t, err)
+		assert.Equal(t, auth.EmptyCredential, cred)
+
+		uri, err := url.Parse("oci:basic.example.com/workflows:v1#tasks.yaml")
+		require.NoError(t, err)
+		fetcher, err := svc.createFetcher(uri)
+		require.NoError(t, err)
+		oci, ok := fetcher.(*OCIClient)
+		require.True(t, ok)
+		cred, err = oci.client.(*auth.Client).Credential(ctx, "basic.example.com")
+		require.NoError(t, err)
+		assert.Equal(t, auth.Credential{Username: "user", Password: "pass"}, cred)
+	})
+}
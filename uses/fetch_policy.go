@@ -23,6 +23,10 @@ const (
 	FetchPolicyIfNotPresent FetchPolicy = "if-not-present"
 	// FetchPolicyNever will never fetch from source, only using the cache (which must exist)
 	FetchPolicyNever FetchPolicy = "never"
+	// FetchPolicyRefresh will revalidate the cache with the source using conditional requests, only downloading changed workflows
+	//
+	// Sources that do not support conditional requests behave the same as FetchPolicyAlways
+	FetchPolicyRefresh FetchPolicy = "refresh"
 	// DefaultFetchPolicy is the default fetch policy used when none is specified
 	DefaultFetchPolicy FetchPolicy = FetchPolicyIfNotPresent
 )
@@ -33,6 +37,7 @@ func AvailablePolicies() []string {
 		string(FetchPolicyAlways),
 		string(FetchPolicyIfNotPresent),
 		string(FetchPolicyNever),
+		string(FetchPolicyRefresh),
 	}
 }
 
@@ -50,6 +55,8 @@ func (f *FetchPolicy) Set(value string) error {
 		*f = FetchPolicyIfNotPresent
 	case string(FetchPolicyNever):
 		*f = FetchPolicyNever
+	case string(FetchPolicyRefresh):
+		*f = FetchPolicyRefresh
 	default:
 		return fmt.Errorf("invalid fetch policy: %s", value)
 	}
@@ -25,10 +25,11 @@ func TestFetchPolicy(t *testing.T) {
 	t.Run("available policies", func(t *testing.T) {
 		t.Parallel()
 		policies := AvailablePolicies()
-		assert.Len(t, policies, 3)
+		assert.Len(t, policies, 4)
 		assert.Contains(t, policies, string(FetchPolicyAlways))
 		assert.Contains(t, policies, string(FetchPolicyIfNotPresent))
 		assert.Contains(t, policies, string(FetchPolicyNever))
+		assert.Contains(t, policies, string(FetchPolicyRefresh))
 	})
 
 	t.Run("pflag value interface", func(t *testing.T) {
@@ -49,6 +50,10 @@ func TestFetchPolicy(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, FetchPolicyIfNotPresent, policy)
 
+		err = policy.Set("refresh")
+		require.NoError(t, err)
+		assert.Equal(t, FetchPolicyRefresh, policy)
+
 		err = policy.Set("never")
 		require.NoError(t, err)
 		assert.Equal(t, FetchPolicyNever, policy)
@@ -83,7 +88,7 @@ func TestFetchPolicy(t *testing.T) {
 	t.Run("JSON schema", func(t *testing.T) {
 		t.Parallel()
 
-		golden := `{"type":"string","enum":["always","if-not-present","never"],"description":"Policy for fetching resources"}`
+		golden := `{"type":"string","enum":["always","if-not-present","never","refresh"],"description":"Policy for fetching resources"}`
 
 		reflector := jsonschema.Reflector{DoNotReference: true}
 		fetchPolicySchema := reflector.Reflect(FetchPolicy(""))
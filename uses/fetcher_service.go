@@ -4,23 +4,51 @@
 package uses
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/package-url/packageurl-go"
 	"github.com/spf13/afero"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
 )
 
 // FetcherService creates and manages fetchers
 type FetcherService struct {
-	client       *http.Client
-	fsys         afero.Fs
-	fetcherCache map[string]Fetcher
-	storage      Storage
-	policy       FetchPolicy
-	mu           sync.RWMutex
+	client           *http.Client
+	plainClient      *http.Client
+	fsys             afero.Fs
+	fileRoot         string
+	fetcherCache     map[string]Fetcher
+	storage          Storage
+	vendor           Storage
+	headers          HostHeaders
+	proxy            *url.URL
+	rootCAs          *x509.CertPool
+	clientCerts      []tls.Certificate
+	retry            RetryPolicy
+	middleware       []FetcherMiddleware
+	policy           FetchPolicy
+	timeout          time.Duration
+	concurrency      int
+	mirrors          Mirrors
+	tokenFromGH      bool
+	ghTokens         sync.Map
+	credentialHelper CredentialHelper
+	helperTokens     sync.Map
+	keychain         bool
+	keychainTokens   sync.Map
+	netrc            *Netrc
+	mu               sync.RWMutex
 }
 
 // FetcherServiceOption is a function that configures a FetcherService
@@ -33,6 +61,16 @@ func WithFS(fsys afero.Fs) FetcherServiceOption {
 	}
 }
 
+// WithFileRoot confines file: workflows to the given directory
+//
+// Paths that escape the root, through "..", absolute paths or symlinks, fail to fetch.
+// Use it when running untrusted workflows that may reference arbitrary local files
+func WithFileRoot(root string) FetcherServiceOption {
+	return func(s *FetcherService) {
+		s.fileRoot = root
+	}
+}
+
 // WithClient sets the HTTP client to be used by the fetcher service
 func WithClient(client *http.Client) FetcherServiceOption {
 	return func(s *FetcherService) {
@@ -40,6 +78,23 @@ func WithClient(client *http.Client) FetcherServiceOption {
 	}
 }
 
+// WithTimeout sets the maximum time allowed for each request made by remote fetchers (http, pkg and oci)
+func WithTimeout(timeout time.Duration) FetcherServiceOption {
+	return func(s *FetcherService) {
+		s.timeout = timeout
+	}
+}
+
+// DefaultConcurrency is the number of workflows fetched at once when no concurrency is set
+const DefaultConcurrency = 4
+
+// WithConcurrency sets the maximum number of workflows fetched at once when fetching a workflow's dependencies
+func WithConcurrency(n int) FetcherServiceOption {
+	return func(s *FetcherService) {
+		s.concurrency = n
+	}
+}
+
 // WithStorage sets the store to be used by the fetcher service
 func WithStorage(store Storage) FetcherServiceOption {
 	return func(s *FetcherService) {
@@ -47,6 +102,24 @@ func WithStorage(store Storage) FetcherServiceOption {
 	}
 }
 
+// WithVendor sets the store of vendored workflows to be used by the fetcher service
+//
+// Vendored workflows take precedence over all other sources, regardless of fetch policy
+func WithVendor(vendor Storage) FetcherServiceOption {
+	return func(s *FetcherService) {
+		s.vendor = vendor
+	}
+}
+
+// WithHeaders sets additional headers to send with requests to specific hosts
+//
+// Headers are applied to all fetchers that use the HTTP client (http, pkg and oci)
+func WithHeaders(headers HostHeaders) FetcherServiceOption {
+	return func(s *FetcherService) {
+		s.headers = headers
+	}
+}
+
 // WithFetchPolicy sets the fetch policy to be used by the fetcher service
 func WithFetchPolicy(policy FetchPolicy) FetcherServiceOption {
 	return func(s *FetcherService) {
@@ -75,6 +148,31 @@ func NewFetcherService(opts ...FetcherServiceOption) (*FetcherService, error) {
 		svc.client = &http.Client{}
 	}
 
+	if svc.timeout > 0 {
+		clone := *svc.client
+		clone.Timeout = svc.timeout
+		svc.client = &clone
+	}
+
+	client, err := svc.withTransport(svc.client)
+	if err != nil {
+		return nil, err
+	}
+	svc.client = client
+	svc.plainClient = client
+
+	if svc.retry.Attempts > 0 {
+		svc.client = withRetry(svc.client, svc.retry)
+	}
+
+	if len(svc.headers) > 0 {
+		svc.client = withHostHeaders(svc.client, svc.headers)
+	}
+
+	if err := svc.mirrors.Validate(); err != nil {
+		return nil, err
+	}
+
 	if svc.policy == FetchPolicyNever && svc.storage == nil {
 		return nil, fmt.Errorf("store is not initialized")
 	}
@@ -96,7 +194,7 @@ func (s *FetcherService) GetFetcher(uri *url.URL) (Fetcher, error) {
 	}
 
 	if s.policy == FetchPolicyNever {
-		return s.storage, nil
+		return s.wrap(s.vendored(uri, s.storage)), nil
 	}
 
 	s.mu.RLock()
@@ -106,11 +204,21 @@ func (s *FetcherService) GetFetcher(uri *url.URL) (Fetcher, error) {
 		return fetcher, nil
 	}
 
-	fetcher, err := s.createFetcher(uri)
+	target, isMirrored, err := s.mirrors.Rewrite(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	fetcher, err = s.createFetcher(target)
 	if err != nil {
 		return nil, err
 	}
 
+	// the store and vendor are keyed by the original URL, so the mirror is only used by the source
+	if isMirrored {
+		fetcher = mirrored(fetcher, s.mirrors)
+	}
+
 	if s.storage != nil && uri.Scheme != "file" {
 		fetcher = &StoreFetcher{
 			Source: fetcher,
@@ -119,20 +227,122 @@ func (s *FetcherService) GetFetcher(uri *url.URL) (Fetcher, error) {
 		}
 	}
 
+	fetcher = s.vendored(uri, fetcher)
+
+	// fetchers are shared, so concurrent fetches of the same URI only hit the source (and write to the store) once
+	if uri.Scheme != "file" {
+		fetcher = &SingleFlightFetcher{Source: fetcher}
+	}
+
+	fetcher = s.wrap(fetcher)
+
 	s.mu.Lock()
+	// another caller may have created a fetcher for the same URI while this one was being created
+	if existing, ok := s.fetcherCache[uri.String()]; ok && existing != nil {
+		s.mu.Unlock()
+		return existing, nil
+	}
 	s.fetcherCache[uri.String()] = fetcher
 	s.mu.Unlock()
 
 	return fetcher, nil
 }
 
+// ResolveRelative is ResolveRelative, also resolving local paths that name an existing directory to the DefaultFileName within it
+//
+// Directories are looked up in the service's filesystem, and only within its file root when one is set.
+// A nil service resolves the same as ResolveRelative
+func (s *FetcherService) ResolveRelative(prev *url.URL, u string, pkgAliases v1.AliasMap) (*url.URL, error) {
+	if s == nil {
+		return ResolveRelative(prev, u, pkgAliases)
+	}
+	return resolveRelative(prev, u, pkgAliases, s.isLocalDir)
+}
+
+// isLocalDir reports whether p is a directory within the service's filesystem and file root
+func (s *FetcherService) isLocalDir(p string) bool {
+	p = filepath.Clean(p)
+	if s.fileRoot != "" {
+		if err := (&LocalFetcher{fsys: s.fsys, root: s.fileRoot}).confine(p); err != nil {
+			return false
+		}
+	}
+	info, err := s.fsys.Stat(p)
+	return err == nil && info.IsDir()
+}
+
+// Client returns an HTTP client with the configured transport, for requests made by workflows rather than remote fetchers
+//
+// Unlike the client of remote fetchers it does not retry requests, and only sends the host headers when asked, as they may hold credentials.
+// Returns nil for a nil service
+func (s *FetcherService) Client(hostHeaders bool) *http.Client {
+	if s == nil {
+		return nil
+	}
+	if hostHeaders && len(s.headers) > 0 {
+		return withHostHeaders(s.plainClient, s.headers)
+	}
+	return s.plainClient
+}
+
+// Storage returns the store of remote workflows, nil if none is set
+func (s *FetcherService) Storage() Storage {
+	if s == nil {
+		return nil
+	}
+	return s.storage
+}
+
+// Concurrency returns the maximum number of workflows fetched at once, DefaultConcurrency if unset
+func (s *FetcherService) Concurrency() int {
+	if s == nil || s.concurrency <= 0 {
+		return DefaultConcurrency
+	}
+	return s.concurrency
+}
+
+// ghToken returns the GitHub CLI's token for the host of a GitHub API base URL, gh is only asked once per host
+func (s *FetcherService) ghToken(base string) string {
+	host := GitHubHost(base)
+	if token, ok := s.ghTokens.Load(host); ok {
+		return token.(string)
+	}
+	token := TokenFromGH(context.Background(), host)
+	s.ghTokens.Store(host, token)
+	return token
+}
+
+// token returns the token for host from the credential helper, then the OS keychain, empty if neither have one
+func (s *FetcherService) token(ctx context.Context, kind, host string) (string, error) {
+	token, err := s.helperToken(ctx, kind, host)
+	if err != nil || token != "" {
+		return token, err
+	}
+	return s.keychainToken(host), nil
+}
+
+// vendored wraps the given fetcher to prefer vendored workflows, if a vendor store is set
+func (s *FetcherService) vendored(uri *url.URL, fetcher Fetcher) Fetcher {
+	if s.vendor == nil || uri.Scheme == "file" {
+		return fetcher
+	}
+	return &VendorFetcher{
+		Source: fetcher,
+		Vendor: s.vendor,
+	}
+}
+
 // createFetcher creates a new fetcher for the given URI
 func (s *FetcherService) createFetcher(uri *url.URL) (Fetcher, error) {
 	var fetcher Fetcher
 
 	switch uri.Scheme {
 	case "http", "https":
-		fetcher = NewHTTPClient(s.client)
+		client := s.client
+		if s.netrc != nil {
+			client = withNetrc(client, s.netrc)
+		}
+		fetcher = NewHTTPClient(client)
 	case "pkg":
 		pURL, err := packageurl.FromString(uri.String())
 		if err != nil {
@@ -145,9 +355,36 @@ func (s *FetcherService) createFetcher(uri *url.URL) (Fetcher, error) {
 
 		switch pURL.Type {
 		case packageurl.TypeGithub:
-			fetcher, err = NewGitHubClient(s.client, baseURL, tokenEnv)
+			var token string
+			if tokenEnv == "" {
+				token, err = s.token(context.Background(), CredentialTypeGitHub, GitHubHost(baseURL))
+			}
+			switch {
+			case err != nil:
+			case token != "":
+				fetcher, err = newGitHubClient(s.client, baseURL, token)
+			default:
+				var gh *GitHubClient
+				gh, err = NewGitHubClient(s.client, baseURL, tokenEnv)
+				if err == nil && s.tokenFromGH && tokenEnv == "" && os.Getenv("GITHUB_TOKEN") == "" {
+					if token := s.ghToken(baseURL); token != "" {
+						gh.client = gh.client.WithAuthToken(token)
+					}
+				}
+				fetcher = gh
+			}
 		case packageurl.TypeGitlab:
-			fetcher, err = NewGitLabClient(s.client, baseURL, tokenEnv)
+			var token string
+			if tokenEnv == "" {
+				token, err = s.token(context.Background(), CredentialTypeGitLab, urlHost(baseURL, "https://gitlab.com"))
+			}
+			switch {
+			case err != nil:
+			case token != "":
+				fetcher, err = newGitLabClient(s.client, baseURL, token, "")
+			default:
+				fetcher, err = NewGitLabClient(s.client, baseURL, tokenEnv)
+			}
 		default:
 			return nil, fmt.Errorf("unsupported package type: %q", pURL.Type)
 		}
@@ -157,15 +394,19 @@ func (s *FetcherService) createFetcher(uri *url.URL) (Fetcher, error) {
 		}
 
 	case "file":
-		fetcher = NewLocalFetcher(s.fsys)
+		fetcher = &LocalFetcher{fsys: s.fsys, root: s.fileRoot}
 	case "oci":
 		var err error
 		insecureSkipTLSVerify := uri.Query().Get(OCIQueryParamInsecureSkipTLSVerify) == "true"
 		plainHTTP := uri.Query().Get(OCIQueryParamPlainHTTP) == "true"
-		fetcher, err = NewOCIClient(s.client, insecureSkipTLSVerify, plainHTTP)
+		oci, err := NewOCIClient(s.client, insecureSkipTLSVerify, plainHTTP)
 		if err != nil {
 			return nil, err
 		}
+		if client, ok := oci.client.(*auth.Client); ok && (s.credentialHelper != "" || s.keychain) {
+			client.Credential = s.tokenCredential(client.Credential)
+		}
+		fetcher = oci
 	default:
 		return nil, fmt.Errorf("unsupported scheme: %q", uri.Scheme)
 	}
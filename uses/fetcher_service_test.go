@@ -8,8 +8,11 @@ import (
 	"io"
 	"iter"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -226,6 +229,81 @@ func TestFetcherService(t *testing.T) {
 				assert.Equal(t, FetchPolicyIfNotPresent, storeFetcher.Policy)
 			},
 		},
+		{
+			name: "with vendor",
+			opts: []FetcherServiceOption{
+				WithVendor(createMockStorage("vendored content")),
+			},
+			uri:          "https://example.com",
+			expectedType: &VendorFetcher{},
+			verifyFetcher: func(t *testing.T, f Fetcher) {
+				vendorFetcher, ok := f.(*VendorFetcher)
+				require.True(t, ok)
+				assert.IsType(t, &HTTPClient{}, vendorFetcher.Source)
+				assert.IsType(t, &mockStorage{}, vendorFetcher.Vendor)
+			},
+		},
+		{
+			name: "with vendor and storage",
+			opts: []FetcherServiceOption{
+				WithVendor(createMockStorage("vendored content")),
+				WithStorage(createMockStorage("stored content")),
+			},
+			uri:          "pkg:github/defenseunicorns/maru2",
+			expectedType: &VendorFetcher{},
+			verifyFetcher: func(t *testing.T, f Fetcher) {
+				vendorFetcher, ok := f.(*VendorFetcher)
+				require.True(t, ok)
+				assert.IsType(t, &StoreFetcher{}, vendorFetcher.Source)
+			},
+		},
+		{
+			name: "with vendor and FetchPolicyNever",
+			opts: []FetcherServiceOption{
+				WithVendor(createMockStorage("vendored content")),
+				WithStorage(createMockStorage("stored content")),
+				WithFetchPolicy(FetchPolicyNever),
+			},
+			uri:          "https://example.com",
+			expectedType: &VendorFetcher{},
+			verifyFetcher: func(t *testing.T, f Fetcher) {
+				vendorFetcher, ok := f.(*VendorFetcher)
+				require.True(t, ok)
+				assert.IsType(t, &mockStorage{}, vendorFetcher.Source)
+			},
+		},
+		{
+			name: "with vendor - file scheme",
+			opts: []FetcherServiceOption{
+				WithVendor(createMockStorage("vendored content")),
+			},
+			uri:          "file:///tmp/example.txt",
+			expectedType: &LocalFetcher{},
+		},
+		{
+			name: "with headers",
+			opts: []FetcherServiceOption{
+				WithHeaders(HostHeaders{"example.com": http.Header{"Authorization": []string{"Bearer abc"}}}),
+			},
+			uri:          "https://example.com",
+			expectedType: &HTTPClient{},
+			verifyService: func(t *testing.T, s *FetcherService) {
+				transport, ok := s.client.Transport.(*headerTransport)
+				require.True(t, ok)
+				assert.Equal(t, "Bearer abc", transport.headers["example.com"].Get("Authorization"))
+			},
+		},
+		{
+			name: "with empty headers",
+			opts: []FetcherServiceOption{
+				WithHeaders(HostHeaders{}),
+			},
+			uri:          "https://example.com",
+			expectedType: &HTTPClient{},
+			verifyService: func(t *testing.T, s *FetcherService) {
+				assert.Nil(t, s.client.Transport)
+			},
+		},
 		{
 			name:         "get oci fetcher basic",
 			uri:          "oci://registry.example.com/namespace/image:tag",
@@ -307,7 +385,6 @@ func TestFetcherService(t *testing.T) {
 			}
 
 			require.NoError(t, err)
-			assert.IsType(t, tc.expectedType, fetcher)
 
 			if tc.checkSameCache {
 				fetcher2, err := service.GetFetcher(uri)
@@ -315,9 +392,85 @@ func TestFetcherService(t *testing.T) {
 				assert.Same(t, fetcher, fetcher2, "fetchers should be the same instance due to caching")
 			}
 
+			// remote fetchers are wrapped to deduplicate concurrent fetches, see TestFetcherServiceSingleFlight
+			if sf, ok := fetcher.(*SingleFlightFetcher); ok {
+				fetcher = sf.Source
+			}
+			assert.IsType(t, tc.expectedType, fetcher)
+
 			if tc.verifyFetcher != nil {
 				tc.verifyFetcher(t, fetcher)
 			}
 		})
 	}
 }
+
+func TestFetcherServiceSingleFlight(t *testing.T) {
+	var hits atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		<-release
+		_, _ = w.Write([]byte("schema-version: v1\n"))
+	}))
+	t.Cleanup(server.Close)
+
+	store, err := NewLocalStore(afero.NewMemMapFs())
+	require.NoError(t, err)
+
+	svc, err := NewFetcherService(WithStorage(store), WithFetchPolicy(FetchPolicyAlways))
+	require.NoError(t, err)
+
+	uri, err := url.Parse(server.URL + "/tasks.yaml")
+	require.NoError(t, err)
+
+	fetcher, err := svc.GetFetcher(uri)
+	require.NoError(t, err)
+	assert.IsType(t, &SingleFlightFetcher{}, fetcher)
+
+	local, err := svc.GetFetcher(&url.URL{Scheme: "file", Opaque: "tasks.yaml"})
+	require.NoError(t, err)
+	assert.IsType(t, &LocalFetcher{}, local)
+
+	const n = 10
+	var started atomic.Int32
+	var wg sync.WaitGroup
+	contents := make([]string, n)
+	errs := make([]error, n)
+	for i := range n {
+		wg.Go(func() {
+			f, err := svc.GetFetcher(uri)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			started.Add(1)
+			rc, err := f.Fetch(t.Context(), uri)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			defer rc.Close()
+			b, err := io.ReadAll(rc)
+			contents[i], errs[i] = string(b), err
+		})
+	}
+
+	// hold the first fetch at the server until every caller is waiting on it
+	require.Eventually(t, func() bool { return hits.Load() == 1 && started.Load() == n }, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i := range n {
+		require.NoError(t, errs[i])
+		assert.Equal(t, "schema-version: v1\n", contents[i])
+	}
+	assert.Equal(t, int32(1), hits.Load())
+
+	// once complete, fetches follow the fetch policy as usual
+	rc, err := fetcher.Fetch(t.Context(), uri)
+	require.NoError(t, err)
+	rc.Close()
+	assert.Equal(t, int32(2), hits.Load())
+}
@@ -0,0 +1,26 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+//go:build !unix && !windows
+
+package uses
+
+import (
+	"errors"
+	"os"
+)
+
+// flock is a no-op on platforms without advisory file locking
+func flock(_ *os.File, _ bool) error {
+	return nil
+}
+
+// tryFlock fails on platforms without advisory file locking, so a mutex never silently excludes nothing
+func tryFlock(_ *os.File) (bool, error) {
+	return false, errors.ErrUnsupported
+}
+
+// funlock is a no-op on platforms without advisory file locking
+func funlock(_ *os.File) error {
+	return nil
+}
@@ -4,14 +4,20 @@
 package uses
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
 	"strings"
+	"time"
 
+	"github.com/goccy/go-yaml"
 	"github.com/google/go-github/v75/github"
 	"github.com/package-url/packageurl-go"
 )
@@ -23,10 +29,8 @@ type GitHubClient struct {
 
 // NewGitHubClient creates a new GitHub client
 //
-// Uses auth token from tokenEnv > GITHUB_ENV > no auth token
+// Uses auth token from tokenEnv > GITHUB_TOKEN > no auth token
 func NewGitHubClient(client *http.Client, base string, tokenEnv string) (*GitHubClient, error) {
-	c := github.NewClient(client)
-
 	if tokenEnv == "" {
 		tokenEnv = "GITHUB_TOKEN"
 	}
@@ -36,7 +40,14 @@ func NewGitHubClient(client *http.Client, base string, tokenEnv string) (*GitHub
 		return nil, fmt.Errorf("token environment variable %s is not set", tokenEnv)
 	}
 
-	if ok {
+	return newGitHubClient(client, base, token)
+}
+
+// newGitHubClient creates a new GitHub client using token, unauthenticated if token is empty
+func newGitHubClient(client *http.Client, base, token string) (*GitHubClient, error) {
+	c := github.NewClient(client)
+
+	if token != "" {
 		c = c.WithAuthToken(token)
 	}
 
@@ -55,6 +66,86 @@ func NewGitHubClient(client *http.Client, base string, tokenEnv string) (*GitHub
 	return &GitHubClient{client: c}, nil
 }
 
+// ghTimeout is the maximum time allowed for `gh auth token` to respond
+const ghTimeout = 5 * time.Second
+
+// WithGitHubTokenFromGH enables discovering a token from the GitHub CLI (gh) for pkg:github fetches
+//
+// A token is only discovered when neither token-from-env nor GITHUB_TOKEN are set
+func WithGitHubTokenFromGH(enabled bool) FetcherServiceOption {
+	return func(s *FetcherService) {
+		s.tokenFromGH = enabled
+	}
+}
+
+// GitHubHost returns the host the GitHub CLI stores credentials under for a GitHub API base URL
+//
+// An empty base is github.com, the host of a GitHub Enterprise Server API (e.g. https://ghe.example.com/api/v3) is used as is
+func GitHubHost(base string) string {
+	if base == "" {
+		return "github.com"
+	}
+	u, err := url.Parse(base)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	if u.Host == "api.github.com" {
+		return "github.com"
+	}
+	return u.Host
+}
+
+// TokenFromGH discovers the GitHub CLI's token for host
+//
+// Uses the output of `gh auth token --hostname <host>` > the oauth_token within gh's hosts.yml > no token
+func TokenFromGH(ctx context.Context, host string) string {
+	if host == "" {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ghTimeout)
+	defer cancel()
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, "gh", "auth", "token", "--hostname", host)
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err == nil {
+		if token := strings.TrimSpace(stdout.String()); token != "" {
+			return token
+		}
+	}
+
+	b, err := os.ReadFile(filepath.Join(ghConfigDir(), "hosts.yml"))
+	if err != nil {
+		return ""
+	}
+	var hosts map[string]struct {
+		OAuthToken string `json:"oauth_token"`
+	}
+	if err := yaml.Unmarshal(b, &hosts); err != nil {
+		return ""
+	}
+	return hosts[host].OAuthToken
+}
+
+// ghConfigDir mirrors the GitHub CLI's config directory lookup
+func ghConfigDir() string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh")
+	}
+	if dir := os.Getenv("AppData"); runtime.GOOS == "windows" && dir != "" {
+		return filepath.Join(dir, "GitHub CLI")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gh")
+}
+
 // Fetch downloads a file from GitHub
 func (g *GitHubClient) Fetch(ctx context.Context, uri *url.URL) (io.ReadCloser, error) {
 	if uri == nil {
@@ -4,8 +4,15 @@
 package uses
 
 import (
+	"encoding/base64"
+	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -96,3 +103,94 @@ echo:
 		assert.Equal(t, expectedBaseURL, actualBaseURL)
 	})
 }
+
+func TestGitHubHost(t *testing.T) {
+	assert.Equal(t, "github.com", GitHubHost(""))
+	assert.Equal(t, "github.com", GitHubHost("https://api.github.com"))
+	assert.Equal(t, "ghe.example.com", GitHubHost("https://ghe.example.com/api/v3"))
+	assert.Empty(t, GitHubHost(":%invalid"))
+}
+
+func TestTokenFromGH(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake gh is a shell script")
+	}
+
+	ctx := t.Context()
+
+	fakeGH := func(t *testing.T, script string) {
+		t.Helper()
+		bin := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(bin, "gh"), []byte("#!/bin/sh\n"+script), 0o755))
+		t.Setenv("PATH", bin)
+	}
+
+	t.Run("gh auth token", func(t *testing.T) {
+		fakeGH(t, `[ "$1 $2 $3 $4" = "auth token --hostname ghe.example.com" ] && echo "gho_from_gh" && exit 0
+exit 1
+`)
+		assert.Equal(t, "gho_from_gh", TokenFromGH(ctx, "ghe.example.com"))
+	})
+
+	t.Run("hosts.yml", func(t *testing.T) {
+		fakeGH(t, "exit 1\n")
+		dir := t.TempDir()
+		t.Setenv("GH_CONFIG_DIR", dir)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "hosts.yml"), []byte(`github.com:
+    user: octocat
+    oauth_token: gho_from_hosts
+    git_protocol: https
+`), 0o600))
+
+		assert.Equal(t, "gho_from_hosts", TokenFromGH(ctx, "github.com"))
+		assert.Empty(t, TokenFromGH(ctx, "ghe.example.com"))
+	})
+
+	t.Run("not installed or logged in", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+		t.Setenv("GH_CONFIG_DIR", t.TempDir())
+		assert.Empty(t, TokenFromGH(ctx, "github.com"))
+		assert.Empty(t, TokenFromGH(ctx, ""))
+	})
+
+	t.Run("fetcher service", func(t *testing.T) {
+		var authorization []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = append(authorization, r.Header.Get("Authorization"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"type": "file", "encoding": "base64", "content": %q}`, base64.StdEncoding.EncodeToString([]byte("schema-version: v1\n")))
+		}))
+		defer server.Close()
+
+		u, err := url.Parse(server.URL)
+		require.NoError(t, err)
+		fakeGH(t, fmt.Sprintf(`[ "$4" = %q ] && echo "gho_from_gh" && exit 0
+exit 1
+`, u.Host))
+		t.Setenv("GITHUB_TOKEN", "")
+
+		uri, err := ResolveRelative(nil, fmt.Sprintf("pkg:github/defenseunicorns/maru2@main?base-url=%s#tasks.yaml", url.QueryEscape(server.URL)), nil)
+		require.NoError(t, err)
+
+		fetch := func(t *testing.T, opts ...FetcherServiceOption) {
+			t.Helper()
+			svc, err := NewFetcherService(opts...)
+			require.NoError(t, err)
+			fetcher, err := svc.GetFetcher(uri)
+			require.NoError(t, err)
+			rc, err := fetcher.Fetch(ctx, uri)
+			require.NoError(t, err)
+			b, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			require.NoError(t, rc.Close())
+			assert.Equal(t, "schema-version: v1\n", string(b))
+		}
+
+		fetch(t)
+		fetch(t, WithGitHubTokenFromGH(true))
+		t.Setenv("GITHUB_TOKEN", "ghp_from_env")
+		fetch(t, WithGitHubTokenFromGH(true))
+
+		assert.Equal(t, []string{"", "Bearer gho_from_gh", "Bearer ghp_from_env"}, authorization)
+	})
+}
@@ -23,8 +23,12 @@ type GitLabClient struct {
 
 // NewGitLabClient creates a new GitLab client
 //
-// Uses auth token from tokenEnv > GITLAB_ENV > no auth token, uses https://gitlab.com as the base URL if none is provided
+// Uses auth token from tokenEnv > GITLAB_TOKEN > CI_JOB_TOKEN > no auth token, uses https://gitlab.com as the base URL if none is provided
+//
+// When running in GitLab CI without tokenEnv or GITLAB_TOKEN set, CI_SERVER_URL is the default base URL,
+// and CI_JOB_TOKEN is used for requests to that instance so pipelines do not need personal access tokens
 func NewGitLabClient(client *http.Client, base string, tokenEnv string) (*GitLabClient, error) {
+	explicit := tokenEnv != ""
 	if tokenEnv == "" {
 		tokenEnv = "GITLAB_TOKEN"
 	}
@@ -34,6 +38,22 @@ func NewGitLabClient(client *http.Client, base string, tokenEnv string) (*GitLab
 		return nil, fmt.Errorf("token environment variable %s is not set", tokenEnv)
 	}
 
+	var jobToken string
+	if !explicit && token == "" && os.Getenv("GITLAB_CI") == "true" {
+		server := os.Getenv("CI_SERVER_URL")
+		if base == "" {
+			base = server
+		}
+		if sameHost(base, server) {
+			jobToken = os.Getenv("CI_JOB_TOKEN")
+		}
+	}
+
+	return newGitLabClient(client, base, token, jobToken)
+}
+
+// newGitLabClient creates a new GitLab client using token, or jobToken as a CI job token when set
+func newGitLabClient(client *http.Client, base, token, jobToken string) (*GitLabClient, error) {
 	if base == "" {
 		base = "https://gitlab.com"
 	}
@@ -46,13 +66,32 @@ func NewGitLabClient(client *http.Client, base string, tokenEnv string) (*GitLab
 		opts = append(opts, gitlab.WithHTTPClient(client))
 	}
 
-	c, err := gitlab.NewClient(token, opts...)
+	var c *gitlab.Client
+	var err error
+	if jobToken != "" {
+		c, err = gitlab.NewJobClient(jobToken, opts...)
+	} else {
+		c, err = gitlab.NewClient(token, opts...)
+	}
 	if err != nil {
 		return nil, err
 	}
 	return &GitLabClient{c}, nil
 }
 
+// sameHost reports whether both URLs are valid and have the same host
+func sameHost(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil || ua.Host == "" {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil || ub.Host == "" {
+		return false
+	}
+	return ua.Host == ub.Host
+}
+
 // Fetch downloads a file from GitLab
 func (g *GitLabClient) Fetch(ctx context.Context, uri *url.URL) (io.ReadCloser, error) {
 	if uri == nil {
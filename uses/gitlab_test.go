@@ -5,6 +5,8 @@ package uses
 
 import (
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/charmbracelet/log"
@@ -88,6 +90,66 @@ hello-world:
 		assert.NotNil(t, client)
 	})
 
+	t.Run("gitlab ci job token", func(t *testing.T) {
+		var headers http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			headers = r.Header.Clone()
+			_, _ = w.Write([]byte("schema-version: v1\n"))
+		}))
+		defer server.Close()
+
+		t.Setenv("GITLAB_TOKEN", "")
+		t.Setenv("GITLAB_CI", "true")
+		t.Setenv("CI_SERVER_URL", server.URL)
+		t.Setenv("CI_JOB_TOKEN", "job-token")
+
+		ctx := log.WithContext(t.Context(), log.New(io.Discard))
+		u, err := ResolveRelative(nil, "pkg:gitlab/noxsios/vai@main#vai.yaml", nil)
+		require.NoError(t, err)
+
+		// same instance, defaults to CI_SERVER_URL
+		client, err := NewGitLabClient(nil, "", "")
+		require.NoError(t, err)
+		assert.Equal(t, server.URL+"/api/v4/", client.client.BaseURL().String())
+		rc, err := client.Fetch(ctx, u)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		assert.Equal(t, "job-token", headers.Get("JOB-TOKEN"))
+		assert.Empty(t, headers.Get("PRIVATE-TOKEN"))
+
+		// another instance, no job token
+		client, err = NewGitLabClient(nil, "https://gitlab.example.com", "")
+		require.NoError(t, err)
+		assert.Equal(t, "https://gitlab.example.com/api/v4/", client.client.BaseURL().String())
+
+		// GITLAB_TOKEN takes priority
+		t.Setenv("GITLAB_TOKEN", "personal-token")
+		client, err = NewGitLabClient(nil, server.URL, "")
+		require.NoError(t, err)
+		rc, err = client.Fetch(ctx, u)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		assert.Equal(t, "personal-token", headers.Get("PRIVATE-TOKEN"))
+		assert.Empty(t, headers.Get("JOB-TOKEN"))
+
+		// as does token-from-env
+		t.Setenv("GITLAB_TOKEN", "")
+		t.Setenv("CUSTOM_GITLAB_TOKEN", "custom-token")
+		client, err = NewGitLabClient(nil, server.URL, "CUSTOM_GITLAB_TOKEN")
+		require.NoError(t, err)
+		rc, err = client.Fetch(ctx, u)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		assert.Equal(t, "custom-token", headers.Get("PRIVATE-TOKEN"))
+		assert.Empty(t, headers.Get("JOB-TOKEN"))
+
+		// outside of gitlab ci
+		t.Setenv("GITLAB_CI", "")
+		client, err = NewGitLabClient(nil, "", "")
+		require.NoError(t, err)
+		assert.Equal(t, "https://gitlab.com/api/v4/", client.client.BaseURL().String())
+	})
+
 	t.Run("base url", func(t *testing.T) {
 		t.Parallel()
 		client, err := NewGitLabClient(nil, "", "")
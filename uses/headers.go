@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"net/http"
+)
+
+// HostHeaders maps a host (e.g. "example.com" or "example.com:8443") to the headers to send with every request to it
+type HostHeaders map[string]http.Header
+
+// headerTransport is an http.RoundTripper that adds headers to requests based upon the request's host
+type headerTransport struct {
+	base    http.RoundTripper
+	headers HostHeaders
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	headers, ok := t.headers[req.URL.Host]
+	if !ok {
+		headers, ok = t.headers[req.URL.Hostname()]
+	}
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+
+	// RoundTrippers must not modify the original request
+	clone := req.Clone(req.Context())
+	for key, values := range headers {
+		clone.Header.Del(key)
+		for _, value := range values {
+			clone.Header.Add(key, value)
+		}
+	}
+	return t.base.RoundTrip(clone)
+}
+
+// withHostHeaders returns a shallow copy of the client whose transport adds the given headers
+func withHostHeaders(client *http.Client, headers HostHeaders) *http.Client {
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	clone := *client
+	clone.Transport = &headerTransport{
+		base:    base,
+		headers: headers,
+	}
+	return &clone
+}
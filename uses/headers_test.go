@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+func TestHeaderTransport(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		headers  HostHeaders
+		expected map[string]string
+	}{
+		{
+			name: "match host and port",
+			headers: HostHeaders{
+				serverURL.Host: http.Header{"Authorization": []string{"Bearer abc"}},
+			},
+			expected: map[string]string{"Authorization": "Bearer abc", "User-Agent": "maru2"},
+		},
+		{
+			name: "match hostname",
+			headers: HostHeaders{
+				serverURL.Hostname(): http.Header{"X-Custom": []string{"foo"}},
+			},
+			expected: map[string]string{"X-Custom": "foo", "User-Agent": "maru2"},
+		},
+		{
+			name: "override existing header",
+			headers: HostHeaders{
+				serverURL.Host: http.Header{"User-Agent": []string{"custom"}},
+			},
+			expected: map[string]string{"User-Agent": "custom"},
+		},
+		{
+			name: "no match",
+			headers: HostHeaders{
+				"example.com": http.Header{"Authorization": []string{"Bearer abc"}},
+			},
+			expected: map[string]string{"Authorization": "", "User-Agent": "maru2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := withHostHeaders(&http.Client{}, tc.headers)
+
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+			req.Header.Set("User-Agent", "maru2")
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			for k, v := range tc.expected {
+				assert.Equal(t, v, received.Get(k))
+			}
+			// the original request is never modified
+			assert.Equal(t, "maru2", req.Header.Get("User-Agent"))
+			assert.Empty(t, req.Header.Get("Authorization"))
+		})
+	}
+
+	t.Run("does not modify the original client", func(t *testing.T) {
+		original := &http.Client{}
+		client := withHostHeaders(original, HostHeaders{"example.com": http.Header{}})
+		assert.Nil(t, original.Transport)
+		assert.IsType(t, &headerTransport{}, client.Transport)
+		assert.Equal(t, http.DefaultTransport, client.Transport.(*headerTransport).base)
+	})
+
+	t.Run("preserved by the oci client", func(t *testing.T) {
+		for _, base := range []*http.Client{{}, {Transport: &http.Transport{}}} {
+			client := withHostHeaders(base, HostHeaders{"example.com": http.Header{"X-Custom": []string{"foo"}}})
+
+			oci, err := NewOCIClient(client, true, false)
+			require.NoError(t, err)
+
+			authClient, ok := oci.client.(*auth.Client)
+			require.True(t, ok)
+			transport, ok := authClient.Client.Transport.(*headerTransport)
+			require.True(t, ok)
+			assert.Equal(t, "foo", transport.headers["example.com"].Get("X-Custom"))
+			assert.NotNil(t, transport.base)
+		}
+	})
+
+	t.Run("sent by the service client when asked", func(t *testing.T) {
+		var nilSvc *FetcherService
+		assert.Nil(t, nilSvc.Client(false))
+
+		svc, err := NewFetcherService(
+			WithHeaders(HostHeaders{serverURL.Host: http.Header{"Authorization": []string{"Bearer abc"}}}),
+			WithRetry(RetryPolicy{Attempts: 3}),
+		)
+		require.NoError(t, err)
+
+		for _, hostHeaders := range []bool{false, true} {
+			client := svc.Client(hostHeaders)
+			// builtins retry on their own
+			transport := client.Transport
+			if ht, ok := transport.(*headerTransport); ok {
+				transport = ht.base
+			}
+			_, retried := transport.(*retryTransport)
+			assert.False(t, retried)
+
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			if hostHeaders {
+				assert.Equal(t, "Bearer abc", received.Get("Authorization"))
+			} else {
+				assert.Empty(t, received.Get("Authorization"))
+			}
+		}
+	})
+}
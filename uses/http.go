@@ -28,22 +28,52 @@ func NewHTTPClient(client *http.Client) *HTTPClient {
 // Sets a maru2 user agent and handles standard HTTP error responses.
 // Returns the response body as a ReadCloser for streaming
 func (f *HTTPClient) Fetch(ctx context.Context, uri *url.URL) (io.ReadCloser, error) {
+	rc, _, err := f.FetchIfModified(ctx, uri, Validators{})
+	return rc, err
+}
+
+// FetchIfModified downloads workflow content unless it is unchanged since prev was recorded
+//
+// Sends If-None-Match and If-Modified-Since conditional request headers from prev,
+// returning ErrNotModified when the server responds with 304 Not Modified
+func (f *HTTPClient) FetchIfModified(ctx context.Context, uri *url.URL, prev Validators) (io.ReadCloser, Validators, error) {
 	if uri == nil {
-		return nil, fmt.Errorf("uri is nil")
+		return nil, Validators{}, fmt.Errorf("uri is nil")
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri.String(), nil)
 	if err != nil {
-		return nil, err
+		return nil, Validators{}, err
 	}
 	req.Header.Set("User-Agent", "maru2")
+	if prev.ETag != "" {
+		req.Header.Set("If-None-Match", prev.ETag)
+	}
+	if !prev.LastModified.IsZero() {
+		req.Header.Set("If-Modified-Since", prev.LastModified.UTC().Format(http.TimeFormat))
+	}
 
 	resp, err := f.client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, Validators{}, err
+	}
+
+	v := Validators{ETag: resp.Header.Get("ETag")}
+	if lm, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		v.LastModified = lm
 	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("get %q: %s", uri.String(), resp.Status)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, v, nil
+	case http.StatusNotModified:
+		resp.Body.Close()
+		if v.IsZero() {
+			v = prev
+		}
+		return nil, v, ErrNotModified
+	default:
+		resp.Body.Close()
+		return nil, Validators{}, fmt.Errorf("get %q: %s", uri.String(), resp.Status)
 	}
-	return resp.Body, nil
 }
@@ -9,7 +9,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/stretchr/testify/assert"
@@ -73,3 +75,52 @@ func TestHTTPFetcher(t *testing.T) {
 	f(s1)
 	f(s2)
 }
+
+func TestHTTPFetcherConditional(t *testing.T) {
+	ctx := log.WithContext(t.Context(), log.New(io.Discard))
+	content := `schema-version: v1`
+	modified := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/etag.yaml" {
+			w.Header().Set("ETag", `"v1"`)
+		}
+		http.ServeContent(w, r, "tasks.yaml", modified, strings.NewReader(content))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewHTTPClient(server.Client())
+
+	u, err := url.Parse(server.URL + "/etag.yaml")
+	require.NoError(t, err)
+
+	rc, v, err := client.FetchIfModified(ctx, u, Validators{})
+	require.NoError(t, err)
+	b, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	assert.Equal(t, content, string(b))
+	assert.Equal(t, Validators{ETag: `"v1"`, LastModified: modified}, v)
+
+	// If-None-Match
+	rc, v, err = client.FetchIfModified(ctx, u, Validators{ETag: `"v1"`})
+	require.ErrorIs(t, err, ErrNotModified)
+	assert.Nil(t, rc)
+	assert.Equal(t, `"v1"`, v.ETag)
+
+	rc, _, err = client.FetchIfModified(ctx, u, Validators{ETag: `"v0"`})
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+
+	// If-Modified-Since
+	u, err = url.Parse(server.URL + "/modified.yaml")
+	require.NoError(t, err)
+
+	_, v, err = client.FetchIfModified(ctx, u, Validators{LastModified: modified})
+	require.ErrorIs(t, err, ErrNotModified)
+	assert.Equal(t, Validators{LastModified: modified}, v)
+
+	rc, _, err = client.FetchIfModified(ctx, u, Validators{LastModified: modified.Add(-time.Hour)})
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+}
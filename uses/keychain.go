@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+)
+
+// KeychainService is the service tokens are stored under in the OS keychain
+const KeychainService = "maru2"
+
+// WithKeychain enables reading tokens stored with `maru2 login` from the OS keychain for pkg:github, pkg:gitlab and oci fetches
+//
+// Tokens from the keychain take priority over GITHUB_TOKEN, GITLAB_TOKEN, CI_JOB_TOKEN, the GitHub CLI and docker credentials
+func WithKeychain(enabled bool) FetcherServiceOption {
+	return func(s *FetcherService) {
+		s.keychain = enabled
+	}
+}
+
+// KeychainToken returns the token stored for host in the OS keychain, empty if none is stored
+func KeychainToken(host string) (string, error) {
+	token, err := keyring.Get(KeychainService, host)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", nil
+	}
+	return token, err
+}
+
+// StoreKeychainToken stores the token for host in the OS keychain, replacing any stored token
+func StoreKeychainToken(host, token string) error {
+	return keyring.Set(KeychainService, host, token)
+}
+
+// DeleteKeychainToken removes the token stored for host from the OS keychain
+func DeleteKeychainToken(host string) error {
+	err := keyring.Delete(KeychainService, host)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("no token is stored for %s", host)
+	}
+	return err
+}
+
+// keychainToken returns the token stored for host, the keychain is only read once per host
+//
+// An unavailable keychain (e.g. no secret service on Linux) is treated as having no token
+func (s *FetcherService) keychainToken(host string) string {
+	if !s.keychain || host == "" {
+		return ""
+	}
+	if token, ok := s.keychainTokens.Load(host); ok {
+		return token.(string)
+	}
+	token, err := KeychainToken(host)
+	if err != nil {
+		token = ""
+	}
+	s.keychainTokens.Store(host, token)
+	return token
+}
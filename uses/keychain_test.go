@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+func TestKeychain(t *testing.T) {
+	keyring.MockInit()
+
+	ctx := log.WithContext(t.Context(), log.New(io.Discard))
+
+	t.Run("store and delete", func(t *testing.T) {
+		token, err := KeychainToken("example.com")
+		require.NoError(t, err)
+		assert.Empty(t, token)
+
+		require.NoError(t, StoreKeychainToken("example.com", "first"))
+		require.NoError(t, StoreKeychainToken("example.com", "second"))
+		token, err = KeychainToken("example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "second", token)
+
+		require.NoError(t, DeleteKeychainToken("example.com"))
+		require.EqualError(t, DeleteKeychainToken("example.com"), "no token is stored for example.com")
+	})
+
+	t.Run("github", func(t *testing.T) {
+		var authorization []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = append(authorization, r.Header.Get("Authorization"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"type": "file", "encoding": "base64", "content": %q}`, base64.StdEncoding.EncodeToString([]byte("schema-version: v1\n")))
+		}))
+		defer server.Close()
+
+		u, err := url.Parse(server.URL)
+		require.NoError(t, err)
+		require.NoError(t, StoreKeychainToken(u.Host, "from-keychain"))
+		t.Cleanup(func() { _ = DeleteKeychainToken(u.Host) })
+		t.Setenv("GITHUB_TOKEN", "from-env")
+
+		uri, err := ResolveRelative(nil, fmt.Sprintf("pkg:github/defenseunicorns/maru2@main?base-url=%s#tasks.yaml", url.QueryEscape(server.URL)), nil)
+		require.NoError(t, err)
+
+		fetch := func(t *testing.T, opts ...FetcherServiceOption) {
+			t.Helper()
+			svc, err := NewFetcherService(opts...)
+			require.NoError(t, err)
+			fetcher, err := svc.GetFetcher(uri)
+			require.NoError(t, err)
+			rc, err := fetcher.Fetch(ctx, uri)
+			require.NoError(t, err)
+			require.NoError(t, rc.Close())
+		}
+
+		fetch(t)
+		fetch(t, WithKeychain(true))
+		fetch(t, WithKeychain(true), WithCredentialHelper(`echo "from-helper"`))
+
+		assert.Equal(t, []string{"Bearer from-env", "Bearer from-keychain", "Bearer from-helper"}, authorization)
+	})
+
+	t.Run("oci", func(t *testing.T) {
+		require.NoError(t, StoreKeychainToken("registry.example.com", "user:pass"))
+		t.Cleanup(func() { _ = DeleteKeychainToken("registry.example.com") })
+
+		svc, err := NewFetcherService(WithKeychain(true))
+		require.NoError(t, err)
+		credential := svc.tokenCredential(func(context.Context, string) (auth.Credential, error) {
+			return auth.Credential{Username: "docker"}, nil
+		})
+
+		cred, err := credential(ctx, "registry.example.com")
+		require.NoError(t, err)
+		assert.Equal(t, auth.Credential{Username: "user", Password: "pass"}, cred)
+
+		cred, err = credential(ctx, "other.example.com")
+		require.NoError(t, err)
+		assert.Equal(t, auth.Credential{Username: "docker"}, cred)
+	})
+
+	t.Run("unavailable keychain", func(t *testing.T) {
+		keyring.MockInitWithError(errors.New("no secret service"))
+		t.Cleanup(keyring.MockInit)
+
+		_, err := KeychainToken("example.com")
+		require.EqualError(t, err, "no secret service")
+
+		svc, err := NewFetcherService(WithKeychain(true))
+		require.NoError(t, err)
+		assert.Empty(t, svc.keychainToken("example.com"))
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/url"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/afero"
 )
@@ -16,11 +17,12 @@ import (
 // LocalFetcher fetches a file from the local filesystem.
 type LocalFetcher struct {
 	fsys afero.Fs
+	root string
 }
 
 // NewLocalFetcher creates a new local fetcher
 func NewLocalFetcher(fsys afero.Fs) *LocalFetcher {
-	return &LocalFetcher{fsys}
+	return &LocalFetcher{fsys: fsys}
 }
 
 // Fetch opens a file handle at the given location
@@ -48,14 +50,51 @@ func (f *LocalFetcher) Fetch(ctx context.Context, uri *url.URL) (io.ReadCloser,
 	p := clone.String()
 	p = filepath.Clean(p)
 
+	if f.root != "" {
+		if err := f.confine(p); err != nil {
+			return nil, err
+		}
+	}
+
 	fileInfo, err := f.fsys.Stat(p)
 	if err != nil {
 		return nil, err
 	}
 
 	if fileInfo.IsDir() {
-		return nil, fmt.Errorf("read %s: is a directory", p)
+		return nil, fmt.Errorf("read %s: is a directory, reference %s/ to use its %s", p, p, DefaultFileName)
 	}
 
 	return f.fsys.Open(p)
 }
+
+// confine errors if p is not within the fetcher's root
+//
+// Symlinks are resolved when reading from the OS filesystem, so a link cannot point outside of the root
+func (f *LocalFetcher) confine(p string) error {
+	root, err := filepath.Abs(f.root)
+	if err != nil {
+		return err
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := f.fsys.(*afero.OsFs); ok {
+		root, err = filepath.EvalSymlinks(root)
+		if err != nil {
+			return err
+		}
+		abs, err = filepath.EvalSymlinks(abs)
+		if err != nil {
+			return err
+		}
+	}
+
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("read %s: outside of %s", p, f.root)
+	}
+	return nil
+}
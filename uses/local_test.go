@@ -7,6 +7,8 @@ import (
 	"context"
 	"io"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -36,7 +38,7 @@ func TestLocalFetcher(t *testing.T) {
 		{
 			name:        "is a directory",
 			uses:        "file:bar",
-			expectedErr: `read bar: is a directory`,
+			expectedErr: `read bar: is a directory, reference bar/ to use its tasks.yaml`,
 		},
 		{
 			name:        "bad scheme",
@@ -107,3 +109,91 @@ func TestLocalFetcher(t *testing.T) {
 		require.ErrorIs(t, err, context.Canceled)
 	})
 }
+
+func TestLocalFetcherRoot(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "root")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "nested"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "tasks.yaml"), []byte("inside"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "nested", "tasks.yaml"), []byte("nested"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "outside.yaml"), []byte("outside"), 0o644))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "outside.yaml"), filepath.Join(root, "link.yaml")))
+
+	testCases := []struct {
+		name        string
+		path        string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "within root",
+			path:     filepath.Join(root, "tasks.yaml"),
+			expected: "inside",
+		},
+		{
+			name:     "nested with dot dot back into root",
+			path:     filepath.Join(root, "nested", "..", "nested", "tasks.yaml"),
+			expected: "nested",
+		},
+		{
+			name:        "dot dot escape",
+			path:        filepath.Join(root, "..", "outside.yaml"),
+			expectedErr: "outside of " + root,
+		},
+		{
+			name:        "absolute path outside",
+			path:        filepath.Join(dir, "outside.yaml"),
+			expectedErr: "outside of " + root,
+		},
+		{
+			name:        "symlink outside",
+			path:        filepath.Join(root, "link.yaml"),
+			expectedErr: "outside of " + root,
+		},
+		{
+			name:        "does not exist",
+			path:        filepath.Join(root, "missing.yaml"),
+			expectedErr: "no such file or directory",
+		},
+	}
+
+	svc, err := NewFetcherService(WithFileRoot(root))
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := log.WithContext(t.Context(), log.New(io.Discard))
+
+			u := &url.URL{Scheme: "file", Path: tc.path}
+			fetcher, err := svc.GetFetcher(u)
+			require.NoError(t, err)
+
+			rc, err := fetcher.Fetch(ctx, u)
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			defer rc.Close()
+
+			b, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(b))
+		})
+	}
+
+	t.Run("relative paths", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fs, "workflows/tasks.yaml", []byte("inside"), 0o644))
+		require.NoError(t, afero.WriteFile(fs, "secret.yaml", []byte("outside"), 0o644))
+
+		fetcher := &LocalFetcher{fsys: fs, root: "workflows"}
+
+		rc, err := fetcher.Fetch(t.Context(), &url.URL{Scheme: "file", Opaque: "workflows/tasks.yaml"})
+		require.NoError(t, err)
+		rc.Close()
+
+		_, err = fetcher.Fetch(t.Context(), &url.URL{Scheme: "file", Opaque: "workflows/../secret.yaml"})
+		require.EqualError(t, err, "read secret.yaml: outside of workflows")
+	})
+}
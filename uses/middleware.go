@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"context"
+	"io"
+	"net/url"
+)
+
+// FetcherFunc is an adapter to allow the use of ordinary functions as Fetchers
+type FetcherFunc func(ctx context.Context, uri *url.URL) (io.ReadCloser, error)
+
+// Fetch implements the Fetcher interface
+func (f FetcherFunc) Fetch(ctx context.Context, uri *url.URL) (io.ReadCloser, error) {
+	return f(ctx, uri)
+}
+
+// FetcherMiddleware wraps a Fetcher to add behavior around every fetch (e.g. logging, tracing or deny-lists)
+type FetcherMiddleware func(next Fetcher) Fetcher
+
+// WithMiddleware adds middleware that wraps every fetcher returned by the fetcher service
+//
+// Middleware is applied in order, the first middleware is the outermost and sees every fetch first
+func WithMiddleware(middleware ...FetcherMiddleware) FetcherServiceOption {
+	return func(s *FetcherService) {
+		s.middleware = append(s.middleware, middleware...)
+	}
+}
+
+// wrap applies the fetcher service's middleware to a fetcher
+func (s *FetcherService) wrap(fetcher Fetcher) Fetcher {
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		fetcher = s.middleware[i](fetcher)
+	}
+	return fetcher
+}
@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "tasks.yaml", []byte("schema-version: v1\n"), 0o644))
+
+	store, err := NewLocalStore(afero.NewMemMapFs())
+	require.NoError(t, err)
+	remote, err := url.Parse("https://example.com/tasks.yaml")
+	require.NoError(t, err)
+	require.NoError(t, store.Store(strings.NewReader("schema-version: v1\n"), remote))
+
+	var calls []string
+	record := func(name string) FetcherMiddleware {
+		return func(next Fetcher) Fetcher {
+			return FetcherFunc(func(ctx context.Context, uri *url.URL) (io.ReadCloser, error) {
+				calls = append(calls, name+" "+uri.String())
+				return next.Fetch(ctx, uri)
+			})
+		}
+	}
+	deny := func(next Fetcher) Fetcher {
+		return FetcherFunc(func(ctx context.Context, uri *url.URL) (io.ReadCloser, error) {
+			if uri.Host == "denied.example.com" {
+				return nil, fmt.Errorf("%s is not allowed", uri.Host)
+			}
+			return next.Fetch(ctx, uri)
+		})
+	}
+
+	testCases := []struct {
+		name          string
+		opts          []FetcherServiceOption
+		uri           string
+		expectedCalls []string
+		expectedErr   string
+	}{
+		{
+			name:          "applied in order",
+			opts:          []FetcherServiceOption{WithFS(fs), WithMiddleware(record("first"), record("second"))},
+			uri:           "file:tasks.yaml",
+			expectedCalls: []string{"first file:tasks.yaml", "second file:tasks.yaml"},
+		},
+		{
+			name:          "multiple options",
+			opts:          []FetcherServiceOption{WithFS(fs), WithMiddleware(record("first")), WithMiddleware(record("second"))},
+			uri:           "file:tasks.yaml",
+			expectedCalls: []string{"first file:tasks.yaml", "second file:tasks.yaml"},
+		},
+		{
+			name:          "fetch policy never",
+			opts:          []FetcherServiceOption{WithStorage(store), WithFetchPolicy(FetchPolicyNever), WithMiddleware(record("only"))},
+			uri:           "https://example.com/tasks.yaml",
+			expectedCalls: []string{"only https://example.com/tasks.yaml"},
+		},
+		{
+			name:        "deny list",
+			opts:        []FetcherServiceOption{WithMiddleware(record("outer"), deny)},
+			uri:         "https://denied.example.com/tasks.yaml",
+			expectedErr: "denied.example.com is not allowed",
+			expectedCalls: []string{
+				"outer https://denied.example.com/tasks.yaml",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls = nil
+
+			svc, err := NewFetcherService(tc.opts...)
+			require.NoError(t, err)
+
+			uri, err := url.Parse(tc.uri)
+			require.NoError(t, err)
+
+			fetcher, err := svc.GetFetcher(uri)
+			require.NoError(t, err)
+
+			rc, err := fetcher.Fetch(t.Context(), uri)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+				b, err := io.ReadAll(rc)
+				require.NoError(t, err)
+				require.NoError(t, rc.Close())
+				assert.Equal(t, "schema-version: v1\n", string(b))
+			}
+			assert.Equal(t, tc.expectedCalls, calls)
+		})
+	}
+}
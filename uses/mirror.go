@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/log"
+
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+)
+
+// Mirror redirects fetches of remote workflows under a URL prefix to a mirror
+type Mirror struct {
+	// Prefix of resolved workflow URLs to redirect (e.g. oci:ghcr.io/ or pkg:github/defenseunicorns/)
+	Prefix string `json:"prefix" jsonschema:"minLength=1"`
+	// Replacement for the prefix, defaults to the prefix
+	Replace string `json:"replace,omitempty"`
+	// Base URL of the mirror's API, set as the base-url qualifier of redirected pkg URLs
+	BaseURL string `json:"base-url,omitempty"`
+}
+
+// Mirrors is an ordered list of mirrors, the first mirror whose prefix matches a URL is used
+type Mirrors []Mirror
+
+// WithMirrors sets the mirrors remote workflows are fetched from
+//
+// Mirrored workflows keep their original URL as their identity, so they are stored, vendored and resolved
+// relative to the URL written in the workflow
+func WithMirrors(mirrors Mirrors) FetcherServiceOption {
+	return func(s *FetcherService) {
+		s.mirrors = mirrors
+	}
+}
+
+// Validate checks every mirror redirects to a supported scheme
+func (m Mirrors) Validate() error {
+	for i, mirror := range m {
+		if mirror.Prefix == "" {
+			return fmt.Errorf("mirror %d: prefix cannot be empty", i)
+		}
+		if mirror.Replace == "" && mirror.BaseURL == "" {
+			return fmt.Errorf("mirror %d: one of replace or base-url must be set", i)
+		}
+		for _, s := range []string{mirror.Prefix, mirror.Replace} {
+			if s == "" {
+				continue
+			}
+			scheme, _, ok := strings.Cut(s, ":")
+			if !ok || scheme == "file" || !slices.Contains(v1.SupportedSchemes(), scheme) {
+				return fmt.Errorf("mirror %d: %q must start with one of the remote schemes (http, https, pkg, oci)", i, s)
+			}
+		}
+		if mirror.BaseURL != "" {
+			u, err := url.Parse(mirror.BaseURL)
+			if err != nil || u.Host == "" {
+				return fmt.Errorf("mirror %d: base-url %q must be a valid URL", i, mirror.BaseURL)
+			}
+		}
+	}
+	return nil
+}
+
+// Rewrite returns the URL of the first mirror whose prefix matches uri, and whether a mirror matched
+func (m Mirrors) Rewrite(uri *url.URL) (*url.URL, bool, error) {
+	for _, mirror := range m {
+		rest, ok := strings.CutPrefix(uri.String(), mirror.Prefix)
+		if !ok {
+			continue
+		}
+
+		replace := mirror.Replace
+		if replace == "" {
+			replace = mirror.Prefix
+		}
+
+		next, err := url.Parse(replace + rest)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to mirror %q: %w", uri, err)
+		}
+
+		if mirror.BaseURL != "" && next.Scheme == "pkg" {
+			q := next.Query()
+			q.Set(QualifierBaseURL, mirror.BaseURL)
+			next.RawQuery = q.Encode()
+		}
+
+		return next, true, nil
+	}
+	return uri, false, nil
+}
+
+// MirrorFetcher is a fetcher that redirects fetches to a mirror before passing them to its source
+type MirrorFetcher struct {
+	Source  Fetcher
+	Mirrors Mirrors
+}
+
+// Fetch implements the Fetcher interface
+func (f *MirrorFetcher) Fetch(ctx context.Context, uri *url.URL) (io.ReadCloser, error) {
+	next, err := f.mirror(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	return f.Source.Fetch(ctx, next)
+}
+
+// mirror rewrites uri to its mirror
+func (f *MirrorFetcher) mirror(ctx context.Context, uri *url.URL) (*url.URL, error) {
+	next, ok, err := f.Mirrors.Rewrite(uri)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		log.FromContext(ctx).Debug("mirroring", "url", uri, "mirror", next)
+	}
+	return next, nil
+}
+
+// conditionalMirrorFetcher is a MirrorFetcher whose source supports conditional requests
+type conditionalMirrorFetcher struct {
+	*MirrorFetcher
+}
+
+// FetchIfModified implements the ConditionalFetcher interface
+func (f *conditionalMirrorFetcher) FetchIfModified(ctx context.Context, uri *url.URL, prev Validators) (io.ReadCloser, Validators, error) {
+	next, err := f.mirror(ctx, uri)
+	if err != nil {
+		return nil, Validators{}, err
+	}
+	return f.Source.(ConditionalFetcher).FetchIfModified(ctx, next, prev)
+}
+
+// mirrored wraps the given fetcher to redirect fetches to a mirror, keeping support for conditional requests
+func mirrored(source Fetcher, mirrors Mirrors) Fetcher {
+	f := &MirrorFetcher{Source: source, Mirrors: mirrors}
+	if _, ok := source.(ConditionalFetcher); ok {
+		return &conditionalMirrorFetcher{f}
+	}
+	return f
+}
@@ -0,0 +1,180 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMirrorsRewrite(t *testing.T) {
+	mirrors := Mirrors{
+		{Prefix: "oci:ghcr.io/", Replace: "oci:zot.example.com/ghcr.io/"},
+		{Prefix: "https://raw.githubusercontent.com/", Replace: "https://artifactory.example.com/github-raw/"},
+		{Prefix: "pkg:github/defenseunicorns/", Replace: "pkg:github/mirror/"},
+		{Prefix: "pkg:gitlab/", BaseURL: "https://artifactory.example.com/api/gitlab"},
+		{Prefix: "pkg:github/", Replace: "pkg:gitlab/github/", BaseURL: "https://gitlab.example.com"},
+	}
+
+	testCases := []struct {
+		name        string
+		uri         string
+		expected    string
+		expectedOk  bool
+		expectedErr string
+	}{
+		{
+			name:       "oci",
+			uri:        "oci:ghcr.io/defenseunicorns/tasks:v1#tasks.yaml",
+			expected:   "oci:zot.example.com/ghcr.io/defenseunicorns/tasks:v1#tasks.yaml",
+			expectedOk: true,
+		},
+		{
+			name:       "https",
+			uri:        "https://raw.githubusercontent.com/defenseunicorns/maru2/main/tasks.yaml?task=build",
+			expected:   "https://artifactory.example.com/github-raw/defenseunicorns/maru2/main/tasks.yaml?task=build",
+			expectedOk: true,
+		},
+		{
+			name:       "first match wins",
+			uri:        "pkg:github/defenseunicorns/maru2@main?task=build#tasks.yaml",
+			expected:   "pkg:github/mirror/maru2@main?task=build#tasks.yaml",
+			expectedOk: true,
+		},
+		{
+			name:       "base url",
+			uri:        "pkg:gitlab/noxsios/maru2@main#tasks.yaml",
+			expected:   "pkg:gitlab/noxsios/maru2@main?base-url=https%3A%2F%2Fartifactory.example.com%2Fapi%2Fgitlab#tasks.yaml",
+			expectedOk: true,
+		},
+		{
+			name:       "replace and base url",
+			uri:        "pkg:github/noxsios/vai@main?base-url=https%3A%2F%2Fapi.github.com#tasks.yaml",
+			expected:   "pkg:gitlab/github/noxsios/vai@main?base-url=https%3A%2F%2Fgitlab.example.com#tasks.yaml",
+			expectedOk: true,
+		},
+		{
+			name:     "no match",
+			uri:      "https://example.com/tasks.yaml",
+			expected: "https://example.com/tasks.yaml",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			uri, err := url.Parse(tc.uri)
+			require.NoError(t, err)
+
+			next, ok, err := mirrors.Rewrite(uri)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedOk, ok)
+			assert.Equal(t, tc.expected, next.String())
+		})
+	}
+}
+
+func TestMirrorsValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		mirrors     Mirrors
+		expectedErr string
+	}{
+		{
+			name: "valid",
+			mirrors: Mirrors{
+				{Prefix: "oci:ghcr.io/", Replace: "oci:zot.example.com/"},
+				{Prefix: "pkg:github/", BaseURL: "https://ghe.example.com/api/v3"},
+			},
+		},
+		{
+			name:        "empty prefix",
+			mirrors:     Mirrors{{Replace: "oci:zot.example.com/"}},
+			expectedErr: "mirror 0: prefix cannot be empty",
+		},
+		{
+			name:        "nothing to rewrite",
+			mirrors:     Mirrors{{Prefix: "oci:ghcr.io/"}},
+			expectedErr: "mirror 0: one of replace or base-url must be set",
+		},
+		{
+			name:        "local prefix",
+			mirrors:     Mirrors{{Prefix: "file:tasks/", Replace: "https://example.com/"}},
+			expectedErr: `mirror 0: "file:tasks/" must start with one of the remote schemes (http, https, pkg, oci)`,
+		},
+		{
+			name:        "unsupported replace",
+			mirrors:     Mirrors{{Prefix: "https://example.com/", Replace: "ftp://example.com/"}},
+			expectedErr: `mirror 0: "ftp://example.com/" must start with one of the remote schemes (http, https, pkg, oci)`,
+		},
+		{
+			name:        "invalid base url",
+			mirrors:     Mirrors{{Prefix: "pkg:github/", BaseURL: "ghe.example.com"}},
+			expectedErr: `mirror 0: base-url "ghe.example.com" must be a valid URL`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.mirrors.Validate()
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFetcherServiceMirrors(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		_, _ = w.Write([]byte("schema-version: v1\n"))
+	}))
+	defer server.Close()
+
+	store, err := NewLocalStore(afero.NewMemMapFs())
+	require.NoError(t, err)
+
+	svc, err := NewFetcherService(
+		WithStorage(store),
+		WithFetchPolicy(FetchPolicyRefresh),
+		WithMirrors(Mirrors{{Prefix: "https://upstream.example.com/", Replace: server.URL + "/mirror/"}}),
+	)
+	require.NoError(t, err)
+
+	uri, err := url.Parse("https://upstream.example.com/tasks.yaml")
+	require.NoError(t, err)
+
+	fetcher, err := svc.GetFetcher(uri)
+	require.NoError(t, err)
+
+	rc, err := fetcher.Fetch(t.Context(), uri)
+	require.NoError(t, err)
+	b, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	assert.Equal(t, "schema-version: v1\n", string(b))
+
+	assert.Equal(t, []string{"/mirror/tasks.yaml"}, requested)
+
+	// stored under the original URL
+	exists, err := store.Exists(uri)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	_, err = NewFetcherService(WithMirrors(Mirrors{{Prefix: "oci:ghcr.io/"}}))
+	require.EqualError(t, err, "mirror 0: one of replace or base-url must be set")
+}
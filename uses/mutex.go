@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/charmbracelet/log"
+
+	v1 "github.com/defenseunicorns/maru2/schema/v1"
+)
+
+// MutexDir is the directory within the store that holds the lock files of named mutexes
+const MutexDir = "locks"
+
+// mutexPollInterval is how often a held mutex is checked for release
+const mutexPollInterval = 100 * time.Millisecond
+
+// Mutex acquires the named mutex, waiting until it is released by any other holder or ctx is done
+//
+// Mutexes are advisory file locks within the store, so they exclude every maru2 process on the host
+// sharing the store. Locking between processes is only possible when the store is backed by the OS filesystem.
+// Returns a function to release the mutex
+func (s *LocalStore) Mutex(ctx context.Context, name string) (func(), error) {
+	if !v1.MutexNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("mutex name %q does not satisfy %q", name, v1.MutexNamePattern)
+	}
+
+	if err := s.fsys.MkdirAll(MutexDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	f, err := s.fsys.OpenFile(path.Join(MutexDir, name+".lock"), os.O_RDONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	osf, ok := osFile(f)
+	if !ok {
+		return func() { f.Close() }, nil
+	}
+
+	waiting := false
+	for {
+		acquired, err := tryFlock(osf)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("unable to lock %s: %w", name, err)
+		}
+		if acquired {
+			return func() {
+				_ = funlock(osf)
+				f.Close()
+			}, nil
+		}
+
+		if !waiting {
+			log.FromContext(ctx).Info("waiting for mutex", "name", name)
+			waiting = true
+		}
+
+		select {
+		case <-ctx.Done():
+			f.Close()
+			return nil, ctx.Err()
+		case <-time.After(mutexPollInterval):
+		}
+	}
+}
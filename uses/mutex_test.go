@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStoreMutex(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("advisory file locking is not supported")
+	}
+
+	dir := t.TempDir()
+
+	// separate stores over the same directory behave like separate processes
+	first, err := NewLocalStore(afero.NewBasePathFs(afero.NewOsFs(), dir))
+	require.NoError(t, err)
+	second, err := NewLocalStore(afero.NewBasePathFs(afero.NewOsFs(), dir))
+	require.NoError(t, err)
+
+	unlock, err := first.Mutex(t.Context(), "deploy")
+	require.NoError(t, err)
+
+	exists, err := afero.Exists(afero.NewOsFs(), dir+"/locks/deploy.lock")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 3*mutexPollInterval)
+	defer cancel()
+	_, err = second.Mutex(ctx, "deploy")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// other mutexes are independent
+	unlockOther, err := second.Mutex(t.Context(), "other")
+	require.NoError(t, err)
+	unlockOther()
+
+	acquired := make(chan struct{})
+	go func() {
+		unlock, err := second.Mutex(t.Context(), "deploy")
+		if err == nil {
+			unlock()
+		}
+		close(acquired)
+	}()
+
+	unlock()
+
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("mutex was not acquired after being released")
+	}
+
+	// gc does not remove lock files
+	require.NoError(t, first.GC())
+	exists, err = afero.Exists(afero.NewOsFs(), dir+"/locks/deploy.lock")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	_, err = first.Mutex(t.Context(), "../escape")
+	require.EqualError(t, err, `mutex name "../escape" does not satisfy "^[_a-zA-Z][a-zA-Z0-9_-]*$"`)
+}
+
+func TestLocalStoreMutexInMemory(t *testing.T) {
+	store, err := NewLocalStore(afero.NewMemMapFs())
+	require.NoError(t, err)
+
+	unlock, err := store.Mutex(t.Context(), "deploy")
+	require.NoError(t, err)
+	unlock()
+}
@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// NetrcLogin is the login and password for a machine in a .netrc file
+type NetrcLogin struct {
+	Login    string
+	Password string
+}
+
+// Netrc holds the credentials of a .netrc file
+type Netrc struct {
+	machines map[string]NetrcLogin
+}
+
+// ParseNetrc parses the machine, default, login and password entries of a .netrc file
+//
+// account entries are ignored, macdef macros are skipped, and a token starting with # starts a comment.
+// The default entry is parsed but never used, as it would send its credentials to any host a workflow is fetched from
+func ParseNetrc(r io.Reader) (*Netrc, error) {
+	n := &Netrc{machines: make(map[string]NetrcLogin)}
+
+	var tokens []string
+	scanner := bufio.NewScanner(r)
+	inMacro := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if inMacro {
+			inMacro = strings.TrimSpace(line) != ""
+			continue
+		}
+		fields := strings.Fields(line)
+		for i, field := range fields {
+			// # within a token, such as a password, is not a comment
+			if strings.HasPrefix(field, "#") {
+				fields = fields[:i]
+				break
+			}
+			if field == "macdef" {
+				fields = fields[:i]
+				inMacro = true
+				break
+			}
+		}
+		tokens = append(tokens, fields...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var current *NetrcLogin
+	var machine string
+	flush := func() {
+		if current == nil || machine == "" {
+			return
+		}
+		if _, ok := n.machines[machine]; !ok { // the first entry for a machine is used
+			n.machines[machine] = *current
+		}
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		value := func() (string, error) {
+			if i+1 >= len(tokens) {
+				return "", fmt.Errorf("netrc: %q is missing a value", tokens[i])
+			}
+			i++
+			return tokens[i], nil
+		}
+
+		switch tokens[i] {
+		case "machine":
+			flush()
+			name, err := value()
+			if err != nil {
+				return nil, err
+			}
+			machine, current = name, &NetrcLogin{}
+		case "default":
+			flush()
+			machine, current = "", &NetrcLogin{}
+		case "login", "password", "account":
+			if current == nil {
+				return nil, fmt.Errorf("netrc: %q must follow a machine or default", tokens[i])
+			}
+			kind := tokens[i]
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			switch kind {
+			case "login":
+				current.Login = v
+			case "password":
+				current.Password = v
+			}
+		default:
+			return nil, fmt.Errorf("netrc: unexpected token %q", tokens[i])
+		}
+	}
+	flush()
+
+	return n, nil
+}
+
+// NetrcPath returns the path of the user's .netrc file, $NETRC if set, otherwise ~/.netrc (~/_netrc on Windows)
+func NetrcPath() (string, error) {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(home, "_netrc"), nil
+	}
+	return filepath.Join(home, ".netrc"), nil
+}
+
+// LoadNetrc parses the .netrc file at path, a file that does not exist has no credentials
+func LoadNetrc(path string) (*Netrc, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Netrc{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	n, err := ParseNetrc(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return n, nil
+}
+
+// Lookup returns the credentials of the machine entry for host
+func (n *Netrc) Lookup(host string) (NetrcLogin, bool) {
+	if n == nil {
+		return NetrcLogin{}, false
+	}
+	login, ok := n.machines[host]
+	return login, ok
+}
+
+// WithNetrc sets the .netrc credentials used for basic auth when fetching https workflows
+//
+// Credentials are only sent over https, and never replace an Authorization header set for the host in the config
+func WithNetrc(n *Netrc) FetcherServiceOption {
+	return func(s *FetcherService) {
+		s.netrc = n
+	}
+}
+
+// netrcTransport is an http.RoundTripper that adds basic auth from a .netrc file to https requests
+type netrcTransport struct {
+	base  http.RoundTripper
+	netrc *Netrc
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t *netrcTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "https" || req.Header.Get("Authorization") != "" {
+		return t.base.RoundTrip(req)
+	}
+	login, ok := t.netrc.Lookup(req.URL.Hostname())
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+
+	// RoundTrippers must not modify the original request
+	clone := req.Clone(req.Context())
+	clone.SetBasicAuth(login.Login, login.Password)
+	return t.base.RoundTrip(clone)
+}
+
+// withNetrc returns a shallow copy of the client whose transport adds basic auth from n
+func withNetrc(client *http.Client, n *Netrc) *http.Client {
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	clone := *client
+	clone.Transport = &netrcTransport{
+		base:  base,
+		netrc: n,
+	}
+	return &clone
+}
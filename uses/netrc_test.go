@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNetrc(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		lookups   map[string]NetrcLogin
+		missing   []string
+		expectErr string
+	}{
+		{
+			name: "machines, comments and default",
+			content: `# artifact servers
+machine artifacts.example.com login ci password s3cret
+machine other.example.com
+  login bot
+  account ignored
+  password hunter2 # trailing comment
+machine hash.example.com login bot password p#ss #comment
+
+macdef init
+  cd /pub
+  login ignored password ignored
+
+machine artifacts.example.com login second password ignored
+default login anonymous password guest
+`,
+			lookups: map[string]NetrcLogin{
+				"artifacts.example.com": {Login: "ci", Password: "s3cret"},
+				"other.example.com":     {Login: "bot", Password: "hunter2"},
+				"hash.example.com":      {Login: "bot", Password: "p#ss"},
+			},
+			// the default entry would send its credentials to any host
+			missing: []string{"unknown.example.com"},
+		},
+		{
+			name:    "no default",
+			content: `machine artifacts.example.com login ci password s3cret`,
+			lookups: map[string]NetrcLogin{
+				"artifacts.example.com": {Login: "ci", Password: "s3cret"},
+			},
+			missing: []string{"unknown.example.com"},
+		},
+		{
+			name:    "empty",
+			missing: []string{"artifacts.example.com"},
+		},
+		{
+			name:      "missing value",
+			content:   `machine artifacts.example.com login`,
+			expectErr: `netrc: "login" is missing a value`,
+		},
+		{
+			name:      "login before machine",
+			content:   `login ci password s3cret`,
+			expectErr: `netrc: "login" must follow a machine or default`,
+		},
+		{
+			name:      "unknown token",
+			content:   `machine artifacts.example.com user ci`,
+			expectErr: `netrc: unexpected token "user"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n, err := ParseNetrc(strings.NewReader(tc.content))
+			if tc.expectErr != "" {
+				require.EqualError(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			for host, expected := range tc.lookups {
+				login, ok := n.Lookup(host)
+				assert.True(t, ok, host)
+				assert.Equal(t, expected, login, host)
+			}
+			for _, host := range tc.missing {
+				_, ok := n.Lookup(host)
+				assert.False(t, ok, host)
+			}
+		})
+	}
+
+	var n *Netrc
+	_, ok := n.Lookup("artifacts.example.com")
+	assert.False(t, ok)
+}
+
+func TestLoadNetrc(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Setenv("NETRC", filepath.Join(dir, "netrc"))
+	path, err := NetrcPath()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "netrc"), path)
+
+	t.Setenv("NETRC", "")
+	t.Setenv("HOME", dir)
+	path, err = NetrcPath()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, ".netrc"), path)
+
+	n, err := LoadNetrc(path)
+	require.NoError(t, err)
+	_, ok := n.Lookup("artifacts.example.com")
+	assert.False(t, ok)
+
+	require.NoError(t, os.WriteFile(path, []byte("machine artifacts.example.com login ci password s3cret\n"), 0o600))
+	n, err = LoadNetrc(path)
+	require.NoError(t, err)
+	login, ok := n.Lookup("artifacts.example.com")
+	assert.True(t, ok)
+	assert.Equal(t, NetrcLogin{Login: "ci", Password: "s3cret"}, login)
+
+	require.NoError(t, os.WriteFile(path, []byte("machine\n"), 0o600))
+	_, err = LoadNetrc(path)
+	require.EqualError(t, err, path+`: netrc: "machine" is missing a value`)
+}
+
+func TestNetrcFetch(t *testing.T) {
+	var authorization []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte("schema-version: v1\n"))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	n, err := ParseNetrc(strings.NewReader("machine " + u.Hostname() + " login ci password s3cret\n"))
+	require.NoError(t, err)
+
+	ctx := log.WithContext(t.Context(), log.New(io.Discard))
+	uri, err := url.Parse(server.URL + "/tasks.yaml")
+	require.NoError(t, err)
+
+	fetch := func(t *testing.T, opts ...FetcherServiceOption) {
+		t.Helper()
+		svc, err := NewFetcherService(append([]FetcherServiceOption{WithClient(server.Client())}, opts...)...)
+		require.NoError(t, err)
+		fetcher, err := svc.GetFetcher(uri)
+		require.NoError(t, err)
+		rc, err := fetcher.Fetch(ctx, uri)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+	}
+
+	fetch(t)
+	fetch(t, WithNetrc(n))
+	// headers from the config take priority
+	fetch(t, WithNetrc(n), WithHeaders(HostHeaders{u.Host: http.Header{"Authorization": []string{"Bearer token"}}}))
+
+	assert.Equal(t, []string{"", "Basic Y2k6czNjcmV0", "Bearer token"}, authorization)
+
+	// credentials are never sent over plain http
+	var plain string
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		plain = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte("schema-version: v1\n"))
+	}))
+	defer httpServer.Close()
+
+	client := withNetrc(httpServer.Client(), &Netrc{machines: map[string]NetrcLogin{"127.0.0.1": {Login: "ci", Password: "s3cret"}}})
+	resp, err := client.Get(httpServer.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Empty(t, plain)
+}
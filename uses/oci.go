@@ -4,6 +4,7 @@
 package uses
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/json"
@@ -14,6 +15,8 @@ import (
 
 	"github.com/charmbracelet/log"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
 	"oras.land/oras-go/v2/registry/remote"
 	"oras.land/oras-go/v2/registry/remote/auth"
 	"oras.land/oras-go/v2/registry/remote/credentials"
@@ -26,10 +29,21 @@ type OCIClient struct {
 	plainHTTP bool
 }
 
+// DockerCredentials loads registry credentials from the docker config
+//
+// The config is read from $DOCKER_CONFIG/config.json, otherwise ~/.docker/config.json,
+// credentials are looked up from credHelpers, then credsStore, then auths.
+// If none are configured the platform's default credential helper is used when installed
+func DockerCredentials() (credentials.Store, error) {
+	return credentials.NewStoreFromDocker(credentials.StoreOptions{DetectDefaultNativeStore: true})
+}
+
 // NewOCIClient creates a new ORAS client
+//
+// Registry credentials are read using DockerCredentials, headers set for the registry host
+// in the maru2 config (e.g. Authorization) are sent in addition to and take priority over them
 func NewOCIClient(baseClient *http.Client, insecureSkipTLSVerify, plainHTTP bool) (*OCIClient, error) {
-	storeOpts := credentials.StoreOptions{DetectDefaultNativeStore: true}
-	credStore, err := credentials.NewStoreFromDocker(storeOpts)
+	credStore, err := DockerCredentials()
 	if err != nil {
 		return nil, err
 	}
@@ -38,8 +52,25 @@ func NewOCIClient(baseClient *http.Client, insecureSkipTLSVerify, plainHTTP bool
 		Timeout: baseClient.Timeout,
 	}
 
-	if baseClient.Transport != nil {
-		if transport, ok := baseClient.Transport.(*http.Transport); ok {
+	base := baseClient.Transport
+	ht, hasHeaders := base.(*headerTransport)
+	if hasHeaders {
+		base = ht.base
+		if base == http.DefaultTransport {
+			base = nil
+		}
+	}
+
+	rt, hasRetry := base.(*retryTransport)
+	if hasRetry {
+		base = rt.base
+		if base == http.DefaultTransport {
+			base = nil
+		}
+	}
+
+	if base != nil {
+		if transport, ok := base.(*http.Transport); ok {
 			clone := transport.Clone()
 			if clone.TLSClientConfig == nil {
 				clone.TLSClientConfig = &tls.Config{}
@@ -50,7 +81,27 @@ func NewOCIClient(baseClient *http.Client, insecureSkipTLSVerify, plainHTTP bool
 	} else {
 		transport := http.DefaultTransport.(*http.Transport).Clone()
 		transport.TLSClientConfig.InsecureSkipVerify = insecureSkipTLSVerify
-		httpClient.Transport = retry.NewTransport(transport)
+		if hasRetry {
+			httpClient.Transport = transport
+		} else {
+			httpClient.Transport = retry.NewTransport(transport)
+		}
+	}
+
+	if hasRetry {
+		base := httpClient.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		httpClient.Transport = &retryTransport{base: base, policy: rt.policy}
+	}
+
+	if hasHeaders {
+		rt := httpClient.Transport
+		if rt == nil {
+			rt = http.DefaultTransport
+		}
+		httpClient.Transport = &headerTransport{base: rt, headers: ht.headers}
 	}
 
 	client := &auth.Client{
@@ -63,6 +114,9 @@ func NewOCIClient(baseClient *http.Client, insecureSkipTLSVerify, plainHTTP bool
 }
 
 // Fetch uses ORAS to fetch the workflow out of the OCI repository
+//
+// The reference may be a tag (oci:registry/repo:tag) or a digest (oci:registry/repo@sha256:...),
+// the manifest and workflow contents are verified against their digests
 func (c *OCIClient) Fetch(ctx context.Context, uri *url.URL) (io.ReadCloser, error) {
 	if uri == nil {
 		return nil, fmt.Errorf("uri is nil")
@@ -86,23 +140,23 @@ func (c *OCIClient) Fetch(ctx context.Context, uri *url.URL) (io.ReadCloser, err
 		return nil, err
 	}
 
-	repo, err := remote.NewRepository(clone.String())
+	repo, err := c.repository(clone.String())
 	if err != nil {
 		return nil, err
 	}
-	repo.Client = c.client
-	repo.PlainHTTP = c.plainHTTP
 
 	rootDesc, rootReadCloser, err := repo.FetchReference(ctx, clone.String())
 	if err != nil {
 		return nil, err
 	}
+	defer rootReadCloser.Close()
 
 	if rootDesc.MediaType != ocispec.MediaTypeImageManifest {
 		return nil, fmt.Errorf("unexpected mediatype, want %q got %q", ocispec.MediaTypeImageManifest, rootDesc.MediaType)
 	}
 
-	b, err := io.ReadAll(rootReadCloser)
+	// when fetched by digest, the descriptor holds the pinned digest
+	b, err := content.ReadAll(rootReadCloser, rootDesc)
 	if err != nil {
 		return nil, err
 	}
@@ -119,9 +173,62 @@ func (c *OCIClient) Fetch(ctx context.Context, uri *url.URL) (io.ReadCloser, err
 
 	for _, desc := range manifest.Layers {
 		if desc.Annotations != nil && desc.Annotations[ocispec.AnnotationTitle] == path {
-			return repo.Fetch(ctx, desc)
+			rc, err := repo.Fetch(ctx, desc)
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+
+			b, err := content.ReadAll(rc, desc)
+			if err != nil {
+				return nil, err
+			}
+			return io.NopCloser(bytes.NewReader(b)), nil
 		}
 	}
 
 	return nil, fmt.Errorf("%s: not found", path)
 }
+
+// Tags lists the tags available in the OCI repository, any tag or digest in the reference is ignored
+func (c *OCIClient) Tags(ctx context.Context, uri *url.URL) ([]string, error) {
+	if uri == nil {
+		return nil, fmt.Errorf("uri is nil")
+	}
+
+	if uri.Scheme != "oci" {
+		return nil, fmt.Errorf("scheme is not \"oci\"")
+	}
+
+	ref, err := registry.ParseReference(uri.Opaque)
+	if err != nil {
+		return nil, err
+	}
+	ref.Reference = ""
+
+	repo, err := c.repository(ref.String())
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	err = repo.Tags(ctx, "", func(page []string) error {
+		tags = append(tags, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// repository creates a remote repository for the reference using the client's settings
+func (c *OCIClient) repository(reference string) (*remote.Repository, error) {
+	repo, err := remote.NewRepository(reference)
+	if err != nil {
+		return nil, err
+	}
+	repo.Client = c.client
+	repo.PlainHTTP = c.plainHTTP
+	return repo, nil
+}
@@ -4,11 +4,15 @@
 package uses_test
 
 import (
+	"encoding/base64"
 	"fmt"
 	"io"
+	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/charmbracelet/log"
@@ -119,6 +123,57 @@ tasks:
 			}},
 		}, wf)
 
+		// fetches by digest, w/ and w/o a tag
+		repo, err := remote.NewRepository(fmt.Sprintf("%s/workflow-1", registry))
+		require.NoError(t, err)
+		repo.PlainHTTP = isPlainHTTP
+		repo.Client = &auth.Client{Client: httpClient}
+		desc, err := repo.Resolve(ctx, "latest")
+		require.NoError(t, err)
+
+		for _, ref := range []string{
+			fmt.Sprintf("oci:%s/workflow-1@%s", registry, desc.Digest),
+			fmt.Sprintf("oci:%s/workflow-1:latest@%s", registry, desc.Digest),
+		} {
+			uri, err = url.Parse(ref)
+			require.NoError(t, err)
+
+			rc, err = client.Fetch(ctx, uri)
+			require.NoError(t, err)
+			pinned, err := v1.Read(rc)
+			require.NoError(t, err)
+			require.NoError(t, rc.Close())
+			assert.Equal(t, wf, pinned)
+		}
+
+		// fails w/ unknown digest
+		dne := "sha256:" + strings.Repeat("0", 64)
+		uri, err = url.Parse(fmt.Sprintf("oci:%s/workflow-1@%s", registry, dne))
+		require.NoError(t, err)
+
+		rc, err = client.Fetch(ctx, uri)
+		assert.Nil(t, rc)
+		require.EqualError(t, err, fmt.Sprintf("%s/workflow-1@%s: not found", registry, dne))
+
+		// fails w/ malformed digest
+		uri, err = url.Parse(fmt.Sprintf("oci:%s/workflow-1@sha256:abc", registry))
+		require.NoError(t, err)
+
+		rc, err = client.Fetch(ctx, uri)
+		assert.Nil(t, rc)
+		require.ErrorContains(t, err, "invalid reference")
+
+		// lists tags
+		uri, err = url.Parse(fmt.Sprintf("oci:%s/workflow-1:dne#file:foo.yaml", registry))
+		require.NoError(t, err)
+
+		tags, err := client.Tags(ctx, uri)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"latest"}, tags)
+
+		_, err = client.Tags(ctx, &url.URL{Scheme: "https"})
+		require.EqualError(t, err, `scheme is not "oci"`)
+
 		// fails w/ internal not found error
 		uri, err = url.Parse(fmt.Sprintf("oci:%s/workflow-1:latest#file:foo.yaml", registry))
 		require.NoError(t, err)
@@ -155,3 +210,115 @@ tasks:
 	seed(s2)
 	f(s2)
 }
+
+func TestOCIClientDockerCredentials(t *testing.T) {
+	r := olareg.New(olaregcfg.Config{
+		Storage: olaregcfg.ConfigStorage{
+			StoreType: olaregcfg.StoreMem,
+		},
+	})
+	open := httptest.NewServer(r)
+	protected := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if user, pass, ok := req.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="maru2"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		r.ServeHTTP(w, req)
+	}))
+	t.Cleanup(func() {
+		open.Close()
+		protected.Close()
+		_ = r.Close()
+	})
+
+	ctx := log.WithContext(t.Context(), log.New(io.Discard))
+
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile(uses.DefaultFileName, []byte(`
+schema-version: v1
+tasks:
+  default:
+    steps:
+      - run: echo "Hello, world!"
+`), 0o644))
+
+	openURL, err := url.Parse(open.URL)
+	require.NoError(t, err)
+	dst, err := remote.NewRepository(fmt.Sprintf("%s/workflow:latest", openURL.Host))
+	require.NoError(t, err)
+	dst.PlainHTTP = true
+	require.NoError(t, maru2.Publish(ctx, dst, []string{uses.DefaultFileName}))
+
+	protectedURL, err := url.Parse(protected.URL)
+	require.NoError(t, err)
+	host := protectedURL.Host
+	uri, err := url.Parse(fmt.Sprintf("oci:%s/workflow:latest", host))
+	require.NoError(t, err)
+
+	fetch := func(t *testing.T, config string) error {
+		t.Helper()
+		dir := t.TempDir()
+		t.Setenv("DOCKER_CONFIG", dir)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0o600))
+
+		client, err := uses.NewOCIClient(&http.Client{}, false, true)
+		require.NoError(t, err)
+
+		rc, err := client.Fetch(ctx, uri)
+		if err != nil {
+			return err
+		}
+		return rc.Close()
+	}
+
+	t.Run("no credentials", func(t *testing.T) {
+		require.ErrorContains(t, fetch(t, `{}`), "basic credential not found")
+	})
+
+	t.Run("auths", func(t *testing.T) {
+		auth := base64.StdEncoding.EncodeToString([]byte("user:pass"))
+		require.NoError(t, fetch(t, fmt.Sprintf(`{"auths": {%q: {"auth": %q}}}`, host, auth)))
+	})
+
+	t.Run("credHelpers", func(t *testing.T) {
+		bin := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(bin, "docker-credential-maru2-test"), []byte(`#!/bin/sh
+read -r server
+echo "{\"ServerURL\":\"$server\",\"Username\":\"user\",\"Secret\":\"pass\"}"
+`), 0o755))
+		t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		require.NoError(t, fetch(t, fmt.Sprintf(`{"credHelpers": {%q: "maru2-test"}}`, host)))
+	})
+
+	t.Run("maru2 config headers take priority", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv("DOCKER_CONFIG", dir)
+		wrong := base64.StdEncoding.EncodeToString([]byte("user:wrong"))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), fmt.Appendf(nil, `{"auths": {%q: {"auth": %q}}}`, host, wrong), 0o600))
+
+		svc, err := uses.NewFetcherService(uses.WithHeaders(uses.HostHeaders{
+			host: http.Header{"Authorization": []string{"Basic " + base64.StdEncoding.EncodeToString([]byte("user:pass"))}},
+		}))
+		require.NoError(t, err)
+
+		plain, err := url.Parse(uri.String() + "?" + uses.OCIQueryParamPlainHTTP + "=true")
+		require.NoError(t, err)
+		fetcher, err := svc.GetFetcher(plain)
+		require.NoError(t, err)
+
+		rc, err := fetcher.Fetch(ctx, plain)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv("DOCKER_CONFIG", dir)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(`i'm not JSON`), 0o600))
+
+		_, err := uses.NewOCIClient(&http.Client{}, false, true)
+		require.ErrorContains(t, err, "invalid config format")
+	})
+}
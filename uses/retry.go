@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// DefaultRetryStatusCodes are the HTTP status codes retried when a RetryPolicy does not set any
+var DefaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy configures how requests made by remote fetchers are retried
+//
+// Only idempotent requests (GET, HEAD and OPTIONS) are retried, upon a network error or a retryable status code
+type RetryPolicy struct {
+	// Maximum number of retries after the first attempt, zero disables retries
+	Attempts int
+	// Delay before the first retry, doubled after every retry
+	Backoff time.Duration
+	// Maximum delay between retries, zero for no maximum
+	MaxBackoff time.Duration
+	// HTTP status codes to retry, defaults to DefaultRetryStatusCodes
+	StatusCodes []int
+}
+
+// WithRetry sets the retry policy for requests made by remote fetchers (http, pkg and oci)
+func WithRetry(policy RetryPolicy) FetcherServiceOption {
+	return func(s *FetcherService) {
+		s.retry = policy
+	}
+}
+
+// retryTransport is an http.RoundTripper that retries failed requests according to a RetryPolicy
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if attempt >= t.policy.Attempts || !t.retryable(req, resp, err) {
+			return resp, err
+		}
+
+		delay := t.delay(attempt, resp)
+
+		logger := log.FromContext(ctx)
+		if err != nil {
+			logger.Debug("retrying request", "url", req.URL, "err", err, "attempt", attempt+1, "delay", delay)
+		} else {
+			logger.Debug("retrying request", "url", req.URL, "status", resp.StatusCode, "attempt", attempt+1, "delay", delay)
+			// drain the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryable returns whether a request should be retried given its response or error
+func (t *retryTransport) retryable(req *http.Request, resp *http.Response, err error) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		return false
+	}
+
+	if err != nil {
+		return req.Context().Err() == nil
+	}
+
+	codes := t.policy.StatusCodes
+	if len(codes) == 0 {
+		codes = DefaultRetryStatusCodes
+	}
+	return slices.Contains(codes, resp.StatusCode)
+}
+
+// delay returns how long to wait before the next attempt, respecting the server's Retry-After header (in seconds) if set
+func (t *retryTransport) delay(attempt int, resp *http.Response) time.Duration {
+	delay := t.policy.Backoff
+	for range attempt {
+		if delay > math.MaxInt64/2 || (t.policy.MaxBackoff > 0 && delay >= t.policy.MaxBackoff) {
+			break
+		}
+		delay *= 2
+	}
+
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			delay = time.Duration(seconds) * time.Second
+		}
+	}
+
+	if t.policy.MaxBackoff > 0 && delay > t.policy.MaxBackoff {
+		delay = t.policy.MaxBackoff
+	}
+	return delay
+}
+
+// withRetry returns a shallow copy of the client whose transport retries failed requests
+func withRetry(client *http.Client, policy RetryPolicy) *http.Client {
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	clone := *client
+	clone.Transport = &retryTransport{
+		base:   base,
+		policy: policy,
+	}
+	return &clone
+}
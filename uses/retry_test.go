@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+func TestRetryTransport(t *testing.T) {
+	testCases := []struct {
+		name          string
+		policy        RetryPolicy
+		method        string
+		statuses      []int
+		expectedCalls int32
+		expectedCode  int
+	}{
+		{
+			name:          "succeeds after retries",
+			policy:        RetryPolicy{Attempts: 3, Backoff: time.Millisecond},
+			statuses:      []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+			expectedCalls: 3,
+			expectedCode:  http.StatusOK,
+		},
+		{
+			name:          "gives up after attempts",
+			policy:        RetryPolicy{Attempts: 2, Backoff: time.Millisecond},
+			statuses:      []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK},
+			expectedCalls: 3,
+			expectedCode:  http.StatusBadGateway,
+		},
+		{
+			name:          "status not retried",
+			policy:        RetryPolicy{Attempts: 3, Backoff: time.Millisecond},
+			statuses:      []int{http.StatusNotFound, http.StatusOK},
+			expectedCalls: 1,
+			expectedCode:  http.StatusNotFound,
+		},
+		{
+			name:          "custom status codes",
+			policy:        RetryPolicy{Attempts: 3, Backoff: time.Millisecond, StatusCodes: []int{http.StatusInternalServerError}},
+			statuses:      []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK},
+			expectedCalls: 2,
+			expectedCode:  http.StatusBadGateway,
+		},
+		{
+			name:          "non-idempotent method not retried",
+			policy:        RetryPolicy{Attempts: 3, Backoff: time.Millisecond},
+			method:        http.MethodPost,
+			statuses:      []int{http.StatusBadGateway, http.StatusOK},
+			expectedCalls: 1,
+			expectedCode:  http.StatusBadGateway,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				n := calls.Add(1)
+				w.WriteHeader(tc.statuses[n-1])
+			}))
+			t.Cleanup(server.Close)
+
+			client := withRetry(&http.Client{}, tc.policy)
+
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req, err := http.NewRequestWithContext(t.Context(), method, server.URL, nil)
+			require.NoError(t, err)
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+			assert.Equal(t, tc.expectedCalls, calls.Load())
+		})
+	}
+
+	t.Run("network errors", func(t *testing.T) {
+		var calls atomic.Int32
+		transport := &retryTransport{
+			base: roundTripFunc(func(_ *http.Request) (*http.Response, error) {
+				if calls.Add(1) < 3 {
+					return nil, errors.New("connection reset")
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}, nil
+			}),
+			policy: RetryPolicy{Attempts: 3, Backoff: time.Millisecond},
+		}
+
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "https://example.com", nil)
+		require.NoError(t, err)
+		resp, err := transport.RoundTrip(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("cancelled while waiting", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		transport := &retryTransport{
+			base: roundTripFunc(func(_ *http.Request) (*http.Response, error) {
+				cancel()
+				return &http.Response{StatusCode: http.StatusBadGateway, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}, nil
+			}),
+			policy: RetryPolicy{Attempts: 3, Backoff: time.Hour},
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com", nil)
+		require.NoError(t, err)
+		_, err = transport.RoundTrip(req) //nolint:bodyclose // the response is nil
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("delay", func(t *testing.T) {
+		transport := &retryTransport{policy: RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}}
+
+		assert.Equal(t, time.Second, transport.delay(0, nil))
+		assert.Equal(t, 4*time.Second, transport.delay(2, nil))
+		assert.Equal(t, 5*time.Second, transport.delay(3, nil))
+		assert.Equal(t, 5*time.Second, transport.delay(100, nil))
+		assert.Equal(t, 2*time.Second, transport.delay(0, &http.Response{Header: http.Header{"Retry-After": []string{"2"}}}))
+		assert.Equal(t, 5*time.Second, transport.delay(0, &http.Response{Header: http.Header{"Retry-After": []string{"60"}}}))
+		assert.Equal(t, time.Second, transport.delay(0, &http.Response{Header: http.Header{"Retry-After": []string{"soon"}}}))
+	})
+
+	t.Run("preserved by the fetcher service and oci client", func(t *testing.T) {
+		svc, err := NewFetcherService(
+			WithRetry(RetryPolicy{Attempts: 2}),
+			WithHeaders(HostHeaders{"example.com": http.Header{}}),
+		)
+		require.NoError(t, err)
+
+		headers, ok := svc.client.Transport.(*headerTransport)
+		require.True(t, ok)
+		assert.IsType(t, &retryTransport{}, headers.base)
+
+		oci, err := NewOCIClient(svc.client, true, false)
+		require.NoError(t, err)
+
+		authClient, ok := oci.client.(*auth.Client)
+		require.True(t, ok)
+		headers, ok = authClient.Client.Transport.(*headerTransport)
+		require.True(t, ok)
+		rt, ok := headers.base.(*retryTransport)
+		require.True(t, ok)
+		assert.Equal(t, 2, rt.policy.Attempts)
+		transport, ok := rt.base.(*http.Transport)
+		require.True(t, ok)
+		assert.True(t, transport.TLSClientConfig.InsecureSkipVerify)
+	})
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
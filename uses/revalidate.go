@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"time"
+)
+
+// ErrNotModified is returned by a ConditionalFetcher when the workflow has not changed since it was stored
+var ErrNotModified = errors.New("not modified")
+
+// Validators are the HTTP cache validators of a fetched workflow, used to revalidate it with conditional requests
+type Validators struct {
+	// ETag response header, sent as If-None-Match
+	ETag string
+	// Last-Modified response header, sent as If-Modified-Since
+	LastModified time.Time
+}
+
+// IsZero reports whether no validators are set
+func (v Validators) IsZero() bool {
+	return v.ETag == "" && v.LastModified.IsZero()
+}
+
+// ConditionalFetcher is a Fetcher that can skip downloading a workflow that has not changed
+type ConditionalFetcher interface {
+	Fetcher
+	// FetchIfModified fetches the workflow unless it is unchanged since prev was recorded, in which case ErrNotModified is returned
+	//
+	// The returned validators are for the fetched workflow, or the unchanged workflow when ErrNotModified is returned
+	FetchIfModified(ctx context.Context, uri *url.URL, prev Validators) (io.ReadCloser, Validators, error)
+}
+
+// ValidatedStorage is a Storage that records the cache validators of stored workflows
+type ValidatedStorage interface {
+	Storage
+	// Describe returns the descriptor of a stored workflow
+	Describe(uri *url.URL) (Descriptor, bool)
+	// StoreValidated stores a workflow along with its validators
+	StoreValidated(r io.Reader, uri *url.URL, v Validators) error
+	// Revalidated records that a stored workflow is unchanged at its source
+	Revalidated(uri *url.URL, v Validators) error
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/url"
+	"sync"
+)
+
+// flight is a fetch that is in progress or has completed
+type flight struct {
+	done    chan struct{}
+	waiters int
+	data    []byte
+	err     error
+}
+
+// flightGroup deduplicates concurrent fetches of the same URI
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// join returns the in progress flight for key, or starts a new one if there is none
+//
+// The caller that starts a flight must call land once its fetch returns
+func (g *flightGroup) join(key string) (f *flight, leader bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.flights == nil {
+		g.flights = make(map[string]*flight)
+	}
+	if f, ok := g.flights[key]; ok {
+		f.waiters++
+		return f, false
+	}
+	f = &flight{done: make(chan struct{})}
+	g.flights[key] = f
+	return f, true
+}
+
+// land stops new callers from joining a flight, returning how many callers are waiting on its result
+//
+// Only concurrent fetches are deduplicated, later fetches follow the fetch policy as usual
+func (g *flightGroup) land(key string, f *flight) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	delete(g.flights, key)
+	return f.waiters
+}
+
+// SingleFlightFetcher is a fetcher that deduplicates concurrent fetches of the same URI
+//
+// Only one fetch is made to the source, the other callers wait for and share its result.
+// As the result is shared, a fetch cancelled by the first caller's context fails for every caller.
+// The result is only buffered in memory when there are callers to share it with, otherwise it is streamed
+type SingleFlightFetcher struct {
+	Source Fetcher
+
+	group flightGroup
+}
+
+// Fetch implements the Fetcher interface
+func (f *SingleFlightFetcher) Fetch(ctx context.Context, uri *url.URL) (io.ReadCloser, error) {
+	key := uri.String()
+
+	fl, leader := f.group.join(key)
+	if !leader {
+		<-fl.done
+		if fl.err != nil {
+			return nil, fl.err
+		}
+		return io.NopCloser(bytes.NewReader(fl.data)), nil
+	}
+	defer close(fl.done)
+
+	rc, err := f.Source.Fetch(ctx, uri)
+	waiters := f.group.land(key, fl)
+	if err != nil {
+		fl.err = err
+		return nil, err
+	}
+	if waiters == 0 {
+		return rc, nil
+	}
+	defer rc.Close()
+
+	fl.data, fl.err = io.ReadAll(rc)
+	if fl.err != nil {
+		return nil, fl.err
+	}
+	return io.NopCloser(bytes.NewReader(fl.data)), nil
+}
@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSingleFlightFetcher(t *testing.T) {
+	uri, err := url.Parse("https://example.com/tasks.yaml")
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		var calls atomic.Int32
+		f := &SingleFlightFetcher{Source: &mockFetcher{
+			fetchFunc: func(_ context.Context, _ *url.URL) (io.ReadCloser, error) {
+				calls.Add(1)
+				return io.NopCloser(strings.NewReader("content")), nil
+			},
+		}}
+
+		for range 2 {
+			rc, err := f.Fetch(t.Context(), uri)
+			require.NoError(t, err)
+			b, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			assert.Equal(t, "content", string(b))
+			require.NoError(t, rc.Close())
+		}
+		// sequential fetches are not deduplicated
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		f := &SingleFlightFetcher{Source: &mockFetcher{
+			fetchFunc: func(_ context.Context, _ *url.URL) (io.ReadCloser, error) {
+				return nil, errors.New("boom")
+			},
+		}}
+
+		rc, err := f.Fetch(t.Context(), uri)
+		require.EqualError(t, err, "boom")
+		assert.Nil(t, rc)
+	})
+}
+
+func TestSingleFlightFetcherStreams(t *testing.T) {
+	uri, err := url.Parse("https://example.com/tasks.yaml")
+	require.NoError(t, err)
+
+	source := io.NopCloser(strings.NewReader("content"))
+	f := &SingleFlightFetcher{Source: &mockFetcher{
+		fetchFunc: func(_ context.Context, _ *url.URL) (io.ReadCloser, error) {
+			return source, nil
+		},
+	}}
+
+	// without any other callers to share with, the source is returned as-is rather than buffered
+	rc, err := f.Fetch(t.Context(), uri)
+	require.NoError(t, err)
+	assert.Equal(t, source, rc)
+	assert.Empty(t, f.group.flights)
+}
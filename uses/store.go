@@ -5,7 +5,6 @@ package uses
 
 import (
 	"bufio"
-	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
@@ -13,6 +12,7 @@ import (
 	"fmt"
 	"io"
 	"iter"
+	"maps"
 	"net/url"
 	"os"
 	"regexp"
@@ -20,6 +20,8 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
+	"unicode"
 
 	"github.com/spf13/afero"
 )
@@ -31,11 +33,21 @@ var DigestPattern = regexp.MustCompile(`^h1:([a-fA-F0-9]{64})$`)
 type Descriptor struct {
 	Size int64
 	Hex  string
+	// When the workflow was stored, zero if unknown (e.g. stored by an older version)
+	Fetched time.Time
+	// Cache validators returned by the source, used by the refresh fetch policy
+	Validators
 }
 
 // IndexFileName is the name of the index file.
 const IndexFileName = "index.txt"
 
+// tempSuffix is the suffix of files being written to the store
+const tempSuffix = ".tmp"
+
+// staleTempAge is how long a file can be written to before GC considers it abandoned
+const staleTempAge = time.Hour
+
 // Storage interface for storing and retrieving cached remote workflows.
 type Storage interface {
 	Fetcher
@@ -45,11 +57,17 @@ type Storage interface {
 }
 
 // LocalStore is a cache for storing and retrieving cached remote workflows from a filesystem.
+//
+// When backed by the OS filesystem, an advisory lock on the index file is held while reading and writing the store,
+// so multiple processes can safely share it. Writes reload the index first, to keep entries stored by other processes
 type LocalStore struct {
 	index map[string]Descriptor
 
 	fsys afero.Fs
 
+	// now returns the current time, defaults to time.Now
+	now func() time.Time
+
 	mu sync.RWMutex
 }
 
@@ -58,24 +76,15 @@ type LocalStore struct {
 // Initializes or loads an existing cache with integrity checking.
 // The index.txt file tracks cached workflows with SHA256 digests
 func NewLocalStore(fsys afero.Fs) (*LocalStore, error) {
-	index := make(map[string]Descriptor, 0)
-
-	_, err := fsys.Stat(IndexFileName)
-	if os.IsNotExist(err) {
-		f, err := fsys.Create(IndexFileName)
-		if err != nil {
-			return nil, err
-		}
-		defer f.Close()
-
-		return &LocalStore{
-			fsys:  fsys,
-			index: index,
-		}, nil
+	s := &LocalStore{
+		fsys: fsys,
 	}
+
+	unlock, err := s.lock(false)
 	if err != nil {
 		return nil, err
 	}
+	defer unlock()
 
 	f, err := fsys.Open(IndexFileName)
 	if err != nil {
@@ -83,20 +92,18 @@ func NewLocalStore(fsys afero.Fs) (*LocalStore, error) {
 	}
 	defer f.Close()
 
-	index, err = ParseIndex(f)
+	s.index, err = ParseIndex(f)
 	if err != nil {
 		return nil, err
 	}
 
-	return &LocalStore{
-		fsys:  fsys,
-		index: index,
-	}, nil
+	return s, nil
 }
 
 // ParseIndex reads and validates cache index entries
 //
-// Each line format: <url> h1:<sha256-hex> <size-bytes>
+// Each line format: <url> h1:<sha256-hex> <size-bytes> [key=value...]
+// Known keys are "fetched" and "last-modified" (RFC 3339 timestamps) and "etag", unknown keys are ignored.
 // Returns a map of URLs to their descriptors for cache lookups
 func ParseIndex(r io.Reader) (map[string]Descriptor, error) {
 	index := make(map[string]Descriptor, 0)
@@ -109,10 +116,30 @@ func ParseIndex(r io.Reader) (map[string]Descriptor, error) {
 		}
 		var desc Descriptor
 		fields := strings.Fields(line)
-		if len(fields) != 3 {
+		if len(fields) < 3 {
 			return nil, fmt.Errorf("invalid line format")
 		}
 		var err error
+		for _, field := range fields[3:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid line format")
+			}
+			switch key {
+			case "fetched":
+				desc.Fetched, err = time.Parse(time.RFC3339, value)
+				if err != nil {
+					return nil, err
+				}
+			case "last-modified":
+				desc.LastModified, err = time.Parse(time.RFC3339, value)
+				if err != nil {
+					return nil, err
+				}
+			case "etag":
+				desc.ETag = value
+			}
+		}
 		desc.Size, err = strconv.ParseInt(fields[2], 10, 64)
 		if err != nil {
 			return nil, err
@@ -136,8 +163,19 @@ func ParseIndex(r io.Reader) (map[string]Descriptor, error) {
 
 // Fetch retrieves a workflow from the store
 func (s *LocalStore) Fetch(_ context.Context, uri *url.URL) (io.ReadCloser, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	// not a read lock, as the index is reloaded in case another process changed it
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
 
 	desc, ok := s.index[s.id(uri)]
 	if !ok {
@@ -153,31 +191,114 @@ func (s *LocalStore) Fetch(_ context.Context, uri *url.URL) (io.ReadCloser, erro
 }
 
 // Store a workflow in the store.
-func (s *LocalStore) Store(rc io.Reader, uri *url.URL) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+//
+// The workflow is streamed to a temporary file while it is hashed, so it is never held in memory
+// and the store is not locked while reading from r
+func (s *LocalStore) Store(r io.Reader, uri *url.URL) error {
+	return s.StoreValidated(r, uri, Validators{})
+}
+
+// StoreValidated stores a workflow in the store along with its cache validators
+func (s *LocalStore) StoreValidated(r io.Reader, uri *url.URL, v Validators) error {
+	tmp, err := afero.TempFile(s.fsys, ".", "*"+tempSuffix)
+	if err != nil {
+		return err
+	}
+	defer s.fsys.Remove(tmp.Name())
 
 	hasher := sha256.New()
 
-	var buf bytes.Buffer
+	size, err := io.Copy(io.MultiWriter(hasher, tmp), r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	encoded := hex.EncodeToString(hasher.Sum(nil))
 
-	mw := io.MultiWriter(hasher, &buf)
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	if _, err := io.Copy(mw, rc); err != nil {
+	unlock, err := s.lock(true)
+	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	encoded := hex.EncodeToString(hasher.Sum(nil))
+	if err := s.reload(); err != nil {
+		return err
+	}
 
-	if err := afero.WriteFile(s.fsys, encoded, buf.Bytes(), 0o644); err != nil {
+	if err := s.fsys.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	// renamed rather than written in place, so readers never see a partial write
+	if err := s.fsys.Rename(tmp.Name(), encoded); err != nil {
 		return err
 	}
 
 	s.index[s.id(uri)] = Descriptor{
-		Size: int64(buf.Len()),
-		Hex:  encoded,
+		Size:       size,
+		Hex:        encoded,
+		Fetched:    s.clock().UTC().Truncate(time.Second),
+		Validators: indexable(v),
+	}
+
+	return s.writeIndex()
+}
+
+// Revalidated records that a stored workflow is unchanged at its source, updating when it was fetched and its validators
+func (s *LocalStore) Revalidated(uri *url.URL, v Validators) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := s.reload(); err != nil {
+		return err
+	}
+
+	id := s.id(uri)
+	desc, ok := s.index[id]
+	if !ok {
+		return fmt.Errorf("%s is not in the store", id)
+	}
+	desc.Fetched = s.clock().UTC().Truncate(time.Second)
+	if !v.IsZero() {
+		desc.Validators = indexable(v)
+	}
+	s.index[id] = desc
+
+	return s.writeIndex()
+}
+
+// Describe returns the descriptor of a stored workflow
+func (s *LocalStore) Describe(uri *url.URL) (Descriptor, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	desc, ok := s.index[s.id(uri)]
+	return desc, ok
+}
+
+// indexable drops validators that cannot be written to the index
+func indexable(v Validators) Validators {
+	// ETags are quoted strings without whitespace, anything else is malformed
+	if strings.ContainsFunc(v.ETag, unicode.IsSpace) {
+		v.ETag = ""
 	}
+	v.LastModified = v.LastModified.UTC().Truncate(time.Second)
+	return v
+}
 
+// writeIndex writes the index to the filesystem, the caller must hold the lock
+func (s *LocalStore) writeIndex() error {
 	keys := make([]string, 0, len(s.index))
 	for key := range s.index {
 		keys = append(keys, key)
@@ -187,16 +308,100 @@ func (s *LocalStore) Store(rc io.Reader, uri *url.URL) error {
 	var b []byte
 	for _, key := range keys {
 		desc := s.index[key]
-		b = fmt.Appendf(b, "%s h1:%s %d\n", key, desc.Hex, desc.Size)
+		b = fmt.Appendf(b, "%s h1:%s %d", key, desc.Hex, desc.Size)
+		if !desc.Fetched.IsZero() {
+			b = fmt.Appendf(b, " fetched=%s", desc.Fetched.Format(time.RFC3339))
+		}
+		if desc.ETag != "" {
+			b = fmt.Appendf(b, " etag=%s", desc.ETag)
+		}
+		if !desc.LastModified.IsZero() {
+			b = fmt.Appendf(b, " last-modified=%s", desc.LastModified.Format(time.RFC3339))
+		}
+		b = append(b, '\n')
 	}
 
+	// written in place rather than renamed over, as the lock is held on the index file itself
 	return afero.WriteFile(s.fsys, IndexFileName, b, 0o644)
 }
 
+// reload replaces the index with the one on the filesystem, which may have been written by another process
+//
+// The caller must hold the lock
+func (s *LocalStore) reload() error {
+	f, err := s.fsys.Open(IndexFileName)
+	if os.IsNotExist(err) {
+		s.index = make(map[string]Descriptor, 0)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	index, err := ParseIndex(f)
+	if err != nil {
+		return err
+	}
+	s.index = index
+	return nil
+}
+
+// lock acquires an advisory lock on the index file, shared or exclusive, and returns a function to release it
+//
+// The index file is created if it does not exist. Locking between processes is only possible
+// when the store is backed by the OS filesystem
+func (s *LocalStore) lock(exclusive bool) (func(), error) {
+	f, err := s.fsys.OpenFile(IndexFileName, os.O_RDONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	osf, ok := osFile(f)
+	if !ok {
+		return func() { f.Close() }, nil
+	}
+
+	if err := flock(osf, exclusive); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("unable to lock %s: %w", IndexFileName, err)
+	}
+
+	return func() {
+		_ = funlock(osf)
+		f.Close()
+	}, nil
+}
+
+// osFile returns the underlying *os.File of a file opened from an afero.OsFs, or an afero.BasePathFs over one
+func osFile(f afero.File) (*os.File, bool) {
+	for {
+		switch v := f.(type) {
+		case *os.File:
+			return v, true
+		case *afero.BasePathFile:
+			f = v.File
+		default:
+			return nil, false
+		}
+	}
+}
+
 // Exists checks if a workflow exists in the store.
 func (s *LocalStore) Exists(uri *url.URL) (bool, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	// not a read lock, as the index is reloaded in case another process changed it
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lock(false)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	if err := s.reload(); err != nil {
+		return false, err
+	}
 
 	desc, ok := s.index[s.id(uri)]
 	if !ok {
@@ -234,13 +439,39 @@ func (s *LocalStore) Exists(uri *url.URL) (bool, error) {
 	return true, nil
 }
 
+// Remove deletes a workflow from the store's index
+//
+// The workflow's file is left in place until the next GC, as it may be shared with other workflows
+func (s *LocalStore) Remove(uri *url.URL) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := s.reload(); err != nil {
+		return err
+	}
+
+	id := s.id(uri)
+	if _, ok := s.index[id]; !ok {
+		return fmt.Errorf("%s is not in the store", id)
+	}
+	delete(s.index, id)
+
+	return s.writeIndex()
+}
+
 // List returns a Go 1.23+ iterator to loop over all of the stored workflows
 //
 // ok but does this really need to be an iterator, no
 // ill prob move it to a regular map access w/ maps.Copy, but this was still fun
 func (s *LocalStore) List() iter.Seq2[string, Descriptor] {
 	return func(yield func(string, Descriptor) bool) {
-		for k, v := range s.index {
+		for k, v := range s.entries() {
 			if !yield(k, v) {
 				return
 			}
@@ -248,11 +479,73 @@ func (s *LocalStore) List() iter.Seq2[string, Descriptor] {
 	}
 }
 
+// entries returns a copy of the index, reloaded in case another process changed it
+//
+// The index already loaded is returned if it cannot be reloaded
+func (s *LocalStore) entries() map[string]Descriptor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if unlock, err := s.lock(false); err == nil {
+		_ = s.reload()
+		unlock()
+	}
+
+	return maps.Clone(s.index)
+}
+
+// GCOption configures garbage collection of a LocalStore
+type GCOption func(*gcOptions)
+
+type gcOptions struct {
+	maxAge  time.Duration
+	maxSize int64
+}
+
+// WithMaxAge evicts workflows that were stored longer than the given duration ago
+//
+// Workflows without a stored time (stored by an older version of maru2) are always evicted
+func WithMaxAge(age time.Duration) GCOption {
+	return func(o *gcOptions) {
+		o.maxAge = age
+	}
+}
+
+// WithMaxSize evicts the least recently stored workflows until the store is at most the given number of bytes
+func WithMaxSize(size int64) GCOption {
+	return func(o *gcOptions) {
+		o.maxSize = size
+	}
+}
+
 // GC performs garbage collection on the store.
-func (s *LocalStore) GC() error {
+//
+// Files no longer referenced by the index are always removed, workflows are only evicted from the index given GCOptions
+func (s *LocalStore) GC(opts ...GCOption) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	unlock, err := s.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := s.reload(); err != nil {
+		return err
+	}
+
+	var o gcOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if evicted := s.evict(o); evicted {
+		if err := s.writeIndex(); err != nil {
+			return err
+		}
+	}
+
 	all, err := afero.ReadDir(s.fsys, ".")
 	if err != nil {
 		return err
@@ -260,7 +553,12 @@ func (s *LocalStore) GC() error {
 
 outer:
 	for _, fi := range all {
-		if fi.IsDir() || fi.Name() == "index.txt" {
+		if fi.IsDir() || fi.Name() == IndexFileName {
+			continue
+		}
+
+		// files still being written are stored without holding the lock, so are not yet in the index
+		if strings.HasSuffix(fi.Name(), tempSuffix) && s.clock().Sub(fi.ModTime()) < staleTempAge {
 			continue
 		}
 
@@ -277,6 +575,67 @@ outer:
 	return nil
 }
 
+// evict removes workflows from the index according to the GC options, returning whether any were removed
+//
+// The caller must hold the lock
+func (s *LocalStore) evict(o gcOptions) bool {
+	evicted := false
+
+	if o.maxAge > 0 {
+		cutoff := s.clock().Add(-o.maxAge)
+		for key, desc := range s.index {
+			if desc.Fetched.Before(cutoff) {
+				delete(s.index, key)
+				evicted = true
+			}
+		}
+	}
+
+	if o.maxSize > 0 {
+		// workflows with the same content share a file, so sizes are counted once per file
+		size := func() int64 {
+			var total int64
+			seen := make(map[string]bool, len(s.index))
+			for _, desc := range s.index {
+				if !seen[desc.Hex] {
+					seen[desc.Hex] = true
+					total += desc.Size
+				}
+			}
+			return total
+		}
+
+		keys := make([]string, 0, len(s.index))
+		for key := range s.index {
+			keys = append(keys, key)
+		}
+		slices.SortFunc(keys, func(a, b string) int {
+			if c := s.index[a].Fetched.Compare(s.index[b].Fetched); c != 0 {
+				return c
+			}
+			return strings.Compare(a, b)
+		})
+
+		for _, key := range keys {
+			if size() <= o.maxSize {
+				break
+			}
+			delete(s.index, key)
+			evicted = true
+		}
+	}
+
+	return evicted
+}
+
+// clock returns the current time
+func (s *LocalStore) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
 func (s *LocalStore) id(uri *url.URL) string {
 	clone := *uri
 	clone.RawQuery = ""
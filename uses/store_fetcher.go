@@ -5,6 +5,7 @@ package uses
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/url"
@@ -18,12 +19,33 @@ type StoreFetcher struct {
 	Policy FetchPolicy
 }
 
+type fetchResultKey struct{}
+
+// FetchResult describes how a StoreFetcher served a fetch
+type FetchResult struct {
+	// Whether the workflow was read from the store instead of downloaded from its source
+	Cached bool
+}
+
+// WithFetchResult returns a context that records how fetches made with it are served into r
+func WithFetchResult(ctx context.Context, r *FetchResult) context.Context {
+	return context.WithValue(ctx, fetchResultKey{}, r)
+}
+
+// fetchedFromStore records that a fetch was served from the store
+func fetchedFromStore(ctx context.Context) {
+	if r, ok := ctx.Value(fetchResultKey{}).(*FetchResult); ok && r != nil {
+		r.Cached = true
+	}
+}
+
 // Fetch implements the Fetcher interface
 //
 // This is one of my favorite functions
 func (f *StoreFetcher) Fetch(ctx context.Context, uri *url.URL) (io.ReadCloser, error) {
 	switch f.Policy {
 	case FetchPolicyNever:
+		fetchedFromStore(ctx)
 		return f.Store.Fetch(ctx, uri)
 	case FetchPolicyIfNotPresent:
 		exists, err := f.Store.Exists(uri)
@@ -31,9 +53,17 @@ func (f *StoreFetcher) Fetch(ctx context.Context, uri *url.URL) (io.ReadCloser,
 			return nil, err
 		}
 		if exists {
+			fetchedFromStore(ctx)
 			return f.Store.Fetch(ctx, uri)
 		}
 		fallthrough
+	case FetchPolicyRefresh:
+		cf, isConditional := f.Source.(ConditionalFetcher)
+		vs, isValidated := f.Store.(ValidatedStorage)
+		if isConditional && isValidated {
+			return f.refresh(ctx, cf, vs, uri)
+		}
+		fallthrough
 	case FetchPolicyAlways:
 		rc, err := f.Source.Fetch(ctx, uri)
 		if err != nil {
@@ -50,3 +80,36 @@ func (f *StoreFetcher) Fetch(ctx context.Context, uri *url.URL) (io.ReadCloser,
 		return nil, fmt.Errorf("unsupported fetch policy: %s", f.Policy)
 	}
 }
+
+// refresh revalidates a stored workflow with its source, only downloading it if it has changed
+func (f *StoreFetcher) refresh(ctx context.Context, source ConditionalFetcher, store ValidatedStorage, uri *url.URL) (io.ReadCloser, error) {
+	var prev Validators
+	if desc, ok := store.Describe(uri); ok {
+		exists, err := store.Exists(uri)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			prev = desc.Validators
+		}
+	}
+
+	rc, v, err := source.FetchIfModified(ctx, uri, prev)
+	if errors.Is(err, ErrNotModified) {
+		if err := store.Revalidated(uri, v); err != nil {
+			return nil, err
+		}
+		fetchedFromStore(ctx)
+		return store.Fetch(ctx, uri)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	if err := store.StoreValidated(rc, uri, v); err != nil {
+		return nil, err
+	}
+
+	return store.Fetch(ctx, uri)
+}
@@ -5,14 +5,18 @@ package uses
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
 	"iter"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
 
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -359,3 +363,74 @@ func TestStoreFetcher(t *testing.T) {
 		})
 	}
 }
+
+func TestStoreFetcherRefresh(t *testing.T) {
+	content := "schema-version: v1\n"
+	var downloads, revalidations int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", fmt.Sprintf("%q", fmt.Sprintf("%x", sha256.Sum256([]byte(content)))))
+		if r.Header.Get("If-None-Match") == w.Header().Get("ETag") {
+			revalidations++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		_, _ = w.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+
+	store, err := NewLocalStore(afero.NewMemMapFs())
+	require.NoError(t, err)
+
+	fetcher := &StoreFetcher{
+		Source: NewHTTPClient(server.Client()),
+		Store:  store,
+		Policy: FetchPolicyRefresh,
+	}
+
+	uri, err := url.Parse(server.URL + "/tasks.yaml")
+	require.NoError(t, err)
+
+	var cached bool
+	fetch := func() string {
+		t.Helper()
+		var result FetchResult
+		rc, err := fetcher.Fetch(WithFetchResult(t.Context(), &result), uri)
+		cached = result.Cached
+		require.NoError(t, err)
+		defer rc.Close()
+		b, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	// not stored, downloaded
+	assert.Equal(t, content, fetch())
+	assert.Equal(t, 1, downloads)
+	assert.False(t, cached)
+	desc, ok := store.Describe(uri)
+	require.True(t, ok)
+	assert.NotEmpty(t, desc.ETag)
+
+	// unchanged, revalidated
+	assert.Equal(t, content, fetch())
+	assert.Equal(t, 1, downloads)
+	assert.Equal(t, 1, revalidations)
+	assert.True(t, cached)
+
+	// changed, downloaded
+	content = "schema-version: v1\ntasks: {}\n"
+	assert.Equal(t, content, fetch())
+	assert.Equal(t, 2, downloads)
+	assert.Equal(t, 1, revalidations)
+	assert.False(t, cached)
+
+	// sources that do not support conditional requests are always fetched
+	source := &mockFetcher{fetchFunc: func(_ context.Context, _ *url.URL) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("from source")), nil
+	}}
+	fetcher.Source = source
+	assert.Equal(t, "from source", fetch())
+	assert.Equal(t, "from source", fetch())
+	assert.Equal(t, 2, source.fetchCalls)
+}
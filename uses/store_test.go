@@ -7,12 +7,18 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"io"
 	"maps"
 	"net/url"
 	"os"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
@@ -112,11 +118,11 @@ func TestLocalStoreFetch(t *testing.T) {
 			index: map[string]Descriptor{
 				"https://example.com/workflow": {
 					Size: 12,
-					Hex:  "1234abcd",
+					Hex:  "1234abcd00000000000000000000000000000000000000000000000000000000",
 				},
 			},
 			files: map[string][]byte{
-				"1234abcd": []byte("hello world!"),
+				"1234abcd00000000000000000000000000000000000000000000000000000000": []byte("hello world!"),
 			},
 			uri:      "https://example.com/workflow",
 			expected: "hello world!",
@@ -126,11 +132,11 @@ func TestLocalStoreFetch(t *testing.T) {
 			index: map[string]Descriptor{
 				"https://example.com/workflow": {
 					Size: 12,
-					Hex:  "1234abcd",
+					Hex:  "1234abcd00000000000000000000000000000000000000000000000000000000",
 				},
 			},
 			files: map[string][]byte{
-				"1234abcd": []byte("hello world!"),
+				"1234abcd00000000000000000000000000000000000000000000000000000000": []byte("hello world!"),
 			},
 			uri:      "https://example.com/workflow?param=value",
 			expected: "hello world!",
@@ -146,12 +152,12 @@ func TestLocalStoreFetch(t *testing.T) {
 			index: map[string]Descriptor{
 				"https://example.com/workflow": {
 					Size: 12,
-					Hex:  "1234abcd",
+					Hex:  "1234abcd00000000000000000000000000000000000000000000000000000000",
 				},
 			},
 			files:       map[string][]byte{},
 			uri:         "https://example.com/workflow",
-			expectedErr: "open 1234abcd: file does not exist",
+			expectedErr: "open 1234abcd00000000000000000000000000000000000000000000000000000000: file does not exist",
 		},
 	}
 
@@ -163,6 +169,8 @@ func TestLocalStoreFetch(t *testing.T) {
 				index: tc.index,
 				fsys:  fs,
 			}
+			// the index is reloaded from the filesystem
+			require.NoError(t, store.writeIndex())
 
 			for name, content := range tc.files {
 				err := afero.WriteFile(fs, name, content, 0o644)
@@ -237,7 +245,7 @@ func TestLocalStoreStore(t *testing.T) {
 			initialIndex: map[string]Descriptor{
 				"https://example.com/workflow": {
 					Size: 12,
-					Hex:  "old_hash",
+					Hex:  "0000000000000000000000000000000000000000000000000000000000000000",
 				},
 			},
 			uri:     "https://example.com/workflow",
@@ -247,7 +255,7 @@ func TestLocalStoreStore(t *testing.T) {
 				desc := s.index["https://example.com/workflow"]
 				assert.Equal(t, int64(15), desc.Size)
 				assert.Equal(t, contentHex, desc.Hex)
-				assert.NotEqual(t, "old_hash", desc.Hex)
+				assert.NotEqual(t, "0000000000000000000000000000000000000000000000000000000000000000", desc.Hex)
 			},
 		},
 	}
@@ -261,7 +269,7 @@ func TestLocalStoreStore(t *testing.T) {
 				fsys:  fs,
 			}
 
-			err := afero.WriteFile(fs, IndexFileName, []byte("{}"), 0o644)
+			err := store.writeIndex()
 			require.NoError(t, err)
 
 			uri, err := url.Parse(tc.uri)
@@ -337,23 +345,23 @@ func TestLocalStoreExists(t *testing.T) {
 			index: map[string]Descriptor{
 				"https://example.com/workflow": {
 					Size: 12,
-					Hex:  "1234abcd",
+					Hex:  "1234abcd00000000000000000000000000000000000000000000000000000000",
 				},
 			},
 			files:       map[string]string{},
 			uri:         "https://example.com/workflow",
-			expectedErr: "descriptor exists in index, but no corresponding file was found, possible cache corruption: 1234abcd",
+			expectedErr: "descriptor exists in index, but no corresponding file was found, possible cache corruption: 1234abcd00000000000000000000000000000000000000000000000000000000",
 		},
 		{
 			name: "size mismatch",
 			index: map[string]Descriptor{
 				"https://example.com/workflow": {
 					Size: 20, // Wrong size
-					Hex:  "1234abcd",
+					Hex:  "1234abcd00000000000000000000000000000000000000000000000000000000",
 				},
 			},
 			files: map[string]string{
-				"1234abcd": "hello world!", // Actual size is 12
+				"1234abcd00000000000000000000000000000000000000000000000000000000": "hello world!", // Actual size is 12
 			},
 			uri:         "https://example.com/workflow",
 			expectedErr: "size mismatch, expected 20, got 12",
@@ -363,11 +371,11 @@ func TestLocalStoreExists(t *testing.T) {
 			index: map[string]Descriptor{
 				"https://example.com/workflow": {
 					Size: 12,
-					Hex:  "wrong_hash", // Wrong hash
+					Hex:  "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", // Wrong hash
 				},
 			},
 			files: map[string]string{
-				"wrong_hash": "hello world!",
+				"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff": "hello world!",
 			},
 			uri:         "https://example.com/workflow",
 			expectedErr: "hash mismatch",
@@ -382,6 +390,8 @@ func TestLocalStoreExists(t *testing.T) {
 				index: tc.index,
 				fsys:  fs,
 			}
+			// the index is reloaded from the filesystem
+			require.NoError(t, store.writeIndex())
 
 			for name, content := range tc.files {
 				err := afero.WriteFile(fs, name, []byte(content), 0o644)
@@ -450,6 +460,22 @@ func TestParseIndex(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:  "entry with fields",
+			input: "https://example.com h1:7509e5bda0c762d2bac7f90d758b5b2263fa01ccbc542ab5e3df163be08e6ca9 10 fetched=2025-01-01T00:00:00Z unknown=ignored\n",
+			expected: map[string]Descriptor{
+				"https://example.com": {
+					Size:    10,
+					Hex:     "7509e5bda0c762d2bac7f90d758b5b2263fa01ccbc542ab5e3df163be08e6ca9",
+					Fetched: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			name:        "invalid fetched time",
+			input:       "https://example.com h1:7509e5bda0c762d2bac7f90d758b5b2263fa01ccbc542ab5e3df163be08e6ca9 10 fetched=yesterday\n",
+			expectedErr: `parsing time "yesterday" as "2006-01-02T15:04:05Z07:00": cannot parse "yesterday" as "2006"`,
+		},
 		{
 			name:        "invalid format - too few fields",
 			input:       "https://example.com h1:7509e5bda0c762d2bac7f90d758b5b2263fa01ccbc542ab5e3df163be08e6ca9\n",
@@ -523,6 +549,7 @@ func TestLocalStoreGC(t *testing.T) {
 	fs := afero.NewMemMapFs()
 	store, err := NewLocalStore(fs)
 	require.NoError(t, err)
+	store.now = func() time.Time { return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) }
 
 	err = store.Store(strings.NewReader("hello world!"), &url.URL{Scheme: "https", Host: "example.com", Path: "/workflow"})
 	require.NoError(t, err)
@@ -533,7 +560,7 @@ func TestLocalStoreGC(t *testing.T) {
 
 	indexContent, err := afero.ReadFile(fs, IndexFileName)
 	require.NoError(t, err)
-	assert.Equal(t, "https://example.com/workflow h1:7509e5bda0c762d2bac7f90d758b5b2263fa01ccbc542ab5e3df163be08e6ca9 12\n", string(indexContent))
+	assert.Equal(t, "https://example.com/workflow h1:7509e5bda0c762d2bac7f90d758b5b2263fa01ccbc542ab5e3df163be08e6ca9 12 fetched=2025-01-01T00:00:00Z\n", string(indexContent))
 
 	err = store.GC()
 	require.NoError(t, err)
@@ -586,8 +613,8 @@ func TestLocalStoreGC(t *testing.T) {
 
 	updatedIndexContent, err := afero.ReadFile(fs, IndexFileName)
 	require.NoError(t, err)
-	assert.Equal(t, `https://example.com/new-workflow h1:fe32608c9ef5b6cf7e3f946480253ff76f24f4ec0678f3d0f07f9844cbff9601 11
-https://example.com/workflow h1:7509e5bda0c762d2bac7f90d758b5b2263fa01ccbc542ab5e3df163be08e6ca9 12
+	assert.Equal(t, `https://example.com/new-workflow h1:fe32608c9ef5b6cf7e3f946480253ff76f24f4ec0678f3d0f07f9844cbff9601 11 fetched=2025-01-01T00:00:00Z
+https://example.com/workflow h1:7509e5bda0c762d2bac7f90d758b5b2263fa01ccbc542ab5e3df163be08e6ca9 12 fetched=2025-01-01T00:00:00Z
 `, string(updatedIndexContent))
 
 	err = store.GC()
@@ -609,8 +636,8 @@ https://example.com/workflow h1:7509e5bda0c762d2bac7f90d758b5b2263fa01ccbc542ab5
 
 	updatedIndexContent, err = afero.ReadFile(fs, IndexFileName)
 	require.NoError(t, err)
-	assert.Equal(t, `https://example.com/new-workflow h1:fe32608c9ef5b6cf7e3f946480253ff76f24f4ec0678f3d0f07f9844cbff9601 11
-https://example.com/workflow h1:187897ce0afcf20b50ba2b37dca84a951b7046f29ed5ab94f010619f69d6e189 4
+	assert.Equal(t, `https://example.com/new-workflow h1:fe32608c9ef5b6cf7e3f946480253ff76f24f4ec0678f3d0f07f9844cbff9601 11 fetched=2025-01-01T00:00:00Z
+https://example.com/workflow h1:187897ce0afcf20b50ba2b37dca84a951b7046f29ed5ab94f010619f69d6e189 4 fetched=2025-01-01T00:00:00Z
 `, string(updatedIndexContent))
 
 	_, err = fs.Stat(wf1)
@@ -688,4 +715,326 @@ https://github.com/owner/repo h1:187897ce0afcf20b50ba2b37dca84a951b7046f29ed5ab9
 		assert.Len(t, items, 2)
 		assert.True(t, count <= 2)
 	})
+
+	t.Run("entries stored by another store", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+
+		store, err := NewLocalStore(fs)
+		require.NoError(t, err)
+		other, err := NewLocalStore(fs)
+		require.NoError(t, err)
+
+		uri, err := url.Parse("https://example.com/workflow")
+		require.NoError(t, err)
+		require.NoError(t, other.Store(strings.NewReader("echo"), uri))
+
+		assert.Equal(t, []string{"https://example.com/workflow"}, slices.Collect(maps.Keys(maps.Collect(store.List()))))
+	})
+}
+
+func TestLocalStoreGCEviction(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	// content and the time (relative to now) it was stored
+	entries := map[string]struct {
+		content string
+		age     time.Duration
+	}{
+		"https://example.com/old":    {"old workflow", 48 * time.Hour},
+		"https://example.com/recent": {"recent workflow", time.Hour},
+		"https://example.com/copy":   {"recent workflow", 2 * time.Hour},
+		"https://example.com/new":    {"new", 0},
+	}
+
+	testCases := []struct {
+		name     string
+		opts     []GCOption
+		index    string
+		expected []string
+	}{
+		{
+			name:     "no options",
+			expected: []string{"https://example.com/copy", "https://example.com/new", "https://example.com/old", "https://example.com/recent"},
+		},
+		{
+			name:     "max age",
+			opts:     []GCOption{WithMaxAge(24 * time.Hour)},
+			expected: []string{"https://example.com/copy", "https://example.com/new", "https://example.com/recent"},
+		},
+		{
+			name:     "max age without fetched time",
+			opts:     []GCOption{WithMaxAge(24 * time.Hour)},
+			index:    "https://example.com/unknown h1:7509e5bda0c762d2bac7f90d758b5b2263fa01ccbc542ab5e3df163be08e6ca9 12\n",
+			expected: []string{"https://example.com/copy", "https://example.com/new", "https://example.com/recent"},
+		},
+		{
+			// old (12) + recent/copy (15, shared) + new (3) = 30
+			name:     "max size evicts least recently stored",
+			opts:     []GCOption{WithMaxSize(18)},
+			expected: []string{"https://example.com/copy", "https://example.com/new", "https://example.com/recent"},
+		},
+		{
+			name:     "max size with shared content",
+			opts:     []GCOption{WithMaxSize(17)},
+			expected: []string{"https://example.com/new"},
+		},
+		{
+			name:     "max size already satisfied",
+			opts:     []GCOption{WithMaxSize(30)},
+			expected: []string{"https://example.com/copy", "https://example.com/new", "https://example.com/old", "https://example.com/recent"},
+		},
+		{
+			name:     "max size and max age",
+			opts:     []GCOption{WithMaxAge(24 * time.Hour), WithMaxSize(3)},
+			expected: []string{"https://example.com/new"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(fs, IndexFileName, []byte(tc.index), 0o644))
+
+			store, err := NewLocalStore(fs)
+			require.NoError(t, err)
+
+			for id, entry := range entries {
+				store.now = func() time.Time { return now.Add(-entry.age) }
+				uri, err := url.Parse(id)
+				require.NoError(t, err)
+				require.NoError(t, store.Store(strings.NewReader(entry.content), uri))
+			}
+			store.now = func() time.Time { return now }
+
+			require.NoError(t, store.GC(tc.opts...))
+
+			var ids []string
+			for id := range store.List() {
+				ids = append(ids, id)
+			}
+			slices.Sort(ids)
+			assert.Equal(t, tc.expected, ids)
+
+			// the index on disk matches, and only referenced files remain
+			f, err := fs.Open(IndexFileName)
+			require.NoError(t, err)
+			defer f.Close()
+			index, err := ParseIndex(f)
+			require.NoError(t, err)
+			assert.Equal(t, store.index, index)
+
+			files, err := afero.ReadDir(fs, ".")
+			require.NoError(t, err)
+			referenced := map[string]bool{}
+			for _, desc := range index {
+				referenced[desc.Hex] = true
+			}
+			for _, fi := range files {
+				if fi.Name() == IndexFileName {
+					continue
+				}
+				assert.True(t, referenced[fi.Name()], fi.Name())
+			}
+		})
+	}
+}
+
+func TestLocalStoreConcurrentProcesses(t *testing.T) {
+	fs := afero.NewBasePathFs(afero.NewOsFs(), t.TempDir())
+
+	// each store has its own index in memory and its own handle on the lock file, as separate processes would
+	n := 10
+	stores := make([]*LocalStore, n)
+	for i := range n {
+		store, err := NewLocalStore(fs)
+		require.NoError(t, err)
+		stores[i] = store
+	}
+
+	var wg sync.WaitGroup
+	for i, store := range stores {
+		wg.Go(func() {
+			uri := &url.URL{Scheme: "https", Host: "example.com", Path: fmt.Sprintf("/workflow-%d", i)}
+			assert.NoError(t, store.Store(strings.NewReader(fmt.Sprintf("workflow %d", i)), uri))
+		})
+	}
+	wg.Wait()
+
+	store, err := NewLocalStore(fs)
+	require.NoError(t, err)
+	assert.Len(t, store.index, n)
+
+	require.NoError(t, stores[0].GC())
+	assert.Len(t, stores[0].index, n)
+
+	for i := range n {
+		uri := &url.URL{Scheme: "https", Host: "example.com", Path: fmt.Sprintf("/workflow-%d", i)}
+		ok, err := store.Exists(uri)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+}
+
+func TestLocalStoreSharedIndex(t *testing.T) {
+	fs := afero.NewBasePathFs(afero.NewOsFs(), t.TempDir())
+
+	// separate instances, as separate processes sharing a store would have
+	writer, err := NewLocalStore(fs)
+	require.NoError(t, err)
+	reader, err := NewLocalStore(fs)
+	require.NoError(t, err)
+
+	uri := &url.URL{Scheme: "https", Host: "example.com", Path: "/workflow.yaml"}
+	require.NoError(t, writer.Store(strings.NewReader("hello world!"), uri))
+
+	ok, err := reader.Exists(uri)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	rc, err := reader.Fetch(t.Context(), uri)
+	require.NoError(t, err)
+	b, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	assert.Equal(t, "hello world!", string(b))
+
+	require.NoError(t, writer.Remove(uri))
+	require.NoError(t, writer.GC())
+
+	ok, err = reader.Exists(uri)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = reader.Fetch(t.Context(), uri)
+	require.EqualError(t, err, "descriptor not found")
+}
+
+func TestLocalStoreRemove(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	store, err := NewLocalStore(fs)
+	require.NoError(t, err)
+	store.now = func() time.Time { return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	a := &url.URL{Scheme: "https", Host: "example.com", Path: "/a.yaml"}
+	b := &url.URL{Scheme: "https", Host: "example.com", Path: "/b.yaml"}
+	require.NoError(t, store.Store(strings.NewReader("hello world!"), a))
+	require.NoError(t, store.Store(strings.NewReader("hello world!"), b))
+	digest := store.index["https://example.com/a.yaml"].Hex
+
+	err = store.Remove(&url.URL{Scheme: "https", Host: "example.com", Path: "/a.yaml", RawQuery: "task=foo"})
+	require.NoError(t, err)
+	assert.NotContains(t, store.index, "https://example.com/a.yaml")
+
+	indexContent, err := afero.ReadFile(fs, IndexFileName)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/b.yaml h1:"+digest+" 12 fetched=2025-01-01T00:00:00Z\n", string(indexContent))
+
+	// the file is still referenced by b.yaml
+	require.NoError(t, store.GC())
+	_, err = fs.Stat(digest)
+	require.NoError(t, err)
+
+	err = store.Remove(a)
+	require.EqualError(t, err, "https://example.com/a.yaml is not in the store")
+
+	require.NoError(t, store.Remove(b))
+	require.NoError(t, store.GC())
+	_, err = fs.Stat(digest)
+	require.ErrorIs(t, err, os.ErrNotExist)
+	assert.Empty(t, store.index)
+}
+
+func TestLocalStoreValidators(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	store, err := NewLocalStore(fs)
+	require.NoError(t, err)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	uri := &url.URL{Scheme: "https", Host: "example.com", Path: "/a.yaml"}
+	lastModified := time.Date(2024, 6, 1, 12, 0, 0, 0, time.FixedZone("EST", -5*60*60))
+	require.NoError(t, store.StoreValidated(strings.NewReader("hello world!"), uri, Validators{ETag: `W/"abc="`, LastModified: lastModified}))
+
+	desc, ok := store.Describe(&url.URL{Scheme: "https", Host: "example.com", Path: "/a.yaml", RawQuery: "task=foo"})
+	require.True(t, ok)
+	assert.Equal(t, Validators{ETag: `W/"abc="`, LastModified: lastModified.UTC()}, desc.Validators)
+
+	indexContent, err := afero.ReadFile(fs, IndexFileName)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/a.yaml h1:"+desc.Hex+` 12 fetched=2025-01-01T00:00:00Z etag=W/"abc=" last-modified=2024-06-01T17:00:00Z`+"\n", string(indexContent))
+
+	// validators survive a round trip through the index
+	reloaded, err := NewLocalStore(fs)
+	require.NoError(t, err)
+	assert.Equal(t, desc, reloaded.index["https://example.com/a.yaml"])
+
+	// revalidating updates when it was fetched, keeping the validators if none are given
+	now = now.Add(time.Hour)
+	require.NoError(t, store.Revalidated(uri, Validators{}))
+	desc, _ = store.Describe(uri)
+	assert.Equal(t, now, desc.Fetched)
+	assert.Equal(t, `W/"abc="`, desc.ETag)
+
+	require.NoError(t, store.Revalidated(uri, Validators{ETag: `"def"`}))
+	desc, _ = store.Describe(uri)
+	assert.Equal(t, Validators{ETag: `"def"`}, desc.Validators)
+
+	// malformed etags are not stored
+	require.NoError(t, store.StoreValidated(strings.NewReader("hello world!"), uri, Validators{ETag: `"a b"`}))
+	desc, _ = store.Describe(uri)
+	assert.Empty(t, desc.ETag)
+
+	err = store.Revalidated(&url.URL{Scheme: "https", Host: "example.com", Path: "/dne.yaml"}, Validators{})
+	require.EqualError(t, err, "https://example.com/dne.yaml is not in the store")
+}
+
+func TestLocalStoreStoreStreaming(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	store, err := NewLocalStore(fs)
+	require.NoError(t, err)
+
+	uri := &url.URL{Scheme: "https", Host: "example.com", Path: "/workflow"}
+
+	// a failed read leaves neither a partial file nor an index entry behind
+	err = store.Store(io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(errors.New("connection reset"))), uri)
+	require.EqualError(t, err, "connection reset")
+	assert.Empty(t, store.index)
+
+	files, err := afero.ReadDir(fs, ".")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, IndexFileName, files[0].Name())
+
+	// larger than any single read, so the content is hashed and written in chunks
+	content := strings.Repeat("a", 1<<20+1)
+	require.NoError(t, store.Store(iotest.HalfReader(strings.NewReader(content)), uri))
+
+	desc := store.index["https://example.com/workflow"]
+	assert.Equal(t, int64(len(content)), desc.Size)
+	sum := sha256.Sum256([]byte(content))
+	assert.Equal(t, hex.EncodeToString(sum[:]), desc.Hex)
+
+	fi, err := fs.Stat(desc.Hex)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), fi.Size())
+	assert.Equal(t, os.FileMode(0o644), fi.Mode().Perm())
+}
+
+func TestLocalStoreGCTempFiles(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	store, err := NewLocalStore(fs)
+	require.NoError(t, err)
+
+	require.NoError(t, afero.WriteFile(fs, "123.tmp", []byte("in progress"), 0o600))
+
+	// files being written by another process are left alone
+	require.NoError(t, store.GC())
+	_, err = fs.Stat("123.tmp")
+	require.NoError(t, err)
+
+	// unless they have been abandoned
+	store.now = func() time.Time { return time.Now().Add(staleTempAge) }
+	require.NoError(t, store.GC())
+	_, err = fs.Stat("123.tmp")
+	require.ErrorIs(t, err, os.ErrNotExist)
 }
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// WithProxy sets the proxy to be used by the fetcher service's HTTP client
+//
+// By default the proxy is read from the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables
+func WithProxy(proxy *url.URL) FetcherServiceOption {
+	return func(s *FetcherService) {
+		s.proxy = proxy
+	}
+}
+
+// WithRootCAs sets the certificate authorities used to verify servers, in place of the system's
+func WithRootCAs(pool *x509.CertPool) FetcherServiceOption {
+	return func(s *FetcherService) {
+		s.rootCAs = pool
+	}
+}
+
+// WithClientCertificates sets the certificates presented to servers that require mutual TLS
+func WithClientCertificates(certs ...tls.Certificate) FetcherServiceOption {
+	return func(s *FetcherService) {
+		s.clientCerts = certs
+	}
+}
+
+// LoadRootCAs loads PEM encoded certificate authorities from a file, appended to the system's certificate pool
+func LoadRootCAs(path string) (*x509.CertPool, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, fmt.Errorf("no certificates found in %q", path)
+	}
+
+	return pool, nil
+}
+
+// withTransport returns a shallow copy of the client with a transport configured by the fetcher service
+//
+// The client is returned as-is if there is nothing to configure
+func (s *FetcherService) withTransport(client *http.Client) (*http.Client, error) {
+	if s.proxy == nil && s.rootCAs == nil && len(s.clientCerts) == 0 {
+		return client, nil
+	}
+
+	var transport *http.Transport
+	switch base := client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = base.Clone()
+	default:
+		return nil, fmt.Errorf("unable to configure proxy or TLS settings on a %T transport", base)
+	}
+
+	if s.proxy != nil {
+		transport.Proxy = http.ProxyURL(s.proxy)
+	}
+
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	if s.rootCAs != nil {
+		transport.TLSClientConfig.RootCAs = s.rootCAs
+	}
+	if len(s.clientCerts) > 0 {
+		transport.TLSClientConfig.Certificates = s.clientCerts
+	}
+
+	clone := *client
+	clone.Transport = transport
+	return &clone, nil
+}
@@ -0,0 +1,164 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	clientCert := server.TLS.Certificates[0]
+
+	testCases := []struct {
+		name        string
+		opts        []FetcherServiceOption
+		expectedErr string
+	}{
+		{
+			name:        "untrusted server",
+			expectedErr: "certificate signed by unknown authority",
+		},
+		{
+			name:        "missing client certificate",
+			opts:        []FetcherServiceOption{WithRootCAs(pool)},
+			expectedErr: "certificate required",
+		},
+		{
+			name: "trusted server with client certificate",
+			opts: []FetcherServiceOption{WithRootCAs(pool), WithClientCertificates(clientCert)},
+		},
+		{
+			name: "with headers",
+			opts: []FetcherServiceOption{WithRootCAs(pool), WithClientCertificates(clientCert), WithHeaders(HostHeaders{"example.com": http.Header{}})},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, err := NewFetcherService(tc.opts...)
+			require.NoError(t, err)
+
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+
+			resp, err := svc.client.Do(req)
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+
+	t.Run("proxy", func(t *testing.T) {
+		var proxied string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied = r.URL.String()
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(proxy.Close)
+
+		proxyURL, err := url.Parse(proxy.URL)
+		require.NoError(t, err)
+
+		svc, err := NewFetcherService(WithProxy(proxyURL))
+		require.NoError(t, err)
+
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.com/workflow.yaml", nil)
+		require.NoError(t, err)
+
+		resp, err := svc.client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "http://example.com/workflow.yaml", proxied)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		base := &http.Client{}
+		svc, err := NewFetcherService(WithClient(base), WithTimeout(10*time.Millisecond))
+		require.NoError(t, err)
+		assert.Equal(t, 10*time.Millisecond, svc.client.Timeout)
+		assert.Zero(t, base.Timeout, "the given client is not modified")
+
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(slow.Close)
+
+		uri, err := url.Parse(slow.URL + "/workflow.yaml")
+		require.NoError(t, err)
+		fetcher, err := svc.GetFetcher(uri)
+		require.NoError(t, err)
+		_, err = fetcher.Fetch(t.Context(), uri)
+		require.ErrorContains(t, err, "Client.Timeout exceeded")
+	})
+
+	t.Run("unsupported transport", func(t *testing.T) {
+		_, err := NewFetcherService(
+			WithClient(&http.Client{Transport: &headerTransport{base: http.DefaultTransport}}),
+			WithRootCAs(pool),
+		)
+		require.EqualError(t, err, "unable to configure proxy or TLS settings on a *uses.headerTransport transport")
+	})
+}
+
+func TestLoadRootCAs(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+
+	ca := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(ca, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+
+	empty := filepath.Join(dir, "empty.pem")
+	require.NoError(t, os.WriteFile(empty, []byte("not a certificate"), 0o600))
+
+	pool, err := LoadRootCAs(ca)
+	require.NoError(t, err)
+
+	svc, err := NewFetcherService(WithRootCAs(pool))
+	require.NoError(t, err)
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := svc.client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	_, err = LoadRootCAs(empty)
+	require.EqualError(t, err, "no certificates found in \""+empty+"\"")
+
+	_, err = LoadRootCAs(filepath.Join(dir, "missing.pem"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
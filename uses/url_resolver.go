@@ -19,8 +19,15 @@ import (
 //
 // Handles multiple URL schemes (file, http, https, pkg, oci) with proper path resolution.
 // Supports package URL aliases, task parameters, and cross-scheme transitions.
+// Paths that name a directory (ending in a slash, "." or "..") resolve to the DefaultFileName within it,
+// FetcherService.ResolveRelative also resolves local directories that exist.
 // Returns resolved URL ready for fetching
 func ResolveRelative(prev *url.URL, u string, pkgAliases v1.AliasMap) (*url.URL, error) {
+	return resolveRelative(prev, u, pkgAliases, nil)
+}
+
+// resolveRelative is ResolveRelative, with local paths that isDir reports as a directory also resolving to the DefaultFileName within it
+func resolveRelative(prev *url.URL, u string, pkgAliases v1.AliasMap, isDir func(p string) bool) (*url.URL, error) {
 	uri, err := url.Parse(u)
 	if err != nil {
 		return nil, err
@@ -34,6 +41,7 @@ func ResolveRelative(prev *url.URL, u string, pkgAliases v1.AliasMap) (*url.URL,
 	}
 
 	if uri.Scheme == "file" && uri.Opaque == "" { // absolute path
+		uri.Path = resolveLocalDir(uri.Path, isDir)
 		return uri, nil
 	}
 
@@ -94,9 +102,11 @@ func ResolveRelative(prev *url.URL, u string, pkgAliases v1.AliasMap) (*url.URL,
 				return nil, err
 			}
 
-			if pURL.Subpath == "" {
-				pURL.Subpath = DefaultFileName
+			// the subpath is cleaned when parsed, dropping a trailing slash
+			if pURL.Subpath != "" && strings.HasSuffix(uri.Fragment, "/") {
+				pURL.Subpath += "/"
 			}
+			pURL.Subpath = resolveDir(pURL.Subpath)
 			if pURL.Version == "" {
 				pURL.Version = DefaultVersion
 			}
@@ -106,6 +116,9 @@ func ResolveRelative(prev *url.URL, u string, pkgAliases v1.AliasMap) (*url.URL,
 			}
 			return url.Parse(pURL.String())
 		}
+		if uri.Scheme == "file" {
+			uri.Opaque = resolveLocalDir(uri.Opaque, isDir)
+		}
 		return uri, nil
 
 	// file -> file
@@ -114,23 +127,18 @@ func ResolveRelative(prev *url.URL, u string, pkgAliases v1.AliasMap) (*url.URL,
 		if dir != "." {
 			next := &url.URL{
 				Scheme:   "file",
-				Opaque:   filepath.Join(dir, uri.Opaque),
+				Opaque:   resolveLocalDir(joinPath(dir, uri.Opaque), isDir),
 				RawQuery: uri.RawQuery,
 			}
-			if next.Opaque == "." {
-				next.Opaque = DefaultFileName
-			}
 			return next, nil
 		}
+		uri.Opaque = resolveLocalDir(uri.Opaque, isDir)
 		return uri, nil
 
 	// http(s) -> file
 	case (prev.Scheme == "https" || prev.Scheme == "http") && uri.Scheme == "file":
 		next := *prev // https://github.com/golang/go/issues/38351
-		next.Path = filepath.Join(filepath.Dir(prev.Path), uri.Opaque)
-		if next.Path == "." || next.Path == "/" {
-			next.Path = "/" + DefaultFileName
-		}
+		next.Path = resolveDir(joinPath(filepath.Dir(prev.Path), uri.Opaque))
 		next.RawQuery = uri.RawQuery
 		return &next, nil
 
@@ -141,10 +149,7 @@ func ResolveRelative(prev *url.URL, u string, pkgAliases v1.AliasMap) (*url.URL,
 			return nil, err
 		}
 
-		pURL.Subpath = filepath.Join(filepath.Dir(pURL.Subpath), uri.Opaque)
-		if pURL.Subpath == "." {
-			pURL.Subpath = DefaultFileName
-		}
+		pURL.Subpath = resolveDir(joinPath(filepath.Dir(pURL.Subpath), uri.Opaque))
 		if pURL.Version == "" {
 			pURL.Version = DefaultVersion
 		}
@@ -169,11 +174,7 @@ func ResolveRelative(prev *url.URL, u string, pkgAliases v1.AliasMap) (*url.URL,
 		switch uri.Scheme {
 		case "file":
 			// join the paths if they exist
-			path := filepath.Join(filepath.Dir(prev.Fragment), uri.Opaque)
-			if path == "." {
-				path = DefaultFileName
-			}
-			next.Fragment = path
+			next.Fragment = resolveDir(joinPath(filepath.Dir(prev.Fragment), uri.Opaque))
 
 			return &next, nil
 		default:
@@ -185,6 +186,33 @@ func ResolveRelative(prev *url.URL, u string, pkgAliases v1.AliasMap) (*url.URL,
 	return nil, fmt.Errorf("unable to resolve %q to %q", prev, uri)
 }
 
+// resolveDir resolves a path that names a directory to the DefaultFileName within it
+//
+// A path names a directory when it is empty, ends in a slash, or is "." or ".."
+func resolveDir(p string) string {
+	if p == "" || strings.HasSuffix(p, "/") || p == "." || p == ".." {
+		return filepath.Join(p, DefaultFileName)
+	}
+	return p
+}
+
+// resolveLocalDir is resolveDir for local paths, which also name a directory when isDir reports one at the path
+func resolveLocalDir(p string, isDir func(p string) bool) string {
+	if isDir != nil && isDir(p) {
+		return filepath.Join(p, DefaultFileName)
+	}
+	return resolveDir(p)
+}
+
+// joinPath joins elem onto dir, ending the result in a slash when elem ends in one
+func joinPath(dir, elem string) string {
+	p := filepath.Join(dir, elem)
+	if strings.HasSuffix(elem, "/") && !strings.HasSuffix(p, "/") {
+		p += "/"
+	}
+	return p
+}
+
 func escapeVersion(p string) string {
 	start := strings.Index(p, "@")
 	if start == -1 {
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -74,7 +75,7 @@ func TestResolveURL(t *testing.T) {
 			name: "file -> file with dot path",
 			prev: "file:foo.yaml",
 			uri:  "file:.",
-			next: "file:.",
+			next: "file:tasks.yaml",
 		},
 		{
 			name: "http -> http",
@@ -328,7 +329,7 @@ func TestResolveURL(t *testing.T) {
 			name: "relative file -> abs file",
 			prev: "file:foo/bar.yaml",
 			uri:  "file:/",
-			next: "file:/",
+			next: "file:/tasks.yaml",
 		},
 		{
 			name: "oci -> file",
@@ -354,6 +355,73 @@ func TestResolveURL(t *testing.T) {
 			uri:  "pkg:github/owner/repo@v1.0.0#dir/foo.yaml",
 			next: "oci:registry.uds.sh/maru2:latest#pkg:github/owner/repo@v1.0.0%23dir/foo.yaml",
 		},
+		{
+			name: "file -> directory with trailing slash",
+			prev: "file:foo.yaml",
+			uri:  "file:packages/api/",
+			next: "file:packages/api/tasks.yaml",
+		},
+		{
+			name: "file -> directory with trailing slash and task param",
+			prev: "file:dir/foo.yaml",
+			uri:  "file:packages/api.v2/?task=build",
+			next: "file:dir/packages/api.v2/tasks.yaml?task=build",
+		},
+		{
+			name: "nil prev: file without an extension",
+			uri:  "file:Taskfile",
+			next: "file:Taskfile",
+		},
+		{
+			name: "file -> file without an extension",
+			prev: "file:dir/foo.yaml",
+			uri:  "file:Taskfile?task=build",
+			next: "file:dir/Taskfile?task=build",
+		},
+		{
+			name: "nil prev: abs directory with trailing slash",
+			uri:  "file:/src/packages/api/",
+			next: "file:/src/packages/api/tasks.yaml",
+		},
+		{
+			name: "nil prev: pkg directory",
+			uri:  "pkg:github/owner/repo@v1.0.0#packages/api/",
+			next: "pkg:github/owner/repo@v1.0.0#packages/api/tasks.yaml",
+		},
+		{
+			name: "nil prev: pkg path without an extension",
+			uri:  "pkg:github/owner/repo@v1.0.0#packages/api",
+			next: "pkg:github/owner/repo@v1.0.0#packages/api",
+		},
+		{
+			name: "pkg -> file directory",
+			prev: "pkg:github/owner/repo@v1.0.0#packages/api/tasks.yaml",
+			uri:  "file:../web/",
+			next: "pkg:github/owner/repo@v1.0.0#packages/web/tasks.yaml",
+		},
+		{
+			name: "http -> file directory",
+			prev: "https://example.com/dir/bar.yaml",
+			uri:  "file:sub/",
+			next: "https://example.com/dir/sub/tasks.yaml",
+		},
+		{
+			name: "oci -> file directory",
+			prev: "oci:registry.uds.sh/maru2:latest#foo.yaml",
+			uri:  "file:packages/api/",
+			next: "oci:registry.uds.sh/maru2:latest#packages/api/tasks.yaml",
+		},
+		{
+			name: "alias path to directory",
+			prev: "file:foo.yaml",
+			uri:  "custom:task-name",
+			aliases: v1.AliasMap{
+				"custom": {
+					Path: "local/path/to/dir/",
+				},
+			},
+			next: "file:local/path/to/dir/tasks.yaml?task=task-name",
+		},
 		{
 			name: "alias path resolution",
 			prev: "file:foo.yaml",
@@ -620,3 +688,48 @@ func TestEscapeVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestFetcherServiceResolveRelative(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	require.NoError(t, fsys.MkdirAll("/src/packages/api", 0o755))
+	require.NoError(t, fsys.MkdirAll("/other/dir", 0o755))
+	require.NoError(t, afero.WriteFile(fsys, "/src/Taskfile", nil, 0o644))
+
+	svc, err := NewFetcherService(WithFS(fsys))
+	require.NoError(t, err)
+
+	// directories that exist resolve to their tasks.yaml, files without an extension are left as is
+	next, err := svc.ResolveRelative(nil, "file:/src/packages/api", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "file:/src/packages/api/tasks.yaml", next.String())
+
+	next, err = svc.ResolveRelative(nil, "file:/src/Taskfile", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "file:/src/Taskfile", next.String())
+
+	next, err = svc.ResolveRelative(&url.URL{Scheme: "file", Opaque: "/src/tasks.yaml"}, "file:packages/api?task=build", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "file:/src/packages/api/tasks.yaml?task=build", next.String())
+
+	// directories are only looked up in the service's filesystem
+	next, err = ResolveRelative(nil, "file:/src/packages/api", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "file:/src/packages/api", next.String())
+
+	// and within its file root
+	svc, err = NewFetcherService(WithFS(fsys), WithFileRoot("/src"))
+	require.NoError(t, err)
+
+	next, err = svc.ResolveRelative(nil, "file:/src/packages/api", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "file:/src/packages/api/tasks.yaml", next.String())
+
+	next, err = svc.ResolveRelative(&url.URL{Scheme: "file", Opaque: "/src/tasks.yaml"}, "file:../other/dir", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "file:/other/dir", next.String())
+
+	var nilSvc *FetcherService
+	next, err = nilSvc.ResolveRelative(nil, "file:/src/packages/api/", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "file:/src/packages/api/tasks.yaml", next.String())
+}
@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"context"
+	"io"
+	"net/url"
+)
+
+// VendorDirectory is the default location for vendored workflows, relative to the current working directory
+const VendorDirectory = ".maru2/vendor"
+
+// VendorFetcher is a fetcher that prefers workflows that have been vendored
+// into a store, falling back to another fetcher for everything else.
+//
+// Unlike StoreFetcher, the vendored store is never written to during fetches.
+type VendorFetcher struct {
+	Source Fetcher
+	Vendor Storage
+}
+
+// Fetch implements the Fetcher interface
+func (f *VendorFetcher) Fetch(ctx context.Context, uri *url.URL) (io.ReadCloser, error) {
+	exists, err := f.Vendor.Exists(uri)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return f.Vendor.Fetch(ctx, uri)
+	}
+	return f.Source.Fetch(ctx, uri)
+}
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2025-Present Defense Unicorns
+
+package uses
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVendorFetcher(t *testing.T) {
+	testCases := []struct {
+		name            string
+		setup           func(source *mockFetcher, vendor *mockStorage)
+		expectedContent string
+		expectedErr     string
+		sourceCalls     int
+		vendorCalls     int
+	}{
+		{
+			name: "vendored",
+			setup: func(_ *mockFetcher, vendor *mockStorage) {
+				vendor.existsFunc = func(_ *url.URL) (bool, error) {
+					return true, nil
+				}
+				vendor.fetchFunc = func(_ context.Context, _ *url.URL) (io.ReadCloser, error) {
+					return io.NopCloser(strings.NewReader("vendored")), nil
+				}
+			},
+			expectedContent: "vendored",
+			vendorCalls:     1,
+		},
+		{
+			name: "not vendored",
+			setup: func(source *mockFetcher, vendor *mockStorage) {
+				vendor.existsFunc = func(_ *url.URL) (bool, error) {
+					return false, nil
+				}
+				source.fetchFunc = func(_ context.Context, _ *url.URL) (io.ReadCloser, error) {
+					return io.NopCloser(strings.NewReader("source")), nil
+				}
+			},
+			expectedContent: "source",
+			sourceCalls:     1,
+		},
+		{
+			name: "vendor is corrupt",
+			setup: func(_ *mockFetcher, vendor *mockStorage) {
+				vendor.existsFunc = func(_ *url.URL) (bool, error) {
+					return false, errors.New("hash mismatch")
+				}
+			},
+			expectedErr: "hash mismatch",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			source := &mockFetcher{}
+			vendor := &mockStorage{}
+			tc.setup(source, vendor)
+
+			f := &VendorFetcher{Source: source, Vendor: vendor}
+
+			uri, err := url.Parse("https://example.com/tasks.yaml")
+			require.NoError(t, err)
+
+			rc, err := f.Fetch(t.Context(), uri)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			defer rc.Close()
+
+			b, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedContent, string(b))
+			assert.Equal(t, tc.sourceCalls, source.fetchCalls)
+			assert.Equal(t, tc.vendorCalls, vendor.fetchCalls)
+			assert.Equal(t, 0, vendor.storeCalls)
+		})
+	}
+}
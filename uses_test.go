@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -84,6 +86,55 @@ func TestFetchAll(t *testing.T) {
 			b, _ := yaml.Marshal(wf)
 			_, _ = w.Write(b)
 
+		case "/cycle-a.yaml":
+			b, _ := yaml.Marshal(v1.Workflow{
+				SchemaVersion: v1.SchemaVersion,
+				Tasks: v1.TaskMap{
+					"default": v1.Task{Steps: []v1.Step{{Uses: "file:cycle-b.yaml?task=build"}}},
+				},
+			})
+			_, _ = w.Write(b)
+
+		case "/cycle-b.yaml":
+			b, _ := yaml.Marshal(v1.Workflow{
+				SchemaVersion: v1.SchemaVersion,
+				Tasks: v1.TaskMap{
+					"build": v1.Task{Steps: []v1.Step{{Uses: "test"}}},
+					"test":  v1.Task{Steps: []v1.Step{{Uses: "file:cycle-a.yaml"}}},
+				},
+			})
+			_, _ = w.Write(b)
+
+		case "/retry.yaml":
+			b, _ := yaml.Marshal(v1.Workflow{
+				SchemaVersion: v1.SchemaVersion,
+				Tasks: v1.TaskMap{
+					"default": v1.Task{Steps: []v1.Step{{Run: "exit 1"}, {Uses: "again", If: "failure()"}}},
+					"again":   v1.Task{Steps: []v1.Step{{Uses: "default"}}},
+				},
+			})
+			_, _ = w.Write(b)
+
+		case "/mutual-a.yaml":
+			b, _ := yaml.Marshal(v1.Workflow{
+				SchemaVersion: v1.SchemaVersion,
+				Tasks: v1.TaskMap{
+					"default": v1.Task{Steps: []v1.Step{{Uses: "file:mutual-b.yaml?task=build"}}},
+					"lint":    v1.Task{Steps: []v1.Step{{Run: "echo 'lint'"}}},
+				},
+			})
+			_, _ = w.Write(b)
+
+		case "/mutual-b.yaml":
+			b, _ := yaml.Marshal(v1.Workflow{
+				SchemaVersion: v1.SchemaVersion,
+				Tasks: v1.TaskMap{
+					"build": v1.Task{Steps: []v1.Step{{Run: "echo 'build'"}}},
+					"check": v1.Task{Steps: []v1.Step{{Uses: "file:mutual-a.yaml?task=lint"}}},
+				},
+			})
+			_, _ = w.Write(b)
+
 		default:
 			w.WriteHeader(http.StatusNotFound)
 			_, _ = w.Write([]byte("not found"))
@@ -98,6 +149,45 @@ func TestFetchAll(t *testing.T) {
 		wf          v1.Workflow
 		expectedErr string
 	}{
+		{
+			name: "with cycle across remote references",
+			wf: v1.Workflow{
+				Tasks: v1.TaskMap{
+					"default": v1.Task{
+						Steps: []v1.Step{
+							{Uses: server.URL + "/cycle-a.yaml"},
+						},
+					},
+				},
+			},
+			expectedErr: fmt.Sprintf("uses cycle: %[1]s/cycle-a.yaml?task=default -> %[1]s/cycle-b.yaml?task=build -> %[1]s/cycle-b.yaml?task=test -> %[1]s/cycle-a.yaml?task=default", server.URL),
+		},
+		{
+			name: "with recursion within a workflow",
+			wf: v1.Workflow{
+				Tasks: v1.TaskMap{
+					"default": v1.Task{
+						Steps: []v1.Step{
+							{Run: "exit 1"},
+							{Uses: "default", If: "failure()"},
+							{Uses: server.URL + "/retry.yaml"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "with workflows referencing each other",
+			wf: v1.Workflow{
+				Tasks: v1.TaskMap{
+					"default": v1.Task{
+						Steps: []v1.Step{
+							{Uses: server.URL + "/mutual-a.yaml"},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "no references",
 			wf:   workflowNoRefs,
@@ -294,6 +384,200 @@ func TestFetchAll(t *testing.T) {
 	}
 }
 
+func TestFetchAllConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+
+		time.Sleep(50 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		if strings.HasPrefix(r.URL.Path, "/missing") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("schema-version: v1\ntasks:\n  default:\n    steps:\n      - run: echo\n"))
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	ctx := log.WithContext(t.Context(), log.New(io.Discard))
+
+	var steps []v1.Step
+	for i := range 6 {
+		steps = append(steps, v1.Step{Uses: fmt.Sprintf("%s/workflow%d.yaml", server.URL, i)})
+	}
+	wf := v1.Workflow{Tasks: v1.TaskMap{"default": v1.Task{Steps: steps}}}
+
+	for _, limit := range []int{1, 3} {
+		svc, err := uses.NewFetcherService(uses.WithClient(server.Client()), uses.WithConcurrency(limit))
+		require.NoError(t, err)
+
+		maxInFlight = 0
+		require.NoError(t, FetchAll(ctx, svc, wf, nil))
+		assert.Equal(t, limit, maxInFlight)
+	}
+
+	// errors are reported in task order, regardless of which fetch finished first
+	svc, err := uses.NewFetcherService(uses.WithClient(server.Client()))
+	require.NoError(t, err)
+	wf.Tasks["default"] = v1.Task{Steps: []v1.Step{
+		{Uses: server.URL + "/workflow0.yaml"},
+		{Uses: server.URL + "/missing1.yaml"},
+		{Uses: server.URL + "/missing2.yaml"},
+	}}
+	err = FetchAll(ctx, svc, wf, nil)
+	require.EqualError(t, err, fmt.Sprintf("get \"%s/missing1.yaml\": 404 Not Found", server.URL))
+}
+
+func TestFetchIncludes(t *testing.T) {
+	svc, err := uses.NewFetcherService(uses.WithClient(&http.Client{Timeout: time.Second}))
+	require.NoError(t, err)
+
+	files := map[string]string{
+		"/tasks.yaml": `
+schema-version: v1
+includes:
+  - path: ci/tasks.yaml
+    prefix: ci-
+tasks:
+  default:
+    steps:
+      - uses: ci-build
+`,
+		"/ci/tasks.yaml": `
+schema-version: v1
+includes:
+  - path: lib/tasks.yaml
+tasks:
+  build:
+    steps:
+      - uses: lint
+      - uses: file:scripts/release.yaml?task=release
+`,
+		"/ci/lib/tasks.yaml": `
+schema-version: v1
+tasks:
+  lint:
+    steps:
+      - run: echo lint
+`,
+		"/conflict.yaml": `
+schema-version: v1
+includes:
+  - path: ci/lib/tasks.yaml
+tasks:
+  lint:
+    steps:
+      - run: echo lint
+`,
+		"/cycle-a.yaml": `
+schema-version: v1
+includes:
+  - path: cycle-b.yaml
+tasks:
+  a:
+    steps:
+      - run: echo a
+`,
+		"/cycle-b.yaml": `
+schema-version: v1
+includes:
+  - path: cycle-a.yaml
+    prefix: b-
+tasks:
+  b:
+    steps:
+      - run: echo b
+`,
+		"/missing.yaml": `
+schema-version: v1
+includes:
+  - path: ci/lib/tasks.yaml
+tasks:
+  default:
+    steps:
+      - uses: build
+`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name          string
+		path          string
+		expectedTasks []string
+		expectedUses  map[string][]string
+		expectedErr   string
+	}{
+		{
+			name:          "nested includes with prefix",
+			path:          "/tasks.yaml",
+			expectedTasks: []string{"default", "ci-build", "ci-lint"},
+			expectedUses: map[string][]string{
+				"default":  {"ci-build"},
+				"ci-build": {"ci-lint", "file:ci/scripts/release.yaml?task=release"},
+			},
+		},
+		{
+			name:        "task conflict",
+			path:        "/conflict.yaml",
+			expectedErr: `.includes[0] ci/lib/tasks.yaml: task "lint" already exists`,
+		},
+		{
+			name:        "include cycle",
+			path:        "/cycle-a.yaml",
+			expectedErr: fmt.Sprintf(`.includes[0] cycle-b.yaml: .includes[0] include cycle: %[1]s/cycle-a.yaml -> %[1]s/cycle-b.yaml -> %[1]s/cycle-a.yaml`, server.URL),
+		},
+		{
+			name:        "uses not found after includes are merged",
+			path:        "/missing.yaml",
+			expectedErr: `.tasks.default[0].uses "build" not found`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := log.WithContext(t.Context(), log.New(io.Discard))
+
+			uri, err := url.Parse(server.URL + tc.path)
+			require.NoError(t, err)
+
+			wf, err := Fetch(ctx, svc, uri)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Empty(t, wf.Includes)
+			assert.ElementsMatch(t, tc.expectedTasks, wf.Tasks.OrderedTaskNames())
+			for name, expected := range tc.expectedUses {
+				var actual []string
+				for _, step := range wf.Tasks[name].Steps {
+					actual = append(actual, step.Uses)
+				}
+				assert.Equal(t, expected, actual)
+			}
+		})
+	}
+}
+
 func TestListAllLocal(t *testing.T) {
 	ctx := log.WithContext(t.Context(), log.New(io.Discard))
 
@@ -486,7 +770,7 @@ tasks:
 `,
 			},
 			srcURL:       "file:tasks.yaml",
-			expectedRefs: []string{"file:tasks.yaml", "file:dep.yaml", "file:alias-dep.yaml", "file:nested/alias-dep2.yaml"},
+			expectedRefs: []string{"file:tasks.yaml", "file:dep.yaml", "file:nested/alias-dep2.yaml", "file:alias-dep.yaml"},
 		},
 		{
 			name: "workflow with aliases without paths (only remote type aliases)",
@@ -550,7 +834,7 @@ tasks:
 `,
 			},
 			srcURL:       "file:tasks.yaml",
-			expectedRefs: []string{"file:tasks.yaml", "file:main-dep.yaml", "file:local-dep.yaml", "file:another-local-dep.yaml"},
+			expectedRefs: []string{"file:tasks.yaml", "file:main-dep.yaml", "file:another-local-dep.yaml", "file:local-dep.yaml"},
 		},
 		{
 			name: "workflow with alias pointing to non-existent file",
@@ -611,6 +895,29 @@ tasks:
 			srcURL:       "file:tasks.yaml",
 			expectedRefs: []string{"file:tasks.yaml", "file:valid-dep.yaml"},
 		},
+		{
+			name: "workflow with includes",
+			files: map[string]string{
+				"tasks.yaml": `
+schema-version: v1
+includes:
+  - path: ci/tasks.yaml
+tasks:
+  main:
+    steps:
+      - uses: build
+`,
+				"ci/tasks.yaml": `
+schema-version: v1
+tasks:
+  build:
+    steps:
+      - run: "echo build"
+`,
+			},
+			srcURL:       "file:tasks.yaml",
+			expectedRefs: []string{"file:tasks.yaml", "file:ci/tasks.yaml"},
+		},
 	}
 
 	for _, tc := range tests {
@@ -634,7 +941,7 @@ tasks:
 			}
 
 			require.NoError(t, err)
-			assert.ElementsMatch(t, tc.expectedRefs, refs)
+			assert.Equal(t, tc.expectedRefs, refs)
 		})
 	}
 }
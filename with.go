@@ -5,10 +5,18 @@ package maru2
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"maps"
+	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"runtime"
 	"slices"
@@ -18,6 +26,7 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
+	"github.com/goccy/go-yaml"
 	"github.com/spf13/cast"
 
 	"github.com/defenseunicorns/maru2/schema"
@@ -33,9 +42,158 @@ func RegisterWhichShortcut(short, long string) {
 	shortcuts.Store(short, long)
 }
 
+// hashFiles returns the sha256 digest of the files matching the glob patterns, for use in cache keys
+//
+// Files are hashed by name and content in lexical order, so the digest only changes when the files do.
+// Directories are skipped, and patterns that match no files are an error
+func hashFiles(patterns ...string) (string, error) {
+	var matches []string
+	for _, pattern := range patterns {
+		m, err := filepath.Glob(pattern)
+		if err != nil {
+			return "", err
+		}
+		matches = append(matches, m...)
+	}
+	slices.Sort(matches)
+	matches = slices.Compact(matches)
+
+	hasher := sha256.New()
+	hashed := 0
+	for _, match := range matches {
+		fi, err := os.Stat(match)
+		if err != nil {
+			return "", err
+		}
+		if fi.IsDir() {
+			continue
+		}
+
+		f, err := os.Open(match)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(hasher, "%s\x00%d\x00", filepath.ToSlash(match), fi.Size())
+		_, err = io.Copy(hasher, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+		hashed++
+	}
+
+	if hashed == 0 {
+		return "", fmt.Errorf("no files match %q", patterns)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// lookupEnv returns the value of an environment variable, or the optional fallback if it is not set
+func lookupEnv(name string, fallback ...string) (string, error) {
+	if len(fallback) > 1 {
+		return "", fmt.Errorf("env %q accepts at most one default, got %d", name, len(fallback))
+	}
+	if v, ok := os.LookupEnv(name); ok {
+		return v, nil
+	}
+	if len(fallback) == 1 {
+		return fallback[0], nil
+	}
+	return "", fmt.Errorf("environment variable %q is not set", name)
+}
+
+// fromJSON parses a JSON document, such as a step output, into maps, slices and scalars
+func fromJSON(v any) (any, error) {
+	str, err := structuredInput("fromJSON", v)
+	if err != nil || str == "" {
+		return nil, err
+	}
+	var parsed any
+	if err := json.Unmarshal([]byte(str), &parsed); err != nil {
+		return nil, fmt.Errorf("fromJSON: %w", err)
+	}
+	return parsed, nil
+}
+
+// fromYAML parses a YAML document, such as a step output, into maps, slices and scalars
+func fromYAML(v any) (any, error) {
+	str, err := structuredInput("fromYAML", v)
+	if err != nil || str == "" {
+		return nil, err
+	}
+	var parsed any
+	if err := yaml.Unmarshal([]byte(str), &parsed); err != nil {
+		return nil, fmt.Errorf("fromYAML: %w", err)
+	}
+	return parsed, nil
+}
+
+// toJSON encodes a value as compact JSON
+func toJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("toJSON: %w", err)
+	}
+	return string(b), nil
+}
+
+// structuredInput returns the document to parse, nil (e.g. a missing output during a dry run) is an empty document
+func structuredInput(fn string, v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return strings.TrimSpace(v), nil
+	case []byte:
+		return strings.TrimSpace(string(v)), nil
+	default:
+		return "", fmt.Errorf("%s: expected a string, got %T", fn, v)
+	}
+}
+
+type workflowOriginKey struct{}
+
+type taskNameKey struct{}
+
+// withWorkflowOrigin returns a context carrying the location of the workflow whose steps are being templated
+func withWorkflowOrigin(ctx context.Context, origin *url.URL) context.Context {
+	return context.WithValue(ctx, workflowOriginKey{}, origin)
+}
+
+// workflowOrigin returns the location of the workflow whose steps are being templated, or nil if unknown
+func workflowOrigin(ctx context.Context) *url.URL {
+	origin, _ := ctx.Value(workflowOriginKey{}).(*url.URL)
+	return origin
+}
+
+// withTaskName returns a context carrying the name of the task whose steps are being templated
+func withTaskName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, taskNameKey{}, name)
+}
+
+// taskName returns the name of the task whose steps are being templated
+func taskName(ctx context.Context) string {
+	name, _ := ctx.Value(taskNameKey{}).(string)
+	return name
+}
+
+type lenientTemplatesKey struct{}
+
+// withTemplateMode returns a context whose templates are rendered in the given mode
+func withTemplateMode(ctx context.Context, mode string) context.Context {
+	return context.WithValue(ctx, lenientTemplatesKey{}, mode == v1.TemplateModeLenient)
+}
+
+// lenientTemplates reports whether templates render missing inputs and outputs as empty strings
+func lenientTemplates(ctx context.Context) bool {
+	lenient, _ := ctx.Value(lenientTemplatesKey{}).(bool)
+	return lenient
+}
+
 // TemplateString expands templates in str using Go's text/template engine
 //
-// In dry run mode, missing inputs and outputs are rendered with special markers
+// In dry run mode, missing inputs and outputs are rendered with special markers.
+// In lenient mode (template-mode: lenient), they are rendered as empty strings with a warning
 func TemplateString(ctx context.Context, str string, with schema.With, previousOutputs CommandOutputs, dry bool) (string, error) {
 	var tmpl *template.Template
 
@@ -47,6 +205,8 @@ func TemplateString(ctx context.Context, str string, with schema.With, previousO
 
 	logger := log.FromContext(ctx)
 
+	secrets := secretsFromContext(ctx)
+
 	which := func(key string) (string, error) {
 		value, ok := shortcuts.Load(key)
 		if !ok {
@@ -61,6 +221,19 @@ func TemplateString(ctx context.Context, str string, with schema.With, previousO
 		return full, nil
 	}
 
+	// hashFiles fails the same as file when the workflow is not allowed to read local files
+	hashFiles := func(patterns ...string) (string, error) {
+		return hashTemplateFiles(ctx, patterns...)
+	}
+
+	// steps returns the outputs of every previous step, keyed by step id
+	steps := func() CommandOutputs {
+		if previousOutputs == nil {
+			return CommandOutputs{}
+		}
+		return previousOutputs
+	}
+
 	if dry {
 		style := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFBF00")) // amber
 
@@ -87,31 +260,82 @@ func TemplateString(ctx context.Context, str string, with schema.With, previousO
 				logger.Warnf("no output %q from %q", id, stepName)
 				return style.Render(fmt.Sprintf("❯ from %s %s ❮", stepName, id)), nil
 			},
-			"which": which,
+			"which":     which,
+			"hashFiles": hashFiles,
+			"fromJSON":  fromJSON,
+			"fromYAML":  fromYAML,
+			"toJSON":    toJSON,
+			"steps":     steps,
+			"secret": func(name string) (any, error) {
+				if _, ok := secrets[name]; !ok {
+					logger.Warnf("secret %q was not provided, available: %s", name, secrets.Names())
+				}
+				return style.Render(fmt.Sprintf("❯ secret %s ❮", name)), nil
+			},
+			"env": func(name string, fallback ...string) (any, error) {
+				if _, err := lookupEnv(name, fallback...); err != nil {
+					logger.Warn(err.Error())
+				}
+				return style.Render(fmt.Sprintf("❯ env %s ❮", name)), nil
+			},
+			"file": func(path string) (any, error) {
+				if err := checkFileRead(ctx, path); err != nil {
+					return "", err
+				}
+				if _, err := os.Stat(path); err != nil {
+					logger.Warn(err.Error())
+				}
+				return style.Render(fmt.Sprintf("❯ file %s ❮", path)), nil
+			},
 		}
 		tmpl = template.New("dry-run expression evaluator").Funcs(fm)
 	} else {
+		lenient := lenientTemplates(ctx)
+		missing := func(err error) (any, error) {
+			if lenient {
+				logger.Warn(err.Error() + ", rendering an empty string")
+				return "", nil
+			}
+			return "", err
+		}
+
 		fm := template.FuncMap{
 			"input": func(in string) (any, error) {
 				v, ok := with[in]
 				if !ok {
-					return "", fmt.Errorf("input %q does not exist in %s", in, inputKeys)
+					return missing(fmt.Errorf("input %q does not exist in %s", in, inputKeys))
 				}
 				return v, nil
 			},
 			"from": func(stepName, id string) (any, error) {
 				stepOutputs, ok := previousOutputs[stepName]
 				if !ok {
-					return "", fmt.Errorf("no outputs from step %q", stepName)
+					return missing(fmt.Errorf("no outputs from step %q", stepName))
 				}
 
 				v, ok := stepOutputs[id]
 				if ok {
 					return v, nil
 				}
-				return "", fmt.Errorf("no output %q from step %q", id, stepName)
+				return missing(fmt.Errorf("no output %q from step %q", id, stepName))
+			},
+			"which":     which,
+			"hashFiles": hashFiles,
+			"fromJSON":  fromJSON,
+			"fromYAML":  fromYAML,
+			"toJSON":    toJSON,
+			"steps":     steps,
+			"secret": func(name string) (any, error) {
+				v, ok := secrets[name]
+				if !ok {
+					return "", fmt.Errorf("secret %q does not exist in %s", name, secrets.Names())
+				}
+				return v, nil
+			},
+			"env": lookupEnv,
+			"file": func(path string) (string, error) {
+				return readTemplateFile(ctx, path)
 			},
-			"which": which,
 		}
 		tmpl = template.New("expression evaluator").Funcs(fm)
 	}
@@ -124,14 +348,32 @@ func TemplateString(ctx context.Context, str string, with schema.With, previousO
 
 	var result strings.Builder
 
+	// the task is already given by TASK_NAME
+	var origin string
+	if u := workflowOrigin(ctx); u != nil {
+		clone := *u
+		q := clone.Query()
+		q.Del("task")
+		clone.RawQuery = q.Encode()
+		origin = clone.String()
+	}
+
 	if err := tmpl.Execute(&result, struct {
-		OS       string
-		ARCH     string
-		PLATFORM string
+		OS              string
+		ARCH            string
+		PLATFORM        string
+		RUN_ID          string //nolint:revive
+		TASK_NAME       string //nolint:revive
+		WORKFLOW_ORIGIN string //nolint:revive
+		DRY_RUN         bool   //nolint:revive
 	}{
-		OS:       runtime.GOOS,
-		ARCH:     runtime.GOARCH,
-		PLATFORM: fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+		OS:              runtime.GOOS,
+		ARCH:            runtime.GOARCH,
+		PLATFORM:        fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+		RUN_ID:          RunID(ctx),
+		TASK_NAME:       taskName(ctx),
+		WORKFLOW_ORIGIN: origin,
+		DRY_RUN:         dry,
 	}); err != nil {
 		return "", err
 	}
@@ -223,7 +465,9 @@ func MergeWithAndParams(ctx context.Context, with schema.With, params v1.InputMa
 
 		// provided > default from env > default > dne
 		if _, ok := merged[name]; !ok {
-			if required && merged[name] == nil && param.Default == nil && param.DefaultFromEnv == "" {
+			// a required input is satisfied by providing an input it conflicts with instead
+			satisfied := slices.ContainsFunc(params.Conflicts(name), func(other string) bool { return with[other] != nil })
+			if required && !satisfied && merged[name] == nil && param.Default == nil && param.DefaultFromEnv == "" {
 				return nil, fmt.Errorf("missing required input: %q", name)
 			}
 			if merged == nil {
@@ -319,7 +563,64 @@ func MergeWithAndParams(ctx context.Context, with schema.With, params v1.InputMa
 				return nil, fmt.Errorf("failed to validate: input=%s, value=%s, regexp=%s", name, merged[name], param.Validate)
 			}
 		}
+
+		if param.Type == v1.InputTypePath && param.Path != nil && merged[name] != nil {
+			if err := checkPathInput(name, merged[name], *param.Path); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// relationships are checked against the inputs that were provided, not their defaults
+	for name, param := range params.OrderedSeq() {
+		if with[name] == nil {
+			continue
+		}
+		for _, other := range params.Conflicts(name) {
+			if with[other] != nil {
+				return nil, fmt.Errorf("input %q conflicts with %q, only one can be provided", name, other)
+			}
+		}
+		for _, other := range param.Requires {
+			if merged[other] == nil {
+				return nil, fmt.Errorf("input %q requires %q", name, other)
+			}
+		}
 	}
 
 	return merged, nil
 }
+
+// checkPathInput checks the value of a path input against its path options
+//
+// Relative paths are resolved from the directory maru2 runs in
+func checkPathInput(name string, value any, opts v1.PathOptions) error {
+	p, err := cast.ToE[string](value)
+	if err != nil {
+		return err
+	}
+
+	if len(opts.Extensions) > 0 && !slices.ContainsFunc(opts.Extensions, func(ext string) bool { return strings.HasSuffix(p, ext) }) {
+		return fmt.Errorf("input %q must be a path ending in one of [%s], got %q", name, strings.Join(opts.Extensions, ", "), p)
+	}
+
+	if !opts.MustExist && opts.Kind == "" {
+		return nil
+	}
+
+	fi, err := os.Stat(p)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("input %q must be a path that exists, %q does not", name, p)
+		}
+		return fmt.Errorf("input %q: %w", name, err)
+	}
+
+	switch {
+	case opts.Kind == "file" && fi.IsDir():
+		return fmt.Errorf("input %q must be a file, %q is a directory", name, p)
+	case opts.Kind == "directory" && !fi.IsDir():
+		return fmt.Errorf("input %q must be a directory, %q is a file", name, p)
+	}
+	return nil
+}
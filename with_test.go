@@ -4,8 +4,13 @@
 package maru2
 
 import (
+	"fmt"
 	"io"
+	"net/url"
+	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/charmbracelet/log"
@@ -21,6 +26,7 @@ func TestTemplateString(t *testing.T) {
 		name           string
 		input          schema.With
 		previousOutput CommandOutputs
+		secrets        Secrets
 		str            string
 		expected       string
 		expectedError  string
@@ -74,6 +80,11 @@ func TestTemplateString(t *testing.T) {
 			str:      "PLATFORM: ${{ .PLATFORM }}",
 			expected: "PLATFORM: " + runtime.GOOS + "/" + runtime.GOARCH,
 		},
+		{
+			name:     "with RUN_ID variable",
+			str:      "RUN_ID: ${{ .RUN_ID }}",
+			expected: "RUN_ID: 01ARZ3NDEKTSV4RRFFQ69G5FAV",
+		},
 		{
 			name:  "with multiple variables",
 			input: schema.With{"name": "test"},
@@ -114,6 +125,25 @@ func TestTemplateString(t *testing.T) {
 			expectedError: "exec: \"missing\": executable file not found in $PATH",
 			dryRun:        true,
 		},
+		{
+			name:     "with secret",
+			secrets:  Secrets{"token": "s3cr3t"},
+			str:      "token: ${{ secret \"token\" }}",
+			expected: "token: s3cr3t",
+		},
+		{
+			name:          "with missing secret",
+			secrets:       Secrets{"token": "s3cr3t"},
+			str:           "token: ${{ secret \"missing\" }}",
+			expectedError: "secret \"missing\" does not exist in [token]",
+		},
+		{
+			name:     "dry run - with secret",
+			secrets:  Secrets{"token": "s3cr3t"},
+			str:      "token: ${{ secret \"token\" }}",
+			expected: "token: ❯ secret token ❮",
+			dryRun:   true,
+		},
 		{
 			name:     "dry run - no template",
 			str:      "hello world",
@@ -180,6 +210,57 @@ func TestTemplateString(t *testing.T) {
 			expected: "Hello test, status: success, OS: " + runtime.GOOS,
 			dryRun:   true,
 		},
+		{
+			name:           "fromJSON of a step output",
+			str:            `${{ (fromJSON (from "api" "body")).id }} ${{ index (fromJSON (from "api" "body")).tags 1 }}`,
+			previousOutput: CommandOutputs{"api": map[string]any{"body": `{"id": 42, "tags": ["a", "b"]}`}},
+			expected:       "42 b",
+		},
+		{
+			name:           "fromYAML of a step output",
+			str:            `${{ (fromYAML (from "kubectl" "status")).phase }}`,
+			previousOutput: CommandOutputs{"kubectl": map[string]any{"status": "phase: Running\n"}},
+			expected:       "Running",
+		},
+		{
+			name:           "toJSON of a step output",
+			str:            `${{ toJSON (from "api" "json") }}`,
+			previousOutput: CommandOutputs{"api": map[string]any{"json": map[string]any{"id": 42}}},
+			expected:       `{"id":42}`,
+		},
+		{
+			name:           "steps exposes every output",
+			str:            `${{ index steps "build" "version" }} ${{ range $id, $out := steps }}${{ $id }}=${{ len $out }};${{ end }} ${{ toJSON (index steps "build") }}`,
+			previousOutput: CommandOutputs{"build": map[string]any{"version": "1.0", "arch": "amd64"}, "test": map[string]any{"passed": true}},
+			expected:       `1.0 build=2;test=1; {"arch":"amd64","version":"1.0"}`,
+		},
+		{
+			name:     "steps without outputs",
+			str:      `${{ len steps }}`,
+			expected: "0",
+		},
+		{
+			name:           "steps during a dry run",
+			str:            `${{ (steps).build.version }}`,
+			previousOutput: CommandOutputs{"build": map[string]any{"version": "1.0"}},
+			expected:       "1.0",
+			dryRun:         true,
+		},
+		{
+			name:          "fromJSON of invalid JSON",
+			str:           `${{ fromJSON "{" }}`,
+			expectedError: "fromJSON: unexpected end of JSON input",
+		},
+		{
+			name:          "fromJSON of a non-string",
+			str:           `${{ fromJSON 1 }}`,
+			expectedError: "fromJSON: expected a string, got int",
+		},
+		{
+			name:          "hashFiles with no matches",
+			str:           `${{ hashFiles "testdata/does-not-exist/*" }}`,
+			expectedError: `no files match ["testdata/does-not-exist/*"]`,
+		},
 		{
 			name:          "dry run - invalid template syntax",
 			str:           "Hello ${{ input",
@@ -199,6 +280,8 @@ func TestTemplateString(t *testing.T) {
 			t.Parallel()
 
 			ctx := log.WithContext(t.Context(), log.New(io.Discard))
+			ctx = WithRunID(ctx, "01ARZ3NDEKTSV4RRFFQ69G5FAV")
+			ctx = WithSecrets(ctx, tc.secrets)
 
 			result, err := TemplateString(ctx, tc.str, tc.input, tc.previousOutput, tc.dryRun)
 
@@ -213,6 +296,118 @@ func TestTemplateString(t *testing.T) {
 	}
 }
 
+func TestTemplateStringLenient(t *testing.T) {
+	var buf strings.Builder
+	ctx := log.WithContext(t.Context(), log.New(&buf))
+	ctx = withTemplateMode(ctx, v1.TemplateModeLenient)
+
+	result, err := TemplateString(ctx, `make ${{ input "target" }} FLAGS="${{ from "flags" "value" }}" V=${{ input "verbose" }}`, schema.With{"verbose": 1}, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, `make  FLAGS="" V=1`, result)
+	assert.Equal(t, "WARN input \"target\" does not exist in [verbose], rendering an empty string\nWARN no outputs from step \"flags\", rendering an empty string\n", buf.String())
+
+	// secrets are never lenient
+	_, err = TemplateString(ctx, `${{ secret "token" }}`, nil, nil, false)
+	require.ErrorContains(t, err, `secret "token" does not exist`)
+
+	_, err = TemplateString(withTemplateMode(ctx, v1.TemplateModeStrict), `${{ input "target" }}`, schema.With{}, nil, false)
+	require.ErrorContains(t, err, `input "target" does not exist in []`)
+}
+
+func TestTemplateStringMetadata(t *testing.T) {
+	ctx := log.WithContext(t.Context(), log.New(io.Discard))
+	ctx = WithRunID(ctx, "01ARZ3NDEKTSV4RRFFQ69G5FAV")
+
+	str := `${{ .TASK_NAME }} ${{ .WORKFLOW_ORIGIN }} ${{ .RUN_ID }} ${{ if .DRY_RUN }}dry${{ else }}live${{ end }}`
+
+	result, err := TemplateString(ctx, str, nil, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, "  01ARZ3NDEKTSV4RRFFQ69G5FAV live", result)
+
+	ctx = withTaskName(ctx, "build")
+	ctx = withWorkflowOrigin(ctx, &url.URL{Scheme: "file", Opaque: "tasks.yaml"})
+
+	result, err = TemplateString(ctx, str, nil, nil, true)
+	require.NoError(t, err)
+	assert.Equal(t, "build file:tasks.yaml 01ARZ3NDEKTSV4RRFFQ69G5FAV dry", result)
+}
+
+func TestHashFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	require.NoError(t, os.MkdirAll("a/b", 0o755))
+	require.NoError(t, os.WriteFile("go.sum", []byte("sum"), 0o644))
+	require.NoError(t, os.WriteFile("a/b/package-lock.json", []byte("lock"), 0o644))
+
+	sum, err := hashFiles("go.sum")
+	require.NoError(t, err)
+	assert.Len(t, sum, 64)
+
+	// directories and duplicate matches are ignored
+	again, err := hashFiles("*", "go.sum")
+	require.NoError(t, err)
+	assert.Equal(t, sum, again)
+
+	both, err := hashFiles("go.sum", "a/*/package-lock.json")
+	require.NoError(t, err)
+	assert.NotEqual(t, sum, both)
+
+	// the order of patterns does not matter
+	reversed, err := hashFiles("a/*/package-lock.json", "go.sum")
+	require.NoError(t, err)
+	assert.Equal(t, both, reversed)
+
+	require.NoError(t, os.WriteFile("go.sum", []byte("changed"), 0o644))
+	changed, err := hashFiles("go.sum")
+	require.NoError(t, err)
+	assert.NotEqual(t, sum, changed)
+
+	_, err = hashFiles("missing")
+	require.EqualError(t, err, `no files match ["missing"]`)
+
+	_, err = hashFiles("[")
+	require.EqualError(t, err, "syntax error in pattern")
+}
+
+func TestTemplateEnvAndFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("MARU2_TEST_TOKEN", "hunter2")
+	require.NoError(t, os.WriteFile("VERSION", []byte("v1.2.3\n"), 0o644))
+
+	ctx := log.WithContext(t.Context(), log.New(io.Discard))
+
+	result, err := TemplateString(ctx, `${{ env "MARU2_TEST_TOKEN" }} ${{ env "MARU2_TEST_UNSET" "fallback" }} ${{ file "VERSION" }}`, nil, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2 fallback v1.2.3", result)
+
+	_, err = TemplateString(ctx, `${{ env "MARU2_TEST_UNSET" }}`, nil, nil, false)
+	require.ErrorContains(t, err, `environment variable "MARU2_TEST_UNSET" is not set`)
+
+	_, err = TemplateString(ctx, `${{ env "MARU2_TEST_UNSET" "a" "b" }}`, nil, nil, false)
+	require.ErrorContains(t, err, `env "MARU2_TEST_UNSET" accepts at most one default, got 2`)
+
+	_, err = TemplateString(ctx, `${{ file "missing" }}`, nil, nil, false)
+	require.ErrorContains(t, err, "open missing: no such file or directory")
+
+	// values are never shown during a dry run
+	result, err = TemplateString(ctx, `${{ env "MARU2_TEST_TOKEN" }} ${{ file "missing" }}`, nil, nil, true)
+	require.NoError(t, err)
+	assert.Equal(t, "❯ env MARU2_TEST_TOKEN ❮ ❯ file missing ❮", result)
+
+	// file reads are only disabled for remote workflows
+	ctx = WithoutRemoteFileReads(ctx)
+
+	result, err = TemplateString(withWorkflowOrigin(ctx, &url.URL{Scheme: "file", Opaque: "tasks.yaml"}), `${{ file "VERSION" }}`, nil, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.3", result)
+
+	remote := withWorkflowOrigin(ctx, &url.URL{Scheme: "https", Host: "example.com", Path: "/tasks.yaml"})
+	for _, dry := range []bool{false, true} {
+		_, err = TemplateString(remote, `${{ file "VERSION" }}`, nil, nil, dry)
+		require.ErrorContains(t, err, `unable to read "VERSION": file reads are disabled for remote workflows (https://example.com/tasks.yaml)`)
+	}
+}
+
 func TestMergeWithAndParams(t *testing.T) {
 	requiredFalse := false
 	requiredTrue := true
@@ -243,6 +438,60 @@ func TestMergeWithAndParams(t *testing.T) {
 			},
 			expected: schema.With{},
 		},
+		{
+			name: "required input satisfied by a conflicting input",
+			with: schema.With{"tag": "v1.0.0"},
+			params: v1.InputMap{
+				"tag":    v1.InputParameter{ConflictsWith: []string{"branch"}},
+				"branch": v1.InputParameter{},
+			},
+			expected: schema.With{"tag": "v1.0.0"},
+		},
+		{
+			name: "neither of conflicting inputs",
+			with: schema.With{},
+			params: v1.InputMap{
+				"tag":    v1.InputParameter{ConflictsWith: []string{"branch"}},
+				"branch": v1.InputParameter{Required: &requiredFalse},
+			},
+			expectedError: `missing required input: "tag"`,
+		},
+		{
+			name: "both of conflicting inputs",
+			with: schema.With{"tag": "v1.0.0", "branch": "main"},
+			params: v1.InputMap{
+				"tag":    v1.InputParameter{ConflictsWith: []string{"branch"}},
+				"branch": v1.InputParameter{},
+			},
+			expectedError: `input "branch" conflicts with "tag", only one can be provided`,
+		},
+		{
+			name: "conflicting input with a default is not provided",
+			with: schema.With{"branch": "main"},
+			params: v1.InputMap{
+				"tag":    v1.InputParameter{ConflictsWith: []string{"branch"}, Default: "latest"},
+				"branch": v1.InputParameter{Required: &requiredFalse},
+			},
+			expected: schema.With{"tag": "latest", "branch": "main"},
+		},
+		{
+			name: "required input provided",
+			with: schema.With{"username": "admin", "password": "hunter2"},
+			params: v1.InputMap{
+				"username": v1.InputParameter{Required: &requiredFalse, Requires: []string{"password"}},
+				"password": v1.InputParameter{Required: &requiredFalse},
+			},
+			expected: schema.With{"username": "admin", "password": "hunter2"},
+		},
+		{
+			name: "required input missing",
+			with: schema.With{"username": "admin"},
+			params: v1.InputMap{
+				"username": v1.InputParameter{Required: &requiredFalse, Requires: []string{"password"}},
+				"password": v1.InputParameter{Required: &requiredFalse},
+			},
+			expectedError: `input "username" requires "password"`,
+		},
 		{
 			name: "with default values",
 			with: schema.With{},
@@ -1656,3 +1905,66 @@ func TestPerformLookups(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckPathInput(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("kind: Deployment"), 0o644))
+
+	tests := []struct {
+		name          string
+		value         any
+		opts          v1.PathOptions
+		expectedError string
+	}{
+		{
+			name:  "no options",
+			value: filepath.Join(dir, "missing"),
+		},
+		{
+			name:  "existing file",
+			value: file,
+			opts:  v1.PathOptions{MustExist: true, Kind: "file", Extensions: []string{".yml", ".yaml"}},
+		},
+		{
+			name:  "existing directory",
+			value: dir,
+			opts:  v1.PathOptions{Kind: "directory"},
+		},
+		{
+			name:          "missing path",
+			value:         filepath.Join(dir, "missing"),
+			opts:          v1.PathOptions{MustExist: true},
+			expectedError: fmt.Sprintf(`input "p" must be a path that exists, %q does not`, filepath.Join(dir, "missing")),
+		},
+		{
+			name:          "file is not a directory",
+			value:         file,
+			opts:          v1.PathOptions{Kind: "directory"},
+			expectedError: fmt.Sprintf(`input "p" must be a directory, %q is a file`, file),
+		},
+		{
+			name:          "directory is not a file",
+			value:         dir,
+			opts:          v1.PathOptions{Kind: "file"},
+			expectedError: fmt.Sprintf(`input "p" must be a file, %q is a directory`, dir),
+		},
+		{
+			name:          "wrong extension",
+			value:         "notes.txt",
+			opts:          v1.PathOptions{Extensions: []string{".yaml", ".yml"}},
+			expectedError: `input "p" must be a path ending in one of [.yaml, .yml], got "notes.txt"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkPathInput("p", tc.value, tc.opts)
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}